@@ -0,0 +1,42 @@
+// Token of user calendar feed: feed URL /api/v1/users/{user_id}/calendar.ics?token=... can be shared
+// with calendar app while feed of other users stays unavailable
+package main
+
+import (
+	"em_golang_rest_service_example/internal/config"
+	"em_golang_rest_service_example/internal/model"
+
+	"fmt"
+	"os"
+
+	"github.com/google/uuid"
+)
+
+func main() {
+	// 1.User id
+	if len(os.Args) != 2 {
+		fmt.Printf("Usage: %s <user_id>\n", os.Args[0])
+		os.Exit(1)
+	}
+
+	userId, err := uuid.Parse(os.Args[1])
+	if err != nil {
+		fmt.Printf("Error: invalid user id: %v\n", err)
+		os.Exit(1)
+	}
+
+	// 2.Configuration
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Error while reading configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	if cfg.TokenSecret == "" {
+		fmt.Printf("Error: calendar token secret is not set, calendar feeds are available without token\n")
+		os.Exit(1)
+	}
+
+	// 3.Token
+	fmt.Println(model.CalendarToken(cfg.TokenSecret, userId))
+}
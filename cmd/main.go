@@ -1,16 +1,16 @@
 package main
 
 import (
-	_ "em_golang_rest_service_example/docs"
+	_ "em_golang_rest_service_example/docs/v1"
 	"em_golang_rest_service_example/internal/config"
 	"em_golang_rest_service_example/internal/http-server/handlers"
+	"em_golang_rest_service_example/internal/http-server/middleware/deprecation"
 	mwLogger "em_golang_rest_service_example/internal/http-server/middleware/logger"
 	"em_golang_rest_service_example/internal/model"
 	pg "em_golang_rest_service_example/internal/storage/postgres"
 	"em_golang_rest_service_example/internal/storage/sqlite"
 
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -68,7 +68,7 @@ func main() {
 	}
 
 	// 4.Router
-	router = setupRouter(logger, repo)
+	router = setupRouter(logger, repo, cfg.TokenSecret, cfg.LegacyErrors, cfg.IdempotencyTTL)
 
 	// 5.Starting
 	logger.Info("starting server", "address", cfg.Address)
@@ -91,10 +91,20 @@ func main() {
 	}()
 	logger.Info("server started")
 
-	// 6.Stopping
+	// 6.Background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	go runRenewer(jobsCtx, logger, repo, cfg.RenewerInterval)
+	go runExpirer(jobsCtx, logger, repo)
+	go runIdempotencyCleaner(jobsCtx, logger, repo)
+
+	// 7.Stopping
 	<-done
 	logger.Info("stopping server")
 
+	stopJobs()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -122,38 +132,147 @@ func setupLogger(env string) *slog.Logger {
 type Repo interface {
 	CreateSubscription(subscription model.SubscriptionSpec) (int64, error)
 	GetSubscription(id int64) (model.Subscription, error)
-	UpdateSubscription(id int64, newServiceName string, newPrice int, newStart, newEnd model.Date) error
+	GetSubscriptionFields(id int64, fields model.FieldSet) (model.Subscription, error)
+	UpdateSubscription(id int64, update model.SubscriptionUpdate) error
 	DeleteSubscription(id int64) error
-	GetSubscriptions(limit, offset *int) ([]model.Subscription, error)
-	FilterSubscriptions(startDate, endDate model.Date, userId uuid.UUID, serviceName *string) ([]model.Subscription, error)
+	GetSubscriptions(limit, offset *int, filter model.SubscriptionFilter, fields model.FieldSet) ([]model.Subscription, error)
+	StreamSubscriptions(ctx context.Context, limit, offset *int, filter model.SubscriptionFilter, fields model.FieldSet, yield func(sub *model.Subscription) error) error
+	ImportSubscriptions(specs []model.SubscriptionSpec, policy model.ConflictPolicy, dryRun bool) ([]model.ImportResult, error)
+	FilterSubscriptions(startDate, endDate model.Date, userId uuid.UUID, serviceName *string, filter model.SubscriptionFilter) ([]model.Subscription, error)
+	SearchSubscriptions(query string, limit int) ([]model.Subscription, error)
+	GetCharges(ids []int64, asOf *time.Time) (map[int64][]model.Charge, error)
+	AddPriceChange(id int64, change model.PriceChange) (int64, error)
+	AddDiscount(id int64, discount model.Discount) (int64, error)
+	ChangeStatus(id int64, transition model.StatusTransition, newEnd *model.Date) (int64, error)
+	ExpireSubscriptions(month model.Date) (int64, error)
+	RenewSubscriptions(month model.Date) (int64, error)
+	SetAutoRenew(id int64, autoRenew bool, billingPeriod int) error
+	CreateService(service model.Service) (int64, error)
+	GetService(id int64) (model.Service, error)
+	GetServices() ([]model.Service, error)
+	ResolveService(name string) (model.Service, error)
+	UpdateService(id int64, service model.Service) error
+	DeleteService(id int64) error
+	GetUserSubscriptions(userId uuid.UUID, limit, offset *int) ([]model.Subscription, error)
+	DeleteUserSubscriptions(userId uuid.UUID) (int64, error)
+	SetMembers(id int64, members []model.Member) error
+	SetTags(id int64, category string, tags []string) error
+	ReserveIdempotencyKey(key, requestHash string, ttl time.Duration) error
+	GetIdempotencyKey(key string) (model.IdempotencyRecord, error)
+	SaveIdempotentResponse(key string, status int, contentType string, body []byte) error
+	DeleteIdempotencyKey(key string) error
+	DeleteExpiredIdempotencyKeys() (int64, error)
+}
+
+// Periodically extend ended auto-renewing subscriptions until context is cancelled
+// (renewal is idempotent, so restarts and concurrent instances cannot renew twice)
+func runRenewer(ctx context.Context, logger *slog.Logger, repo Repo, interval time.Duration) {
+	logger = logger.With(slog.String("op", "renewer"))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		renewed, err := repo.RenewSubscriptions(model.CurrentMonth())
+		if err != nil {
+			logger.Error("failed to renew subscriptions", "details", err)
+		} else if renewed > 0 {
+			logger.Info("subscriptions renewed", "count", renewed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
-func setupRouter(l *slog.Logger, repo Repo) *chi.Mux {
+// How often ended subscriptions are marked as expired
+const expirerInterval = time.Hour
+
+// Periodically mark ended subscriptions as expired until context is cancelled
+func runExpirer(ctx context.Context, logger *slog.Logger, repo Repo) {
+	logger = logger.With(slog.String("op", "expirer"))
+
+	ticker := time.NewTicker(expirerInterval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := repo.ExpireSubscriptions(model.CurrentMonth())
+		if err != nil {
+			logger.Error("failed to expire subscriptions", "details", err)
+		} else if expired > 0 {
+			logger.Info("subscriptions expired", "count", expired)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// How often expired idempotency keys are deleted
+const idempotencyCleanerInterval = time.Hour
+
+// Periodically delete expired idempotency keys until context is cancelled
+func runIdempotencyCleaner(ctx context.Context, logger *slog.Logger, repo Repo) {
+	logger = logger.With(slog.String("op", "idempotency_cleaner"))
+
+	ticker := time.NewTicker(idempotencyCleanerInterval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := repo.DeleteExpiredIdempotencyKeys()
+		if err != nil {
+			logger.Error("failed to delete expired idempotency keys", "details", err)
+		} else if deleted > 0 {
+			logger.Info("expired idempotency keys deleted", "count", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Deprecation moment and removal date of unversioned API paths
+var (
+	legacyRoutesDeprecatedAt = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	legacyRoutesSunset       = time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func setupRouter(l *slog.Logger, repo Repo, calendarSecret string, legacyErrors bool, idempotencyTTL time.Duration) *chi.Mux {
 	router := chi.NewRouter()
 
 	router.Use(middleware.RequestID) // tracing purposes
-	router.Use(mwLogger.New(l))      // logging purposes (using our logger implementation)
-	router.Use(middleware.Recoverer) // for panic recovering while handler failing
-	router.Use(middleware.URLFormat) // URL parser
-
-	router.Post("/subscription", handlers.NewCreateHandler(l, repo))
-	router.Get("/subscription/{id}", handlers.NewReadHandler(l, repo))
-	router.Get("/subscriptions", handlers.NewListHandler(l, repo))
-	router.Patch("/subscription/{id}", handlers.NewUpdateHandler(l, repo))
-	router.Delete("/subscription/{id}", handlers.NewDeleteHandler(l, repo))
-	router.Get("/subscriptions/total-cost", handlers.NewTotalCostHandler(l, repo))
-
-	router.NotFound(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"error":       "endpoint_not_found",
-			"message":     "requested API endpoint not found",
-			"path":        r.URL.Path,
-			"method":      r.Method,
-			"status_code": http.StatusNotFound,
-		})
+	if legacyErrors {
+		router.Use(handlers.LegacyErrors) // errors in {"status":"Error","error":"..."} format during migration
+	}
+	router.Use(mwLogger.New(l))          // logging purposes (using our logger implementation)
+	router.Use(handlers.NewRecoverer(l)) // for panic recovering while handler failing
+	router.Use(middleware.URLFormat)     // URL parser
+
+	// Retried POST requests with Idempotency-Key get response of the first one
+	router.Use(handlers.NewIdempotency(l, repo, idempotencyTTL))
+
+	// Current API version
+	router.Route("/api/v1", func(r chi.Router) {
+		routesV1(r, l, repo, calendarSecret)
 	})
 
+	// Unversioned paths are aliases of v1 kept until clients move to versioned ones
+	router.Group(func(r chi.Router) {
+		r.Use(deprecation.New(legacyRoutesDeprecatedAt, legacyRoutesSunset, "/api/v1"))
+		routesV1(r, l, repo, calendarSecret)
+	})
+
+	router.NotFound(handlers.NotFound)
+	router.MethodNotAllowed(handlers.MethodNotAllowed)
+
 	return router
 }
@@ -0,0 +1,69 @@
+// One-off migration linking existing subscriptions to catalog services
+// (service names are replaced with canonical ones, unknown names are registered in catalog)
+package main
+
+import (
+	"em_golang_rest_service_example/internal/config"
+	"em_golang_rest_service_example/internal/model"
+	pg "em_golang_rest_service_example/internal/storage/postgres"
+	"em_golang_rest_service_example/internal/storage/sqlite"
+
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+type normalizer interface {
+	NormalizeServiceNames() (model.NormalizeReport, error)
+}
+
+func main() {
+	// 1.Configuration
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Error while reading configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	// 2.Storage
+	var repo normalizer
+
+	switch cfg.Env {
+	case config.DevEnv:
+		sqliteRepo, err := sqlite.NewStorage(&cfg.StorageCfg.StoragePath, logger)
+		if err != nil {
+			fmt.Printf("Failed to initialize storage: %v\n", err)
+			os.Exit(1)
+		}
+		defer sqliteRepo.Close()
+
+		repo = &sqliteRepo
+
+	case config.ProdEnv:
+		pgRepo, err := pg.NewStorage(&cfg.StorageCfg, logger)
+		if err != nil {
+			fmt.Printf("Failed to initialize storage: %v\n", err)
+			os.Exit(1)
+		}
+		defer pgRepo.Close()
+
+		repo = &pgRepo
+
+	default:
+		fmt.Printf("Error: unsupported configuration env\n")
+		os.Exit(1)
+	}
+
+	// 3.Normalization
+	report, err := repo.NormalizeServiceNames()
+	if err != nil {
+		fmt.Printf("Failed to normalize service names: %v\n", err)
+		os.Exit(1)
+	}
+
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(out))
+}
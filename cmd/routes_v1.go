@@ -0,0 +1,49 @@
+package main
+
+import (
+	"em_golang_rest_service_example/internal/http-server/handlers"
+	"log/slog"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Swagger general info of API v1 (spec is generated into docs/v1 from handlers tagged v1)
+//
+// @title Subscriptions API
+// @version 1.0
+// @description Service for managing user subscriptions and their costs
+// @BasePath /api/v1
+
+// Register routes of API v1 (handlers of the same route in newer version can differ in DTOs)
+func routesV1(r chi.Router, l *slog.Logger, repo Repo, calendarSecret string) {
+	r.Post("/subscription", handlers.NewCreateHandler(l, repo))
+	r.Get("/subscription/{id}", handlers.NewReadHandler(l, repo))
+	r.Get("/subscriptions", handlers.NewListHandler(l, repo))
+	r.Put("/subscription/{id}", handlers.NewUpdateHandler(l, repo))
+	r.Patch("/subscription/{id}", handlers.NewPatchHandler(l, repo))
+	r.Delete("/subscription/{id}", handlers.NewDeleteHandler(l, repo))
+	r.Get("/subscriptions/total-cost", handlers.NewTotalCostHandler(l, repo))
+	r.Get("/subscriptions/search", handlers.NewSearchHandler(l, repo))
+	// URLFormat strips extension, so it is served as /subscriptions/export.csv
+	r.Get("/subscriptions/export", handlers.NewExportHandler(l, repo))
+	r.Post("/subscriptions/import", handlers.NewImportHandler(l, repo))
+	r.Post("/subscription/{id}/prices", handlers.NewAddPriceHandler(l, repo))
+	r.Post("/subscription/{id}/discounts", handlers.NewAddDiscountHandler(l, repo))
+	r.Post("/subscription/{id}/pause", handlers.NewPauseHandler(l, repo))
+	r.Post("/subscription/{id}/resume", handlers.NewResumeHandler(l, repo))
+	r.Post("/subscription/{id}/cancel", handlers.NewCancelHandler(l, repo))
+	r.Put("/subscription/{id}/members", handlers.NewSetMembersHandler(l, repo))
+	r.Put("/subscription/{id}/tags", handlers.NewSetTagsHandler(l, repo))
+	r.Put("/subscription/{id}/renewal", handlers.NewSetAutoRenewHandler(l, repo))
+	r.Post("/services", handlers.NewCreateServiceHandler(l, repo))
+	r.Get("/services", handlers.NewListServicesHandler(l, repo))
+	r.Get("/services/{id}", handlers.NewReadServiceHandler(l, repo))
+	r.Patch("/services/{id}", handlers.NewUpdateServiceHandler(l, repo))
+	r.Delete("/services/{id}", handlers.NewDeleteServiceHandler(l, repo))
+	r.Get("/users/{user_id}/subscriptions", handlers.NewUserListHandler(l, repo))
+	r.Get("/users/{user_id}/summary", handlers.NewUserSummaryHandler(l, repo))
+	r.Delete("/users/{user_id}/subscriptions", handlers.NewDeleteUserHandler(l, repo))
+	// URLFormat strips extension, so it is served as /users/{user_id}/calendar.ics
+	r.Get("/users/{user_id}/calendar", handlers.NewCalendarHandler(l, repo, calendarSecret))
+	r.Post("/reconciliation", handlers.NewReconciliationHandler(l, repo))
+}
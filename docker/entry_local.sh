@@ -1,6 +1,16 @@
 #!/bin/sh
 
-sqlite3 ./db/storage.db 'VACUUM;'
-sqlite3 ./db/storage.db < ./internal/storage/sqlite/migrations/000001_init_mg.up.sql
+DB=./db/storage.db
 
-./dist/app
\ No newline at end of file
+sqlite3 $DB 'VACUUM;'
+
+# Apply only not yet applied migrations (version is tracked with user_version pragma)
+version=$(sqlite3 $DB 'PRAGMA user_version;')
+for mg in ./internal/storage/sqlite/migrations/*.up.sql; do
+    n=$(basename "$mg" | cut -d_ -f1 | sed 's/^0*//')
+    if [ "$n" -gt "$version" ]; then
+        sqlite3 $DB < "$mg" && sqlite3 $DB "PRAGMA user_version = $n;" || exit 1
+    fi
+done
+
+./dist/app
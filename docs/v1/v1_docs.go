@@ -0,0 +1,3090 @@
+// Package v1 Code generated by swaggo/swag. DO NOT EDIT
+package v1
+
+import "github.com/swaggo/swag"
+
+const docTemplatev1 = `{
+    "schemes": {{ marshal .Schemes }},
+    "swagger": "2.0",
+    "info": {
+        "description": "{{escape .Description}}",
+        "title": "{{.Title}}",
+        "contact": {},
+        "version": "{{.Version}}"
+    },
+    "host": "{{.Host}}",
+    "basePath": "{{.BasePath}}",
+    "paths": {
+        "/reconciliation": {
+            "post": {
+                "description": "Match CSV statement (date, merchant and amount columns; YYYY-MM-DD or DD.MM.YYYY dates) against charges expected in statement months:\npayment matches charge of the same month if merchant contains service name or alias and amount differs by tolerance at most",
+                "consumes": [
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Reconcile bank statement",
+                "parameters": [
+                    {
+                        "description": "CSV statement",
+                        "name": "statement",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of user whose subscriptions are expected",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Max difference of payment and charge amounts (decimal string, 0 by default)",
+                        "name": "tolerance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Subscription status filter",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Subscription category filter",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Subscription tag filter",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter expression, e.g. price\u003e=300 and service_name~\\",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key making retries safe (response of the first request is replayed)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.ReconciliationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/services": {
+            "get": {
+                "description": "Get all catalog services",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Get all catalog services",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.ServiceListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create catalog service with canonical name and aliases",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Create catalog service",
+                "parameters": [
+                    {
+                        "description": "Service data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.ServiceRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key making retries safe (response of the first request is replayed)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.CreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/{id}": {
+            "get": {
+                "description": "Read catalog service",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Read catalog service",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Service ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.ServiceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete catalog service which is not used by subscriptions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Delete catalog service",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Service ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Replace catalog service data and aliases, subscriptions of service get new name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Update catalog service",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Service ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Service new data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.ServiceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscription": {
+            "post": {
+                "description": "Create new subscription",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Create new subscription",
+                "parameters": [
+                    {
+                        "description": "Subscription data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.CreateRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key making retries safe (response of the first request is replayed)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.CreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscription/{id}": {
+            "get": {
+                "description": "Read subscription. Response has ETag and Last-Modified, so it can be requested conditionally.\nWith fields parameter response has listed fields only (unknown field is validation error)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Read subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields of response, e.g. id,service_name,price (all by default)",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of cached response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified of cached response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.ReadResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Hash of response"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Moment of last change of subscription"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Subscription is not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace all editable subscription fields, omitted optional ones are reset to defaults as on creation\n(user, price schedule, discounts, status and renewals are kept, they are changed by own endpoints)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Replace subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subscription new data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.UpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete subscription",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Delete subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update fields present in JSON Merge Patch (RFC 7396), merged subscription is validated before it is saved",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Update subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subscription merge patch",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.PatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscription/{id}/cancel": {
+            "post": {
+                "description": "Cancel active or paused subscription immediately (end date is moved to effective month) or at end of period",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Cancel subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cancellation data",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.CancelRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key making retries safe (response of the first request is replayed)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.ChangeStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscription/{id}/discounts": {
+            "post": {
+                "description": "Add percentage or fixed promotional discount with optional validity range",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Add subscription discount",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Discount data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.AddDiscountRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key making retries safe (response of the first request is replayed)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.CreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscription/{id}/members": {
+            "put": {
+                "description": "Replace users sharing subscription cost with split weights",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Set members of shared subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Members data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.MembersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscription/{id}/pause": {
+            "post": {
+                "description": "Pause active subscription since specified month, paused months are not billed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Pause subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Pause data",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.ChangeStatusRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key making retries safe (response of the first request is replayed)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.ChangeStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscription/{id}/prices": {
+            "post": {
+                "description": "Schedule new subscription price since specified month (previous months keep old price)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Add subscription price change",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Price change data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.AddPriceRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key making retries safe (response of the first request is replayed)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.CreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscription/{id}/renewal": {
+            "put": {
+                "description": "Turn auto-renewal on or off and set billing period renewals extend subscription by",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Set auto-renewal of subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Auto-renewal settings",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.RenewalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscription/{id}/resume": {
+            "post": {
+                "description": "Resume paused subscription since specified month",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Resume subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Resume data",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.ChangeStatusRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key making retries safe (response of the first request is replayed)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.ChangeStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscription/{id}/tags": {
+            "put": {
+                "description": "Replace spending category and free-form tags of subscription",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Set category and tags of subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Category and tags",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.TagsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions": {
+            "get": {
+                "description": "Get all subscriptions. With Accept: application/x-ndjson subscriptions (ordered by id) are streamed\nas ListItem objects one per line; if reading fails in the middle, error object is the last line.\nJSON response has ETag (hash of response), so it can be requested conditionally with If-None-Match\n(there is no Last-Modified, as deletions would not move it).\nWith fields parameter items have listed fields only (unknown field is validation error)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Get all subscriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "application/x-ndjson for streaming",
+                        "name": "Accept",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (requires offset)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page offset (requires limit)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "paused",
+                            "cancelled",
+                            "expired"
+                        ],
+                        "type": "string",
+                        "description": "Status filter",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Category filter",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag filter",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Metadata filter, key is any top level metadata key (several keys are allowed)",
+                        "name": "metadata.key",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter expression, e.g. price\u003e=300 and service_name~\\",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields of items, e.g. id,service_name,price (all by default)",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of cached response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.ListResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Hash of response"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "List is not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/export.csv": {
+            "get": {
+                "description": "Stream all or filtered subscriptions (ordered by id) as CSV with header row, prices are decimal strings and tags are separated by | (file can be imported back)",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Export subscriptions to CSV",
+                "parameters": [
+                    {
+                        "enum": [
+                            "month",
+                            "iso"
+                        ],
+                        "type": "string",
+                        "description": "Format of dates: month (MM-YYYY, default) or iso (YYYY-MM-DD)",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "comma",
+                            "semicolon",
+                            "tab"
+                        ],
+                        "type": "string",
+                        "description": "Field delimiter, comma by default",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "paused",
+                            "cancelled",
+                            "expired"
+                        ],
+                        "type": "string",
+                        "description": "Status filter",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Category filter",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag filter",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Metadata filter, key is any top level metadata key (several keys are allowed)",
+                        "name": "metadata.key",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter expression, e.g. price\u003e=300 and service_name~\\",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/import": {
+            "post": {
+                "description": "Import subscriptions from JSON array of create requests or CSV with header row (columns are named as create request fields,\ntags are separated by \"|\", unknown columns like id and status of export are ignored). Rows are validated as on creation\nand imported by chunks of 100 in transactions; in dry run every chunk is rolled back, so conflicts between chunks are not found.\nConflict policy applies per row: with fail (default) row of existing subscription is reported in errors as failed\nand other rows are still imported, import as a whole is not rejected",
+                "consumes": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Import subscriptions",
+                "parameters": [
+                    {
+                        "description": "Subscriptions data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_http-server_handlers.CreateRequest"
+                            }
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate and report without writing anything",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "skip",
+                            "update",
+                            "fail"
+                        ],
+                        "type": "string",
+                        "description": "Handling of row with existing subscription of the same service and user: skip it, update subscription or fail the row (default)",
+                        "name": "on_conflict",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "comma",
+                            "semicolon",
+                            "tab"
+                        ],
+                        "type": "string",
+                        "description": "CSV field delimiter, comma by default",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "month",
+                            "iso"
+                        ],
+                        "type": "string",
+                        "description": "Format of CSV dates: month (MM-YYYY, default) or iso (YYYY-MM-DD)",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key making retries safe (response of the first request is replayed)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.ImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.ImportProblem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.ImportProblem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/search": {
+            "get": {
+                "description": "Search subscriptions whose service name is close to query (misspelled names are found too),\nthe closest ones go first; only service name is searched (subscription has no notes)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Search subscriptions by service name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query, e.g. yandx (up to 100 characters)",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of found subscriptions (20 by default, up to 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.ListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/total-cost": {
+            "get": {
+                "description": "Calculate total cost with specified filters as sum of ledger charges (paused months are not billed, shared subscriptions are split between members)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Calculate total cost with specified filters",
+                "parameters": [
+                    {
+                        "description": "filters data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.TotalCostRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.TotalCostResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/calendar.ics": {
+            "get": {
+                "description": "Get iCalendar (RFC 5545) feed with all-day event for every renewal and end of user subscriptions in 12 months since current one,\nprice is in event description. Token is required if calendar token secret is configured",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Get user calendar feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Calendar feed token of user",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "iCalendar feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/subscriptions": {
+            "get": {
+                "description": "Get all subscriptions of user ordered by id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Get user subscriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (requires offset)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page offset (requires limit)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.ListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete all subscriptions of user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Delete user subscriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.DeleteUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/summary": {
+            "get": {
+                "description": "Get active subscriptions count, monthly run-rate, spend year to date and next expiring subscription",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v1"
+                ],
+                "summary": "Get user summary",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Month in MM-YYYY format (current month by default)",
+                        "name": "month",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.UserSummaryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_http-server_handlers.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "internal_http-server_handlers.AddDiscountRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount off monthly price as decimal string or integer of minor units (required for fixed discount)",
+                    "type": "string",
+                    "example": "100.00"
+                },
+                "kind": {
+                    "description": "Discount kind: \"percent\" or \"fixed\" (required)",
+                    "type": "string",
+                    "enum": [
+                        "percent",
+                        "fixed"
+                    ]
+                },
+                "percent": {
+                    "description": "Percent off monthly price, 1-100 (required for percent discount)",
+                    "type": "integer"
+                },
+                "valid_from": {
+                    "description": "First month of discount validity (optional)",
+                    "type": "string"
+                },
+                "valid_to": {
+                    "description": "Month when discount stops being valid, exclusive like subscription end date (optional)",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.AddPriceRequest": {
+            "type": "object",
+            "properties": {
+                "effective_from": {
+                    "description": "Month since which new price is effective, must be inside subscription period (required)",
+                    "type": "string"
+                },
+                "price": {
+                    "description": "New monthly price as decimal string (\"9.99\") or integer of minor units (999) (required)",
+                    "type": "string",
+                    "example": "9.99"
+                }
+            }
+        },
+        "internal_http-server_handlers.CancelRequest": {
+            "type": "object",
+            "properties": {
+                "effective_from": {
+                    "description": "Month since which immediate cancellation applies (not billed), current month by default (optional)",
+                    "type": "string"
+                },
+                "mode": {
+                    "description": "Cancellation mode: \"immediate\" (default) stops billing since effective month,\n\"end_of_period\" keeps billing till subscription end date (optional)",
+                    "type": "string",
+                    "enum": [
+                        "immediate",
+                        "end_of_period"
+                    ]
+                }
+            }
+        },
+        "internal_http-server_handlers.ChangeStatusRequest": {
+            "type": "object",
+            "properties": {
+                "effective_from": {
+                    "description": "Month since which new status applies, current month by default (optional)",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.ChangeStatusResponse": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "description": "Subscription end date after change",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Reponse optional error message (optional field)",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Id of stored status transition",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Reponse status (required field)",
+                    "type": "string"
+                },
+                "subscription_status": {
+                    "description": "New subscription status",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.CostGroupItem": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "description": "Cost of group subscriptions (decimal string)",
+                    "type": "string",
+                    "example": "19.98"
+                },
+                "key": {
+                    "description": "Category or tag (empty for subscriptions without category or tags)",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.CreateRequest": {
+            "type": "object",
+            "properties": {
+                "auto_renew": {
+                    "description": "Extend subscription by billing period when it passes end date;\ndefault is true if end date is not set and false otherwise (optional)",
+                    "type": "boolean"
+                },
+                "billing_period": {
+                    "description": "Billing period in months from 1 to 12, one month if not set (optional)",
+                    "type": "integer"
+                },
+                "category": {
+                    "description": "Spending category, case insensitive; category of catalog service is used if not set (optional)",
+                    "type": "string"
+                },
+                "end_date": {
+                    "description": "Start date of subscription (optional, one billing period after start date if not set)",
+                    "type": "string"
+                },
+                "members": {
+                    "description": "Users sharing the cost with split weights, owner should be listed too if pays a part (optional)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.MemberItem"
+                    }
+                },
+                "metadata": {
+                    "description": "Custom attributes as JSON object (optional)",
+                    "type": "object"
+                },
+                "price": {
+                    "description": "Subscription monthly price as decimal string (\"9.99\") or integer of minor units (999);\ndefault price of catalog service is used if not set",
+                    "type": "string",
+                    "example": "9.99"
+                },
+                "service_id": {
+                    "description": "Catalog service id, takes precedence over service name (optional)",
+                    "type": "integer"
+                },
+                "service_name": {
+                    "description": "Subscription service name or its alias, case insensitive (required if service id is not set)",
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "Start date of subscription (required)",
+                    "type": "string"
+                },
+                "tags": {
+                    "description": "Free-form labels, case insensitive (optional)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "trial_months": {
+                    "description": "Number of first months billed with trial price (optional)",
+                    "type": "integer"
+                },
+                "trial_price": {
+                    "description": "Monthly price during trial, free if not set (optional)",
+                    "type": "string",
+                    "example": "0.00"
+                },
+                "user_id": {
+                    "description": "If of user who purchased the subscription (required)",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.CreateResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Reponse optional error message (optional field)",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Subscription identifier",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Reponse status (required field)",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.DeleteUserResponse": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "description": "Number of deleted subscriptions",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "Reponse optional error message (optional field)",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Reponse status (required field)",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.DiscountItem": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount off monthly price (for fixed discount)",
+                    "type": "string",
+                    "example": "100.00"
+                },
+                "id": {
+                    "description": "Discount id",
+                    "type": "integer"
+                },
+                "kind": {
+                    "description": "Discount kind: \"percent\" or \"fixed\"",
+                    "type": "string"
+                },
+                "percent": {
+                    "description": "Percent off monthly price (for percent discount)",
+                    "type": "integer"
+                },
+                "valid_from": {
+                    "description": "First month of discount validity",
+                    "type": "string"
+                },
+                "valid_to": {
+                    "description": "Month when discount stops being valid (exclusive)",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.ExpectedChargeItem": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Expected charge (decimal string)",
+                    "type": "string",
+                    "example": "9.99"
+                },
+                "month": {
+                    "description": "Billed month",
+                    "type": "string"
+                },
+                "service_name": {
+                    "description": "Subscription service name",
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "description": "Subscription id",
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_http-server_handlers.FieldErrorItem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Stable machine-readable error code (missing_value, invalid_value)",
+                    "type": "string"
+                },
+                "field": {
+                    "description": "Request body field or query parameter",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "Human-readable explanation",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.ImportErrorItem": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Reason of failure",
+                    "type": "string"
+                },
+                "fields": {
+                    "description": "Invalid fields of row (validation failures only)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.FieldErrorItem"
+                    }
+                },
+                "row": {
+                    "description": "Line number for CSV, position in array (from 1) for JSON",
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_http-server_handlers.ImportProblem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Stable machine-readable error code",
+                    "type": "string"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "detail": {
+                    "description": "Human-readable explanation of this occurrence",
+                    "type": "string"
+                },
+                "dry_run": {
+                    "description": "Nothing is written in dry run, counters show what would be done",
+                    "type": "boolean"
+                },
+                "errors": {
+                    "description": "Errors of failed rows (first 1000)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.ImportErrorItem"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "field": {
+                    "description": "Request body field or query parameter causing the problem (optional)",
+                    "type": "string"
+                },
+                "instance": {
+                    "description": "Request ID",
+                    "type": "string"
+                },
+                "invalid_params": {
+                    "description": "All invalid fields of request (validation_failed only), named after RFC 7807 example extension",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.FieldErrorItem"
+                    }
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "HTTP status code",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "Short summary of problem (HTTP status text)",
+                    "type": "string"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_http-server_handlers.ImportResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "description": "Nothing is written in dry run, counters show what would be done",
+                    "type": "boolean"
+                },
+                "error": {
+                    "description": "Reponse optional error message (optional field)",
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Errors of failed rows (first 1000)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.ImportErrorItem"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Reponse status (required field)",
+                    "type": "string"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_http-server_handlers.ListItem": {
+            "type": "object",
+            "properties": {
+                "auto_renew": {
+                    "description": "Extend subscription by billing period when it passes end date",
+                    "type": "boolean"
+                },
+                "billing_period": {
+                    "description": "Billing period in months",
+                    "type": "integer"
+                },
+                "category": {
+                    "description": "Spending category (empty if not categorized)",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "Creation moment (RFC 3339)",
+                    "type": "string"
+                },
+                "end_date": {
+                    "description": "Start date of subscription",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Subscription id",
+                    "type": "integer"
+                },
+                "metadata": {
+                    "description": "Custom attributes (empty object if not set)",
+                    "type": "object"
+                },
+                "price": {
+                    "description": "Subscription monthly price (decimal string)",
+                    "type": "string",
+                    "example": "9.99"
+                },
+                "service_name": {
+                    "description": "Subscription service name",
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "Start date of subscription",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Lifecycle status: active, paused, cancelled or expired",
+                    "type": "string"
+                },
+                "tags": {
+                    "description": "Free-form labels",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "trial_months": {
+                    "description": "Number of first months billed with trial price",
+                    "type": "integer"
+                },
+                "trial_price": {
+                    "description": "Monthly price during trial (decimal string)",
+                    "type": "string",
+                    "example": "0.00"
+                },
+                "updated_at": {
+                    "description": "Moment of last change of subscription or its details (RFC 3339)",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "If of user who purchased the subscription",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.ListResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Reponse optional error message (optional field)",
+                    "type": "string"
+                },
+                "items": {
+                    "description": "Data about all subscriptions got",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.ListItem"
+                    }
+                },
+                "status": {
+                    "description": "Reponse status (required field)",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.MemberItem": {
+            "type": "object",
+            "properties": {
+                "user_id": {
+                    "description": "Id of member user",
+                    "type": "string"
+                },
+                "weight": {
+                    "description": "Split weight: member pays weight / sum of weights of cost (from 1 to 1000)",
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_http-server_handlers.MembersRequest": {
+            "type": "object",
+            "properties": {
+                "members": {
+                    "description": "All members of subscription (empty list makes owner pay alone)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.MemberItem"
+                    }
+                }
+            }
+        },
+        "internal_http-server_handlers.PatchRequest": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "description": "New end date, null resets it to one billing period after start date",
+                    "type": "string"
+                },
+                "metadata": {
+                    "description": "Patch of custom attributes merged with current ones (null members remove keys), null removes all of them",
+                    "type": "object"
+                },
+                "price": {
+                    "description": "New price as decimal string (\"9.99\") or integer of minor units (999) (cannot be null)",
+                    "type": "string",
+                    "example": "9.99"
+                },
+                "service_name": {
+                    "description": "New service name (cannot be null)",
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "New start date (cannot be null)",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.PriceScheduleItem": {
+            "type": "object",
+            "properties": {
+                "effective_from": {
+                    "description": "Month since which price is effective",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Price change id",
+                    "type": "integer"
+                },
+                "price": {
+                    "description": "Monthly price since effective date (decimal string)",
+                    "type": "string",
+                    "example": "9.99"
+                }
+            }
+        },
+        "internal_http-server_handlers.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Stable machine-readable error code",
+                    "type": "string"
+                },
+                "detail": {
+                    "description": "Human-readable explanation of this occurrence",
+                    "type": "string"
+                },
+                "field": {
+                    "description": "Request body field or query parameter causing the problem (optional)",
+                    "type": "string"
+                },
+                "instance": {
+                    "description": "Request ID",
+                    "type": "string"
+                },
+                "invalid_params": {
+                    "description": "All invalid fields of request (validation_failed only), named after RFC 7807 example extension",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.FieldErrorItem"
+                    }
+                },
+                "status": {
+                    "description": "HTTP status code",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "Short summary of problem (HTTP status text)",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.ReadResponse": {
+            "type": "object",
+            "properties": {
+                "auto_renew": {
+                    "description": "Extend subscription by billing period when it passes end date",
+                    "type": "boolean"
+                },
+                "billing_period": {
+                    "description": "Billing period in months",
+                    "type": "integer"
+                },
+                "category": {
+                    "description": "Spending category (empty if not categorized)",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "Creation moment (RFC 3339)",
+                    "type": "string"
+                },
+                "discounts": {
+                    "description": "Promotional discounts",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.DiscountItem"
+                    }
+                },
+                "end_date": {
+                    "description": "Start date of subscription",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Reponse optional error message (optional field)",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Subscription id",
+                    "type": "integer"
+                },
+                "members": {
+                    "description": "Users sharing the cost (empty if owner pays alone)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.MemberItem"
+                    }
+                },
+                "metadata": {
+                    "description": "Custom attributes (empty object if not set)",
+                    "type": "object"
+                },
+                "price": {
+                    "description": "Subscription monthly price (decimal string)",
+                    "type": "string",
+                    "example": "9.99"
+                },
+                "price_schedule": {
+                    "description": "Price changes after start date",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.PriceScheduleItem"
+                    }
+                },
+                "renewals": {
+                    "description": "Periods added by auto-renewal",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.RenewalItem"
+                    }
+                },
+                "service_id": {
+                    "description": "Id of catalog service",
+                    "type": "integer"
+                },
+                "service_name": {
+                    "description": "Subscription service name",
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "Start date of subscription",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Reponse status (required field)",
+                    "type": "string"
+                },
+                "status_history": {
+                    "description": "Status changes for audit",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.StatusTransitionItem"
+                    }
+                },
+                "subscription_status": {
+                    "description": "Lifecycle status: active, paused, cancelled or expired (status is response status)",
+                    "type": "string"
+                },
+                "tags": {
+                    "description": "Free-form labels",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "trial_months": {
+                    "description": "Number of first months billed with trial price",
+                    "type": "integer"
+                },
+                "trial_price": {
+                    "description": "Monthly price during trial (decimal string)",
+                    "type": "string",
+                    "example": "0.00"
+                },
+                "updated_at": {
+                    "description": "Moment of last change of subscription or its details (RFC 3339)",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "If of user who purchased the subscription",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.ReconciledItem": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Expected charge (decimal string)",
+                    "type": "string",
+                    "example": "9.99"
+                },
+                "month": {
+                    "description": "Billed month",
+                    "type": "string"
+                },
+                "payment": {
+                    "description": "Matched statement payment",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/internal_http-server_handlers.StatementPaymentItem"
+                        }
+                    ]
+                },
+                "service_name": {
+                    "description": "Subscription service name",
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "description": "Subscription id",
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_http-server_handlers.ReconciliationResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Reponse optional error message (optional field)",
+                    "type": "string"
+                },
+                "from": {
+                    "description": "First month of statement payments",
+                    "type": "string"
+                },
+                "matched": {
+                    "description": "Expected charges found in statement",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.ReconciledItem"
+                    }
+                },
+                "missing": {
+                    "description": "Expected charges not found in statement",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.ExpectedChargeItem"
+                    }
+                },
+                "status": {
+                    "description": "Reponse status (required field)",
+                    "type": "string"
+                },
+                "to": {
+                    "description": "Last month of statement payments",
+                    "type": "string"
+                },
+                "unknown": {
+                    "description": "Untracked payments repeating in several months (probably subscriptions not added yet)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.RecurringPaymentItem"
+                    }
+                }
+            }
+        },
+        "internal_http-server_handlers.RecurringPaymentItem": {
+            "type": "object",
+            "properties": {
+                "merchant": {
+                    "description": "Merchant as written in first payment",
+                    "type": "string"
+                },
+                "payments": {
+                    "description": "Payments ordered as in statement",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.StatementPaymentItem"
+                    }
+                }
+            }
+        },
+        "internal_http-server_handlers.RenewalItem": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "Renewal id",
+                    "type": "integer"
+                },
+                "period_end": {
+                    "description": "Month after last month of period (new end date of subscription)",
+                    "type": "string"
+                },
+                "period_start": {
+                    "description": "First month of period",
+                    "type": "string"
+                },
+                "price": {
+                    "description": "Monthly price effective at period start (decimal string)",
+                    "type": "string",
+                    "example": "9.99"
+                },
+                "renewed_at": {
+                    "description": "Moment of renewal in RFC 3339 format",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.RenewalRequest": {
+            "type": "object",
+            "properties": {
+                "auto_renew": {
+                    "description": "Extend subscription when it passes end date",
+                    "type": "boolean"
+                },
+                "billing_period": {
+                    "description": "Billing period in months (from 1 to 12), subscription is extended by it; one month if not set",
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_http-server_handlers.Response": {
+            "description": "Common response",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Reponse optional error message (optional field)",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Reponse status (required field)",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.ServiceItem": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "description": "Other names of service",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "category": {
+                    "description": "Service category",
+                    "type": "string"
+                },
+                "default_price": {
+                    "description": "Monthly price for new subscriptions without price (decimal string)",
+                    "type": "string",
+                    "example": "9.99"
+                },
+                "id": {
+                    "description": "Service id",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Canonical service name",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.ServiceListResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Reponse optional error message (optional field)",
+                    "type": "string"
+                },
+                "items": {
+                    "description": "All catalog services",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.ServiceItem"
+                    }
+                },
+                "status": {
+                    "description": "Reponse status (required field)",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.ServiceRequest": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "description": "Other names of service, case and separators insensitive (optional)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "category": {
+                    "description": "Service category (optional)",
+                    "type": "string"
+                },
+                "default_price": {
+                    "description": "Monthly price for new subscriptions without price (optional)",
+                    "type": "string",
+                    "example": "9.99"
+                },
+                "name": {
+                    "description": "Canonical service name (required)",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.ServiceResponse": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "description": "Other names of service",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "category": {
+                    "description": "Service category",
+                    "type": "string"
+                },
+                "default_price": {
+                    "description": "Monthly price for new subscriptions without price (decimal string)",
+                    "type": "string",
+                    "example": "9.99"
+                },
+                "error": {
+                    "description": "Reponse optional error message (optional field)",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Service id",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Canonical service name",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Reponse status (required field)",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.StatementPaymentItem": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Paid amount (decimal string)",
+                    "type": "string",
+                    "example": "9.99"
+                },
+                "date": {
+                    "description": "Payment date in YYYY-MM-DD format",
+                    "type": "string"
+                },
+                "line": {
+                    "description": "Line number in statement (from 1)",
+                    "type": "integer"
+                },
+                "merchant": {
+                    "description": "Merchant description",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.StatusTransitionItem": {
+            "type": "object",
+            "properties": {
+                "changed_at": {
+                    "description": "Moment of change in RFC 3339 format",
+                    "type": "string"
+                },
+                "effective_from": {
+                    "description": "Month since which new status applies",
+                    "type": "string"
+                },
+                "from": {
+                    "description": "Previous status",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Status transition id",
+                    "type": "integer"
+                },
+                "to": {
+                    "description": "New status",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.TagsRequest": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "Spending category, case insensitive (empty removes category)",
+                    "type": "string"
+                },
+                "tags": {
+                    "description": "All tags of subscription, case insensitive (empty list removes tags)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "internal_http-server_handlers.TotalCostRequest": {
+            "type": "object",
+            "properties": {
+                "as_of": {
+                    "description": "Count only charges posted before the moment in RFC 3339 format to get report as it was then (optional)",
+                    "type": "string"
+                },
+                "category": {
+                    "description": "Subscription category (optional)",
+                    "type": "string"
+                },
+                "end_date": {
+                    "description": "Start date of subscription (required)",
+                    "type": "string"
+                },
+                "filter": {
+                    "description": "Filter expression, e.g. price\u003e=300 and service_name~\"yan*\" and active_in(2025-01,2025-06); amounts are integers\nin minor units or quoted decimals (price\u003e=\"3.00\") (optional)",
+                    "type": "string"
+                },
+                "group_by": {
+                    "description": "Report cost by category or tag (optional)",
+                    "type": "string"
+                },
+                "service_name": {
+                    "description": "Subscription service name (optional)",
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "Start date of subscription (required)",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Subscription status: active, paused, cancelled or expired (optional)",
+                    "type": "string"
+                },
+                "tag": {
+                    "description": "Subscription tag (optional)",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "If of user who purchased the subscription (optional)",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.TotalCostResponse": {
+            "type": "object",
+            "properties": {
+                "breakdown": {
+                    "description": "Cost attributed to every user (owners and members of shared subscriptions)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.UserCostItem"
+                    }
+                },
+                "error": {
+                    "description": "Reponse optional error message (optional field)",
+                    "type": "string"
+                },
+                "groups": {
+                    "description": "Cost of every category or tag (ordered by key, omitted if grouping is not requested);\nsubscription with several tags is counted in every tag group",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.CostGroupItem"
+                    }
+                },
+                "status": {
+                    "description": "Reponse status (required field)",
+                    "type": "string"
+                },
+                "total_cost": {
+                    "description": "Calculated total cost (decimal string) summed over charges ledger; shared subscriptions are counted once,\nif user filter is set only user share of them is counted",
+                    "type": "string",
+                    "example": "99.90"
+                }
+            }
+        },
+        "internal_http-server_handlers.UpdateRequest": {
+            "type": "object",
+            "properties": {
+                "auto_renew": {
+                    "description": "Extend subscription by billing period when it passes end date\n(optional, true if end date is omitted and false otherwise)",
+                    "type": "boolean"
+                },
+                "billing_period": {
+                    "description": "New billing period in months from 1 to 12 (optional, one month if omitted)",
+                    "type": "integer"
+                },
+                "category": {
+                    "description": "New spending category, case insensitive (optional, category of catalog service if omitted)",
+                    "type": "string"
+                },
+                "end_date": {
+                    "description": "New end date (optional, one billing period after start date if omitted)",
+                    "type": "string"
+                },
+                "members": {
+                    "description": "New users sharing the cost with split weights (optional, owner pays alone if omitted)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_http-server_handlers.MemberItem"
+                    }
+                },
+                "metadata": {
+                    "description": "New custom attributes (optional, removed if omitted)",
+                    "type": "object"
+                },
+                "price": {
+                    "description": "New price as decimal string (\"9.99\") or integer of minor units (999) (required)",
+                    "type": "string",
+                    "example": "9.99"
+                },
+                "service_name": {
+                    "description": "New service name (required)",
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "New start date (required)",
+                    "type": "string"
+                },
+                "tags": {
+                    "description": "New free-form labels, case insensitive (optional, removed if omitted)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "trial_months": {
+                    "description": "New number of first months billed with trial price (optional, no trial if omitted)",
+                    "type": "integer"
+                },
+                "trial_price": {
+                    "description": "New monthly price during trial (optional, free if omitted)",
+                    "type": "string",
+                    "example": "0.00"
+                }
+            }
+        },
+        "internal_http-server_handlers.UserCostItem": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "description": "User share of total cost (decimal string)",
+                    "type": "string",
+                    "example": "49.95"
+                },
+                "user_id": {
+                    "description": "Id of user",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_http-server_handlers.UserSummaryResponse": {
+            "type": "object",
+            "properties": {
+                "active_count": {
+                    "description": "Number of subscriptions active in the month",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "Reponse optional error message (optional field)",
+                    "type": "string"
+                },
+                "month": {
+                    "description": "Month summary is calculated at",
+                    "type": "string"
+                },
+                "monthly_run_rate": {
+                    "description": "Charge for the month over all user subscriptions (decimal string)",
+                    "type": "string",
+                    "example": "1299.00"
+                },
+                "next_expiring": {
+                    "description": "Subscription which ends first after the month (null if no one)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/internal_http-server_handlers.ListItem"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "Reponse status (required field)",
+                    "type": "string"
+                },
+                "year_to_date_spend": {
+                    "description": "Spend from January up to the month inclusive (decimal string)",
+                    "type": "string",
+                    "example": "5196.00"
+                }
+            }
+        }
+    }
+}`
+
+// SwaggerInfov1 holds exported Swagger Info so clients can modify it
+var SwaggerInfov1 = &swag.Spec{
+	Version:          "1.0",
+	Host:             "",
+	BasePath:         "/api/v1",
+	Schemes:          []string{},
+	Title:            "Subscriptions API",
+	Description:      "Service for managing user subscriptions and their costs",
+	InfoInstanceName: "v1",
+	SwaggerTemplate:  docTemplatev1,
+	LeftDelim:        "{{",
+	RightDelim:       "}}",
+}
+
+func init() {
+	swag.Register(SwaggerInfov1.InstanceName(), SwaggerInfov1)
+}
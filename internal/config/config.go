@@ -19,12 +19,29 @@ type Config struct {
 	Env        string `yaml:"env"`
 	StorageCfg `yaml:"storage"`
 	HTTPServer `yaml:"http_server"`
+	Jobs       `yaml:"jobs"`
+	Calendar   `yaml:"calendar"`
 }
 
 type HTTPServer struct {
 	Address     string        `yaml:"address"`
 	Timeout     time.Duration `yaml:"timeout"`
 	IdleTimeout time.Duration `yaml:"idle_timeout"`
+
+	// Report errors in legacy {"status":"Error","error":"..."} format instead of problem details
+	LegacyErrors bool `yaml:"legacy_errors"`
+
+	// How long responses of POST requests with Idempotency-Key are replayed to retries
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl"`
+}
+
+type Jobs struct {
+	RenewerInterval time.Duration `yaml:"renewer_interval"`
+}
+
+type Calendar struct {
+	// Secret of calendar feed tokens (feeds are available without token if not set)
+	TokenSecret string `yaml:"token_secret"`
 }
 
 type StorageCfg struct {
@@ -98,7 +115,23 @@ func validateCfg(cfg *Config) error {
 		cfg.Timeout = 30 * time.Second
 	}
 
-	// 2.Environment params validation
+	if cfg.IdempotencyTTL == 0 {
+		log.Println("key 'idempotency_ttl' of tag 'http_server' not set, use default '24h'")
+		cfg.IdempotencyTTL = 24 * time.Hour
+	}
+
+	// 2.Background jobs params validation
+	if cfg.RenewerInterval == 0 {
+		log.Println("key 'renewer_interval' of tag 'jobs' not set, use default '1h'")
+		cfg.RenewerInterval = time.Hour
+	}
+
+	// 3.Calendar params validation
+	if cfg.TokenSecret == "" {
+		log.Println("key 'token_secret' of tag 'calendar' not set, calendar feeds are available without token")
+	}
+
+	// 4.Environment params validation
 	if strings.Compare(cfg.Env, "") == 0 {
 		return errors.New("must specify 'env' key in configuration")
 	}
@@ -34,6 +34,9 @@ func TestLoadOk(t *testing.T) {
 	assert.Equal(t, cfg.Address, "localhost:5555")
 	assert.Equal(t, cfg.Timeout, 8*time.Second)
 	assert.Equal(t, cfg.IdleTimeout, 10*time.Second)
+	assert.True(t, cfg.LegacyErrors)
+	assert.Equal(t, cfg.RenewerInterval, 15*time.Minute)
+	assert.Equal(t, cfg.TokenSecret, "test-secret")
 }
 
 func TestLoadNotSetEnv(t *testing.T) {
@@ -97,6 +100,7 @@ func TestLoadValidProd(t *testing.T) {
 	assert.Equal(t, 1, cfg.PgMaxPoolSize)
 	assert.Equal(t, 3, cfg.PgConnectionAttempts)
 	assert.Equal(t, 30*time.Second, cfg.PgConnectionTimeout)
+	assert.Equal(t, time.Hour, cfg.RenewerInterval)
 	assert.NoError(t, err)
 }
 
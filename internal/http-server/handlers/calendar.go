@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/model"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+// Domain part of calendar event UIDs
+const calendarUIDDomain = "subscriptions.em-golang-rest-service-example"
+
+// Max length of calendar content line in octets (RFC 5545, 3.1)
+const calendarLineLength = 75
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=CalendarReader
+type CalendarReader interface {
+	GetUserSubscriptions(userId uuid.UUID, limit, offset *int) ([]model.Subscription, error)
+}
+
+// NewCalendarHandler godoc
+// @Summary Get user calendar feed
+// @Description Get iCalendar (RFC 5545) feed with all-day event for every renewal and end of user subscriptions in 12 months since current one,
+// @Description price is in event description. Token is required if calendar token secret is configured
+// @Tags v1
+// @Produce text/calendar
+// @Param user_id path string true "User ID"
+// @Param token query string false "Calendar feed token of user"
+// @Success 200 {string} string "iCalendar feed"
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /users/{user_id}/calendar.ics [get]
+func NewCalendarHandler(logger *slog.Logger, reader CalendarReader, tokenSecret string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.calendar"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		// 1.Get user id and check token
+		userId, err := uuid.Parse(chi.URLParam(r, "user_id"))
+		if err != nil {
+			logger.Info("invalid user id format", "details", err)
+
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "user_id", "invalid user id format")
+
+			return
+		}
+
+		if tokenSecret != "" && !model.CheckCalendarToken(tokenSecret, userId, r.URL.Query().Get("token")) {
+			logger.Info("calendar token is invalid", "user_id", userId)
+
+			renderError(w, r, http.StatusForbidden, CodeTokenInvalid, "token", "calendar token is invalid")
+
+			return
+		}
+
+		// 2.Get user subscriptions
+		subscriptions, err := reader.GetUserSubscriptions(userId, nil, nil)
+		if err != nil {
+			logger.Error("failed to get user subscriptions", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to get user subscriptions")
+
+			return
+		}
+
+		// 3.Render feed
+		events := model.CalendarEvents(subscriptions, userId, model.CurrentMonth())
+
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Content-Disposition", `inline; filename="calendar.ics"`)
+
+		if err := writeCalendar(w, events, time.Now().UTC()); err != nil {
+			logger.Error("failed to write calendar", "details", err)
+			return
+		}
+
+		logger.Info("got user calendar", "user_id", userId, "events", len(events))
+	}
+}
+
+// Write iCalendar feed with all-day events
+func writeCalendar(w io.Writer, events []model.CalendarEvent, stamp time.Time) error {
+	var sb strings.Builder
+
+	line := func(name, value string) {
+		writeCalendarLine(&sb, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//em_golang_rest_service_example//Subscriptions//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", "Subscriptions")
+
+	for i := range events {
+		event := &events[i]
+		day := time.Date(event.Month.Year, time.Month(event.Month.Month), 1, 0, 0, 0, 0, time.UTC)
+
+		line("BEGIN", "VEVENT")
+		line("UID", fmt.Sprintf("%s-%d-%04d%02d@%s", event.Kind, event.SubscriptionID, event.Month.Year, event.Month.Month, calendarUIDDomain))
+		line("DTSTAMP", stamp.Format("20060102T150405Z"))
+		line("DTSTART;VALUE=DATE", day.Format("20060102"))
+		line("DTEND;VALUE=DATE", day.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY", escapeCalendarText(calendarSummary(event)))
+		line("DESCRIPTION", escapeCalendarText(calendarDescription(event)))
+		line("TRANSP", "TRANSPARENT")
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func calendarSummary(event *model.CalendarEvent) string {
+	if event.Kind == model.CalendarRenewal {
+		return event.ServiceName + " renewal"
+	}
+	return event.ServiceName + " ends"
+}
+
+func calendarDescription(event *model.CalendarEvent) string {
+	description := fmt.Sprintf("Price: %s per month", event.Price.Format(model.DefaultCurrency))
+
+	if event.Share != event.Price {
+		description += fmt.Sprintf("\nYour share: %s", event.Share.Format(model.DefaultCurrency))
+	}
+
+	if event.Kind == model.CalendarRenewal {
+		description += fmt.Sprintf("\nRenewed for %d month(s)", event.Period)
+	} else {
+		description += "\nSubscription is not renewed automatically"
+	}
+
+	return description
+}
+
+// Escape TEXT value (RFC 5545, 3.3.11)
+func escapeCalendarText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
+
+// Write content line folded to calendarLineLength octets without splitting UTF-8 characters
+func writeCalendarLine(sb *strings.Builder, line string) {
+	limit := calendarLineLength
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		sb.WriteString(line[:cut])
+		sb.WriteString("\r\n ")
+		line = line[cut:]
+
+		// Continuation line starts with space
+		limit = calendarLineLength - 1
+	}
+
+	sb.WriteString(line)
+	sb.WriteString("\r\n")
+}
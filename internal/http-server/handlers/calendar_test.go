@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
+	"em_golang_rest_service_example/internal/model"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	userId := uuid.New()
+	month := model.CurrentMonth()
+	subscriptions := []model.Subscription{
+		{
+			ID: 1,
+			SubscriptionSpec: model.SubscriptionSpec{
+				ServiceName: "Okko",
+				Price:       39900,
+				UserID:      userId,
+				StartDate:   month.AddDate(0, -1),
+				EndDate:     month.AddDate(0, 2),
+			},
+			Status: model.StatusActive,
+		},
+	}
+
+	const secret = "secret"
+	token := model.CalendarToken(secret, userId)
+
+	cases := []struct {
+		name      string
+		url       string
+		secret    string
+		respCode  int
+		respError string
+		mockError error
+	}{
+		{
+			name:     "Success",
+			url:      "/users/" + userId.String() + "/calendar",
+			respCode: http.StatusOK,
+		},
+		{
+			name:     "Success with token",
+			url:      "/users/" + userId.String() + "/calendar?token=" + token,
+			secret:   secret,
+			respCode: http.StatusOK,
+		},
+		{
+			name:      "Invalid user id",
+			url:       "/users/trash/calendar",
+			respCode:  http.StatusBadRequest,
+			respError: "invalid user id format",
+		},
+		{
+			name:      "Missing token",
+			url:       "/users/" + userId.String() + "/calendar",
+			secret:    secret,
+			respCode:  http.StatusForbidden,
+			respError: "calendar token is invalid",
+		},
+		{
+			name:      "Token of other user",
+			url:       "/users/" + userId.String() + "/calendar?token=" + model.CalendarToken(secret, uuid.New()),
+			secret:    secret,
+			respCode:  http.StatusForbidden,
+			respError: "calendar token is invalid",
+		},
+		{
+			name:      "Any reader error case",
+			url:       "/users/" + userId.String() + "/calendar",
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to get user subscriptions",
+			mockError: errors.New("any error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			readerMock := mocks.NewCalendarReader(t)
+
+			if tc.respError == "" || tc.mockError != nil {
+				readerMock.On("GetUserSubscriptions", userId, (*int)(nil), (*int)(nil)).Return(subscriptions, tc.mockError)
+			}
+
+			router := chi.NewRouter()
+			router.Get("/users/{user_id}/calendar", NewCalendarHandler(logger, readerMock, tc.secret))
+
+			rr := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			assert.NoError(t, err)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.respCode, rr.Code)
+
+			if tc.respError != "" {
+				var resp Response
+				assert.Equal(t, tc.respError, decodeResp(t, rr, &resp))
+				return
+			}
+
+			end := month.AddDate(0, 2)
+			body := rr.Body.String()
+
+			assert.Equal(t, "text/calendar; charset=utf-8", rr.Header().Get("Content-Type"))
+			assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+			assert.True(t, strings.HasSuffix(body, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+			assert.Equal(t, 1, strings.Count(body, "BEGIN:VEVENT"))
+			assert.Contains(t, body, fmt.Sprintf("DTSTART;VALUE=DATE:%04d%02d01\r\n", end.Year, end.Month))
+			assert.Contains(t, body, "SUMMARY:Okko ends\r\n")
+			assert.Contains(t, body, `DESCRIPTION:Price: `+model.Money(39900).Format(model.DefaultCurrency))
+		})
+	}
+}
+
+func TestWriteCalendar(t *testing.T) {
+	events := []model.CalendarEvent{
+		{
+			Kind:           model.CalendarRenewal,
+			SubscriptionID: 7,
+			ServiceName:    "Yandex Plus; family, " + strings.Repeat("плюс", 20),
+			Month:          model.Date{Month: 12, Year: 2026},
+			Price:          60000,
+			Share:          20000,
+			Period:         1,
+		},
+	}
+
+	var sb strings.Builder
+	stamp := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	assert.NoError(t, writeCalendar(&sb, events, stamp))
+
+	body := sb.String()
+	assert.Contains(t, body, "UID:renewal-7-202612@"+calendarUIDDomain+"\r\n")
+	assert.Contains(t, body, "DTSTAMP:20261018T093000Z\r\n")
+	assert.Contains(t, body, "DTSTART;VALUE=DATE:20261201\r\nDTEND;VALUE=DATE:20261202\r\n")
+
+	// Lines are folded to 75 octets without splitting characters
+	for _, line := range strings.Split(strings.TrimSuffix(body, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), calendarLineLength)
+		assert.True(t, strings.ToValidUTF8(line, "") == line, line)
+	}
+
+	unfolded := strings.ReplaceAll(body, "\r\n ", "")
+	assert.Contains(t, unfolded, `SUMMARY:Yandex Plus\; family\, `+strings.Repeat("плюс", 20)+" renewal\r\n")
+	assert.Contains(t, unfolded, `\nYour share: `+model.Money(20000).Format(model.DefaultCurrency)+`\nRenewed for 1 month(s)`+"\r\n")
+}
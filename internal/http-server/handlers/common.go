@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"em_golang_rest_service_example/internal/model"
 	"errors"
 	"io"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/render"
 )
@@ -15,8 +17,7 @@ func parseReq[T any](r *http.Request, w http.ResponseWriter, logger *slog.Logger
 	if errors.Is(err, io.EOF) {
 		logger.Error("request body is empty")
 
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, RespError("empty request"))
+		renderError(w, r, http.StatusBadRequest, CodeEmptyBody, "", "empty request")
 
 		return false
 	}
@@ -24,8 +25,7 @@ func parseReq[T any](r *http.Request, w http.ResponseWriter, logger *slog.Logger
 	if err != nil {
 		logger.Error("failed to decode request body", "details", err)
 
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, RespError("failed to decode request"))
+		renderError(w, r, http.StatusBadRequest, CodeMalformedBody, "", "failed to decode request")
 
 		return false
 	}
@@ -34,3 +34,79 @@ func parseReq[T any](r *http.Request, w http.ResponseWriter, logger *slog.Logger
 
 	return true
 }
+
+// Same as parseReq but empty body is allowed (request keeps default values)
+func parseOptReq[T any](r *http.Request, w http.ResponseWriter, logger *slog.Logger, req *T) bool {
+	err := render.DecodeJSON(r.Body, &req)
+
+	if errors.Is(err, io.EOF) {
+		logger.Info("request body is empty, defaults are used")
+		return true
+	}
+
+	if err != nil {
+		logger.Error("failed to decode request body", "details", err)
+
+		renderError(w, r, http.StatusBadRequest, CodeMalformedBody, "", "failed to decode request")
+
+		return false
+	}
+
+	logger.Info("request body decoded", slog.Any("request", req))
+
+	return true
+}
+
+// Get optional subscriptions filter from URL query (error is *model.FieldError)
+func getSubscriptionFilter(r *http.Request) (model.SubscriptionFilter, error) {
+	var filter model.SubscriptionFilter
+
+	if statusStr := r.URL.Query().Get("status"); statusStr != "" {
+		status, err := model.StatusFromString(statusStr)
+		if err != nil {
+			return model.SubscriptionFilter{}, &model.FieldError{Code: CodeInvalidValue, Field: "status", Message: "status filter is invalid", Err: err}
+		}
+		filter.Status = &status
+	}
+
+	if categoryStr := r.URL.Query().Get("category"); categoryStr != "" {
+		category, err := model.NormalizeLabel(categoryStr)
+		if err != nil {
+			return model.SubscriptionFilter{}, &model.FieldError{Code: CodeInvalidValue, Field: "category", Message: "category filter is invalid", Err: err}
+		}
+		filter.Category = &category
+	}
+
+	if tagStr := r.URL.Query().Get("tag"); tagStr != "" {
+		tag, err := model.NormalizeLabel(tagStr)
+		if err != nil || tag == "" {
+			return model.SubscriptionFilter{}, &model.FieldError{Code: CodeInvalidValue, Field: "tag", Message: "tag filter is invalid", Err: err}
+		}
+		filter.Tag = &tag
+	}
+
+	if exprStr := r.URL.Query().Get("filter"); exprStr != "" {
+		expr, err := model.ParseFilterExpr(exprStr)
+		if err != nil {
+			return model.SubscriptionFilter{}, &model.FieldError{Code: CodeInvalidValue, Field: "filter", Message: "filter is invalid: " + err.Error(), Err: err}
+		}
+		filter.Expr = expr
+	}
+
+	for param, values := range r.URL.Query() {
+		key, ok := strings.CutPrefix(param, "metadata.")
+		if !ok {
+			continue
+		}
+		if !model.IsMetadataKey(key) {
+			return model.SubscriptionFilter{}, &model.FieldError{Code: CodeInvalidValue, Field: param, Message: "metadata filter is invalid"}
+		}
+
+		if filter.Metadata == nil {
+			filter.Metadata = make(map[string]string)
+		}
+		filter.Metadata[key] = values[0]
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Write JSON response with validators: ETag (hash of body) and Last-Modified (if it is known).
+// Client's copy is reported to be current with 304 Not Modified if its ETag is listed in If-None-Match
+// or, when If-None-Match is not sent, it is not older than last modification (If-Modified-Since)
+func renderConditionalJSON(w http.ResponseWriter, r *http.Request, lastModified time.Time, v any) {
+	// 1.Encode body the same way as render.JSON does
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(true)
+	if err := encoder.Encode(v); err != nil {
+		renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to encode response")
+		return
+	}
+
+	// 2.Set validators
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(buf.Bytes()))
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	// 3.Skip body if client has it
+	if notModified(r, etag, lastModified) {
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// Check request preconditions (RFC 9110): If-None-Match wins over If-Modified-Since
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Values("If-None-Match"); len(ifNoneMatch) > 0 {
+		for _, candidate := range strings.Split(strings.Join(ifNoneMatch, ","), ",") {
+			// Weak comparison is used for GET requests
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	ifModifiedSince := r.Header.Get("If-Modified-Since")
+	if ifModifiedSince == "" || lastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+
+	// Last-Modified has seconds precision
+	return !lastModified.Truncate(time.Second).After(since)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"em_golang_rest_service_example/internal/model"
 	"em_golang_rest_service_example/internal/storage"
+	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
@@ -16,11 +17,15 @@ import (
 // swagger:model CreateRequest
 // @ID CreateRequest
 type CreateRequest struct {
-	// Subscription service name (required)
+	// Subscription service name or its alias, case insensitive (required if service id is not set)
 	ServiceName string `json:"service_name"`
 
-	// Subscription monthly price (required)
-	Price int `json:"price"`
+	// Catalog service id, takes precedence over service name (optional)
+	ServiceID int64 `json:"service_id,omitempty"`
+
+	// Subscription monthly price as decimal string ("9.99") or integer of minor units (999);
+	// default price of catalog service is used if not set
+	Price model.Money `json:"price" swaggertype:"string" example:"9.99"`
 
 	// If of user who purchased the subscription (required)
 	UserID string `json:"user_id"`
@@ -28,8 +33,33 @@ type CreateRequest struct {
 	// Start date of subscription (required)
 	StartDate string `json:"start_date"`
 
-	// Start date of subscription (optional)
+	// Start date of subscription (optional, one billing period after start date if not set)
 	EndDate string `json:"end_date,omitempty"`
+
+	// Number of first months billed with trial price (optional)
+	TrialMonths int `json:"trial_months,omitempty"`
+
+	// Monthly price during trial, free if not set (optional)
+	TrialPrice model.Money `json:"trial_price,omitempty" swaggertype:"string" example:"0.00"`
+
+	// Users sharing the cost with split weights, owner should be listed too if pays a part (optional)
+	Members []MemberItem `json:"members,omitempty"`
+
+	// Spending category, case insensitive; category of catalog service is used if not set (optional)
+	Category string `json:"category,omitempty"`
+
+	// Free-form labels, case insensitive (optional)
+	Tags []string `json:"tags,omitempty"`
+
+	// Custom attributes as JSON object (optional)
+	Metadata json.RawMessage `json:"metadata,omitempty" swaggertype:"object"`
+
+	// Extend subscription by billing period when it passes end date;
+	// default is true if end date is not set and false otherwise (optional)
+	AutoRenew *bool `json:"auto_renew,omitempty"`
+
+	// Billing period in months from 1 to 12, one month if not set (optional)
+	BillingPeriod int `json:"billing_period,omitempty"`
 }
 
 // CreateResponse represents response with id on subscription creation
@@ -42,21 +72,33 @@ type CreateResponse struct {
 	Response
 }
 
+// Catalog methods needed to fill subscription data from service
+type serviceResolver interface {
+	GetService(id int64) (model.Service, error)
+	ResolveService(name string) (model.Service, error)
+}
+
 //go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=Creator
 type Creator interface {
 	CreateSubscription(subscription model.SubscriptionSpec) (int64, error)
+	GetService(id int64) (model.Service, error)
+	ResolveService(name string) (model.Service, error)
 }
 
 // NewCreateHandler godoc
 // @Summary Create new subscription
 // @Description Create new subscription
+// @Tags v1
 // @Accept json
 // @Produce json
 // @Param request body CreateRequest true "Subscription data"
+// @Param Idempotency-Key header string false "Key making retries safe (response of the first request is replayed)"
 // @Success 201 {object} CreateResponse
-// @Failure 404 {object} CreateResponse
-// @Failure 409 {object} CreateResponse
-// @Failure 500 {object} CreateResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /subscription [post]
 func NewCreateHandler(logger *slog.Logger, creator Creator) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -81,24 +123,27 @@ func NewCreateHandler(logger *slog.Logger, creator Creator) http.HandlerFunc {
 			return
 		}
 
-		// 3.Prepare subscription
+		// 3.Get catalog service data (canonical name and default price)
+		if ok := applyCatalogService(r, w, &req, creator, logger); !ok {
+			return
+		}
+
+		// 4.Prepare subscription
 		spec := prepareSubscriptionSpec(&req)
 
-		// 4.Create
+		// 5.Create
 		id, err := creator.CreateSubscription(spec)
 		if errors.Is(err, storage.ErrSubscriptionExists) {
 			logger.Info("subscription already exists", "service_name", req.ServiceName, "user_id", req.UserID)
 
-			w.WriteHeader(http.StatusConflict)
-			render.JSON(w, r, CreateResponse{Response: RespError("subscription already exists")})
+			renderError(w, r, http.StatusConflict, CodeSubscriptionExists, "", "subscription already exists")
 
 			return
 		}
 		if err != nil {
 			logger.Error("failed to create subscription", "details", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, CreateResponse{Response: RespError("failed to create subscription")})
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to create subscription")
 
 			return
 		}
@@ -111,71 +156,133 @@ func NewCreateHandler(logger *slog.Logger, creator Creator) http.HandlerFunc {
 }
 
 func validateCreateReq(r *http.Request, w http.ResponseWriter, req *CreateRequest, logger *slog.Logger) bool {
+	err := checkCreateReq(req)
+	if err == nil {
+		return true
+	}
+
+	logger.Error(err.Error(), "details", validationDetails(err))
+	renderFieldError(w, r, err)
+	return false
+}
+
+// Validate subscription data, get *model.ValidationError with all invalid fields (nil if data is valid)
+func checkCreateReq(req *CreateRequest) error {
+	var v model.Validator
+
 	// 1.Service name
-	if req.ServiceName == "" {
-		logger.Error("request serivce name is empty")
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, CreateResponse{Response: RespError("empty service name")})
-		return false
+	if req.ServiceID == 0 {
+		v.Required("service_name", req.ServiceName, "empty service name")
 	}
 
 	// 2.Price
-	if req.Price < 0 {
-		logger.Error("request price cannot be lowe than 0")
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, CreateResponse{Response: RespError("request price is invalid")})
-		return false
-	}
+	v.NotNegative("price", int64(req.Price), "request price is invalid")
+	v.NotNegative("trial_months", int64(req.TrialMonths), "request trial months is invalid")
+	v.NotNegative("trial_price", int64(req.TrialPrice), "request trial price is invalid")
 
 	// 3.User ID
-	if req.UserID == "" {
-		logger.Error("request user id is empty")
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, CreateResponse{Response: RespError("empty user id")})
+	if v.Required("user_id", req.UserID, "empty user id") {
+		v.UUID("user_id", req.UserID, "request user id is invalid")
+	}
+
+	// 4.Dates
+	checkPeriod(&v, req.StartDate, req.EndDate, "empty start date")
+
+	// 5.Members of shared subscription
+	if _, err := parseMembers(req.Members); err != nil {
+		v.Fail("members", model.CodeInvalidValue, "request members are invalid", err)
+	}
+
+	// 6.Category and tags
+	v.Label("category", req.Category, "request category is invalid")
+	v.Tags("tags", req.Tags, "request tags are invalid")
+
+	// 7.Metadata
+	v.Metadata("metadata", req.Metadata, "request metadata is invalid")
+
+	// 8.Billing period
+	if req.BillingPeriod != 0 {
+		v.BillingPeriod("billing_period", req.BillingPeriod, "request billing period is invalid")
+	}
+
+	return v.Err()
+}
+
+// Check required start date and optional end date not before it (shared by create and update)
+func checkPeriod(v *model.Validator, startDate, endDate, emptyStartMsg string) {
+	var start model.Date
+	startOk := v.Required("start_date", startDate, emptyStartMsg)
+	if startOk {
+		start, startOk = v.Date("start_date", startDate, "request start date is invalid")
+	}
+
+	if endDate == "" {
+		return
+	}
+
+	end, endOk := v.Date("end_date", endDate, "request end date is invalid")
+	if startOk && endOk {
+		v.DateOrder("end_date", start, end, "request start date greater than end date")
+	}
+}
+
+// Details of invalid fields for log
+func validationDetails(err error) error {
+	var validationErr *model.ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Details()
+	}
+
+	return errors.Unwrap(err)
+}
+
+// Take service name from catalog if service is referenced by id and default price if price is not set
+// (storage resolves service name to canonical one itself)
+func applyCatalogService(r *http.Request, w http.ResponseWriter, req *CreateRequest, catalog Creator, logger *slog.Logger) bool {
+	err := fillFromCatalog(req, catalog)
+	if errors.Is(err, storage.ErrServiceNotFound) {
+		logger.Info("service not found", "service_id", req.ServiceID)
+		renderError(w, r, http.StatusNotFound, CodeServiceNotFound, "", "service not found")
 		return false
 	}
-	_, err := uuid.Parse(req.UserID)
 	if err != nil {
-		logger.Error("request user id is invalid", "details", err)
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, CreateResponse{Response: RespError("request user id is invalid")})
+		logger.Error("failed to get service", "details", err)
+		renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to get service")
 		return false
 	}
 
-	// 4.Dates
-	if req.StartDate == "" {
-		logger.Error("request start date is empty")
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, CreateResponse{Response: RespError("empty start date")})
-		return false
+	return true
+}
+
+// Same as applyCatalogService but errors are returned (not found error only if service is referenced by id)
+func fillFromCatalog(req *CreateRequest, catalog serviceResolver) error {
+	if req.ServiceID == 0 && req.Price != 0 {
+		return nil
 	}
 
-	startDate, err := model.DateFromString(req.StartDate)
-	if err != nil {
-		logger.Error("request start date is invalid", "details", err)
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, CreateResponse{Response: RespError("request start date is invalid")})
-		return false
+	var service model.Service
+	var err error
+
+	if req.ServiceID != 0 {
+		service, err = catalog.GetService(req.ServiceID)
+	} else {
+		service, err = catalog.ResolveService(req.ServiceName)
 	}
 
-	if req.EndDate != "" {
-		endDate, err := model.DateFromString(req.EndDate)
-		if err != nil {
-			logger.Error("request end date is invalid", "details", err)
-			w.WriteHeader(http.StatusBadRequest)
-			render.JSON(w, r, CreateResponse{Response: RespError("request end date is invalid")})
-			return false
-		}
+	if errors.Is(err, storage.ErrServiceNotFound) && req.ServiceID == 0 {
+		// Service will be registered in catalog with subscription
+		return nil
+	}
+	if err != nil {
+		return err
+	}
 
-		if startDate.GreaterThan(endDate) {
-			logger.Error("request start date greater than end date")
-			w.WriteHeader(http.StatusBadRequest)
-			render.JSON(w, r, CreateResponse{Response: RespError("request start date greater than end date")})
-			return false
-		}
+	req.ServiceName = service.Name
+	if req.Price == 0 {
+		req.Price = service.DefaultPrice
 	}
 
-	return true
+	return nil
 }
 
 func prepareSubscriptionSpec(req *CreateRequest) model.SubscriptionSpec {
@@ -183,18 +290,41 @@ func prepareSubscriptionSpec(req *CreateRequest) model.SubscriptionSpec {
 
 	startDate, _ := model.DateFromString(req.StartDate)
 
+	billingPeriod := req.BillingPeriod
+	if billingPeriod == 0 {
+		billingPeriod = 1
+	}
+
 	endDate := model.Date{}
 	if req.EndDate == "" {
-		endDate = startDate.AddDate(0, 1)
+		endDate = startDate.AddDate(0, billingPeriod)
 	} else {
 		endDate, _ = model.DateFromString(req.EndDate)
 	}
 
+	// Subscription without end date is ongoing, so it is renewed unless renewal is turned off explicitly
+	autoRenew := req.EndDate == ""
+	if req.AutoRenew != nil {
+		autoRenew = *req.AutoRenew
+	}
+
+	members, _ := parseMembers(req.Members)
+	category, tags, _ := parseTags(req.Category, req.Tags)
+	metadata, _ := model.NormalizeMetadata(req.Metadata)
+
 	return model.SubscriptionSpec{
-		ServiceName: req.ServiceName,
-		Price:       req.Price,
-		UserID:      uid,
-		StartDate:   startDate,
-		EndDate:     endDate,
+		ServiceName:   req.ServiceName,
+		Price:         req.Price,
+		UserID:        uid,
+		StartDate:     startDate,
+		EndDate:       endDate,
+		TrialMonths:   req.TrialMonths,
+		TrialPrice:    req.TrialPrice,
+		Members:       members,
+		Category:      category,
+		Tags:          tags,
+		Metadata:      metadata,
+		AutoRenew:     autoRenew,
+		BillingPeriod: billingPeriod,
 	}
 }
@@ -21,7 +21,7 @@ import (
 type readTCase struct {
 	name        string
 	serviceName string
-	price       int
+	price       model.Money
 	userId      string
 	startDate   string
 	endDate     string
@@ -50,28 +50,28 @@ func TestCreateHandler(t *testing.T) {
 			name:        "Validation error on emty service name",
 			serviceName: "",
 			respCode:    http.StatusBadRequest,
-			respError:   "empty service name",
+			respError:   "empty service name; empty user id; empty start date",
 		},
 		{
 			name:        "Validation error on invalid price",
 			serviceName: "Netflix",
 			price:       -500,
 			respCode:    http.StatusBadRequest,
-			respError:   "request price is invalid",
+			respError:   "request price is invalid; empty user id; empty start date",
 		},
 		{
 			name:        "Validation error on emty user id",
 			serviceName: "Any",
 			userId:      "",
 			respCode:    http.StatusBadRequest,
-			respError:   "empty user id",
+			respError:   "empty user id; empty start date",
 		},
 		{
 			name:        "Validation error on invalid user id",
 			serviceName: "Any",
 			userId:      "Trash",
 			respCode:    http.StatusBadRequest,
-			respError:   "request user id is invalid",
+			respError:   "request user id is invalid; empty start date",
 		},
 		{
 			name:        "Validation error on emty start date",
@@ -131,13 +131,13 @@ func TestCreateHandler(t *testing.T) {
 		createRespCheck(t, logger, crMock, &reqInput, http.StatusBadRequest, &expectedErr)
 	})
 
-	// 3.Case when cannot decode request body (price is string, but no integer)
+	// 3.Case when cannot decode request body (price is string, but not a decimal number)
 	t.Run("invalid request body", func(t *testing.T) {
 		crMock := mocks.NewCreator(t)
 
 		invalidInput := fmt.Sprintf(
-			`{"service_name": "%s", "price": "%d", "user_id": "%s", "start_date": "%s", "end_date": "%s"}`,
-			"Google", 900, uuid.NewString(), "07-2027", "",
+			`{"service_name": "%s", "price": "%s", "user_id": "%s", "start_date": "%s", "end_date": "%s"}`,
+			"Google", "9.9.9", uuid.NewString(), "07-2027", "",
 		)
 
 		expectedErr := "failed to decode request"
@@ -145,7 +145,61 @@ func TestCreateHandler(t *testing.T) {
 		createRespCheck(t, logger, crMock, &invalidInput, http.StatusBadRequest, &expectedErr)
 	})
 
-	// 4.Case when got error from mock
+	// 4.Case with decimal string price
+	t.Run("decimal price", func(t *testing.T) {
+		crMock := mocks.NewCreator(t)
+		testData := readTCase{
+			serviceName: "Google", price: 999, userId: uuid.NewString(), startDate: "07-2027", endDate: "08-2027",
+		}
+		spec := getSpecFromreadTCase(t, &testData)
+		crMock.On("CreateSubscription", spec).Return(int64(1), nil)
+
+		testInput := fmt.Sprintf(
+			`{"service_name": "%s", "price": "9.99", "user_id": "%s", "start_date": "%s", "end_date": "%s"}`,
+			testData.serviceName, testData.userId, testData.startDate, testData.endDate,
+		)
+
+		expectedErr := ""
+
+		createRespCheck(t, logger, crMock, &testInput, http.StatusCreated, &expectedErr)
+	})
+
+	// 5.Case with trial period
+	t.Run("trial period", func(t *testing.T) {
+		crMock := mocks.NewCreator(t)
+		testData := readTCase{
+			serviceName: "Okko", price: 39900, userId: uuid.NewString(), startDate: "07-2027", endDate: "07-2028",
+		}
+		spec := getSpecFromreadTCase(t, &testData)
+		spec.TrialMonths = 3
+		spec.TrialPrice = 100
+		crMock.On("CreateSubscription", spec).Return(int64(1), nil)
+
+		testInput := fmt.Sprintf(
+			`{"service_name": "%s", "price": 39900, "user_id": "%s", "start_date": "%s", "end_date": "%s", "trial_months": 3, "trial_price": "1.00"}`,
+			testData.serviceName, testData.userId, testData.startDate, testData.endDate,
+		)
+
+		expectedErr := ""
+
+		createRespCheck(t, logger, crMock, &testInput, http.StatusCreated, &expectedErr)
+	})
+
+	// 6.Case with invalid trial period
+	t.Run("invalid trial months", func(t *testing.T) {
+		crMock := mocks.NewCreator(t)
+
+		testInput := fmt.Sprintf(
+			`{"service_name": "Okko", "price": 39900, "user_id": "%s", "start_date": "07-2027", "trial_months": -1}`,
+			uuid.NewString(),
+		)
+
+		expectedErr := "request trial months is invalid"
+
+		createRespCheck(t, logger, crMock, &testInput, http.StatusBadRequest, &expectedErr)
+	})
+
+	// 7.Case when got error from mock
 	t.Run("already exist subscription", func(t *testing.T) {
 		crMock := mocks.NewCreator(t)
 		testData := readTCase{
@@ -160,6 +214,191 @@ func TestCreateHandler(t *testing.T) {
 
 		createRespCheck(t, logger, crMock, &testInput, http.StatusConflict, &expectedErr)
 	})
+
+	// 8.Case with service referenced by id and default price from catalog
+	t.Run("service from catalog", func(t *testing.T) {
+		crMock := mocks.NewCreator(t)
+		testData := readTCase{
+			serviceName: "Kinopoisk", price: 29900, userId: uuid.NewString(), startDate: "07-2027", endDate: "08-2027",
+		}
+		crMock.On("GetService", int64(3)).Return(model.Service{ID: 3, Name: "Kinopoisk", DefaultPrice: 29900}, nil)
+		crMock.On("CreateSubscription", getSpecFromreadTCase(t, &testData)).Return(int64(1), nil)
+
+		testInput := fmt.Sprintf(
+			`{"service_id": 3, "user_id": "%s", "start_date": "%s", "end_date": "%s"}`,
+			testData.userId, testData.startDate, testData.endDate,
+		)
+
+		expectedErr := ""
+
+		createRespCheck(t, logger, crMock, &testInput, http.StatusCreated, &expectedErr)
+	})
+
+	// 9.Case with alias resolved to catalog service
+	t.Run("service alias", func(t *testing.T) {
+		crMock := mocks.NewCreator(t)
+		testData := readTCase{
+			serviceName: "Yandex Plus", price: 39900, userId: uuid.NewString(), startDate: "07-2027", endDate: "08-2027",
+		}
+		crMock.On("ResolveService", "yandex-plus").Return(model.Service{ID: 2, Name: "Yandex Plus", DefaultPrice: 39900}, nil)
+		crMock.On("CreateSubscription", getSpecFromreadTCase(t, &testData)).Return(int64(1), nil)
+
+		testInput := fmt.Sprintf(
+			`{"service_name": "yandex-plus", "user_id": "%s", "start_date": "%s", "end_date": "%s"}`,
+			testData.userId, testData.startDate, testData.endDate,
+		)
+
+		expectedErr := ""
+
+		createRespCheck(t, logger, crMock, &testInput, http.StatusCreated, &expectedErr)
+	})
+
+	// 10.Case with shared subscription
+	t.Run("shared subscription", func(t *testing.T) {
+		crMock := mocks.NewCreator(t)
+		testData := readTCase{
+			serviceName: "YouTube Family", price: 79900, userId: uuid.NewString(), startDate: "07-2027", endDate: "08-2027",
+		}
+		member := uuid.New()
+		spec := getSpecFromreadTCase(t, &testData)
+		spec.Members = []model.Member{{UserID: spec.UserID, Weight: 1}, {UserID: member, Weight: 1}}
+		crMock.On("CreateSubscription", spec).Return(int64(1), nil)
+
+		testInput := fmt.Sprintf(
+			`{"service_name": "%s", "price": 79900, "user_id": "%s", "start_date": "%s", "end_date": "%s", "members": [{"user_id": "%s", "weight": 1}, {"user_id": "%s", "weight": 1}]}`,
+			testData.serviceName, testData.userId, testData.startDate, testData.endDate, testData.userId, member,
+		)
+
+		expectedErr := ""
+
+		createRespCheck(t, logger, crMock, &testInput, http.StatusCreated, &expectedErr)
+	})
+
+	// 11.Case with unknown service id
+	t.Run("service not found", func(t *testing.T) {
+		crMock := mocks.NewCreator(t)
+		crMock.On("GetService", int64(532)).Return(model.Service{}, storage.ErrServiceNotFound)
+
+		testInput := fmt.Sprintf(`{"service_id": 532, "user_id": "%s", "start_date": "07-2027"}`, uuid.NewString())
+
+		expectedErr := "service not found"
+
+		createRespCheck(t, logger, crMock, &testInput, http.StatusNotFound, &expectedErr)
+	})
+
+	// 12.Case with category and tags (normalized before storing)
+	t.Run("category and tags", func(t *testing.T) {
+		crMock := mocks.NewCreator(t)
+		testData := readTCase{
+			serviceName: "GitHub Copilot", price: 1000, userId: uuid.NewString(), startDate: "07-2027", endDate: "08-2027",
+		}
+		spec := getSpecFromreadTCase(t, &testData)
+		spec.Category = "dev-tools"
+		spec.Tags = []string{"work", "ai"}
+		crMock.On("CreateSubscription", spec).Return(int64(1), nil)
+
+		testInput := fmt.Sprintf(
+			`{"service_name": "%s", "price": 1000, "user_id": "%s", "start_date": "%s", "end_date": "%s", "category": "Dev-Tools", "tags": ["Work", "AI", "work"]}`,
+			testData.serviceName, testData.userId, testData.startDate, testData.endDate,
+		)
+
+		expectedErr := ""
+
+		createRespCheck(t, logger, crMock, &testInput, http.StatusCreated, &expectedErr)
+	})
+
+	// 13.Case with invalid tag
+	t.Run("invalid tag", func(t *testing.T) {
+		crMock := mocks.NewCreator(t)
+
+		testInput := fmt.Sprintf(`{"service_name": "Okko", "price": 100, "user_id": "%s", "start_date": "07-2027", "tags": [" "]}`, uuid.NewString())
+
+		expectedErr := "request tags are invalid"
+
+		createRespCheck(t, logger, crMock, &testInput, http.StatusBadRequest, &expectedErr)
+	})
+
+	// 14.Case with metadata (compacted before storing)
+	t.Run("metadata", func(t *testing.T) {
+		crMock := mocks.NewCreator(t)
+		testData := readTCase{
+			serviceName: "AWS", price: 100000, userId: uuid.NewString(), startDate: "07-2027", endDate: "08-2027",
+		}
+		spec := getSpecFromreadTCase(t, &testData)
+		spec.Metadata = json.RawMessage(`{"cost_center":"R&D","invoice":{"number":532}}`)
+		crMock.On("CreateSubscription", spec).Return(int64(1), nil)
+
+		testInput := fmt.Sprintf(
+			`{"service_name": "%s", "price": 100000, "user_id": "%s", "start_date": "%s", "end_date": "%s", "metadata": {"cost_center": "R&D", "invoice": {"number": 532}}}`,
+			testData.serviceName, testData.userId, testData.startDate, testData.endDate,
+		)
+
+		expectedErr := ""
+
+		createRespCheck(t, logger, crMock, &testInput, http.StatusCreated, &expectedErr)
+	})
+
+	// 15.Case with metadata which is not an object
+	t.Run("invalid metadata", func(t *testing.T) {
+		crMock := mocks.NewCreator(t)
+
+		testInput := fmt.Sprintf(`{"service_name": "Okko", "price": 100, "user_id": "%s", "start_date": "07-2027", "metadata": [1, 2]}`, uuid.NewString())
+
+		expectedErr := "request metadata is invalid: metadata is not a JSON object"
+
+		createRespCheck(t, logger, crMock, &testInput, http.StatusBadRequest, &expectedErr)
+	})
+
+	// 16.Case without end date: ongoing subscription is renewed every billing period
+	t.Run("ongoing subscription", func(t *testing.T) {
+		crMock := mocks.NewCreator(t)
+		testData := readTCase{
+			serviceName: "Spotify", price: 1000, userId: uuid.NewString(), startDate: "07-2027", endDate: "10-2027",
+		}
+		spec := getSpecFromreadTCase(t, &testData)
+		spec.AutoRenew = true
+		spec.BillingPeriod = 3
+		crMock.On("CreateSubscription", spec).Return(int64(1), nil)
+
+		testInput := fmt.Sprintf(
+			`{"service_name": "%s", "price": 1000, "user_id": "%s", "start_date": "%s", "billing_period": 3}`,
+			testData.serviceName, testData.userId, testData.startDate,
+		)
+
+		expectedErr := ""
+
+		createRespCheck(t, logger, crMock, &testInput, http.StatusCreated, &expectedErr)
+	})
+
+	// 17.Case with auto-renewal turned off explicitly
+	t.Run("ongoing subscription without renewal", func(t *testing.T) {
+		crMock := mocks.NewCreator(t)
+		testData := readTCase{
+			serviceName: "Spotify", price: 1000, userId: uuid.NewString(), startDate: "07-2027", endDate: "08-2027",
+		}
+		spec := getSpecFromreadTCase(t, &testData)
+		crMock.On("CreateSubscription", spec).Return(int64(1), nil)
+
+		testInput := fmt.Sprintf(
+			`{"service_name": "%s", "price": 1000, "user_id": "%s", "start_date": "%s", "auto_renew": false}`,
+			testData.serviceName, testData.userId, testData.startDate,
+		)
+
+		expectedErr := ""
+
+		createRespCheck(t, logger, crMock, &testInput, http.StatusCreated, &expectedErr)
+	})
+
+	// 18.Case with billing period out of range
+	t.Run("invalid billing period", func(t *testing.T) {
+		crMock := mocks.NewCreator(t)
+
+		testInput := fmt.Sprintf(`{"service_name": "Okko", "price": 100, "user_id": "%s", "start_date": "07-2027", "billing_period": 13}`, uuid.NewString())
+
+		expectedErr := "request billing period is invalid"
+
+		createRespCheck(t, logger, crMock, &testInput, http.StatusBadRequest, &expectedErr)
+	})
 }
 
 // Helper for check
@@ -176,12 +415,8 @@ func createRespCheck(t *testing.T, l *slog.Logger, c Creator, input *string, exp
 
 	assert.Equal(t, expectedCode, rr.Code)
 
-	body := rr.Body.String()
-
 	var resp CreateResponse
-
-	assert.Nil(t, json.Unmarshal([]byte(body), &resp))
-	assert.Equal(t, *expectedRespErr, resp.Error)
+	assert.Equal(t, *expectedRespErr, decodeResp(t, rr, &resp))
 }
 
 // Helper getter subscription description from test case
@@ -198,11 +433,12 @@ func getSpecFromreadTCase(t *testing.T, tc *readTCase) model.SubscriptionSpec {
 	assert.NoError(t, err)
 
 	spec := model.SubscriptionSpec{
-		ServiceName: tc.serviceName,
-		Price:       tc.price,
-		UserID:      uid,
-		StartDate:   start,
-		EndDate:     end,
+		ServiceName:   tc.serviceName,
+		Price:         tc.price,
+		UserID:        uid,
+		StartDate:     start,
+		EndDate:       end,
+		BillingPeriod: 1,
 	}
 
 	return spec
@@ -20,12 +20,13 @@ type Deleter interface {
 // NewDeleteHandler godoc
 // @Summary Delete subscription
 // @Description Delete subscription
+// @Tags v1
 // @Produce json
 // @Param id path int true "Subscription ID"
 // @Success 200 {object} Response
-// @Failure 400 {object} Response
-// @Failure 404 {object} Response
-// @Failure 500 {object} Response
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /subscription/{id} [delete]
 func NewDeleteHandler(logger *slog.Logger, deleter Deleter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -43,8 +44,7 @@ func NewDeleteHandler(logger *slog.Logger, deleter Deleter) http.HandlerFunc {
 		if idStr == "" {
 			logger.Info("no subscription id in request")
 
-			w.WriteHeader(http.StatusBadRequest)
-			render.JSON(w, r, RespError("no subscription id in request"))
+			renderError(w, r, http.StatusBadRequest, CodeMissingValue, "id", "no subscription id in request")
 
 			return
 		}
@@ -52,8 +52,7 @@ func NewDeleteHandler(logger *slog.Logger, deleter Deleter) http.HandlerFunc {
 		if err != nil {
 			logger.Info("invalid subscription id format", "details", err)
 
-			w.WriteHeader(http.StatusBadRequest)
-			render.JSON(w, r, RespError("invalid subscription id format"))
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "id", "invalid subscription id format")
 
 			return
 		}
@@ -63,16 +62,14 @@ func NewDeleteHandler(logger *slog.Logger, deleter Deleter) http.HandlerFunc {
 		if errors.Is(err, storage.ErrSubscribtionNotFound) {
 			logger.Info("subscription not found", "id", id)
 
-			w.WriteHeader(http.StatusNotFound)
-			render.JSON(w, r, RespError("subscription not found"))
+			renderError(w, r, http.StatusNotFound, CodeSubscriptionNotFound, "", "subscription not found")
 
 			return
 		}
 		if err != nil {
 			logger.Error("failed to delete subscription", "details", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, RespError("failed to delete subscription"))
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to delete subscription")
 
 			return
 		}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
 	"em_golang_rest_service_example/internal/storage"
-	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
@@ -87,10 +86,6 @@ func deleteRespCheck(t *testing.T, l *slog.Logger, d Deleter, id string, expCode
 
 	assert.Equal(t, expCode, rr.Code)
 
-	body := rr.Body.String()
-
 	var resp ReadResponse
-
-	assert.Nil(t, json.Unmarshal([]byte(body), &resp))
-	assert.Equal(t, *expRespErr, resp.Error)
+	assert.Equal(t, *expRespErr, decodeResp(t, rr, &resp))
 }
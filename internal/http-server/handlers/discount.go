@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// AddDiscountRequest represents subscription discount model
+// swagger:model AddDiscountRequest
+// @ID AddDiscountRequest
+type AddDiscountRequest struct {
+	// Discount kind: "percent" or "fixed" (required)
+	Kind string `json:"kind" enums:"percent,fixed"`
+
+	// Percent off monthly price, 1-100 (required for percent discount)
+	Percent int `json:"percent,omitempty"`
+
+	// Amount off monthly price as decimal string or integer of minor units (required for fixed discount)
+	Amount model.Money `json:"amount,omitempty" swaggertype:"string" example:"100.00"`
+
+	// First month of discount validity (optional)
+	ValidFrom string `json:"valid_from,omitempty"`
+
+	// Month when discount stops being valid, exclusive like subscription end date (optional)
+	ValidTo string `json:"valid_to,omitempty"`
+}
+
+// DiscountItem represents one discount of subscription
+// swagger:model DiscountItem
+// @ID DiscountItem
+type DiscountItem struct {
+	// Discount id
+	Id int64 `json:"id"`
+
+	// Discount kind: "percent" or "fixed"
+	Kind string `json:"kind"`
+
+	// Percent off monthly price (for percent discount)
+	Percent int `json:"percent,omitempty"`
+
+	// Amount off monthly price (for fixed discount)
+	Amount model.Money `json:"amount,omitempty" swaggertype:"string" example:"100.00"`
+
+	// First month of discount validity
+	ValidFrom string `json:"valid_from,omitempty"`
+
+	// Month when discount stops being valid (exclusive)
+	ValidTo string `json:"valid_to,omitempty"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=DiscountAdder
+type DiscountAdder interface {
+	AddDiscount(id int64, discount model.Discount) (int64, error)
+}
+
+// NewAddDiscountHandler godoc
+// @Summary Add subscription discount
+// @Description Add percentage or fixed promotional discount with optional validity range
+// @Tags v1
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param request body AddDiscountRequest true "Discount data"
+// @Param Idempotency-Key header string false "Key making retries safe (response of the first request is replayed)"
+// @Success 201 {object} CreateResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /subscription/{id}/discounts [post]
+func NewAddDiscountHandler(logger *slog.Logger, adder DiscountAdder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.add_discount"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		// 1.Get subscription id from request
+		idStr := chi.URLParam(r, "id")
+		if idStr == "" {
+			logger.Info("no subscription id in request")
+
+			renderError(w, r, http.StatusBadRequest, CodeMissingValue, "id", "no subscription id in request")
+
+			return
+		}
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			logger.Info("invalid subscription id format", "details", err)
+
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "id", "invalid subscription id format")
+
+			return
+		}
+
+		// 2.Parse request body
+		var req AddDiscountRequest
+		if ok := parseReq(r, w, logger, &req); !ok {
+			return
+		}
+
+		// 3.Validate request body data
+		discount, validateOk := validateAddDiscountReq(r, w, &req, logger)
+		if !validateOk {
+			return
+		}
+
+		// 4.Add discount
+		discountId, err := adder.AddDiscount(int64(id), discount)
+		if errors.Is(err, storage.ErrSubscribtionNotFound) {
+			logger.Info("subscription not found", "id", id)
+
+			renderError(w, r, http.StatusNotFound, CodeSubscriptionNotFound, "", "subscription not found")
+
+			return
+		}
+		if err != nil {
+			logger.Error("failed to add discount", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to add discount")
+
+			return
+		}
+
+		logger.Info("discount added", "id", id, "discount_id", discountId)
+
+		w.WriteHeader(http.StatusCreated)
+		render.JSON(w, r, CreateResponse{ID: discountId, Response: RespOK()})
+	}
+}
+
+func validateAddDiscountReq(r *http.Request, w http.ResponseWriter, req *AddDiscountRequest, logger *slog.Logger) (model.Discount, bool) {
+	discount := model.Discount{Kind: model.DiscountKind(req.Kind)}
+
+	// 1.Kind and value
+	switch discount.Kind {
+	case model.DiscountPercent:
+		if req.Percent < 1 || req.Percent > 100 {
+			logger.Error("request discount percent is out of range")
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "percent", "request discount percent is invalid")
+			return model.Discount{}, false
+		}
+		discount.Percent = req.Percent
+
+	case model.DiscountFixed:
+		if req.Amount <= 0 {
+			logger.Error("request discount amount must be greater than 0")
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "amount", "request discount amount is invalid")
+			return model.Discount{}, false
+		}
+		discount.Amount = req.Amount
+
+	default:
+		logger.Error("request discount kind is invalid", "kind", req.Kind)
+		renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "kind", "request discount kind is invalid")
+		return model.Discount{}, false
+	}
+
+	// 2.Validity range
+	if req.ValidFrom != "" {
+		validFrom, err := model.DateFromString(req.ValidFrom)
+		if err != nil {
+			logger.Error("request valid from date is invalid", "details", err)
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "valid_from", "request valid from date is invalid")
+			return model.Discount{}, false
+		}
+		discount.ValidFrom = &validFrom
+	}
+
+	if req.ValidTo != "" {
+		validTo, err := model.DateFromString(req.ValidTo)
+		if err != nil {
+			logger.Error("request valid to date is invalid", "details", err)
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "valid_to", "request valid to date is invalid")
+			return model.Discount{}, false
+		}
+		discount.ValidTo = &validTo
+	}
+
+	if discount.ValidFrom != nil && discount.ValidTo != nil && !discount.ValidTo.GreaterThan(*discount.ValidFrom) {
+		logger.Error("request valid to date must be greater than valid from date")
+		renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "valid_to", "request valid to date must be greater than valid from date")
+		return model.Discount{}, false
+	}
+
+	return discount, true
+}
+
+func makeDiscountItems(discounts []model.Discount) []DiscountItem {
+	items := []DiscountItem{}
+
+	for i := 0; i < len(discounts); i++ {
+		item := DiscountItem{
+			Id:      discounts[i].ID,
+			Kind:    string(discounts[i].Kind),
+			Percent: discounts[i].Percent,
+			Amount:  discounts[i].Amount,
+		}
+		if discounts[i].ValidFrom != nil {
+			item.ValidFrom = discounts[i].ValidFrom.ToString()
+		}
+		if discounts[i].ValidTo != nil {
+			item.ValidTo = discounts[i].ValidTo.ToString()
+		}
+
+		items = append(items, item)
+	}
+
+	return items
+}
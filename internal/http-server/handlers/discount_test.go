@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"bytes"
+	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddDiscountHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	march := model.Date{Month: 3, Year: 2026}
+	june := model.Date{Month: 6, Year: 2026}
+
+	cases := []struct {
+		name           string
+		id             string
+		input          string
+		respCode       int
+		respError      string
+		mockNeedCall   bool
+		mockError      error
+		expectDiscount model.Discount
+	}{
+		{
+			name:           "Success percent",
+			id:             "1",
+			input:          `{"kind": "percent", "percent": 50}`,
+			respCode:       http.StatusCreated,
+			mockNeedCall:   true,
+			expectDiscount: model.Discount{Kind: model.DiscountPercent, Percent: 50},
+		},
+		{
+			name:           "Success fixed with range",
+			id:             "1",
+			input:          `{"kind": "fixed", "amount": "100.00", "valid_from": "03-2026", "valid_to": "06-2026"}`,
+			respCode:       http.StatusCreated,
+			mockNeedCall:   true,
+			expectDiscount: model.Discount{Kind: model.DiscountFixed, Amount: 10000, ValidFrom: &march, ValidTo: &june},
+		},
+		{
+			name:      "Invalid id",
+			id:        "trash",
+			input:     `{"kind": "percent", "percent": 50}`,
+			respCode:  http.StatusBadRequest,
+			respError: "invalid subscription id format",
+		},
+		{
+			name:      "Invalid kind",
+			id:        "1",
+			input:     `{"kind": "trash", "percent": 50}`,
+			respCode:  http.StatusBadRequest,
+			respError: "request discount kind is invalid",
+		},
+		{
+			name:      "Percent out of range",
+			id:        "1",
+			input:     `{"kind": "percent", "percent": 101}`,
+			respCode:  http.StatusBadRequest,
+			respError: "request discount percent is invalid",
+		},
+		{
+			name:      "Empty fixed amount",
+			id:        "1",
+			input:     `{"kind": "fixed"}`,
+			respCode:  http.StatusBadRequest,
+			respError: "request discount amount is invalid",
+		},
+		{
+			name:      "Invalid valid from date",
+			id:        "1",
+			input:     `{"kind": "percent", "percent": 10, "valid_from": "trash"}`,
+			respCode:  http.StatusBadRequest,
+			respError: "request valid from date is invalid",
+		},
+		{
+			name:      "Invalid range",
+			id:        "1",
+			input:     `{"kind": "percent", "percent": 10, "valid_from": "06-2026", "valid_to": "03-2026"}`,
+			respCode:  http.StatusBadRequest,
+			respError: "request valid to date must be greater than valid from date",
+		},
+		{
+			name:           "Subscription not found",
+			id:             "532",
+			input:          `{"kind": "percent", "percent": 50}`,
+			respCode:       http.StatusNotFound,
+			respError:      "subscription not found",
+			mockNeedCall:   true,
+			mockError:      storage.ErrSubscribtionNotFound,
+			expectDiscount: model.Discount{Kind: model.DiscountPercent, Percent: 50},
+		},
+		{
+			name:           "Storage error",
+			id:             "1",
+			input:          `{"kind": "percent", "percent": 50}`,
+			respCode:       http.StatusInternalServerError,
+			respError:      "failed to add discount",
+			mockNeedCall:   true,
+			mockError:      errors.New("any error"),
+			expectDiscount: model.Discount{Kind: model.DiscountPercent, Percent: 50},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			adderMock := mocks.NewDiscountAdder(t)
+
+			if tc.mockNeedCall {
+				id, err := strconv.Atoi(tc.id)
+				assert.NoError(t, err)
+
+				adderMock.On("AddDiscount", int64(id), tc.expectDiscount).Return(int64(3), tc.mockError)
+			}
+
+			router := chi.NewRouter()
+			router.Post("/subscription/{id}/discounts", NewAddDiscountHandler(logger, adderMock))
+
+			req, err := http.NewRequest(http.MethodPost, "/subscription/"+tc.id+"/discounts", bytes.NewReader([]byte(tc.input)))
+			assert.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.respCode, rr.Code)
+
+			var resp CreateResponse
+			assert.Equal(t, tc.respError, decodeResp(t, rr, &resp))
+			if tc.respError == "" {
+				assert.Equal(t, int64(3), resp.ID)
+			}
+		})
+	}
+}
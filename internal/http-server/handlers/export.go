@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"em_golang_rest_service_example/internal/model"
+	"encoding/csv"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Values of date_format parameter (export and import)
+const (
+	dateFormatMonth = "month"
+	dateFormatISO   = "iso"
+)
+
+// Values of delimiter parameter (export and import)
+var csvDelimiters = map[string]rune{
+	"comma":     ',',
+	"semicolon": ';',
+	"tab":       '\t',
+}
+
+// Number of rows written between flushes of response
+const exportFlushRows = 100
+
+// Lift server write timeout for streamed response: it may take longer than timeout, and the connection would be
+// closed in the middle of it after status is sent (client could not tell cut-off response from complete one)
+func clearWriteDeadline(controller *http.ResponseController, logger *slog.Logger) {
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		// Response writer without deadlines is not limited by server timeout
+		logger.Debug("write deadline is not cleared", "details", err)
+	}
+}
+
+var exportHeader = []string{
+	"id", "service_name", "service_id", "user_id", "price", "trial_months", "trial_price",
+	"start_date", "end_date", "status", "category", "tags", "auto_renew", "billing_period", "metadata",
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=ExportReader
+type ExportReader interface {
+	StreamSubscriptions(ctx context.Context, limit, offset *int, filter model.SubscriptionFilter, fields model.FieldSet, yield func(sub *model.Subscription) error) error
+}
+
+// NewExportHandler godoc
+// @Summary Export subscriptions to CSV
+// @Description Stream all or filtered subscriptions (ordered by id) as CSV with header row, prices are decimal strings and tags are separated by | (file can be imported back)
+// @Tags v1
+// @Produce text/csv
+// @Param date_format query string false "Format of dates: month (MM-YYYY, default) or iso (YYYY-MM-DD)" Enums(month, iso)
+// @Param delimiter query string false "Field delimiter, comma by default" Enums(comma, semicolon, tab)
+// @Param status query string false "Status filter" Enums(active, paused, cancelled, expired)
+// @Param category query string false "Category filter"
+// @Param tag query string false "Tag filter"
+// @Param metadata.key query string false "Metadata filter, key is any top level metadata key (several keys are allowed)"
+// @Param filter query string false "Filter expression, e.g. price>=300 and service_name~\"yan*\" and active_in(2025-01,2025-06); amounts are integers in minor units or quoted decimals (price>=\"3.00\")"
+// @Success 200 {string} string "CSV file"
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /subscriptions/export.csv [get]
+func NewExportHandler(logger *slog.Logger, exportReader ExportReader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.export"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		// 1.Get format params and filter
+		dateFormat := r.URL.Query().Get("date_format")
+		if dateFormat == "" {
+			dateFormat = dateFormatMonth
+		}
+		if dateFormat != dateFormatMonth && dateFormat != dateFormatISO {
+			logger.Error("date format is invalid", "date_format", dateFormat)
+
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "date_format", "date format is invalid")
+
+			return
+		}
+
+		delimiter := ','
+		if delimiterStr := r.URL.Query().Get("delimiter"); delimiterStr != "" {
+			var ok bool
+
+			delimiter, ok = csvDelimiters[delimiterStr]
+			if !ok {
+				logger.Error("delimiter is invalid", "delimiter", delimiterStr)
+
+				renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "delimiter", "delimiter is invalid")
+
+				return
+			}
+		}
+
+		filter, err := getSubscriptionFilter(r)
+		if err != nil {
+			logger.Error("invalid filter", "details", err)
+
+			renderFieldError(w, r, err)
+
+			return
+		}
+
+		// 2.Stream rows (response starts with first row, so storage error before it is still reported as JSON)
+		writer := csv.NewWriter(w)
+		writer.Comma = delimiter
+
+		controller := http.NewResponseController(w)
+		clearWriteDeadline(controller, logger)
+
+		count := 0
+
+		start := func() error {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="subscriptions.csv"`)
+
+			return writer.Write(exportHeader)
+		}
+
+		err = exportReader.StreamSubscriptions(r.Context(), nil, nil, filter, nil, func(sub *model.Subscription) error {
+			if count == 0 {
+				if err := start(); err != nil {
+					return err
+				}
+			}
+
+			if err := writer.Write(makeExportRecord(sub, dateFormat)); err != nil {
+				return err
+			}
+
+			count++
+			if count%exportFlushRows == 0 {
+				writer.Flush()
+				if err := writer.Error(); err != nil {
+					return err
+				}
+
+				// Response writer may not support flushing, rows are sent when buffer is full then
+				_ = controller.Flush()
+			}
+
+			return nil
+		})
+		if err != nil && count == 0 {
+			logger.Error("failed to get subscription", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to get subscription")
+
+			return
+		}
+		if err != nil {
+			// Status is already sent, client gets truncated file
+			logger.Error("export interrupted", "rows", count, "details", err)
+			return
+		}
+
+		// 3.Header row only if nothing is found
+		if count == 0 {
+			if err := start(); err != nil {
+				logger.Error("failed to write export", "details", err)
+				return
+			}
+		}
+
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			logger.Error("failed to write export", "details", err)
+			return
+		}
+
+		logger.Info("subscriptions exported", "rows", count)
+	}
+}
+
+func makeExportRecord(sub *model.Subscription, dateFormat string) []string {
+	startDate, endDate := sub.StartDate.ToString(), sub.EndDate.ToString()
+	if dateFormat == dateFormatISO {
+		startDate, endDate = sub.StartDate.ToStringISO(), sub.EndDate.ToStringISO()
+	}
+
+	serviceId := ""
+	if sub.ServiceID != 0 {
+		serviceId = strconv.FormatInt(sub.ServiceID, 10)
+	}
+
+	return []string{
+		strconv.FormatInt(sub.ID, 10),
+		sub.ServiceName,
+		serviceId,
+		sub.UserID.String(),
+		sub.Price.String(),
+		strconv.Itoa(sub.TrialMonths),
+		sub.TrialPrice.String(),
+		startDate,
+		endDate,
+		string(sub.Status),
+		sub.Category,
+		strings.Join(sub.Tags, csvTagSeparator),
+		strconv.FormatBool(sub.AutoRenew),
+		strconv.Itoa(sub.Period()),
+		string(makeMetadata(sub.Metadata)),
+	}
+}
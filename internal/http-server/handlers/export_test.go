@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
+	"em_golang_rest_service_example/internal/model"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	userId := uuid.MustParse("6f1e5a1c-4a8e-4f4c-9d8e-3b7a2c1d0e9f")
+	status := model.StatusActive
+
+	subs := []model.Subscription{
+		{
+			ID: 1,
+			SubscriptionSpec: model.SubscriptionSpec{
+				ServiceName:   "Yandex, Plus",
+				Price:         29900,
+				UserID:        userId,
+				StartDate:     model.Date{Month: 1, Year: 2026},
+				EndDate:       model.Date{Month: 2, Year: 2026},
+				Category:      "media",
+				Tags:          []string{"family", "video"},
+				Metadata:      json.RawMessage(`{"card":"1234"}`),
+				BillingPeriod: 1,
+			},
+			ServiceID: 7,
+			Status:    model.StatusActive,
+		},
+		{
+			ID: 2,
+			SubscriptionSpec: model.SubscriptionSpec{
+				ServiceName:   "Wink",
+				Price:         19900,
+				UserID:        userId,
+				StartDate:     model.Date{Month: 3, Year: 2026},
+				EndDate:       model.Date{Month: 6, Year: 2026},
+				TrialMonths:   1,
+				AutoRenew:     true,
+				BillingPeriod: 3,
+			},
+			Status: model.StatusActive,
+		},
+	}
+
+	header := "id,service_name,service_id,user_id,price,trial_months,trial_price,start_date,end_date,status,category,tags,auto_renew,billing_period,metadata\n"
+
+	cases := []struct {
+		name      string
+		query     string
+		filter    model.SubscriptionFilter
+		subs      []model.Subscription
+		respCode  int
+		respBody  string
+		respError string
+		mockError error
+		skipMock  bool
+	}{
+		{
+			name:     "Success",
+			subs:     subs,
+			respCode: http.StatusOK,
+			respBody: header +
+				`1,"Yandex, Plus",7,6f1e5a1c-4a8e-4f4c-9d8e-3b7a2c1d0e9f,299.00,0,0.00,01-2026,02-2026,active,media,family|video,false,1,"{""card"":""1234""}"` + "\n" +
+				`2,Wink,,6f1e5a1c-4a8e-4f4c-9d8e-3b7a2c1d0e9f,199.00,1,0.00,03-2026,06-2026,active,,,true,3,{}` + "\n",
+		},
+		{
+			name:     "ISO dates and semicolon delimiter",
+			query:    "?date_format=iso&delimiter=semicolon&status=active",
+			filter:   model.SubscriptionFilter{Status: &status},
+			subs:     subs[1:],
+			respCode: http.StatusOK,
+			respBody: "id;service_name;service_id;user_id;price;trial_months;trial_price;start_date;end_date;status;category;tags;auto_renew;billing_period;metadata\n" +
+				`2;Wink;;6f1e5a1c-4a8e-4f4c-9d8e-3b7a2c1d0e9f;199.00;1;0.00;2026-03-01;2026-06-01;active;;;true;3;{}` + "\n",
+		},
+		{
+			name:     "Nothing found",
+			respCode: http.StatusOK,
+			respBody: header,
+		},
+		{
+			name:      "Invalid date format",
+			query:     "?date_format=trash",
+			respCode:  http.StatusBadRequest,
+			respError: "date format is invalid",
+			skipMock:  true,
+		},
+		{
+			name:      "Invalid delimiter",
+			query:     "?delimiter=|",
+			respCode:  http.StatusBadRequest,
+			respError: "delimiter is invalid",
+			skipMock:  true,
+		},
+		{
+			name:      "Invalid filter",
+			query:     "?status=trash",
+			respCode:  http.StatusBadRequest,
+			respError: "status filter is invalid",
+			skipMock:  true,
+		},
+		{
+			name:      "Any other reader error case",
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to get subscription",
+			mockError: errors.New("any error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			readerMock := mocks.NewExportReader(t)
+
+			if !tc.skipMock {
+				readerMock.On("StreamSubscriptions", mock.Anything, (*int)(nil), (*int)(nil), tc.filter, model.FieldSet(nil), mock.Anything).
+					Run(func(args mock.Arguments) {
+						yield := args.Get(5).(func(sub *model.Subscription) error)
+						for i := range tc.subs {
+							assert.NoError(t, yield(&tc.subs[i]))
+						}
+					}).
+					Return(tc.mockError)
+			}
+
+			router := chi.NewRouter()
+			router.Get("/subscriptions/export.csv", NewExportHandler(logger, readerMock))
+
+			req, err := http.NewRequest(http.MethodGet, "/subscriptions/export.csv"+tc.query, nil)
+			assert.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.respCode, rr.Code)
+
+			if tc.respError != "" {
+				var resp Response
+				assert.Equal(t, tc.respError, decodeResp(t, rr, &resp))
+
+				return
+			}
+
+			assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+			assert.Equal(t, tc.respBody, rr.Body.String())
+		})
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	userId := uuid.MustParse("6f1e5a1c-4a8e-4f4c-9d8e-3b7a2c1d0e9f")
+
+	subs := []model.Subscription{
+		{
+			ID: 1,
+			SubscriptionSpec: model.SubscriptionSpec{
+				ServiceName:   "Yandex, Plus",
+				Price:         29900,
+				UserID:        userId,
+				StartDate:     model.Date{Month: 1, Year: 2026},
+				EndDate:       model.Date{Month: 2, Year: 2026},
+				Category:      "media",
+				Tags:          []string{"family", "video"},
+				Metadata:      json.RawMessage(`{"card":"1234"}`),
+				BillingPeriod: 1,
+			},
+			ServiceID: 7,
+			Status:    model.StatusActive,
+		},
+		{
+			ID: 2,
+			SubscriptionSpec: model.SubscriptionSpec{
+				ServiceName:   "Wink",
+				Price:         19900,
+				UserID:        userId,
+				StartDate:     model.Date{Month: 3, Year: 2026},
+				EndDate:       model.Date{Month: 6, Year: 2026},
+				TrialMonths:   1,
+				TrialPrice:    100,
+				Tags:          []string{"cinema"},
+				Metadata:      json.RawMessage(`{}`),
+				AutoRenew:     true,
+				BillingPeriod: 3,
+			},
+			Status: model.StatusActive,
+		},
+	}
+
+	// 1.Export subscriptions
+	readerMock := mocks.NewExportReader(t)
+	readerMock.On("StreamSubscriptions", mock.Anything, (*int)(nil), (*int)(nil), model.SubscriptionFilter{}, model.FieldSet(nil), mock.Anything).
+		Run(func(args mock.Arguments) {
+			yield := args.Get(5).(func(sub *model.Subscription) error)
+			for i := range subs {
+				assert.NoError(t, yield(&subs[i]))
+			}
+		}).
+		Return(nil)
+
+	router := chi.NewRouter()
+	router.Get("/subscriptions/export.csv", NewExportHandler(logger, readerMock))
+
+	req, err := http.NewRequest(http.MethodGet, "/subscriptions/export.csv?date_format=iso", nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// 2.Import exported file, the same subscriptions are expected
+	var imported []model.SubscriptionSpec
+
+	importerMock := mocks.NewImporter(t)
+	importerMock.On("GetService", int64(7)).Return(model.Service{ID: 7, Name: "Yandex, Plus", DefaultPrice: 29900}, nil)
+	importerMock.On("ImportSubscriptions", mock.Anything, model.ConflictFail, false).
+		Run(func(args mock.Arguments) {
+			imported = append(imported, args.Get(0).([]model.SubscriptionSpec)...)
+		}).
+		Return([]model.ImportResult{{Outcome: model.ImportCreated, ID: 1}, {Outcome: model.ImportCreated, ID: 2}}, nil)
+
+	router.Post("/subscriptions/import", NewImportHandler(logger, importerMock))
+
+	req, err = http.NewRequest(http.MethodPost, "/subscriptions/import?date_format=iso", strings.NewReader(rr.Body.String()))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "text/csv")
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	require.Len(t, imported, len(subs))
+	for i, sub := range subs {
+		assert.Equal(t, sub.SubscriptionSpec, imported[i])
+	}
+}
+
+func TestExportHandlerWriteTimeout(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	const rows = 3 * exportFlushRows
+	sub := model.Subscription{
+		ID:               1,
+		SubscriptionSpec: model.SubscriptionSpec{ServiceName: "Okko", Price: 39900, UserID: uuid.New(), BillingPeriod: 1},
+		Status:           model.StatusActive,
+	}
+
+	// Rows are read longer than server write timeout
+	readerMock := mocks.NewExportReader(t)
+	readerMock.On("StreamSubscriptions", mock.Anything, (*int)(nil), (*int)(nil), model.SubscriptionFilter{}, model.FieldSet(nil), mock.Anything).
+		Run(func(args mock.Arguments) {
+			yield := args.Get(5).(func(sub *model.Subscription) error)
+			for i := 0; i < rows; i++ {
+				if i%exportFlushRows == 0 {
+					time.Sleep(100 * time.Millisecond)
+				}
+				if yield(&sub) != nil {
+					return
+				}
+			}
+		}).
+		Return(nil)
+
+	router := chi.NewRouter()
+	router.Get("/subscriptions/export.csv", NewExportHandler(logger, readerMock))
+
+	server := httptest.NewUnstartedServer(router)
+	server.Config.WriteTimeout = 150 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/subscriptions/export.csv")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, rows+1, strings.Count(string(body), "\n"))
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/model"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Query parameter with comma separated fields of response (sparse fieldset)
+const fieldsParam = "fields"
+
+// Get fields requested by client (nil means all), error is *model.ValidationError listing allowed fields
+func getFieldSet(r *http.Request, allowed []string) (model.FieldSet, error) {
+	return model.ParseFieldSet(fieldsParam, r.URL.Query().Get(fieldsParam), allowed)
+}
+
+// Select requested fields of response struct by their JSON names (fields with omitempty are skipped when empty,
+// embedded structs are dropped); v is returned as is if all fields are requested
+func selectFields(v any, fields model.FieldSet) any {
+	if fields == nil {
+		return v
+	}
+
+	value := reflect.ValueOf(v)
+	selected := make(map[string]any, len(fields))
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Anonymous {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !fields[name] {
+			continue
+		}
+		if options == "omitempty" && value.Field(i).IsZero() {
+			continue
+		}
+
+		selected[name] = value.Field(i).Interface()
+	}
+
+	return selected
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+
+	// Max size of request body buffered to hash request with idempotency key (larger imports are sent without key)
+	maxIdempotentBodySize = 10 << 20
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=IdempotencyStore
+type IdempotencyStore interface {
+	ReserveIdempotencyKey(key, requestHash string, ttl time.Duration) error
+	GetIdempotencyKey(key string) (model.IdempotencyRecord, error)
+	SaveIdempotentResponse(key string, status int, contentType string, body []byte) error
+	DeleteIdempotencyKey(key string) error
+}
+
+// NewIdempotency returns middleware making retries of POST requests with Idempotency-Key header safe:
+// response of the first request is saved for ttl and replayed to retries with the same method, path and body.
+// Server errors are not saved, so such requests can be retried
+func NewIdempotency(logger *slog.Logger, store IdempotencyStore, ttl time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			const op = "handlers.idempotency"
+
+			logger := logger.With(
+				slog.String("op", op),
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+				slog.String("idempotency_key", key),
+			)
+
+			// 1.Check key
+			if len(key) > maxIdempotencyKeyLength {
+				logger.Info("idempotency key is too long")
+
+				renderError(w, r, http.StatusBadRequest, CodeInvalidValue, IdempotencyKeyHeader, "idempotency key is too long")
+
+				return
+			}
+
+			// 2.Read body to hash request (handler gets the same body)
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodySize))
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				logger.Info("request body is too large for idempotency key", "limit", maxBytesErr.Limit)
+
+				renderError(w, r, http.StatusRequestEntityTooLarge, CodeBodyTooLarge, "", "request body is too large to be sent with idempotency key")
+
+				return
+			}
+			if err != nil {
+				logger.Error("failed to read request body", "details", err)
+
+				renderError(w, r, http.StatusBadRequest, CodeMalformedBody, "", "failed to read request")
+
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			requestHash := hashRequest(r, body)
+
+			// 3.Reserve key or replay saved response
+			err = store.ReserveIdempotencyKey(key, requestHash, ttl)
+			if errors.Is(err, storage.ErrIdempotencyKeyExists) {
+				replayIdempotentResponse(w, r, logger, store, key, requestHash)
+				return
+			}
+			if err != nil {
+				logger.Error("failed to reserve idempotency key", "details", err)
+
+				renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to check idempotency key")
+
+				return
+			}
+
+			// 4.Handle request capturing response, key is released if handler fails or panics
+			saved := false
+			defer func() {
+				if !saved {
+					if err := store.DeleteIdempotencyKey(key); err != nil {
+						logger.Error("failed to release idempotency key", "details", err)
+					}
+				}
+			}()
+
+			var resp bytes.Buffer
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&resp)
+
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if status >= http.StatusInternalServerError {
+				return
+			}
+
+			// 5.Save response
+			err = store.SaveIdempotentResponse(key, status, ww.Header().Get("Content-Type"), resp.Bytes())
+			if err != nil {
+				logger.Error("failed to save idempotent response", "details", err)
+				return
+			}
+			saved = true
+		})
+	}
+}
+
+// Write saved response of request with the same key; request must be the same and completed
+func replayIdempotentResponse(w http.ResponseWriter, r *http.Request, logger *slog.Logger, store IdempotencyStore, key, requestHash string) {
+	// 1.Get saved response
+	record, err := store.GetIdempotencyKey(key)
+	if errors.Is(err, storage.ErrIdempotencyKeyNotFound) {
+		// Key expired after reservation attempt
+		logger.Info("idempotency key is expired")
+
+		renderError(w, r, http.StatusConflict, CodeIdempotencyKeyInUse, IdempotencyKeyHeader, "idempotency key is expired, retry request")
+
+		return
+	}
+	if err != nil {
+		logger.Error("failed to get idempotency key", "details", err)
+
+		renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to check idempotency key")
+
+		return
+	}
+
+	// 2.Check request
+	if record.RequestHash != requestHash {
+		logger.Info("idempotency key is used by another request")
+
+		renderError(w, r, http.StatusUnprocessableEntity, CodeIdempotencyKeyReused, IdempotencyKeyHeader, "idempotency key is already used for another request")
+
+		return
+	}
+
+	if !record.Completed() {
+		logger.Info("request with idempotency key is in progress")
+
+		renderError(w, r, http.StatusConflict, CodeIdempotencyKeyInUse, IdempotencyKeyHeader, "request with the same idempotency key is in progress")
+
+		return
+	}
+
+	logger.Info("replaying saved response", "status", record.Status)
+
+	// 3.Replay
+	if record.ContentType != "" {
+		w.Header().Set("Content-Type", record.ContentType)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.Status)
+	_, _ = w.Write(record.Body)
+}
+
+// Hash method, URL (path and query) and body of request
+func hashRequest(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIdempotency(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	const ttl = time.Hour
+	const body = `{"service_name":"Netflix"}`
+	const created = `{"status":"OK","id":1}`
+
+	hash := hashRequest(httptest.NewRequest(http.MethodPost, "/subscription", nil), []byte(body))
+
+	cases := []struct {
+		name      string
+		method    string
+		key       string
+		body      string
+		failing   bool
+		mockSetup func(store *mocks.IdempotencyStore)
+		respCode  int
+		respBody  string
+		respError string
+		replayed  bool
+		executed  bool
+	}{
+		{
+			name:     "Without key",
+			method:   http.MethodPost,
+			body:     body,
+			respCode: http.StatusCreated,
+			respBody: created,
+			executed: true,
+		},
+		{
+			name:     "Not POST request",
+			method:   http.MethodPut,
+			key:      "retry-1",
+			body:     body,
+			respCode: http.StatusCreated,
+			respBody: created,
+			executed: true,
+		},
+		{
+			name:   "First request",
+			method: http.MethodPost,
+			key:    "retry-1",
+			body:   body,
+			mockSetup: func(store *mocks.IdempotencyStore) {
+				store.On("ReserveIdempotencyKey", "retry-1", hash, ttl).Return(nil)
+				store.On("SaveIdempotentResponse", "retry-1", http.StatusCreated, "application/json", []byte(created)).Return(nil)
+			},
+			respCode: http.StatusCreated,
+			respBody: created,
+			executed: true,
+		},
+		{
+			name:   "Retry",
+			method: http.MethodPost,
+			key:    "retry-1",
+			body:   body,
+			mockSetup: func(store *mocks.IdempotencyStore) {
+				store.On("ReserveIdempotencyKey", "retry-1", hash, ttl).Return(storage.ErrIdempotencyKeyExists)
+				store.On("GetIdempotencyKey", "retry-1").Return(model.IdempotencyRecord{
+					Key: "retry-1", RequestHash: hash, Status: http.StatusCreated, ContentType: "application/json", Body: []byte(created),
+				}, nil)
+			},
+			respCode: http.StatusCreated,
+			respBody: created,
+			replayed: true,
+		},
+		{
+			name:   "Retry with another body",
+			method: http.MethodPost,
+			key:    "retry-1",
+			body:   `{"service_name":"Spotify"}`,
+			mockSetup: func(store *mocks.IdempotencyStore) {
+				store.On("ReserveIdempotencyKey", "retry-1", mock.Anything, ttl).Return(storage.ErrIdempotencyKeyExists)
+				store.On("GetIdempotencyKey", "retry-1").Return(model.IdempotencyRecord{
+					Key: "retry-1", RequestHash: hash, Status: http.StatusCreated, ContentType: "application/json", Body: []byte(created),
+				}, nil)
+			},
+			respCode:  http.StatusUnprocessableEntity,
+			respError: "idempotency key is already used for another request",
+		},
+		{
+			name:   "Retry while first request is in progress",
+			method: http.MethodPost,
+			key:    "retry-1",
+			body:   body,
+			mockSetup: func(store *mocks.IdempotencyStore) {
+				store.On("ReserveIdempotencyKey", "retry-1", hash, ttl).Return(storage.ErrIdempotencyKeyExists)
+				store.On("GetIdempotencyKey", "retry-1").Return(model.IdempotencyRecord{Key: "retry-1", RequestHash: hash}, nil)
+			},
+			respCode:  http.StatusConflict,
+			respError: "request with the same idempotency key is in progress",
+		},
+		{
+			name:    "Server error is not saved",
+			method:  http.MethodPost,
+			key:     "retry-1",
+			body:    body,
+			failing: true,
+			mockSetup: func(store *mocks.IdempotencyStore) {
+				store.On("ReserveIdempotencyKey", "retry-1", hash, ttl).Return(nil)
+				store.On("DeleteIdempotencyKey", "retry-1").Return(nil)
+			},
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to create subscription",
+			executed:  true,
+		},
+		{
+			name:      "Too long key",
+			method:    http.MethodPost,
+			key:       strings.Repeat("k", maxIdempotencyKeyLength+1),
+			body:      body,
+			respCode:  http.StatusBadRequest,
+			respError: "idempotency key is too long",
+		},
+		{
+			name:      "Too large body",
+			method:    http.MethodPost,
+			key:       "retry-1",
+			body:      strings.Repeat("x", maxIdempotentBodySize+1),
+			respCode:  http.StatusRequestEntityTooLarge,
+			respError: "request body is too large to be sent with idempotency key",
+		},
+		{
+			name:     "Large body without key",
+			method:   http.MethodPost,
+			body:     strings.Repeat("x", maxIdempotentBodySize+1),
+			respCode: http.StatusCreated,
+			respBody: created,
+			executed: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			storeMock := mocks.NewIdempotencyStore(t)
+			if tc.mockSetup != nil {
+				tc.mockSetup(storeMock)
+			}
+
+			executed := false
+
+			router := chi.NewRouter()
+			router.Use(NewIdempotency(logger, storeMock, ttl))
+			router.HandleFunc("/subscription", func(w http.ResponseWriter, r *http.Request) {
+				executed = true
+
+				if tc.failing {
+					renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to create subscription")
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(created))
+			})
+
+			req, err := http.NewRequest(tc.method, "/subscription", strings.NewReader(tc.body))
+			assert.NoError(t, err)
+			if tc.key != "" {
+				req.Header.Set(IdempotencyKeyHeader, tc.key)
+			}
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.respCode, rr.Code)
+			assert.Equal(t, tc.executed, executed)
+
+			if tc.replayed {
+				assert.Equal(t, "true", rr.Header().Get(IdempotentReplayedHeader))
+			} else {
+				assert.Empty(t, rr.Header().Get(IdempotentReplayedHeader))
+			}
+
+			if tc.respBody != "" {
+				assert.Equal(t, tc.respBody, rr.Body.String())
+			} else {
+				var resp Response
+				assert.Equal(t, tc.respError, decodeResp(t, rr, &resp))
+			}
+		})
+	}
+}
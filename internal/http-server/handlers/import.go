@@ -0,0 +1,544 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// Number of rows imported in one transaction
+const importChunkSize = 100
+
+// Max number of row errors listed in import report (the rest are only counted)
+const maxImportErrors = 1000
+
+// Separator of tags in CSV column
+const csvTagSeparator = "|"
+
+// ImportResponse represents summary of subscriptions import
+// swagger:model ImportResponse
+// @ID ImportResponse
+type ImportResponse struct {
+	ImportSummary
+
+	Response
+}
+
+// ImportProblem represents import failure with summary of rows processed before it
+// swagger:model ImportProblem
+// @ID ImportProblem
+type ImportProblem struct {
+	Problem
+
+	ImportSummary
+}
+
+// ImportSummary represents counters and errors of imported rows
+// swagger:model ImportSummary
+// @ID ImportSummary
+type ImportSummary struct {
+	// Nothing is written in dry run, counters show what would be done
+	DryRun bool `json:"dry_run"`
+
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+
+	// Errors of failed rows (first 1000)
+	Errors []ImportErrorItem `json:"errors"`
+}
+
+// ImportErrorItem represents failed import row
+// swagger:model ImportErrorItem
+// @ID ImportErrorItem
+type ImportErrorItem struct {
+	// Line number for CSV, position in array (from 1) for JSON
+	Row int `json:"row"`
+
+	// Reason of failure
+	Error string `json:"error"`
+
+	// Invalid fields of row (validation failures only)
+	Fields []FieldErrorItem `json:"fields,omitempty"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=Importer
+type Importer interface {
+	GetService(id int64) (model.Service, error)
+	ResolveService(name string) (model.Service, error)
+	ImportSubscriptions(specs []model.SubscriptionSpec, policy model.ConflictPolicy, dryRun bool) ([]model.ImportResult, error)
+}
+
+// NewImportHandler godoc
+// @Summary Import subscriptions
+// @Description Import subscriptions from JSON array of create requests or CSV with header row (columns are named as create request fields,
+// @Description tags are separated by "|", unknown columns like id and status of export are ignored). Rows are validated as on creation
+// @Description and imported by chunks of 100 in transactions; in dry run every chunk is rolled back, so conflicts between chunks are not found.
+// @Description Conflict policy applies per row: with fail (default) row of existing subscription is reported in errors as failed
+// @Description and other rows are still imported, import as a whole is not rejected
+// @Tags v1
+// @Accept json
+// @Accept text/csv
+// @Produce json
+// @Param request body []CreateRequest true "Subscriptions data"
+// @Param dry_run query bool false "Validate and report without writing anything"
+// @Param on_conflict query string false "Handling of row with existing subscription of the same service and user: skip it, update subscription or fail the row (default)" Enums(skip, update, fail)
+// @Param delimiter query string false "CSV field delimiter, comma by default" Enums(comma, semicolon, tab)
+// @Param date_format query string false "Format of CSV dates: month (MM-YYYY, default) or iso (YYYY-MM-DD)" Enums(month, iso)
+// @Param Idempotency-Key header string false "Key making retries safe (response of the first request is replayed)"
+// @Success 200 {object} ImportResponse
+// @Failure 400 {object} ImportProblem
+// @Failure 415 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} ImportProblem
+// @Router /subscriptions/import [post]
+func NewImportHandler(logger *slog.Logger, importer Importer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.import"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		// 1.Get import options
+		dryRun, policy, ok := getImportOptions(r, w, logger)
+		if !ok {
+			return
+		}
+
+		// 2.Prepare rows reader in according with content type
+		rows, status, err := newImportRows(r)
+		if err != nil {
+			logger.Error("import is invalid", "details", err)
+
+			code := CodeMalformedBody
+			if status == http.StatusUnsupportedMediaType {
+				code = CodeUnsupportedMediaType
+			}
+
+			renderError(w, r, status, code, "", err.Error())
+
+			return
+		}
+
+		// 3.Validate rows and import them by chunks
+		resp := ImportResponse{ImportSummary: ImportSummary{DryRun: dryRun, Errors: []ImportErrorItem{}}}
+
+		var chunk []model.SubscriptionSpec
+		var chunkRows []int
+
+		failRow := func(row int, msg string, fields ...FieldErrorItem) {
+			resp.Failed++
+			if len(resp.Errors) < maxImportErrors {
+				resp.Errors = append(resp.Errors, ImportErrorItem{Row: row, Error: msg, Fields: fields})
+			}
+		}
+
+		importChunk := func() error {
+			if len(chunk) == 0 {
+				return nil
+			}
+
+			results, err := importer.ImportSubscriptions(chunk, policy, dryRun)
+			if err != nil {
+				return err
+			}
+
+			for i, result := range results {
+				switch result.Outcome {
+				case model.ImportCreated:
+					resp.Created++
+				case model.ImportUpdated:
+					resp.Updated++
+				case model.ImportSkipped:
+					resp.Skipped++
+				default:
+					if errors.Is(result.Err, storage.ErrSubscriptionExists) {
+						failRow(chunkRows[i], "subscription already exists")
+					} else {
+						logger.Error("failed to import row", "row", chunkRows[i], "details", result.Err)
+						failRow(chunkRows[i], "failed to import subscription")
+					}
+				}
+			}
+
+			chunk, chunkRows = chunk[:0], chunkRows[:0]
+
+			return nil
+		}
+
+		// Response with summary of rows processed before failure
+		abort := func(status int, code, msg string) {
+			sortImportErrors(resp.Errors)
+
+			if legacyErrors(r) {
+				w.WriteHeader(status)
+				resp.Response = RespError(msg)
+				render.JSON(w, r, resp)
+
+				return
+			}
+
+			writeProblem(w, status, ImportProblem{Problem: newProblem(r, status, code, "", msg), ImportSummary: resp.ImportSummary})
+		}
+
+		for {
+			req, row, err := rows.next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			var rowErr *importRowError
+			if errors.As(err, &rowErr) {
+				failRow(row, rowErr.Error())
+				continue
+			}
+			if err != nil {
+				logger.Error("import is invalid", "row", row, "details", err)
+
+				msg := "import is invalid: " + err.Error()
+				if row > 0 {
+					msg = fmt.Sprintf("import is invalid: row %d: %s", row, err)
+				}
+				abort(http.StatusBadRequest, CodeMalformedBody, msg)
+
+				return
+			}
+
+			if err := checkCreateReq(&req); err != nil {
+				var validationErr *model.ValidationError
+				if errors.As(err, &validationErr) {
+					failRow(row, err.Error(), makeFieldErrorItems(validationErr.Fields)...)
+				} else {
+					failRow(row, err.Error())
+				}
+				continue
+			}
+
+			err = fillFromCatalog(&req, importer)
+			if errors.Is(err, storage.ErrServiceNotFound) {
+				failRow(row, "service not found")
+				continue
+			}
+			if err != nil {
+				logger.Error("failed to get service", "details", err)
+				abort(http.StatusInternalServerError, CodeInternalError, "failed to get service")
+				return
+			}
+
+			chunk = append(chunk, prepareSubscriptionSpec(&req))
+			chunkRows = append(chunkRows, row)
+
+			if len(chunk) < importChunkSize {
+				continue
+			}
+
+			if err := importChunk(); err != nil {
+				logger.Error("failed to import subscriptions", "details", err)
+				abort(http.StatusInternalServerError, CodeInternalError, "failed to import subscriptions")
+				return
+			}
+		}
+
+		if err := importChunk(); err != nil {
+			logger.Error("failed to import subscriptions", "details", err)
+			abort(http.StatusInternalServerError, CodeInternalError, "failed to import subscriptions")
+			return
+		}
+
+		logger.Info(
+			"subscriptions imported",
+			"dry_run", dryRun, "created", resp.Created, "updated", resp.Updated, "skipped", resp.Skipped, "failed", resp.Failed,
+		)
+
+		sortImportErrors(resp.Errors)
+
+		resp.Response = RespOK()
+		render.JSON(w, r, resp)
+	}
+}
+
+// Order errors by rows (invalid rows are reported before failed rows of earlier chunk)
+func sortImportErrors(items []ImportErrorItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Row < items[j].Row
+	})
+}
+
+func getImportOptions(r *http.Request, w http.ResponseWriter, logger *slog.Logger) (bool, model.ConflictPolicy, bool) {
+	dryRun := false
+	if dryRunStr := r.URL.Query().Get("dry_run"); dryRunStr != "" {
+		var err error
+
+		dryRun, err = strconv.ParseBool(dryRunStr)
+		if err != nil {
+			logger.Error("dry run is invalid", "details", err)
+
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "dry_run", "dry run is invalid")
+
+			return false, "", false
+		}
+	}
+
+	policy := model.ConflictFail
+	if policyStr := r.URL.Query().Get("on_conflict"); policyStr != "" {
+		var err error
+
+		policy, err = model.ConflictPolicyFromString(policyStr)
+		if err != nil {
+			logger.Error("on conflict is invalid", "details", err)
+
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "on_conflict", "on conflict is invalid")
+
+			return false, "", false
+		}
+	}
+
+	return dryRun, policy, true
+}
+
+// Error of one row, import goes on with next row
+type importRowError struct {
+	msg string
+}
+
+func (e *importRowError) Error() string {
+	return e.msg
+}
+
+// Source of imported rows, next returns io.EOF after last row
+type importRows interface {
+	next() (CreateRequest, int, error)
+}
+
+// Get rows reader for request body (JSON if content type is not set), status is for returned error
+func newImportRows(r *http.Request) (importRows, int, error) {
+	mediaType := "application/json"
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		var err error
+
+		mediaType, _, err = mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, http.StatusUnsupportedMediaType, errors.New("unsupported content type")
+		}
+	}
+
+	switch mediaType {
+	case "application/json":
+		return &jsonImportRows{decoder: json.NewDecoder(r.Body)}, 0, nil
+	case "text/csv":
+		return newCSVImportRows(r)
+	}
+
+	return nil, http.StatusUnsupportedMediaType, errors.New("unsupported content type")
+}
+
+// Rows of JSON array decoded one by one
+type jsonImportRows struct {
+	decoder *json.Decoder
+	row     int
+}
+
+func (rows *jsonImportRows) next() (CreateRequest, int, error) {
+	// 1.Array start
+	if rows.row == 0 {
+		token, err := rows.decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return CreateRequest{}, 0, errors.New("empty request")
+		}
+		if delim, ok := token.(json.Delim); err != nil || !ok || delim != '[' {
+			return CreateRequest{}, 0, errors.New("array of subscriptions expected")
+		}
+	}
+
+	rows.row++
+
+	// 2.Array end
+	if !rows.decoder.More() {
+		if _, err := rows.decoder.Token(); err != nil {
+			return CreateRequest{}, rows.row, err
+		}
+		return CreateRequest{}, rows.row, io.EOF
+	}
+
+	// 3.Row (decoder reads whole value before decoding, so row with wrong field types can be skipped)
+	var req CreateRequest
+
+	err := rows.decoder.Decode(&req)
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return CreateRequest{}, rows.row, &importRowError{msg: "failed to decode row: " + typeErr.Field + " is invalid"}
+	}
+	if err != nil {
+		return CreateRequest{}, rows.row, err
+	}
+
+	return req, rows.row, nil
+}
+
+// Rows of CSV with header naming columns
+type csvImportRows struct {
+	reader   *csv.Reader
+	columns  map[string]int
+	isoDates bool
+}
+
+func newCSVImportRows(r *http.Request) (importRows, int, error) {
+	// 1.Format options
+	delimiter := ','
+	if delimiterStr := r.URL.Query().Get("delimiter"); delimiterStr != "" {
+		var ok bool
+
+		delimiter, ok = csvDelimiters[delimiterStr]
+		if !ok {
+			return nil, http.StatusBadRequest, errors.New("delimiter is invalid")
+		}
+	}
+
+	dateFormat := r.URL.Query().Get("date_format")
+	if dateFormat != "" && dateFormat != dateFormatMonth && dateFormat != dateFormatISO {
+		return nil, http.StatusBadRequest, errors.New("date format is invalid")
+	}
+
+	rows := &csvImportRows{reader: csv.NewReader(r.Body), columns: make(map[string]int), isoDates: dateFormat == dateFormatISO}
+	rows.reader.Comma = delimiter
+	rows.reader.TrimLeadingSpace = true
+	rows.reader.ReuseRecord = true
+
+	// 2.Header
+	header, err := rows.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, http.StatusBadRequest, errors.New("empty request")
+	}
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("import header is invalid: %w", err)
+	}
+
+	// Spreadsheet apps may start file with byte order mark
+	for i, column := range header {
+		rows.columns[strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))] = i
+	}
+
+	if _, ok := rows.columns["user_id"]; !ok {
+		return nil, http.StatusBadRequest, errors.New("import header is invalid: user_id column is required")
+	}
+
+	return rows, 0, nil
+}
+
+func (rows *csvImportRows) next() (CreateRequest, int, error) {
+	record, err := rows.reader.Read()
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrFieldCount) {
+		return CreateRequest{}, parseErr.Line, &importRowError{msg: "wrong number of fields"}
+	}
+	if errors.As(err, &parseErr) {
+		return CreateRequest{}, parseErr.Line, err
+	}
+	if err != nil {
+		return CreateRequest{}, 0, err
+	}
+
+	line, _ := rows.reader.FieldPos(0)
+
+	req, err := rows.makeCreateRequest(record)
+	if err != nil {
+		return CreateRequest{}, line, &importRowError{msg: err.Error()}
+	}
+
+	return req, line, nil
+}
+
+// Convert CSV record to create request (values left for validation are copied as is)
+func (rows *csvImportRows) makeCreateRequest(record []string) (CreateRequest, error) {
+	field := func(column string) string {
+		if i, ok := rows.columns[column]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	req := CreateRequest{
+		ServiceName: field("service_name"),
+		UserID:      field("user_id"),
+		StartDate:   rows.date(field("start_date")),
+		EndDate:     rows.date(field("end_date")),
+		Category:    field("category"),
+	}
+
+	var err error
+
+	if str := field("service_id"); str != "" {
+		if req.ServiceID, err = strconv.ParseInt(str, 10, 64); err != nil {
+			return CreateRequest{}, errors.New("service id is invalid")
+		}
+	}
+	if str := field("price"); str != "" {
+		if req.Price, err = model.MoneyFromString(str, model.DefaultCurrency); err != nil {
+			return CreateRequest{}, errors.New("price is invalid")
+		}
+	}
+	if str := field("trial_months"); str != "" {
+		if req.TrialMonths, err = strconv.Atoi(str); err != nil {
+			return CreateRequest{}, errors.New("trial months is invalid")
+		}
+	}
+	if str := field("trial_price"); str != "" {
+		if req.TrialPrice, err = model.MoneyFromString(str, model.DefaultCurrency); err != nil {
+			return CreateRequest{}, errors.New("trial price is invalid")
+		}
+	}
+	if str := field("tags"); str != "" {
+		req.Tags = strings.Split(str, csvTagSeparator)
+	}
+	if str := field("metadata"); str != "" {
+		req.Metadata = json.RawMessage(str)
+	}
+	if str := field("auto_renew"); str != "" {
+		autoRenew, err := strconv.ParseBool(str)
+		if err != nil {
+			return CreateRequest{}, errors.New("auto renew is invalid")
+		}
+		req.AutoRenew = &autoRenew
+	}
+	if str := field("billing_period"); str != "" {
+		if req.BillingPeriod, err = strconv.Atoi(str); err != nil {
+			return CreateRequest{}, errors.New("billing period is invalid")
+		}
+	}
+
+	return req, nil
+}
+
+// Convert ISO date to format of create request (invalid date is kept for validation)
+func (rows *csvImportRows) date(str string) string {
+	if !rows.isoDates || str == "" {
+		return str
+	}
+
+	date, err := model.DateFromStringISO(str)
+	if err != nil {
+		return str
+	}
+
+	return date.ToString()
+}
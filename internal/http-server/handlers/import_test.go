@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestImportHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	userId := uuid.NewString()
+
+	jsonRow := func(serviceName string) string {
+		return fmt.Sprintf(`{"service_name": %q, "price": "9.99", "user_id": %q, "start_date": "01-2026", "end_date": "04-2026"}`, serviceName, userId)
+	}
+
+	created := model.ImportResult{Outcome: model.ImportCreated, ID: 1}
+	exists := model.ImportResult{Outcome: model.ImportFailed, Err: storage.ErrSubscriptionExists}
+
+	cases := []struct {
+		name        string
+		query       string
+		contentType string
+		body        string
+		policy      model.ConflictPolicy
+		dryRun      bool
+		specs       int
+		results     []model.ImportResult
+		service     *model.Service
+		respCode    int
+		respError   string
+		resp        ImportSummary
+		mockError   error
+	}{
+		{
+			name:        "Success JSON",
+			contentType: "application/json",
+			body:        "[" + jsonRow("Netflix") + `, {"service_name": "Wink", "price": "1.00"}, ` + jsonRow("Wink") + "]",
+			policy:      model.ConflictFail,
+			specs:       2,
+			results:     []model.ImportResult{created, exists},
+			respCode:    http.StatusOK,
+			resp: ImportSummary{
+				Created: 1,
+				Failed:  2,
+				Errors: []ImportErrorItem{
+					{Row: 2, Error: "empty user id; empty start date", Fields: []FieldErrorItem{
+						{Field: "user_id", Code: CodeMissingValue, Message: "empty user id"},
+						{Field: "start_date", Code: CodeMissingValue, Message: "empty start date"},
+					}},
+					{Row: 3, Error: "subscription already exists"},
+				},
+			},
+		},
+		{
+			name:        "Success CSV dry run",
+			query:       "?dry_run=true&on_conflict=update&delimiter=semicolon&date_format=iso",
+			contentType: "text/csv; charset=utf-8",
+			body: "id;service_name;price;user_id;start_date;end_date;status;tags\n" +
+				"1;Netflix;9.99;" + userId + ";2026-01-01;2026-04-01;active;video|family\n" +
+				"2;Wink;trash;" + userId + ";2026-01-01;2026-04-01;active;\n" +
+				"3;Wink;1.00\n",
+			policy:   model.ConflictUpdate,
+			dryRun:   true,
+			specs:    1,
+			results:  []model.ImportResult{{Outcome: model.ImportUpdated, ID: 1}},
+			respCode: http.StatusOK,
+			resp: ImportSummary{
+				DryRun:  true,
+				Updated: 1,
+				Failed:  2,
+				Errors:  []ImportErrorItem{{Row: 3, Error: "price is invalid"}, {Row: 4, Error: "wrong number of fields"}},
+			},
+		},
+		{
+			name:        "Catalog service by id",
+			contentType: "application/json",
+			body:        fmt.Sprintf(`[{"service_id": 7, "user_id": %q, "start_date": "01-2026"}, {"service_id": 8, "user_id": %q, "start_date": "01-2026"}]`, userId, userId),
+			policy:      model.ConflictFail,
+			specs:       1,
+			results:     []model.ImportResult{created},
+			service:     &model.Service{ID: 7, Name: "Netflix", DefaultPrice: 999},
+			respCode:    http.StatusOK,
+			resp: ImportSummary{
+				Created: 1,
+				Failed:  1,
+				Errors:  []ImportErrorItem{{Row: 2, Error: "service not found"}},
+			},
+		},
+		{
+			name:        "Wrong field type",
+			contentType: "application/json",
+			body:        `[{"service_name": "Netflix", "trial_months": "one"}]`,
+			respCode:    http.StatusOK,
+			resp: ImportSummary{
+				Failed: 1,
+				Errors: []ImportErrorItem{{Row: 1, Error: "failed to decode row: trial_months is invalid"}},
+			},
+		},
+		{
+			name:        "Invalid dry run",
+			query:       "?dry_run=maybe",
+			contentType: "application/json",
+			body:        "[]",
+			respCode:    http.StatusBadRequest,
+			respError:   "dry run is invalid",
+		},
+		{
+			name:        "Invalid conflict policy",
+			query:       "?on_conflict=replace",
+			contentType: "application/json",
+			body:        "[]",
+			respCode:    http.StatusBadRequest,
+			respError:   "on conflict is invalid",
+		},
+		{
+			name:        "Unsupported content type",
+			contentType: "application/xml",
+			body:        "<subscriptions/>",
+			respCode:    http.StatusUnsupportedMediaType,
+			respError:   "unsupported content type",
+		},
+		{
+			name:        "Not an array",
+			contentType: "application/json",
+			body:        jsonRow("Netflix"),
+			respCode:    http.StatusBadRequest,
+			respError:   "import is invalid: array of subscriptions expected",
+		},
+		{
+			name:        "Broken JSON",
+			contentType: "application/json",
+			body:        "[" + jsonRow("Netflix") + ", {",
+			respCode:    http.StatusBadRequest,
+			respError:   "import is invalid: row 2: unexpected EOF",
+		},
+		{
+			name:        "CSV without user id column",
+			contentType: "text/csv",
+			body:        "service_name,price\nNetflix,9.99\n",
+			respCode:    http.StatusBadRequest,
+			respError:   "import header is invalid: user_id column is required",
+		},
+		{
+			name:        "Any other importer error case",
+			contentType: "application/json",
+			body:        "[" + jsonRow("Netflix") + "]",
+			policy:      model.ConflictFail,
+			specs:       1,
+			respCode:    http.StatusInternalServerError,
+			respError:   "failed to import subscriptions",
+			mockError:   errors.New("any error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			importerMock := mocks.NewImporter(t)
+
+			if tc.specs > 0 {
+				importerMock.On("ImportSubscriptions", mock.MatchedBy(func(specs []model.SubscriptionSpec) bool {
+					return len(specs) == tc.specs
+				}), tc.policy, tc.dryRun).Return(tc.results, tc.mockError)
+			}
+			if tc.service != nil {
+				importerMock.On("GetService", tc.service.ID).Return(*tc.service, nil)
+				importerMock.On("GetService", tc.service.ID+1).Return(model.Service{}, storage.ErrServiceNotFound)
+			}
+
+			router := chi.NewRouter()
+			router.Post("/subscriptions/import", NewImportHandler(logger, importerMock))
+
+			req, err := http.NewRequest(http.MethodPost, "/subscriptions/import"+tc.query, strings.NewReader(tc.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", tc.contentType)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.respCode, rr.Code)
+
+			var resp ImportResponse
+			assert.Equal(t, tc.respError, decodeResp(t, rr, &resp))
+
+			if tc.respError == "" {
+				assert.Equal(t, ImportResponse{ImportSummary: tc.resp, Response: RespOK()}, resp)
+			}
+		})
+	}
+}
+
+func TestImportHandlerChunks(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	rows := make([]string, 0, importChunkSize+1)
+	for i := 0; i <= importChunkSize; i++ {
+		rows = append(rows, fmt.Sprintf(`{"service_name": "Service %d", "price": 100, "user_id": %q, "start_date": "01-2026"}`, i, uuid.NewString()))
+	}
+
+	chunk := func(size int) interface{} {
+		return mock.MatchedBy(func(specs []model.SubscriptionSpec) bool { return len(specs) == size })
+	}
+
+	results := make([]model.ImportResult, importChunkSize)
+	for i := range results {
+		results[i] = model.ImportResult{Outcome: model.ImportCreated, ID: int64(i + 1)}
+	}
+
+	importerMock := mocks.NewImporter(t)
+	importerMock.On("ImportSubscriptions", chunk(importChunkSize), model.ConflictSkip, false).Return(results, nil).Once()
+	importerMock.On("ImportSubscriptions", chunk(1), model.ConflictSkip, false).Return([]model.ImportResult{{Outcome: model.ImportSkipped}}, nil).Once()
+
+	router := chi.NewRouter()
+	router.Post("/subscriptions/import", NewImportHandler(logger, importerMock))
+
+	req, err := http.NewRequest(http.MethodPost, "/subscriptions/import?on_conflict=skip", strings.NewReader("["+strings.Join(rows, ",")+"]"))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp ImportResponse
+
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, importChunkSize, resp.Created)
+	assert.Equal(t, 1, resp.Skipped)
+}
@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"context"
 	"em_golang_rest_service_example/internal/model"
+	"encoding/json"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/render"
 )
 
 // ListItem represents one subscription in list model
@@ -20,8 +23,8 @@ type ListItem struct {
 	// Subscription service name
 	ServiceName string `json:"service_name"`
 
-	// Subscription monthly price
-	Price int `json:"price"`
+	// Subscription monthly price (decimal string)
+	Price model.Money `json:"price" swaggertype:"string" example:"9.99"`
 
 	// If of user who purchased the subscription
 	UserID string `json:"user_id"`
@@ -31,6 +34,36 @@ type ListItem struct {
 
 	// Start date of subscription
 	EndDate string `json:"end_date"`
+
+	// Number of first months billed with trial price
+	TrialMonths int `json:"trial_months"`
+
+	// Monthly price during trial (decimal string)
+	TrialPrice model.Money `json:"trial_price" swaggertype:"string" example:"0.00"`
+
+	// Lifecycle status: active, paused, cancelled or expired
+	Status string `json:"status"`
+
+	// Spending category (empty if not categorized)
+	Category string `json:"category"`
+
+	// Free-form labels
+	Tags []string `json:"tags"`
+
+	// Custom attributes (empty object if not set)
+	Metadata json.RawMessage `json:"metadata" swaggertype:"object"`
+
+	// Extend subscription by billing period when it passes end date
+	AutoRenew bool `json:"auto_renew"`
+
+	// Billing period in months
+	BillingPeriod int `json:"billing_period"`
+
+	// Creation moment (RFC 3339)
+	CreatedAt string `json:"created_at"`
+
+	// Moment of last change of subscription or its details (RFC 3339)
+	UpdatedAt string `json:"updated_at"`
 }
 
 // ListResponse represents subscription list model
@@ -43,19 +76,47 @@ type ListResponse struct {
 	Response
 }
 
+// List with requested fields of subscriptions only
+type sparseListResponse struct {
+	Items []any `json:"items"`
+
+	Response
+}
+
+// Media type of list streamed as one JSON object per line
+const ndjsonContentType = "application/x-ndjson"
+
 //go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=ListReader
 type ListReader interface {
-	GetSubscriptions(limit, offset *int) ([]model.Subscription, error)
+	GetSubscriptions(limit, offset *int, filter model.SubscriptionFilter, fields model.FieldSet) ([]model.Subscription, error)
+	StreamSubscriptions(ctx context.Context, limit, offset *int, filter model.SubscriptionFilter, fields model.FieldSet, yield func(sub *model.Subscription) error) error
 }
 
 // NewListHandler godoc
 // @Summary Get all subscriptions
-// @Description Get all subscriptions
+// @Description Get all subscriptions. With Accept: application/x-ndjson subscriptions (ordered by id) are streamed
+// @Description as ListItem objects one per line; if reading fails in the middle, error object is the last line.
+// @Description JSON response has ETag (hash of response), so it can be requested conditionally with If-None-Match
+// @Description (there is no Last-Modified, as deletions would not move it).
+// @Description With fields parameter items have listed fields only (unknown field is validation error)
+// @Tags v1
 // @Accept json
-// @Produce json
+// @Produce json,application/x-ndjson
+// @Param Accept header string false "application/x-ndjson for streaming"
+// @Param limit query int false "Page size (requires offset)"
+// @Param offset query int false "Page offset (requires limit)"
+// @Param status query string false "Status filter" Enums(active, paused, cancelled, expired)
+// @Param category query string false "Category filter"
+// @Param tag query string false "Tag filter"
+// @Param metadata.key query string false "Metadata filter, key is any top level metadata key (several keys are allowed)"
+// @Param filter query string false "Filter expression, e.g. price>=300 and service_name~\"yan*\" and active_in(2025-01,2025-06); amounts are integers in minor units or quoted decimals (price>=\"3.00\")"
+// @Param fields query string false "Comma separated fields of items, e.g. id,service_name,price (all by default)"
+// @Param If-None-Match header string false "ETag of cached response"
 // @Success 200 {object} ListResponse
-// @Failure 400 {object} ListResponse
-// @Failure 500 {object} ListResponse
+// @Header 200 {string} ETag "Hash of response"
+// @Success 304 "List is not modified"
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /subscriptions [get]
 func NewListHandler(logger *slog.Logger, listReader ListReader) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -67,6 +128,7 @@ func NewListHandler(logger *slog.Logger, listReader ListReader) http.HandlerFunc
 		)
 
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Add("Vary", "Accept")
 
 		// 1.Get optional params and validate it
 		limit, offset, ok := getValidatedOptParams(r, w, logger)
@@ -74,33 +136,141 @@ func NewListHandler(logger *slog.Logger, listReader ListReader) http.HandlerFunc
 			return
 		}
 
-		// 2.Get subscriptions
+		filter, err := getSubscriptionFilter(r)
+		if err != nil {
+			logger.Error("invalid filter", "details", err)
+
+			renderFieldError(w, r, err)
+
+			return
+		}
+
+		fields, err := getFieldSet(r, model.ListFields)
+		if err != nil {
+			logger.Info("invalid fields", "details", err)
+
+			renderFieldError(w, r, err)
+
+			return
+		}
+
+		// 2.Stream subscriptions if client asks for it
+		if acceptsNDJSON(r) {
+			streamList(w, r, logger, listReader, filter, fields, limit, offset)
+			return
+		}
+
+		// 3.Get subscriptions
 		var subscriptions []model.Subscription
-		var err error
 
 		if limit == 0 && offset == 0 {
-			subscriptions, err = listReader.GetSubscriptions(nil, nil)
+			subscriptions, err = listReader.GetSubscriptions(nil, nil, filter, fields)
 		} else {
-			subscriptions, err = listReader.GetSubscriptions(&limit, &offset)
+			subscriptions, err = listReader.GetSubscriptions(&limit, &offset, filter, fields)
 		}
 
 		if err != nil {
 			logger.Error("failed to get subscription", "details", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, ListResponse{Response: RespError("failed to get subscription")})
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to get subscription")
 
 			return
 		}
 
 		logger.Info("got subscriptions")
 
-		// 3.Prepare response and render it
-		resp := makeListResp(subscriptions)
-		render.JSON(w, r, resp)
+		// 4.Prepare response and render it (unless client has the same one)
+		var resp any = makeListResp(subscriptions)
+		if fields != nil {
+			resp = makeSparseListResp(subscriptions, fields)
+		}
+		// List has no Last-Modified: deleted subscriptions do not move the latest change of remaining ones,
+		// so only ETag tells if list is changed
+		renderConditionalJSON(w, r, time.Time{}, resp)
 	}
 }
 
+// Check if NDJSON is listed in Accept header
+func acceptsNDJSON(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(value, ",") {
+			mediaType, _, _ := strings.Cut(mediaRange, ";")
+			if strings.EqualFold(strings.TrimSpace(mediaType), ndjsonContentType) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Write subscriptions one per line while reading them from storage (page is read only if limit or offset is set);
+// reading is stopped when client goes away
+func streamList(w http.ResponseWriter, r *http.Request, logger *slog.Logger, listReader ListReader, filter model.SubscriptionFilter, fields model.FieldSet, limit, offset int) {
+	var limitPtr, offsetPtr *int
+	if limit != 0 || offset != 0 {
+		limitPtr, offsetPtr = &limit, &offset
+	}
+
+	encoder := json.NewEncoder(w)
+	controller := http.NewResponseController(w)
+	clearWriteDeadline(controller, logger)
+
+	count := 0
+
+	// Response starts with first row, so storage error before it is still reported with status
+	start := func() {
+		w.Header().Set("Content-Type", ndjsonContentType+"; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+	}
+
+	err := listReader.StreamSubscriptions(r.Context(), limitPtr, offsetPtr, filter, fields, func(sub *model.Subscription) error {
+		if count == 0 {
+			start()
+		}
+
+		if err := encoder.Encode(selectFields(makeListItem(sub), fields)); err != nil {
+			return err
+		}
+
+		count++
+		if count%exportFlushRows == 0 {
+			// Response writer may not support flushing, rows are sent when buffer is full then
+			_ = controller.Flush()
+		}
+
+		return nil
+	})
+	if err != nil && r.Context().Err() != nil {
+		logger.Info("client closed subscriptions stream", "rows", count)
+		return
+	}
+	if err != nil && count == 0 {
+		logger.Error("failed to get subscription", "details", err)
+
+		renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to get subscription")
+
+		return
+	}
+	if err != nil {
+		// Status is already sent, so error is reported by last line
+		logger.Error("subscriptions stream interrupted", "rows", count, "details", err)
+		if legacyErrors(r) {
+			_ = encoder.Encode(RespError("failed to get subscription"))
+		} else {
+			_ = encoder.Encode(newProblem(r, http.StatusInternalServerError, CodeInternalError, "", "failed to get subscription"))
+		}
+
+		return
+	}
+
+	if count == 0 {
+		start()
+	}
+
+	logger.Info("got subscriptions", "rows", count)
+}
+
 func getValidatedOptParams(r *http.Request, w http.ResponseWriter, logger *slog.Logger) (int, int, bool) {
 	limitStr := r.URL.Query().Get("limit")
 	offsetStr := r.URL.Query().Get("offset")
@@ -111,16 +281,14 @@ func getValidatedOptParams(r *http.Request, w http.ResponseWriter, logger *slog.
 	if limitStr != "" && offsetStr == "" {
 		logger.Error("no offset value while limit is set")
 
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, ListResponse{Response: RespError("no offset value while limit is set")})
+		renderError(w, r, http.StatusBadRequest, CodeMissingValue, "offset", "no offset value while limit is set")
 
 		return 0, 0, false
 	}
 	if limitStr == "" && offsetStr != "" {
 		logger.Error("no offset value while limit is set")
 
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, ListResponse{Response: RespError("no limit value while offset is set")})
+		renderError(w, r, http.StatusBadRequest, CodeMissingValue, "limit", "no limit value while offset is set")
 
 		return 0, 0, false
 	}
@@ -129,16 +297,14 @@ func getValidatedOptParams(r *http.Request, w http.ResponseWriter, logger *slog.
 	if err != nil {
 		logger.Error("invalid limit format", "details", err)
 
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, ListResponse{Response: RespError("invalid limit format")})
+		renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "limit", "invalid limit format")
 
 		return 0, 0, false
 	}
 	if limit < 0 {
 		logger.Error("invalid limit value (less than zero)")
 
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, ListResponse{Response: RespError("invalid limit value (less than zero)")})
+		renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "limit", "invalid limit value (less than zero)")
 
 		return 0, 0, false
 	}
@@ -147,16 +313,14 @@ func getValidatedOptParams(r *http.Request, w http.ResponseWriter, logger *slog.
 	if err != nil {
 		logger.Error("invalid offset format", "details", err)
 
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, ListResponse{Response: RespError("invalid offset format")})
+		renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "offset", "invalid offset format")
 
 		return 0, 0, false
 	}
 	if offset < 0 {
 		logger.Error("invalid offset value (less than zero)")
 
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, ListResponse{Response: RespError("invalid offset value (less than zero)")})
+		renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "offset", "invalid offset value (less than zero)")
 
 		return 0, 0, false
 	}
@@ -171,16 +335,42 @@ func makeListResp(subscriptions []model.Subscription) ListResponse {
 	}
 
 	for i := 0; i < len(subscriptions); i++ {
-		item := ListItem{
-			Id:          subscriptions[i].ID,
-			ServiceName: subscriptions[i].ServiceName,
-			Price:       subscriptions[i].Price,
-			UserID:      subscriptions[i].UserID.String(),
-			StartDate:   subscriptions[i].StartDate.ToString(),
-			EndDate:     subscriptions[i].EndDate.ToString(),
-		}
-		resp.Items = append(resp.Items, item)
+		resp.Items = append(resp.Items, makeListItem(&subscriptions[i]))
+	}
+
+	return resp
+}
+
+func makeSparseListResp(subscriptions []model.Subscription, fields model.FieldSet) sparseListResponse {
+	resp := sparseListResponse{
+		Items:    []any{},
+		Response: RespOK(),
+	}
+
+	for i := 0; i < len(subscriptions); i++ {
+		resp.Items = append(resp.Items, selectFields(makeListItem(&subscriptions[i]), fields))
 	}
 
 	return resp
 }
+
+func makeListItem(subscription *model.Subscription) ListItem {
+	return ListItem{
+		Id:            subscription.ID,
+		ServiceName:   subscription.ServiceName,
+		Price:         subscription.Price,
+		UserID:        subscription.UserID.String(),
+		StartDate:     subscription.StartDate.ToString(),
+		EndDate:       subscription.EndDate.ToString(),
+		TrialMonths:   subscription.TrialMonths,
+		TrialPrice:    subscription.TrialPrice,
+		Status:        string(subscription.Status),
+		Category:      subscription.Category,
+		Tags:          makeTagItems(subscription.Tags),
+		Metadata:      makeMetadata(subscription.Metadata),
+		AutoRenew:     subscription.AutoRenew,
+		BillingPeriod: subscription.Period(),
+		CreatedAt:     subscription.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:     subscription.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -2,20 +2,28 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
 	"em_golang_rest_service_example/internal/model"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	neturl "net/url"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListHandler(t *testing.T) {
@@ -25,6 +33,9 @@ func TestListHandler(t *testing.T) {
 		name         string
 		limit        string
 		offset       string
+		status       string
+		metadata     map[string]string
+		filterExpr   string
 		respCode     int
 		respError    string
 		needMockCall bool
@@ -42,6 +53,42 @@ func TestListHandler(t *testing.T) {
 			offset:       "100",
 			needMockCall: true,
 		},
+		{
+			name:         "Success with status filter",
+			respCode:     http.StatusOK,
+			status:       "paused",
+			needMockCall: true,
+		},
+		{
+			name:         "Success with metadata filter",
+			respCode:     http.StatusOK,
+			metadata:     map[string]string{"cost_center": "R&D", "team": "core"},
+			needMockCall: true,
+		},
+		{
+			name:      "Invalid metadata filter key",
+			metadata:  map[string]string{"cost center": "R&D"},
+			respCode:  http.StatusBadRequest,
+			respError: "metadata filter is invalid",
+		},
+		{
+			name:         "Success with filter expression",
+			respCode:     http.StatusOK,
+			filterExpr:   `price >= 300 and service_name ~ "yan*" and active_in(2025-01, 2025-06)`,
+			needMockCall: true,
+		},
+		{
+			name:       "Invalid filter expression",
+			filterExpr: `price ~ "3*"`,
+			respCode:   http.StatusBadRequest,
+			respError:  "filter is invalid: operator ~ is not allowed for price, allowed operators: = != < <= > >= at position 7",
+		},
+		{
+			name:      "Invalid status filter",
+			status:    "trash",
+			respCode:  http.StatusBadRequest,
+			respError: "status filter is invalid",
+		},
 		{
 			name:         "Limit set, but offset - no",
 			limit:        "100",
@@ -102,6 +149,18 @@ func TestListHandler(t *testing.T) {
 			listMock := mocks.NewListReader(t)
 
 			if tc.needMockCall {
+				var filter model.SubscriptionFilter
+				if tc.status != "" {
+					status := model.Status(tc.status)
+					filter.Status = &status
+				}
+				filter.Metadata = tc.metadata
+				if tc.filterExpr != "" {
+					expr, err := model.ParseFilterExpr(tc.filterExpr)
+					assert.NoError(t, err)
+					filter.Expr = expr
+				}
+
 				if tc.limit != "" && tc.offset != "" {
 					limit, err := strconv.Atoi(tc.limit)
 					assert.NoError(t, err)
@@ -109,19 +168,44 @@ func TestListHandler(t *testing.T) {
 					offset, err := strconv.Atoi(tc.offset)
 					assert.NoError(t, err)
 
-					listMock.On("GetSubscriptions", &limit, &offset).Return([]model.Subscription{}, tc.mockError)
+					listMock.On("GetSubscriptions", &limit, &offset, filter, model.FieldSet(nil)).Return([]model.Subscription{}, tc.mockError)
 				} else {
 					var limit, offset *int
-					listMock.On("GetSubscriptions", limit, offset).Return([]model.Subscription{}, tc.mockError)
+					listMock.On("GetSubscriptions", limit, offset, filter, model.FieldSet(nil)).Return([]model.Subscription{}, tc.mockError)
 				}
 			}
 
 			router := chi.NewRouter()
 			router.Get("/subscriptions", NewListHandler(logger, listMock))
 
+			url := constructURL(t, &tc.limit, &tc.offset)
+			if tc.status != "" {
+				if strings.Contains(url, "?") {
+					url += "&status=" + tc.status
+				} else {
+					url += "?status=" + tc.status
+				}
+			}
+			for key, value := range tc.metadata {
+				param := neturl.QueryEscape("metadata."+key) + "=" + neturl.QueryEscape(value)
+				if strings.Contains(url, "?") {
+					url += "&" + param
+				} else {
+					url += "?" + param
+				}
+			}
+			if tc.filterExpr != "" {
+				param := "filter=" + neturl.QueryEscape(tc.filterExpr)
+				if strings.Contains(url, "?") {
+					url += "&" + param
+				} else {
+					url += "?" + param
+				}
+			}
+
 			req, err := http.NewRequest(
 				http.MethodGet,
-				constructURL(t, &tc.limit, &tc.offset),
+				url,
 				bytes.NewReader([]byte{}),
 			)
 			assert.NoError(t, err)
@@ -131,16 +215,274 @@ func TestListHandler(t *testing.T) {
 
 			assert.Equal(t, tc.respCode, rr.Code)
 
-			body := rr.Body.String()
-
 			var resp ReadResponse
+			assert.Equal(t, tc.respError, decodeResp(t, rr, &resp))
+		})
+	}
+}
+
+func TestListHandlerNDJSON(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	subscriptions := []model.Subscription{
+		{ID: 1, SubscriptionSpec: model.SubscriptionSpec{ServiceName: "Okko", Price: 39900, UserID: uuid.New(), Tags: []string{"video"}}, Status: model.StatusActive},
+		{ID: 2, SubscriptionSpec: model.SubscriptionSpec{ServiceName: "Ivi", Price: 29900, UserID: uuid.New()}, Status: model.StatusPaused},
+		{ID: 3, SubscriptionSpec: model.SubscriptionSpec{ServiceName: "Kion", Price: 19900, UserID: uuid.New()}, Status: model.StatusActive},
+	}
+
+	one := 1
+
+	line := func(sub *model.Subscription) string {
+		data, err := json.Marshal(makeListItem(sub))
+		assert.NoError(t, err)
+		return string(data) + "\n"
+	}
+
+	cases := []struct {
+		name        string
+		url         string
+		accept      string
+		limit       *int
+		offset      *int
+		subs        []model.Subscription
+		mockError   error
+		respCode    int
+		respBody    string
+		contentType string
+	}{
+		{
+			name:        "Success",
+			url:         "/subscriptions",
+			accept:      "application/json;q=0.5, application/x-ndjson",
+			subs:        subscriptions,
+			respCode:    http.StatusOK,
+			respBody:    line(&subscriptions[0]) + line(&subscriptions[1]) + line(&subscriptions[2]),
+			contentType: "application/x-ndjson; charset=utf-8",
+		},
+		{
+			name:        "Page is read from storage",
+			url:         "/subscriptions?limit=1&offset=1",
+			accept:      "application/x-ndjson",
+			limit:       &one,
+			offset:      &one,
+			subs:        subscriptions[1:2],
+			respCode:    http.StatusOK,
+			respBody:    line(&subscriptions[1]),
+			contentType: "application/x-ndjson; charset=utf-8",
+		},
+		{
+			name:        "Nothing found",
+			url:         "/subscriptions",
+			accept:      "application/x-ndjson",
+			respCode:    http.StatusOK,
+			contentType: "application/x-ndjson; charset=utf-8",
+		},
+		{
+			name:        "Error before first row",
+			url:         "/subscriptions",
+			accept:      "application/x-ndjson",
+			mockError:   errors.New("any error"),
+			respCode:    http.StatusInternalServerError,
+			respBody:    `{"title":"Internal Server Error","status":500,"code":"internal_error","detail":"failed to get subscription"}` + "\n",
+			contentType: ProblemContentType,
+		},
+		{
+			name:        "Error in the middle",
+			url:         "/subscriptions",
+			accept:      "application/x-ndjson",
+			subs:        subscriptions[:1],
+			mockError:   errors.New("any error"),
+			respCode:    http.StatusOK,
+			respBody:    line(&subscriptions[0]) + `{"title":"Internal Server Error","status":500,"code":"internal_error","detail":"failed to get subscription"}` + "\n",
+			contentType: "application/x-ndjson; charset=utf-8",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			listMock := mocks.NewListReader(t)
+			listMock.On("StreamSubscriptions", mock.Anything, tc.limit, tc.offset, model.SubscriptionFilter{}, model.FieldSet(nil), mock.Anything).
+				Run(func(args mock.Arguments) {
+					yield := args.Get(5).(func(sub *model.Subscription) error)
+					for i := range tc.subs {
+						assert.NoError(t, yield(&tc.subs[i]))
+					}
+				}).
+				Return(func(context.Context, *int, *int, model.SubscriptionFilter, model.FieldSet, func(sub *model.Subscription) error) error {
+					return tc.mockError
+				})
 
-			assert.Nil(t, json.Unmarshal([]byte(body), &resp))
-			assert.Equal(t, tc.respError, resp.Error)
+			router := chi.NewRouter()
+			router.Get("/subscriptions", NewListHandler(logger, listMock))
+
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			assert.NoError(t, err)
+			req.Header.Set("Accept", tc.accept)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.respCode, rr.Code)
+			assert.Equal(t, tc.contentType, rr.Header().Get("Content-Type"))
+			assert.Equal(t, tc.respBody, rr.Body.String())
 		})
 	}
 }
 
+func TestListHandlerNDJSONWriteTimeout(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	const rows = 3 * exportFlushRows
+	sub := model.Subscription{ID: 1, SubscriptionSpec: model.SubscriptionSpec{ServiceName: "Okko", Price: 39900, UserID: uuid.New()}, Status: model.StatusActive}
+
+	// Rows are read longer than server write timeout
+	listMock := mocks.NewListReader(t)
+	listMock.On("StreamSubscriptions", mock.Anything, (*int)(nil), (*int)(nil), model.SubscriptionFilter{}, model.FieldSet(nil), mock.Anything).
+		Run(func(args mock.Arguments) {
+			yield := args.Get(5).(func(sub *model.Subscription) error)
+			for i := 0; i < rows; i++ {
+				if i%exportFlushRows == 0 {
+					time.Sleep(100 * time.Millisecond)
+				}
+				if yield(&sub) != nil {
+					return
+				}
+			}
+		}).
+		Return(nil)
+
+	router := chi.NewRouter()
+	router.Get("/subscriptions", NewListHandler(logger, listMock))
+
+	server := httptest.NewUnstartedServer(router)
+	server.Config.WriteTimeout = 150 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/subscriptions", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", ndjsonContentType)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, rows, strings.Count(string(body), "\n"))
+}
+
+func TestListHandlerConditional(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	subscriptions := []model.Subscription{
+		{ID: 1, UpdatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{ID: 2, UpdatedAt: time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)},
+	}
+
+	listReaderMock := mocks.NewListReader(t)
+	listReaderMock.On("GetSubscriptions", (*int)(nil), (*int)(nil), model.SubscriptionFilter{}, model.FieldSet(nil)).Return(subscriptions, nil).Twice()
+
+	router := chi.NewRouter()
+	router.Get("/subscriptions", NewListHandler(logger, listReaderMock))
+
+	// 1.List has ETag only
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/subscriptions", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("Last-Modified"))
+	assert.NotEmpty(t, rr.Header().Get("ETag"))
+
+	etag := rr.Header().Get("ETag")
+
+	// 2.Polling with the same ETag gets no body
+	req := httptest.NewRequest(http.MethodGet, "/subscriptions", nil)
+	req.Header.Set("If-None-Match", etag)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.String())
+
+	// 3.Deletion does not move the latest change of remaining subscriptions, so If-Modified-Since is ignored
+	listReaderMock.On("GetSubscriptions", (*int)(nil), (*int)(nil), model.SubscriptionFilter{}, model.FieldSet(nil)).Return(subscriptions[1:], nil).Twice()
+
+	req = httptest.NewRequest(http.MethodGet, "/subscriptions", nil)
+	req.Header.Set("If-Modified-Since", "Mon, 02 Mar 2026 08:00:00 GMT")
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp ListResponse
+	assert.Equal(t, "", decodeResp(t, rr, &resp))
+	assert.Len(t, resp.Items, 1)
+
+	req = httptest.NewRequest(http.MethodGet, "/subscriptions", nil)
+	req.Header.Set("If-None-Match", etag)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotEqual(t, etag, rr.Header().Get("ETag"))
+}
+
+func TestListHandlerFields(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	fields := model.FieldSet{model.FieldID: true, model.FieldServiceName: true, model.FieldPrice: true}
+	subscriptions := []model.Subscription{
+		{ID: 1, SubscriptionSpec: model.SubscriptionSpec{ServiceName: "Netflix", Price: 999}},
+		{ID: 2, SubscriptionSpec: model.SubscriptionSpec{ServiceName: "Spotify", Price: 499}},
+	}
+
+	listReaderMock := mocks.NewListReader(t)
+	listReaderMock.On("GetSubscriptions", (*int)(nil), (*int)(nil), model.SubscriptionFilter{}, fields).Return(subscriptions, nil)
+	listReaderMock.On("StreamSubscriptions", mock.Anything, (*int)(nil), (*int)(nil), model.SubscriptionFilter{}, fields, mock.Anything).
+		Run(func(args mock.Arguments) {
+			yield := args.Get(5).(func(sub *model.Subscription) error)
+			for i := range subscriptions {
+				assert.NoError(t, yield(&subscriptions[i]))
+			}
+		}).
+		Return(nil)
+
+	router := chi.NewRouter()
+	router.Get("/subscriptions", NewListHandler(logger, listReaderMock))
+
+	// 1.Items have requested fields only
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/subscriptions?fields=id,service_name,price", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"status":"OK","items":[{"id":1,"service_name":"Netflix","price":"9.99"},{"id":2,"service_name":"Spotify","price":"4.99"}]}`, rr.Body.String())
+
+	// 2.The same for streamed list
+	req := httptest.NewRequest(http.MethodGet, "/subscriptions?fields=id,service_name,price", nil)
+	req.Header.Set("Accept", ndjsonContentType)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"id":1,"price":"9.99","service_name":"Netflix"}`+"\n"+`{"id":2,"price":"4.99","service_name":"Spotify"}`+"\n", rr.Body.String())
+
+	// 3.Details are not listed
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/subscriptions?fields=id,renewals", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	var resp ListResponse
+	assert.Contains(t, decodeResp(t, rr, &resp), `unknown field "renewals", allowed fields: id, service_name, price`)
+}
+
 // Helper function for cinstruct URL with optional parameters
 func constructURL(t *testing.T, limit, offset *string) string {
 	t.Helper()
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+// MemberItem represents user sharing subscription cost
+// swagger:model MemberItem
+// @ID MemberItem
+type MemberItem struct {
+	// Id of member user
+	UserID string `json:"user_id"`
+
+	// Split weight: member pays weight / sum of weights of cost (from 1 to 1000)
+	Weight int64 `json:"weight"`
+}
+
+// MembersRequest represents new members of shared subscription
+// swagger:model MembersRequest
+// @ID MembersRequest
+type MembersRequest struct {
+	// All members of subscription (empty list makes owner pay alone)
+	Members []MemberItem `json:"members"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=MemberSetter
+type MemberSetter interface {
+	SetMembers(id int64, members []model.Member) error
+}
+
+// NewSetMembersHandler godoc
+// @Summary Set members of shared subscription
+// @Description Replace users sharing subscription cost with split weights
+// @Tags v1
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param request body MembersRequest true "Members data"
+// @Success 200 {object} Response
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /subscription/{id}/members [put]
+func NewSetMembersHandler(logger *slog.Logger, setter MemberSetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.set_members"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		// 1.Get subscription id from request
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			logger.Info("invalid subscription id format", "details", err)
+
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "id", "invalid subscription id format")
+
+			return
+		}
+
+		// 2.Parse and validate request
+		var req MembersRequest
+		if ok := parseReq(r, w, logger, &req); !ok {
+			return
+		}
+
+		members, err := parseMembers(req.Members)
+		if err != nil {
+			logger.Info("request members are invalid", "details", err)
+
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "members", "request members are invalid")
+
+			return
+		}
+
+		// 3.Replace members
+		err = setter.SetMembers(id, members)
+		if errors.Is(err, storage.ErrSubscribtionNotFound) {
+			logger.Info("subscription not found", "id", id)
+
+			renderError(w, r, http.StatusNotFound, CodeSubscriptionNotFound, "", "subscription not found")
+
+			return
+		}
+		if err != nil {
+			logger.Error("failed to set members", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to set members")
+
+			return
+		}
+
+		logger.Info("subscription members set", "id", id, "count", len(members))
+
+		render.JSON(w, r, RespOK())
+	}
+}
+
+// Convert members from request and validate them
+func parseMembers(items []MemberItem) ([]model.Member, error) {
+	var members []model.Member
+
+	for _, item := range items {
+		userId, err := uuid.Parse(item.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, model.Member{UserID: userId, Weight: item.Weight})
+	}
+
+	if err := model.ValidateMembers(members); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func makeMemberItems(members []model.Member) []MemberItem {
+	items := []MemberItem{}
+	for _, member := range members {
+		items = append(items, MemberItem{UserID: member.UserID.String(), Weight: member.Weight})
+	}
+
+	return items
+}
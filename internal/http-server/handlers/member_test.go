@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"bytes"
+	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetMembersHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	owner, member := uuid.New(), uuid.New()
+	members := []model.Member{{UserID: owner, Weight: 2}, {UserID: member, Weight: 1}}
+	validBody := fmt.Sprintf(`{"members": [{"user_id": "%s", "weight": 2}, {"user_id": "%s", "weight": 1}]}`, owner, member)
+
+	cases := []struct {
+		name      string
+		id        string
+		body      string
+		respCode  int
+		respError string
+		mockError error
+	}{
+		{
+			name:     "Success",
+			id:       "1",
+			body:     validBody,
+			respCode: http.StatusOK,
+		},
+		{
+			name:      "Invalid id",
+			id:        "trash",
+			body:      validBody,
+			respCode:  http.StatusBadRequest,
+			respError: "invalid subscription id format",
+		},
+		{
+			name:      "Zero weight",
+			id:        "1",
+			body:      fmt.Sprintf(`{"members": [{"user_id": "%s", "weight": 0}]}`, owner),
+			respCode:  http.StatusBadRequest,
+			respError: "request members are invalid",
+		},
+		{
+			name:      "Duplicated member",
+			id:        "1",
+			body:      fmt.Sprintf(`{"members": [{"user_id": "%s", "weight": 1}, {"user_id": "%s", "weight": 1}]}`, owner, owner),
+			respCode:  http.StatusBadRequest,
+			respError: "request members are invalid",
+		},
+		{
+			name:      "Invalid member user id",
+			id:        "1",
+			body:      `{"members": [{"user_id": "trash", "weight": 1}]}`,
+			respCode:  http.StatusBadRequest,
+			respError: "request members are invalid",
+		},
+		{
+			name:      "Not found subscription",
+			id:        "1",
+			body:      validBody,
+			respCode:  http.StatusNotFound,
+			respError: "subscription not found",
+			mockError: storage.ErrSubscribtionNotFound,
+		},
+		{
+			name:      "Any other setter error case",
+			id:        "1",
+			body:      validBody,
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to set members",
+			mockError: errors.New("any error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			setterMock := mocks.NewMemberSetter(t)
+
+			if tc.respError == "" || tc.mockError != nil {
+				setterMock.On("SetMembers", int64(1), members).Return(tc.mockError)
+			}
+
+			router := chi.NewRouter()
+			router.Put("/subscription/{id}/members", NewSetMembersHandler(logger, setterMock))
+
+			req, err := http.NewRequest(http.MethodPut, "/subscription/"+tc.id+"/members", bytes.NewReader([]byte(tc.body)))
+			assert.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.respCode, rr.Code)
+
+			var resp Response
+			assert.Equal(t, tc.respError, decodeResp(t, rr, &resp))
+		})
+	}
+}
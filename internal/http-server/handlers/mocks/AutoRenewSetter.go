@@ -0,0 +1,42 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// AutoRenewSetter is an autogenerated mock type for the AutoRenewSetter type
+type AutoRenewSetter struct {
+	mock.Mock
+}
+
+// SetAutoRenew provides a mock function with given fields: id, autoRenew, billingPeriod
+func (_m *AutoRenewSetter) SetAutoRenew(id int64, autoRenew bool, billingPeriod int) error {
+	ret := _m.Called(id, autoRenew, billingPeriod)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAutoRenew")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, bool, int) error); ok {
+		r0 = rf(id, autoRenew, billingPeriod)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewAutoRenewSetter creates a new instance of AutoRenewSetter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAutoRenewSetter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AutoRenewSetter {
+	mock := &AutoRenewSetter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
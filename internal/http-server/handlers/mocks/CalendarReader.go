@@ -0,0 +1,59 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "em_golang_rest_service_example/internal/model"
+
+	uuid "github.com/google/uuid"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CalendarReader is an autogenerated mock type for the CalendarReader type
+type CalendarReader struct {
+	mock.Mock
+}
+
+// GetUserSubscriptions provides a mock function with given fields: userId, limit, offset
+func (_m *CalendarReader) GetUserSubscriptions(userId uuid.UUID, limit *int, offset *int) ([]model.Subscription, error) {
+	ret := _m.Called(userId, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserSubscriptions")
+	}
+
+	var r0 []model.Subscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *int, *int) ([]model.Subscription, error)); ok {
+		return rf(userId, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *int, *int) []model.Subscription); ok {
+		r0 = rf(userId, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Subscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, *int, *int) error); ok {
+		r1 = rf(userId, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCalendarReader creates a new instance of CalendarReader. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCalendarReader(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CalendarReader {
+	mock := &CalendarReader{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -41,6 +41,62 @@ func (_m *Creator) CreateSubscription(subscription model.SubscriptionSpec) (int6
 	return r0, r1
 }
 
+// GetService provides a mock function with given fields: id
+func (_m *Creator) GetService(id int64) (model.Service, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetService")
+	}
+
+	var r0 model.Service
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) (model.Service, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) model.Service); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(model.Service)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResolveService provides a mock function with given fields: name
+func (_m *Creator) ResolveService(name string) (model.Service, error) {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResolveService")
+	}
+
+	var r0 model.Service
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (model.Service, error)); ok {
+		return rf(name)
+	}
+	if rf, ok := ret.Get(0).(func(string) model.Service); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Get(0).(model.Service)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewCreator creates a new instance of Creator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewCreator(t interface {
@@ -0,0 +1,56 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "em_golang_rest_service_example/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DiscountAdder is an autogenerated mock type for the DiscountAdder type
+type DiscountAdder struct {
+	mock.Mock
+}
+
+// AddDiscount provides a mock function with given fields: id, discount
+func (_m *DiscountAdder) AddDiscount(id int64, discount model.Discount) (int64, error) {
+	ret := _m.Called(id, discount)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddDiscount")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, model.Discount) (int64, error)); ok {
+		return rf(id, discount)
+	}
+	if rf, ok := ret.Get(0).(func(int64, model.Discount) int64); ok {
+		r0 = rf(id, discount)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, model.Discount) error); ok {
+		r1 = rf(id, discount)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewDiscountAdder creates a new instance of DiscountAdder. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDiscountAdder(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DiscountAdder {
+	mock := &DiscountAdder{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,48 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "em_golang_rest_service_example/internal/model"
+)
+
+// ExportReader is an autogenerated mock type for the ExportReader type
+type ExportReader struct {
+	mock.Mock
+}
+
+// StreamSubscriptions provides a mock function with given fields: ctx, limit, offset, filter, fields, yield
+func (_m *ExportReader) StreamSubscriptions(ctx context.Context, limit *int, offset *int, filter model.SubscriptionFilter, fields model.FieldSet, yield func(*model.Subscription) error) error {
+	ret := _m.Called(ctx, limit, offset, filter, fields, yield)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamSubscriptions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *int, *int, model.SubscriptionFilter, model.FieldSet, func(*model.Subscription) error) error); ok {
+		r0 = rf(ctx, limit, offset, filter, fields, yield)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewExportReader creates a new instance of ExportReader. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewExportReader(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ExportReader {
+	mock := &ExportReader{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -4,9 +4,11 @@ package mocks
 
 import (
 	model "em_golang_rest_service_example/internal/model"
+	time "time"
 
-	uuid "github.com/google/uuid"
 	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
 )
 
 // FilteredDataReader is an autogenerated mock type for the FilteredDataReader type
@@ -14,9 +16,9 @@ type FilteredDataReader struct {
 	mock.Mock
 }
 
-// FilterSubscriptions provides a mock function with given fields: startDate, endDate, userId, serviceName
-func (_m *FilteredDataReader) FilterSubscriptions(startDate model.Date, endDate model.Date, userId uuid.UUID, serviceName *string) ([]model.Subscription, error) {
-	ret := _m.Called(startDate, endDate, userId, serviceName)
+// FilterSubscriptions provides a mock function with given fields: startDate, endDate, userId, serviceName, filter
+func (_m *FilteredDataReader) FilterSubscriptions(startDate model.Date, endDate model.Date, userId uuid.UUID, serviceName *string, filter model.SubscriptionFilter) ([]model.Subscription, error) {
+	ret := _m.Called(startDate, endDate, userId, serviceName, filter)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FilterSubscriptions")
@@ -24,19 +26,49 @@ func (_m *FilteredDataReader) FilterSubscriptions(startDate model.Date, endDate
 
 	var r0 []model.Subscription
 	var r1 error
-	if rf, ok := ret.Get(0).(func(model.Date, model.Date, uuid.UUID, *string) ([]model.Subscription, error)); ok {
-		return rf(startDate, endDate, userId, serviceName)
+	if rf, ok := ret.Get(0).(func(model.Date, model.Date, uuid.UUID, *string, model.SubscriptionFilter) ([]model.Subscription, error)); ok {
+		return rf(startDate, endDate, userId, serviceName, filter)
 	}
-	if rf, ok := ret.Get(0).(func(model.Date, model.Date, uuid.UUID, *string) []model.Subscription); ok {
-		r0 = rf(startDate, endDate, userId, serviceName)
+	if rf, ok := ret.Get(0).(func(model.Date, model.Date, uuid.UUID, *string, model.SubscriptionFilter) []model.Subscription); ok {
+		r0 = rf(startDate, endDate, userId, serviceName, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]model.Subscription)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(model.Date, model.Date, uuid.UUID, *string) error); ok {
-		r1 = rf(startDate, endDate, userId, serviceName)
+	if rf, ok := ret.Get(1).(func(model.Date, model.Date, uuid.UUID, *string, model.SubscriptionFilter) error); ok {
+		r1 = rf(startDate, endDate, userId, serviceName, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCharges provides a mock function with given fields: ids, asOf
+func (_m *FilteredDataReader) GetCharges(ids []int64, asOf *time.Time) (map[int64][]model.Charge, error) {
+	ret := _m.Called(ids, asOf)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCharges")
+	}
+
+	var r0 map[int64][]model.Charge
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]int64, *time.Time) (map[int64][]model.Charge, error)); ok {
+		return rf(ids, asOf)
+	}
+	if rf, ok := ret.Get(0).(func([]int64, *time.Time) map[int64][]model.Charge); ok {
+		r0 = rf(ids, asOf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[int64][]model.Charge)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]int64, *time.Time) error); ok {
+		r1 = rf(ids, asOf)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,111 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "em_golang_rest_service_example/internal/model"
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IdempotencyStore is an autogenerated mock type for the IdempotencyStore type
+type IdempotencyStore struct {
+	mock.Mock
+}
+
+// DeleteIdempotencyKey provides a mock function with given fields: key
+func (_m *IdempotencyStore) DeleteIdempotencyKey(key string) error {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteIdempotencyKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetIdempotencyKey provides a mock function with given fields: key
+func (_m *IdempotencyStore) GetIdempotencyKey(key string) (model.IdempotencyRecord, error) {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetIdempotencyKey")
+	}
+
+	var r0 model.IdempotencyRecord
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (model.IdempotencyRecord, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) model.IdempotencyRecord); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Get(0).(model.IdempotencyRecord)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReserveIdempotencyKey provides a mock function with given fields: key, requestHash, ttl
+func (_m *IdempotencyStore) ReserveIdempotencyKey(key string, requestHash string, ttl time.Duration) error {
+	ret := _m.Called(key, requestHash, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReserveIdempotencyKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, time.Duration) error); ok {
+		r0 = rf(key, requestHash, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveIdempotentResponse provides a mock function with given fields: key, status, contentType, body
+func (_m *IdempotencyStore) SaveIdempotentResponse(key string, status int, contentType string, body []byte) error {
+	ret := _m.Called(key, status, contentType, body)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveIdempotentResponse")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, int, string, []byte) error); ok {
+		r0 = rf(key, status, contentType, body)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewIdempotencyStore creates a new instance of IdempotencyStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIdempotencyStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IdempotencyStore {
+	mock := &IdempotencyStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
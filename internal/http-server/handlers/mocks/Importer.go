@@ -0,0 +1,114 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "em_golang_rest_service_example/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Importer is an autogenerated mock type for the Importer type
+type Importer struct {
+	mock.Mock
+}
+
+// GetService provides a mock function with given fields: id
+func (_m *Importer) GetService(id int64) (model.Service, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetService")
+	}
+
+	var r0 model.Service
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) (model.Service, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) model.Service); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(model.Service)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ImportSubscriptions provides a mock function with given fields: specs, policy, dryRun
+func (_m *Importer) ImportSubscriptions(specs []model.SubscriptionSpec, policy model.ConflictPolicy, dryRun bool) ([]model.ImportResult, error) {
+	ret := _m.Called(specs, policy, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportSubscriptions")
+	}
+
+	var r0 []model.ImportResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]model.SubscriptionSpec, model.ConflictPolicy, bool) ([]model.ImportResult, error)); ok {
+		return rf(specs, policy, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func([]model.SubscriptionSpec, model.ConflictPolicy, bool) []model.ImportResult); ok {
+		r0 = rf(specs, policy, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ImportResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]model.SubscriptionSpec, model.ConflictPolicy, bool) error); ok {
+		r1 = rf(specs, policy, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResolveService provides a mock function with given fields: name
+func (_m *Importer) ResolveService(name string) (model.Service, error) {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResolveService")
+	}
+
+	var r0 model.Service
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (model.Service, error)); ok {
+		return rf(name)
+	}
+	if rf, ok := ret.Get(0).(func(string) model.Service); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Get(0).(model.Service)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewImporter creates a new instance of Importer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewImporter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Importer {
+	mock := &Importer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
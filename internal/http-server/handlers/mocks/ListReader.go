@@ -3,9 +3,11 @@
 package mocks
 
 import (
-	model "em_golang_rest_service_example/internal/model"
+	context "context"
 
 	mock "github.com/stretchr/testify/mock"
+
+	model "em_golang_rest_service_example/internal/model"
 )
 
 // ListReader is an autogenerated mock type for the ListReader type
@@ -13,9 +15,9 @@ type ListReader struct {
 	mock.Mock
 }
 
-// GetSubscriptions provides a mock function with given fields: limit, offset
-func (_m *ListReader) GetSubscriptions(limit *int, offset *int) ([]model.Subscription, error) {
-	ret := _m.Called(limit, offset)
+// GetSubscriptions provides a mock function with given fields: limit, offset, filter, fields
+func (_m *ListReader) GetSubscriptions(limit *int, offset *int, filter model.SubscriptionFilter, fields model.FieldSet) ([]model.Subscription, error) {
+	ret := _m.Called(limit, offset, filter, fields)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetSubscriptions")
@@ -23,19 +25,19 @@ func (_m *ListReader) GetSubscriptions(limit *int, offset *int) ([]model.Subscri
 
 	var r0 []model.Subscription
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*int, *int) ([]model.Subscription, error)); ok {
-		return rf(limit, offset)
+	if rf, ok := ret.Get(0).(func(*int, *int, model.SubscriptionFilter, model.FieldSet) ([]model.Subscription, error)); ok {
+		return rf(limit, offset, filter, fields)
 	}
-	if rf, ok := ret.Get(0).(func(*int, *int) []model.Subscription); ok {
-		r0 = rf(limit, offset)
+	if rf, ok := ret.Get(0).(func(*int, *int, model.SubscriptionFilter, model.FieldSet) []model.Subscription); ok {
+		r0 = rf(limit, offset, filter, fields)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]model.Subscription)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*int, *int) error); ok {
-		r1 = rf(limit, offset)
+	if rf, ok := ret.Get(1).(func(*int, *int, model.SubscriptionFilter, model.FieldSet) error); ok {
+		r1 = rf(limit, offset, filter, fields)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -43,6 +45,24 @@ func (_m *ListReader) GetSubscriptions(limit *int, offset *int) ([]model.Subscri
 	return r0, r1
 }
 
+// StreamSubscriptions provides a mock function with given fields: ctx, limit, offset, filter, fields, yield
+func (_m *ListReader) StreamSubscriptions(ctx context.Context, limit *int, offset *int, filter model.SubscriptionFilter, fields model.FieldSet, yield func(*model.Subscription) error) error {
+	ret := _m.Called(ctx, limit, offset, filter, fields, yield)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamSubscriptions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *int, *int, model.SubscriptionFilter, model.FieldSet, func(*model.Subscription) error) error); ok {
+		r0 = rf(ctx, limit, offset, filter, fields, yield)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewListReader creates a new instance of ListReader. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewListReader(t interface {
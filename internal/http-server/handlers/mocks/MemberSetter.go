@@ -0,0 +1,46 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "em_golang_rest_service_example/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MemberSetter is an autogenerated mock type for the MemberSetter type
+type MemberSetter struct {
+	mock.Mock
+}
+
+// SetMembers provides a mock function with given fields: id, members
+func (_m *MemberSetter) SetMembers(id int64, members []model.Member) error {
+	ret := _m.Called(id, members)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetMembers")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, []model.Member) error); ok {
+		r0 = rf(id, members)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMemberSetter creates a new instance of MemberSetter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMemberSetter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MemberSetter {
+	mock := &MemberSetter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,84 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "em_golang_rest_service_example/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PriceScheduler is an autogenerated mock type for the PriceScheduler type
+type PriceScheduler struct {
+	mock.Mock
+}
+
+// AddPriceChange provides a mock function with given fields: id, change
+func (_m *PriceScheduler) AddPriceChange(id int64, change model.PriceChange) (int64, error) {
+	ret := _m.Called(id, change)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddPriceChange")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, model.PriceChange) (int64, error)); ok {
+		return rf(id, change)
+	}
+	if rf, ok := ret.Get(0).(func(int64, model.PriceChange) int64); ok {
+		r0 = rf(id, change)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, model.PriceChange) error); ok {
+		r1 = rf(id, change)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscription provides a mock function with given fields: id
+func (_m *PriceScheduler) GetSubscription(id int64) (model.Subscription, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSubscription")
+	}
+
+	var r0 model.Subscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) (model.Subscription, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) model.Subscription); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(model.Subscription)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPriceScheduler creates a new instance of PriceScheduler. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPriceScheduler(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PriceScheduler {
+	mock := &PriceScheduler{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
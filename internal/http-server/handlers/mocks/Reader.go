@@ -13,27 +13,27 @@ type Reader struct {
 	mock.Mock
 }
 
-// GetSubscription provides a mock function with given fields: id
-func (_m *Reader) GetSubscription(id int64) (model.Subscription, error) {
-	ret := _m.Called(id)
+// GetSubscriptionFields provides a mock function with given fields: id, fields
+func (_m *Reader) GetSubscriptionFields(id int64, fields model.FieldSet) (model.Subscription, error) {
+	ret := _m.Called(id, fields)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetSubscription")
+		panic("no return value specified for GetSubscriptionFields")
 	}
 
 	var r0 model.Subscription
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int64) (model.Subscription, error)); ok {
-		return rf(id)
+	if rf, ok := ret.Get(0).(func(int64, model.FieldSet) (model.Subscription, error)); ok {
+		return rf(id, fields)
 	}
-	if rf, ok := ret.Get(0).(func(int64) model.Subscription); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(int64, model.FieldSet) model.Subscription); ok {
+		r0 = rf(id, fields)
 	} else {
 		r0 = ret.Get(0).(model.Subscription)
 	}
 
-	if rf, ok := ret.Get(1).(func(int64) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(int64, model.FieldSet) error); ok {
+		r1 = rf(id, fields)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,89 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "em_golang_rest_service_example/internal/model"
+
+	uuid "github.com/google/uuid"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Reconciler is an autogenerated mock type for the Reconciler type
+type Reconciler struct {
+	mock.Mock
+}
+
+// FilterSubscriptions provides a mock function with given fields: startDate, endDate, userId, serviceName, filter
+func (_m *Reconciler) FilterSubscriptions(startDate model.Date, endDate model.Date, userId uuid.UUID, serviceName *string, filter model.SubscriptionFilter) ([]model.Subscription, error) {
+	ret := _m.Called(startDate, endDate, userId, serviceName, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FilterSubscriptions")
+	}
+
+	var r0 []model.Subscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(model.Date, model.Date, uuid.UUID, *string, model.SubscriptionFilter) ([]model.Subscription, error)); ok {
+		return rf(startDate, endDate, userId, serviceName, filter)
+	}
+	if rf, ok := ret.Get(0).(func(model.Date, model.Date, uuid.UUID, *string, model.SubscriptionFilter) []model.Subscription); ok {
+		r0 = rf(startDate, endDate, userId, serviceName, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Subscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(model.Date, model.Date, uuid.UUID, *string, model.SubscriptionFilter) error); ok {
+		r1 = rf(startDate, endDate, userId, serviceName, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetServices provides a mock function with no fields
+func (_m *Reconciler) GetServices() ([]model.Service, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetServices")
+	}
+
+	var r0 []model.Service
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]model.Service, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []model.Service); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Service)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewReconciler creates a new instance of Reconciler. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReconciler(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Reconciler {
+	mock := &Reconciler{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,56 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "em_golang_rest_service_example/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ServiceCreator is an autogenerated mock type for the ServiceCreator type
+type ServiceCreator struct {
+	mock.Mock
+}
+
+// CreateService provides a mock function with given fields: service
+func (_m *ServiceCreator) CreateService(service model.Service) (int64, error) {
+	ret := _m.Called(service)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateService")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(model.Service) (int64, error)); ok {
+		return rf(service)
+	}
+	if rf, ok := ret.Get(0).(func(model.Service) int64); ok {
+		r0 = rf(service)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(model.Service) error); ok {
+		r1 = rf(service)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewServiceCreator creates a new instance of ServiceCreator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewServiceCreator(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ServiceCreator {
+	mock := &ServiceCreator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
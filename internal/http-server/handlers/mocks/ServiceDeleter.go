@@ -0,0 +1,42 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// ServiceDeleter is an autogenerated mock type for the ServiceDeleter type
+type ServiceDeleter struct {
+	mock.Mock
+}
+
+// DeleteService provides a mock function with given fields: id
+func (_m *ServiceDeleter) DeleteService(id int64) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteService")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewServiceDeleter creates a new instance of ServiceDeleter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewServiceDeleter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ServiceDeleter {
+	mock := &ServiceDeleter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
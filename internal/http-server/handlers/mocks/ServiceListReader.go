@@ -0,0 +1,58 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "em_golang_rest_service_example/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ServiceListReader is an autogenerated mock type for the ServiceListReader type
+type ServiceListReader struct {
+	mock.Mock
+}
+
+// GetServices provides a mock function with no fields
+func (_m *ServiceListReader) GetServices() ([]model.Service, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetServices")
+	}
+
+	var r0 []model.Service
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]model.Service, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []model.Service); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Service)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewServiceListReader creates a new instance of ServiceListReader. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewServiceListReader(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ServiceListReader {
+	mock := &ServiceListReader{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
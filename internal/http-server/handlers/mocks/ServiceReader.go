@@ -0,0 +1,56 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "em_golang_rest_service_example/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ServiceReader is an autogenerated mock type for the ServiceReader type
+type ServiceReader struct {
+	mock.Mock
+}
+
+// GetService provides a mock function with given fields: id
+func (_m *ServiceReader) GetService(id int64) (model.Service, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetService")
+	}
+
+	var r0 model.Service
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) (model.Service, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) model.Service); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(model.Service)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewServiceReader creates a new instance of ServiceReader. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewServiceReader(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ServiceReader {
+	mock := &ServiceReader{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
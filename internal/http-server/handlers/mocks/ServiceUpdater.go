@@ -0,0 +1,46 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "em_golang_rest_service_example/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ServiceUpdater is an autogenerated mock type for the ServiceUpdater type
+type ServiceUpdater struct {
+	mock.Mock
+}
+
+// UpdateService provides a mock function with given fields: id, service
+func (_m *ServiceUpdater) UpdateService(id int64, service model.Service) error {
+	ret := _m.Called(id, service)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateService")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, model.Service) error); ok {
+		r0 = rf(id, service)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewServiceUpdater creates a new instance of ServiceUpdater. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewServiceUpdater(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ServiceUpdater {
+	mock := &ServiceUpdater{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,58 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "em_golang_rest_service_example/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SubscriptionSearcher is an autogenerated mock type for the SubscriptionSearcher type
+type SubscriptionSearcher struct {
+	mock.Mock
+}
+
+// SearchSubscriptions provides a mock function with given fields: query, limit
+func (_m *SubscriptionSearcher) SearchSubscriptions(query string, limit int) ([]model.Subscription, error) {
+	ret := _m.Called(query, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchSubscriptions")
+	}
+
+	var r0 []model.Subscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int) ([]model.Subscription, error)); ok {
+		return rf(query, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) []model.Subscription); ok {
+		r0 = rf(query, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Subscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(query, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewSubscriptionSearcher creates a new instance of SubscriptionSearcher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSubscriptionSearcher(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SubscriptionSearcher {
+	mock := &SubscriptionSearcher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,42 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// TagSetter is an autogenerated mock type for the TagSetter type
+type TagSetter struct {
+	mock.Mock
+}
+
+// SetTags provides a mock function with given fields: id, category, tags
+func (_m *TagSetter) SetTags(id int64, category string, tags []string) error {
+	ret := _m.Called(id, category, tags)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetTags")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, string, []string) error); ok {
+		r0 = rf(id, category, tags)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewTagSetter creates a new instance of TagSetter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTagSetter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TagSetter {
+	mock := &TagSetter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
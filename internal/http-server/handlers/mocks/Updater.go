@@ -13,17 +13,17 @@ type Updater struct {
 	mock.Mock
 }
 
-// UpdateSubscription provides a mock function with given fields: id, newServiceName, newPrice, newStart, newEnd
-func (_m *Updater) UpdateSubscription(id int64, newServiceName string, newPrice int, newStart model.Date, newEnd model.Date) error {
-	ret := _m.Called(id, newServiceName, newPrice, newStart, newEnd)
+// UpdateSubscription provides a mock function with given fields: id, update
+func (_m *Updater) UpdateSubscription(id int64, update model.SubscriptionUpdate) error {
+	ret := _m.Called(id, update)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateSubscription")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int64, string, int, model.Date, model.Date) error); ok {
-		r0 = rf(id, newServiceName, newPrice, newStart, newEnd)
+	if rf, ok := ret.Get(0).(func(int64, model.SubscriptionUpdate) error); ok {
+		r0 = rf(id, update)
 	} else {
 		r0 = ret.Error(0)
 	}
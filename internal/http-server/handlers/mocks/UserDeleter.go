@@ -0,0 +1,55 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	uuid "github.com/google/uuid"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UserDeleter is an autogenerated mock type for the UserDeleter type
+type UserDeleter struct {
+	mock.Mock
+}
+
+// DeleteUserSubscriptions provides a mock function with given fields: userId
+func (_m *UserDeleter) DeleteUserSubscriptions(userId uuid.UUID) (int64, error) {
+	ret := _m.Called(userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUserSubscriptions")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (int64, error)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) int64); ok {
+		r0 = rf(userId)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewUserDeleter creates a new instance of UserDeleter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserDeleter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserDeleter {
+	mock := &UserDeleter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
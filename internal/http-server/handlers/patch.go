@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"em_golang_rest_service_example/internal/model"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// PatchRequest represents JSON Merge Patch (RFC 7396) of subscription, fields absent in patch are kept
+// swagger:model PatchRequest
+// @ID PatchRequest
+type PatchRequest struct {
+	// New service name (cannot be null)
+	ServiceName patchField[string] `json:"service_name" swaggertype:"string"`
+
+	// New price as decimal string ("9.99") or integer of minor units (999) (cannot be null)
+	Price patchField[model.Money] `json:"price" swaggertype:"string" example:"9.99"`
+
+	// New start date (cannot be null)
+	StartDate patchField[string] `json:"start_date" swaggertype:"string"`
+
+	// New end date, null resets it to one billing period after start date
+	EndDate patchField[string] `json:"end_date" swaggertype:"string"`
+
+	// Patch of custom attributes merged with current ones (null members remove keys), null removes all of them
+	Metadata patchField[json.RawMessage] `json:"metadata" swaggertype:"object"`
+}
+
+// Member of merge patch: Set if it is present in patch, Null if its value is JSON null
+type patchField[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+func (f *patchField[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		f.Null = true
+		return nil
+	}
+
+	return json.Unmarshal(data, &f.Value)
+}
+
+// NewPatchHandler godoc
+// @Summary Update subscription
+// @Description Update fields present in JSON Merge Patch (RFC 7396), merged subscription is validated before it is saved
+// @Tags v1
+// @Accept json
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param request body PatchRequest true "Subscription merge patch"
+// @Success 200 {object} Response
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /subscription/{id} [patch]
+func NewPatchHandler(logger *slog.Logger, updater Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.patch"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Accept-Patch", mergePatchContentType)
+
+		// 1.Get subscription id from request
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			logger.Info("invalid subscription id format", "details", err)
+
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "id", "invalid subscription id format")
+
+			return
+		}
+
+		// 2.Parse patch (body is decoded as JSON whatever its media type is)
+		var req PatchRequest
+		if ok := parseReq(r, w, logger, &req); !ok {
+			return
+		}
+
+		// 3.Validate patched fields
+		update, err := checkPatchReq(&req)
+		if err != nil {
+			logger.Error(err.Error(), "details", validationDetails(err))
+			renderFieldError(w, r, err)
+
+			return
+		}
+
+		// 4.Update
+		err = updater.UpdateSubscription(id, update)
+		if err != nil {
+			renderUpdateError(w, r, logger, id, err)
+
+			return
+		}
+
+		logger.Info("patched subscription", "id", id)
+
+		// 5.Prepare response and render it
+		render.JSON(w, r, RespOK())
+	}
+}
+
+// Validate fields present in patch and convert it to update (error is *model.ValidationError)
+func checkPatchReq(req *PatchRequest) (model.SubscriptionUpdate, error) {
+	var v model.Validator
+	var update model.SubscriptionUpdate
+
+	// 1.Service name
+	if req.ServiceName.Set && v.Required("service_name", req.ServiceName.Value, "request service name is empty") {
+		update.ServiceName = &req.ServiceName.Value
+	}
+
+	// 2.Price
+	if req.Price.Set {
+		if req.Price.Null {
+			v.Fail("price", model.CodeMissingValue, "request price is empty", nil)
+		} else if v.NotNegative("price", int64(req.Price.Value), "request price is invalid") {
+			update.Price = &req.Price.Value
+		}
+	}
+
+	// 3.Dates
+	if req.StartDate.Set && v.Required("start_date", req.StartDate.Value, "request start date is empty") {
+		if startDate, ok := v.Date("start_date", req.StartDate.Value, "request start date is invalid"); ok {
+			update.StartDate = &startDate
+		}
+	}
+
+	if req.EndDate.Set {
+		endDate := model.Date{}
+		if req.EndDate.Value != "" {
+			endDate, _ = v.Date("end_date", req.EndDate.Value, "request end date is invalid")
+		}
+		update.EndDate = &endDate
+	}
+
+	// 4.Metadata
+	if req.Metadata.Set {
+		if req.Metadata.Null {
+			update.Metadata = new(json.RawMessage)
+		} else if bytes.HasPrefix(bytes.TrimSpace(req.Metadata.Value), []byte("{")) {
+			update.MetadataPatch = req.Metadata.Value
+		} else {
+			v.Fail("metadata", model.CodeInvalidValue, "request metadata is invalid: "+model.ErrMetadataInvalid.Error(), model.ErrMetadataInvalid)
+		}
+	}
+
+	return update, v.Err()
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatchHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	price := model.Money(1099)
+	start := model.Date{Month: 3, Year: 2026}
+	metadata := json.RawMessage(`{"team":"core","seats":null}`)
+
+	cases := []struct {
+		name      string
+		id        string
+		body      string
+		update    *model.SubscriptionUpdate
+		respCode  int
+		respError string
+		mockError error
+	}{
+		{
+			name:     "Price only",
+			id:       "1",
+			body:     `{"price": "10.99"}`,
+			update:   &model.SubscriptionUpdate{Price: &price},
+			respCode: http.StatusOK,
+		},
+		{
+			name:     "Start date and metadata patch",
+			id:       "1",
+			body:     `{"start_date": "03-2026", "metadata": {"team":"core","seats":null}}`,
+			update:   &model.SubscriptionUpdate{StartDate: &start, MetadataPatch: metadata},
+			respCode: http.StatusOK,
+		},
+		{
+			name:     "Null end date and metadata",
+			id:       "1",
+			body:     `{"end_date": null, "metadata": null}`,
+			update:   &model.SubscriptionUpdate{EndDate: &model.Date{}, Metadata: new(json.RawMessage)},
+			respCode: http.StatusOK,
+		},
+		{
+			name:     "Empty patch",
+			id:       "1",
+			body:     `{}`,
+			update:   &model.SubscriptionUpdate{},
+			respCode: http.StatusOK,
+		},
+		{
+			name:      "Invalid id",
+			id:        "trash",
+			body:      `{}`,
+			respCode:  http.StatusBadRequest,
+			respError: "invalid subscription id format",
+		},
+		{
+			name:      "Required fields cannot be removed",
+			id:        "1",
+			body:      `{"service_name": null, "price": null, "start_date": null}`,
+			respCode:  http.StatusBadRequest,
+			respError: "request service name is empty; request price is empty; request start date is empty",
+		},
+		{
+			name:      "Invalid values",
+			id:        "1",
+			body:      `{"price": -1, "end_date": "trash", "metadata": [1]}`,
+			respCode:  http.StatusBadRequest,
+			respError: "request price is invalid; request end date is invalid; request metadata is invalid: metadata is not a JSON object",
+		},
+		{
+			name:      "Not found subscription",
+			id:        "2",
+			body:      `{"price": "10.99"}`,
+			update:    &model.SubscriptionUpdate{Price: &price},
+			respCode:  http.StatusNotFound,
+			respError: "subscription not found",
+			mockError: storage.ErrSubscribtionNotFound,
+		},
+		{
+			name:      "Patched subscription is invalid",
+			id:        "1",
+			body:      `{"start_date": "03-2026"}`,
+			update:    &model.SubscriptionUpdate{StartDate: &start},
+			respCode:  http.StatusBadRequest,
+			respError: "start date greater than end date",
+			mockError: &model.ValidationError{Fields: []model.FieldError{
+				{Field: "end_date", Code: model.CodeInvalidValue, Message: "start date greater than end date"},
+			}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			updaterMock := mocks.NewUpdater(t)
+
+			if tc.update != nil {
+				id, err := strconv.ParseInt(tc.id, 10, 64)
+				assert.NoError(t, err)
+
+				updaterMock.On("UpdateSubscription", id, *tc.update).Return(tc.mockError)
+			}
+
+			router := chi.NewRouter()
+			router.Patch("/subscription/{id}", NewPatchHandler(logger, updaterMock))
+
+			req, err := http.NewRequest(http.MethodPatch, "/subscription/"+tc.id, strings.NewReader(tc.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", mergePatchContentType)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.respCode, rr.Code)
+			assert.Equal(t, mergePatchContentType, rr.Header().Get("Accept-Patch"))
+
+			var resp Response
+			assert.Equal(t, tc.respError, decodeResp(t, rr, &resp))
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// AddPriceRequest represents subscription price change model
+// swagger:model AddPriceRequest
+// @ID AddPriceRequest
+type AddPriceRequest struct {
+	// New monthly price as decimal string ("9.99") or integer of minor units (999) (required)
+	Price *model.Money `json:"price" swaggertype:"string" example:"9.99"`
+
+	// Month since which new price is effective, must be inside subscription period (required)
+	EffectiveFrom string `json:"effective_from"`
+}
+
+// PriceScheduleItem represents one price change of subscription
+// swagger:model PriceScheduleItem
+// @ID PriceScheduleItem
+type PriceScheduleItem struct {
+	// Price change id
+	Id int64 `json:"id"`
+
+	// Monthly price since effective date (decimal string)
+	Price model.Money `json:"price" swaggertype:"string" example:"9.99"`
+
+	// Month since which price is effective
+	EffectiveFrom string `json:"effective_from"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=PriceScheduler
+type PriceScheduler interface {
+	GetSubscription(id int64) (model.Subscription, error)
+	AddPriceChange(id int64, change model.PriceChange) (int64, error)
+}
+
+// NewAddPriceHandler godoc
+// @Summary Add subscription price change
+// @Description Schedule new subscription price since specified month (previous months keep old price)
+// @Tags v1
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param request body AddPriceRequest true "Price change data"
+// @Param Idempotency-Key header string false "Key making retries safe (response of the first request is replayed)"
+// @Success 201 {object} CreateResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /subscription/{id}/prices [post]
+func NewAddPriceHandler(logger *slog.Logger, scheduler PriceScheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.add_price"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		// 1.Get subscription id from request
+		idStr := chi.URLParam(r, "id")
+		if idStr == "" {
+			logger.Info("no subscription id in request")
+
+			renderError(w, r, http.StatusBadRequest, CodeMissingValue, "id", "no subscription id in request")
+
+			return
+		}
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			logger.Info("invalid subscription id format", "details", err)
+
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "id", "invalid subscription id format")
+
+			return
+		}
+
+		// 2.Parse request body
+		var req AddPriceRequest
+		if ok := parseReq(r, w, logger, &req); !ok {
+			return
+		}
+
+		// 3.Get subscription for validation against its period
+		subscription, err := scheduler.GetSubscription(int64(id))
+		if errors.Is(err, storage.ErrSubscribtionNotFound) {
+			logger.Info("subscription not found", "id", id)
+
+			renderError(w, r, http.StatusNotFound, CodeSubscriptionNotFound, "", "subscription not found")
+
+			return
+		}
+		if err != nil {
+			logger.Error("failed to get subscription", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to get subscription")
+
+			return
+		}
+
+		// 4.Validate request body data
+		change, validateOk := validateAddPriceReq(r, w, &req, &subscription, logger)
+		if !validateOk {
+			return
+		}
+
+		// 5.Add price change
+		changeId, err := scheduler.AddPriceChange(int64(id), change)
+		if errors.Is(err, storage.ErrSubscribtionNotFound) {
+			logger.Info("subscription not found", "id", id)
+
+			renderError(w, r, http.StatusNotFound, CodeSubscriptionNotFound, "", "subscription not found")
+
+			return
+		}
+		if errors.Is(err, storage.ErrPriceChangeExists) {
+			logger.Info("price change already exists", "id", id, "effective_from", req.EffectiveFrom)
+
+			renderError(w, r, http.StatusConflict, CodePriceChangeExists, "effective_from", "price change already exists")
+
+			return
+		}
+		if err != nil {
+			logger.Error("failed to add price change", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to add price change")
+
+			return
+		}
+
+		logger.Info("price change added", "id", id, "price_change_id", changeId)
+
+		w.WriteHeader(http.StatusCreated)
+		render.JSON(w, r, CreateResponse{ID: changeId, Response: RespOK()})
+	}
+}
+
+func validateAddPriceReq(r *http.Request, w http.ResponseWriter, req *AddPriceRequest, sub *model.Subscription, logger *slog.Logger) (model.PriceChange, bool) {
+	// 1.Price
+	if req.Price == nil {
+		logger.Error("request price is empty")
+		renderError(w, r, http.StatusBadRequest, CodeMissingValue, "price", "empty price")
+		return model.PriceChange{}, false
+	}
+	if *req.Price < 0 {
+		logger.Error("request price cannot be lower than 0")
+		renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "price", "request price is invalid")
+		return model.PriceChange{}, false
+	}
+
+	// 2.Effective date
+	if req.EffectiveFrom == "" {
+		logger.Error("request effective date is empty")
+		renderError(w, r, http.StatusBadRequest, CodeMissingValue, "effective_from", "empty effective date")
+		return model.PriceChange{}, false
+	}
+
+	effectiveFrom, err := model.DateFromString(req.EffectiveFrom)
+	if err != nil {
+		logger.Error("request effective date is invalid", "details", err)
+		renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "effective_from", "request effective date is invalid")
+		return model.PriceChange{}, false
+	}
+
+	if !effectiveFrom.GreaterThan(sub.StartDate) || !sub.EndDate.GreaterThan(effectiveFrom) {
+		logger.Error("request effective date is out of subscription period")
+		renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "effective_from", "request effective date is out of subscription period")
+		return model.PriceChange{}, false
+	}
+
+	return model.PriceChange{Price: *req.Price, EffectiveFrom: effectiveFrom}, true
+}
+
+func makePriceScheduleItems(schedule []model.PriceChange) []PriceScheduleItem {
+	items := []PriceScheduleItem{}
+
+	for i := 0; i < len(schedule); i++ {
+		items = append(items, PriceScheduleItem{
+			Id:            schedule[i].ID,
+			Price:         schedule[i].Price,
+			EffectiveFrom: schedule[i].EffectiveFrom.ToString(),
+		})
+	}
+
+	return items
+}
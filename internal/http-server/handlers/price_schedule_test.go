@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"bytes"
+	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddPriceHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	subscription := model.Subscription{
+		ID: 1,
+		SubscriptionSpec: model.SubscriptionSpec{
+			ServiceName: "Yandex",
+			Price:       40000,
+			StartDate:   model.Date{Month: 1, Year: 2026},
+			EndDate:     model.Date{Month: 1, Year: 2027},
+		},
+	}
+
+	cases := []struct {
+		name         string
+		id           string
+		input        string
+		respCode     int
+		respError    string
+		getNeedCall  bool
+		getError     error
+		addNeedCall  bool
+		addError     error
+		expectChange model.PriceChange
+	}{
+		{
+			name:         "Success",
+			id:           "1",
+			input:        `{"price": "450.50", "effective_from": "06-2026"}`,
+			respCode:     http.StatusCreated,
+			getNeedCall:  true,
+			addNeedCall:  true,
+			expectChange: model.PriceChange{Price: 45050, EffectiveFrom: model.Date{Month: 6, Year: 2026}},
+		},
+		{
+			name:      "Invalid id",
+			id:        "trash",
+			input:     `{"price": "450.50", "effective_from": "06-2026"}`,
+			respCode:  http.StatusBadRequest,
+			respError: "invalid subscription id format",
+		},
+		{
+			name:      "Empty request",
+			id:        "1",
+			respCode:  http.StatusBadRequest,
+			respError: "empty request",
+		},
+		{
+			name:        "Subscription not found",
+			id:          "532",
+			input:       `{"price": "450.50", "effective_from": "06-2026"}`,
+			respCode:    http.StatusNotFound,
+			respError:   "subscription not found",
+			getNeedCall: true,
+			getError:    storage.ErrSubscribtionNotFound,
+		},
+		{
+			name:        "Empty price",
+			id:          "1",
+			input:       `{"effective_from": "06-2026"}`,
+			respCode:    http.StatusBadRequest,
+			respError:   "empty price",
+			getNeedCall: true,
+		},
+		{
+			name:        "Negative price",
+			id:          "1",
+			input:       `{"price": "-1", "effective_from": "06-2026"}`,
+			respCode:    http.StatusBadRequest,
+			respError:   "request price is invalid",
+			getNeedCall: true,
+		},
+		{
+			name:        "Invalid effective date",
+			id:          "1",
+			input:       `{"price": 100, "effective_from": "trash"}`,
+			respCode:    http.StatusBadRequest,
+			respError:   "request effective date is invalid",
+			getNeedCall: true,
+		},
+		{
+			name:        "Effective date equal to start date",
+			id:          "1",
+			input:       `{"price": 100, "effective_from": "01-2026"}`,
+			respCode:    http.StatusBadRequest,
+			respError:   "request effective date is out of subscription period",
+			getNeedCall: true,
+		},
+		{
+			name:        "Effective date after end date",
+			id:          "1",
+			input:       `{"price": 100, "effective_from": "02-2027"}`,
+			respCode:    http.StatusBadRequest,
+			respError:   "request effective date is out of subscription period",
+			getNeedCall: true,
+		},
+		{
+			name:         "Already exists",
+			id:           "1",
+			input:        `{"price": 100, "effective_from": "06-2026"}`,
+			respCode:     http.StatusConflict,
+			respError:    "price change already exists",
+			getNeedCall:  true,
+			addNeedCall:  true,
+			addError:     storage.ErrPriceChangeExists,
+			expectChange: model.PriceChange{Price: 100, EffectiveFrom: model.Date{Month: 6, Year: 2026}},
+		},
+		{
+			name:         "Storage error",
+			id:           "1",
+			input:        `{"price": 100, "effective_from": "06-2026"}`,
+			respCode:     http.StatusInternalServerError,
+			respError:    "failed to add price change",
+			getNeedCall:  true,
+			addNeedCall:  true,
+			addError:     errors.New("any error"),
+			expectChange: model.PriceChange{Price: 100, EffectiveFrom: model.Date{Month: 6, Year: 2026}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			schedulerMock := mocks.NewPriceScheduler(t)
+
+			if tc.getNeedCall {
+				id, err := strconv.Atoi(tc.id)
+				assert.NoError(t, err)
+
+				if tc.getError == nil {
+					schedulerMock.On("GetSubscription", int64(id)).Return(subscription, nil)
+				} else {
+					schedulerMock.On("GetSubscription", int64(id)).Return(model.Subscription{}, tc.getError)
+				}
+			}
+			if tc.addNeedCall {
+				schedulerMock.On("AddPriceChange", subscription.ID, tc.expectChange).Return(int64(7), tc.addError)
+			}
+
+			router := chi.NewRouter()
+			router.Post("/subscription/{id}/prices", NewAddPriceHandler(logger, schedulerMock))
+
+			req, err := http.NewRequest(http.MethodPost, "/subscription/"+tc.id+"/prices", bytes.NewReader([]byte(tc.input)))
+			assert.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.respCode, rr.Code)
+
+			var resp CreateResponse
+			assert.Equal(t, tc.respError, decodeResp(t, rr, &resp))
+			if tc.respError == "" {
+				assert.Equal(t, int64(7), resp.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"em_golang_rest_service_example/internal/model"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// Problem represents error response in RFC 7807 format
+// swagger:model Problem
+// @ID Problem
+type Problem struct {
+	// Short summary of problem (HTTP status text)
+	Title string `json:"title"`
+
+	// HTTP status code
+	Status int `json:"status"`
+
+	// Stable machine-readable error code
+	Code string `json:"code"`
+
+	// Human-readable explanation of this occurrence
+	Detail string `json:"detail"`
+
+	// Request ID
+	Instance string `json:"instance,omitempty"`
+
+	// Request body field or query parameter causing the problem (optional)
+	Field string `json:"field,omitempty"`
+
+	// All invalid fields of request (validation_failed only), named after RFC 7807 example extension
+	InvalidParams []FieldErrorItem `json:"invalid_params,omitempty"`
+}
+
+// FieldErrorItem represents invalid request field
+// swagger:model FieldErrorItem
+// @ID FieldErrorItem
+type FieldErrorItem struct {
+	// Request body field or query parameter
+	Field string `json:"field"`
+
+	// Stable machine-readable error code (missing_value, invalid_value)
+	Code string `json:"code"`
+
+	// Human-readable explanation
+	Message string `json:"message"`
+}
+
+const ProblemContentType = "application/problem+json"
+
+// Error codes
+const (
+	CodeInvalidValue            = model.CodeInvalidValue
+	CodeMissingValue            = model.CodeMissingValue
+	CodeValidationFailed        = "validation_failed"
+	CodeEmptyBody               = "empty_body"
+	CodeMalformedBody           = "malformed_body"
+	CodeUnsupportedMediaType    = "unsupported_media_type"
+	CodeStatementTooLarge       = "statement_too_large"
+	CodeBodyTooLarge            = "body_too_large"
+	CodeTokenInvalid            = "token_invalid"
+	CodeSubscriptionNotFound    = "subscription_not_found"
+	CodeServiceNotFound         = "service_not_found"
+	CodeRouteNotFound           = "route_not_found"
+	CodeMethodNotAllowed        = "method_not_allowed"
+	CodeSubscriptionExists      = "subscription_exists"
+	CodeServiceExists           = "service_exists"
+	CodeServiceInUse            = "service_in_use"
+	CodePriceChangeExists       = "price_change_exists"
+	CodeStatusTransitionInvalid = "status_transition_invalid"
+	CodeStatusConflict          = "status_conflict"
+	CodeTotalTooLarge           = "total_too_large"
+	CodeIdempotencyKeyInUse     = "idempotency_key_in_use"
+	CodeIdempotencyKeyReused    = "idempotency_key_reused"
+	CodeInternalError           = "internal_error"
+)
+
+type legacyErrorsKey struct{}
+
+// LegacyErrors makes handlers report errors in legacy format {"status":"Error","error":"..."} instead of problem details
+func LegacyErrors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), legacyErrorsKey{}, true)))
+	})
+}
+
+func legacyErrors(r *http.Request) bool {
+	legacy, _ := r.Context().Value(legacyErrorsKey{}).(bool)
+	return legacy
+}
+
+func newProblem(r *http.Request, status int, code, field, detail string) Problem {
+	return Problem{
+		Title:    http.StatusText(status),
+		Status:   status,
+		Code:     code,
+		Detail:   detail,
+		Instance: middleware.GetReqID(r.Context()),
+		Field:    field,
+	}
+}
+
+// Write error response with problem details (or legacy one if LegacyErrors is used)
+func renderError(w http.ResponseWriter, r *http.Request, status int, code, field, detail string) {
+	if legacyErrors(r) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(status)
+		render.JSON(w, r, RespError(detail))
+
+		return
+	}
+
+	writeProblem(w, status, newProblem(r, status, code, field, detail))
+}
+
+// Write error response for invalid request: all fields of *model.ValidationError,
+// one field of *model.FieldError, otherwise whole request is invalid
+func renderFieldError(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErr *model.ValidationError
+	if errors.As(err, &validationErr) {
+		if legacyErrors(r) {
+			renderError(w, r, http.StatusBadRequest, CodeValidationFailed, "", validationErr.Error())
+			return
+		}
+
+		problem := newProblem(r, http.StatusBadRequest, CodeValidationFailed, "", validationErr.Error())
+		if len(validationErr.Fields) == 1 {
+			problem.Field = validationErr.Fields[0].Field
+		}
+		problem.InvalidParams = makeFieldErrorItems(validationErr.Fields)
+
+		writeProblem(w, http.StatusBadRequest, problem)
+		return
+	}
+
+	var fieldErr *model.FieldError
+	if errors.As(err, &fieldErr) {
+		renderError(w, r, http.StatusBadRequest, fieldErr.Code, fieldErr.Field, fieldErr.Message)
+		return
+	}
+
+	renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "", err.Error())
+}
+
+func makeFieldErrorItems(fields []model.FieldError) []FieldErrorItem {
+	items := make([]FieldErrorItem, 0, len(fields))
+	for _, field := range fields {
+		items = append(items, FieldErrorItem{Field: field.Field, Code: field.Code, Message: field.Message})
+	}
+
+	return items
+}
+
+// Write problem (Problem or struct embedding it)
+func writeProblem(w http.ResponseWriter, status int, problem any) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(problem)
+}
+
+// NotFound responds to requests of unknown routes
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	if legacyErrors(r) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":       "endpoint_not_found",
+			"message":     "requested API endpoint not found",
+			"path":        r.URL.Path,
+			"method":      r.Method,
+			"status_code": http.StatusNotFound,
+		})
+
+		return
+	}
+
+	renderError(w, r, http.StatusNotFound, CodeRouteNotFound, "", "requested API endpoint not found")
+}
+
+// MethodNotAllowed responds to requests of known routes with unsupported method
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	// Allow header is listed by router only when it writes response itself
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.Routes != nil {
+		path := rctx.RoutePath
+		if path == "" {
+			path = r.URL.Path
+		}
+
+		for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+			if rctx.Routes.Match(chi.NewRouteContext(), method, path) {
+				w.Header().Add("Allow", method)
+			}
+		}
+	}
+
+	if legacyErrors(r) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	renderError(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "", "method is not allowed for requested API endpoint")
+}
+
+// NewRecoverer returns middleware recovering from handler panics with internal error response
+func NewRecoverer(logger *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rvr := recover()
+				if rvr == nil {
+					return
+				}
+				if rvr == http.ErrAbortHandler {
+					// Response is aborted on purpose, so it is not logged
+					panic(rvr)
+				}
+
+				logger.Error("handler panicked",
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+					slog.Any("panic", rvr),
+					slog.String("stack", string(debug.Stack())),
+				)
+
+				if r.Header.Get("Connection") == "Upgrade" {
+					return
+				}
+				if legacyErrors(r) {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "internal server error")
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
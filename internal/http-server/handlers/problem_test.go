@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+// Decode response into resp unless it is problem, get problem detail (empty for successful response)
+func decodeResp(t *testing.T, rr *httptest.ResponseRecorder, resp any) string {
+	t.Helper()
+
+	if rr.Header().Get("Content-Type") == ProblemContentType {
+		var problem Problem
+
+		assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+		assert.Equal(t, rr.Code, problem.Status)
+		assert.NotEmpty(t, problem.Code)
+
+		return problem.Detail
+	}
+
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), resp))
+
+	return ""
+}
+
+func TestProblemResponses(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	newRouter := func(legacy bool) *chi.Mux {
+		router := chi.NewRouter()
+
+		router.Use(middleware.RequestID)
+		if legacy {
+			router.Use(LegacyErrors)
+		}
+		router.Use(NewRecoverer(logger))
+
+		router.Post("/subscription", NewCreateHandler(logger, nil))
+		router.Get("/subscription/{id}", NewReadHandler(logger, nil))
+		router.Delete("/subscription/{id}", func(w http.ResponseWriter, r *http.Request) {
+			panic("handler failed")
+		})
+		router.NotFound(NotFound)
+		router.MethodNotAllowed(MethodNotAllowed)
+
+		return router
+	}
+
+	cases := []struct {
+		name     string
+		method   string
+		url      string
+		body     string
+		respCode int
+		problem  Problem
+		legacy   string
+	}{
+		{
+			name:     "Handler error",
+			method:   http.MethodGet,
+			url:      "/subscription/trash",
+			respCode: http.StatusBadRequest,
+			problem: Problem{
+				Title: "Bad Request", Status: http.StatusBadRequest, Code: CodeInvalidValue,
+				Detail: "invalid subscription id format", Field: "id",
+			},
+			legacy: `{"status":"Error","error":"invalid subscription id format"}`,
+		},
+		{
+			name:     "Validation failed",
+			method:   http.MethodPost,
+			url:      "/subscription",
+			body:     `{"service_name": "Netflix", "price": "-1.00", "user_id": "trash"}`,
+			respCode: http.StatusBadRequest,
+			problem: Problem{
+				Title: "Bad Request", Status: http.StatusBadRequest, Code: CodeValidationFailed,
+				Detail: "request price is invalid; request user id is invalid; empty start date",
+				InvalidParams: []FieldErrorItem{
+					{Field: "price", Code: CodeInvalidValue, Message: "request price is invalid"},
+					{Field: "user_id", Code: CodeInvalidValue, Message: "request user id is invalid"},
+					{Field: "start_date", Code: CodeMissingValue, Message: "empty start date"},
+				},
+			},
+			legacy: `{"status":"Error","error":"request price is invalid; request user id is invalid; empty start date"}`,
+		},
+		{
+			name:     "Unknown route",
+			method:   http.MethodGet,
+			url:      "/trash",
+			respCode: http.StatusNotFound,
+			problem: Problem{
+				Title: "Not Found", Status: http.StatusNotFound, Code: CodeRouteNotFound,
+				Detail: "requested API endpoint not found",
+			},
+			legacy: `{"error":"endpoint_not_found","message":"requested API endpoint not found","method":"GET","path":"/trash","status_code":404}`,
+		},
+		{
+			name:     "Method not allowed",
+			method:   http.MethodPost,
+			url:      "/subscription/1",
+			respCode: http.StatusMethodNotAllowed,
+			problem: Problem{
+				Title: "Method Not Allowed", Status: http.StatusMethodNotAllowed, Code: CodeMethodNotAllowed,
+				Detail: "method is not allowed for requested API endpoint",
+			},
+		},
+		{
+			name:     "Panic",
+			method:   http.MethodDelete,
+			url:      "/subscription/1",
+			respCode: http.StatusInternalServerError,
+			problem: Problem{
+				Title: "Internal Server Error", Status: http.StatusInternalServerError, Code: CodeInternalError,
+				Detail: "internal server error",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// 1.Problem details
+			req, err := http.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+			assert.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			newRouter(false).ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.respCode, rr.Code)
+			assert.Equal(t, ProblemContentType, rr.Header().Get("Content-Type"))
+
+			var problem Problem
+			assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+			assert.NotEmpty(t, problem.Instance)
+
+			tc.problem.Instance = problem.Instance
+			assert.Equal(t, tc.problem, problem)
+
+			if tc.respCode == http.StatusMethodNotAllowed {
+				assert.Equal(t, []string{http.MethodGet, http.MethodDelete}, rr.Header().Values("Allow"))
+			}
+
+			// 2.Legacy format
+			req, err = http.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+			assert.NoError(t, err)
+
+			rr = httptest.NewRecorder()
+			newRouter(true).ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.respCode, rr.Code)
+			if tc.legacy == "" {
+				assert.Empty(t, rr.Body.String())
+			} else {
+				assert.JSONEq(t, tc.legacy, rr.Body.String())
+			}
+		})
+	}
+}
@@ -3,14 +3,15 @@ package handlers
 import (
 	"em_golang_rest_service_example/internal/model"
 	"em_golang_rest_service_example/internal/storage"
+	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/render"
 )
 
 // ReadResponse represents response with subscription was read
@@ -23,8 +24,11 @@ type ReadResponse struct {
 	// Subscription service name
 	ServiceName string `json:"service_name"`
 
-	// Subscription monthly price
-	Price int `json:"price"`
+	// Id of catalog service
+	ServiceID int64 `json:"service_id,omitempty"`
+
+	// Subscription monthly price (decimal string)
+	Price model.Money `json:"price" swaggertype:"string" example:"9.99"`
 
 	// If of user who purchased the subscription
 	UserID string `json:"user_id"`
@@ -35,23 +39,76 @@ type ReadResponse struct {
 	// Start date of subscription
 	EndDate string `json:"end_date"`
 
+	// Number of first months billed with trial price
+	TrialMonths int `json:"trial_months"`
+
+	// Monthly price during trial (decimal string)
+	TrialPrice model.Money `json:"trial_price" swaggertype:"string" example:"0.00"`
+
+	// Lifecycle status: active, paused, cancelled or expired (status is response status)
+	SubscriptionStatus string `json:"subscription_status"`
+
+	// Status changes for audit
+	StatusHistory []StatusTransitionItem `json:"status_history"`
+
+	// Price changes after start date
+	PriceSchedule []PriceScheduleItem `json:"price_schedule"`
+
+	// Promotional discounts
+	Discounts []DiscountItem `json:"discounts"`
+
+	// Users sharing the cost (empty if owner pays alone)
+	Members []MemberItem `json:"members"`
+
+	// Spending category (empty if not categorized)
+	Category string `json:"category"`
+
+	// Free-form labels
+	Tags []string `json:"tags"`
+
+	// Custom attributes (empty object if not set)
+	Metadata json.RawMessage `json:"metadata" swaggertype:"object"`
+
+	// Extend subscription by billing period when it passes end date
+	AutoRenew bool `json:"auto_renew"`
+
+	// Billing period in months
+	BillingPeriod int `json:"billing_period"`
+
+	// Periods added by auto-renewal
+	Renewals []RenewalItem `json:"renewals"`
+
+	// Creation moment (RFC 3339)
+	CreatedAt string `json:"created_at"`
+
+	// Moment of last change of subscription or its details (RFC 3339)
+	UpdatedAt string `json:"updated_at"`
+
 	Response
 }
 
 //go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=Reader
 type Reader interface {
-	GetSubscription(id int64) (model.Subscription, error)
+	GetSubscriptionFields(id int64, fields model.FieldSet) (model.Subscription, error)
 }
 
 // NewReadHandler godoc
 // @Summary Read subscription
-// @Description Read subscription
+// @Description Read subscription. Response has ETag and Last-Modified, so it can be requested conditionally.
+// @Description With fields parameter response has listed fields only (unknown field is validation error)
+// @Tags v1
 // @Produce json
 // @Param id path int true "Subscription ID"
+// @Param fields query string false "Comma separated fields of response, e.g. id,service_name,price (all by default)"
+// @Param If-None-Match header string false "ETag of cached response"
+// @Param If-Modified-Since header string false "Last-Modified of cached response"
 // @Success 200 {object} ReadResponse
-// @Failure 400 {object} ReadResponse
-// @Failure 404 {object} ReadResponse
-// @Failure 500 {object} ReadResponse
+// @Header 200 {string} ETag "Hash of response"
+// @Header 200 {string} Last-Modified "Moment of last change of subscription"
+// @Success 304 "Subscription is not modified"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /subscription/{id} [get]
 func NewReadHandler(logger *slog.Logger, reader Reader) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -69,8 +126,7 @@ func NewReadHandler(logger *slog.Logger, reader Reader) http.HandlerFunc {
 		if idStr == "" {
 			logger.Info("no subscription id in request")
 
-			w.WriteHeader(http.StatusBadRequest)
-			render.JSON(w, r, ReadResponse{Response: RespError("no subscription id in request")})
+			renderError(w, r, http.StatusBadRequest, CodeMissingValue, "id", "no subscription id in request")
 
 			return
 		}
@@ -78,27 +134,37 @@ func NewReadHandler(logger *slog.Logger, reader Reader) http.HandlerFunc {
 		if err != nil {
 			logger.Info("invalid subscription id format", "details", err)
 
-			w.WriteHeader(http.StatusBadRequest)
-			render.JSON(w, r, ReadResponse{Response: RespError("invalid subscription id format")})
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "id", "invalid subscription id format")
 
 			return
 		}
 
-		// 2.Get subscription
-		subscription, err := reader.GetSubscription(int64(id))
+		fields, err := getFieldSet(r, model.ReadFields)
+		if err != nil {
+			logger.Info("invalid fields", "details", err)
+
+			renderFieldError(w, r, err)
+
+			return
+		}
+		// Subscription status is stored in status column
+		if fields[model.FieldSubscriptionStatus] {
+			fields[model.FieldStatus] = true
+		}
+
+		// 2.Get subscription (requested fields only)
+		subscription, err := reader.GetSubscriptionFields(int64(id), fields)
 		if errors.Is(err, storage.ErrSubscribtionNotFound) {
 			logger.Info("subscription not found", "id", id)
 
-			w.WriteHeader(http.StatusNotFound)
-			render.JSON(w, r, ReadResponse{Response: RespError("subscription not found")})
+			renderError(w, r, http.StatusNotFound, CodeSubscriptionNotFound, "", "subscription not found")
 
 			return
 		}
 		if err != nil {
 			logger.Error("failed to get subscription", "details", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, ReadResponse{Response: RespError("failed to get subscription")})
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to get subscription")
 
 			return
 		}
@@ -112,20 +178,44 @@ func NewReadHandler(logger *slog.Logger, reader Reader) http.HandlerFunc {
 			"end_date", subscription.EndDate.ToString(),
 		)
 
-		// 3.Prepare response and render it
+		// 3.Prepare response and render it (unless client has the same one)
 		resp := makeReadResp(&subscription)
-		render.JSON(w, r, resp)
+		renderConditionalJSON(w, r, subscription.UpdatedAt, selectFields(resp, fields))
 	}
 }
 
 func makeReadResp(subscription *model.Subscription) ReadResponse {
 	return ReadResponse{
-		Id:          subscription.ID,
-		ServiceName: subscription.ServiceName,
-		Price:       subscription.Price,
-		UserID:      subscription.UserID.String(),
-		StartDate:   subscription.StartDate.ToString(),
-		EndDate:     subscription.EndDate.ToString(),
-		Response:    RespOK(),
+		Id:                 subscription.ID,
+		ServiceName:        subscription.ServiceName,
+		ServiceID:          subscription.ServiceID,
+		Price:              subscription.Price,
+		UserID:             subscription.UserID.String(),
+		StartDate:          subscription.StartDate.ToString(),
+		EndDate:            subscription.EndDate.ToString(),
+		TrialMonths:        subscription.TrialMonths,
+		TrialPrice:         subscription.TrialPrice,
+		SubscriptionStatus: string(subscription.Status),
+		StatusHistory:      makeStatusTransitionItems(subscription.StatusHistory),
+		PriceSchedule:      makePriceScheduleItems(subscription.PriceSchedule),
+		Discounts:          makeDiscountItems(subscription.Discounts),
+		Members:            makeMemberItems(subscription.Members),
+		Category:           subscription.Category,
+		Tags:               makeTagItems(subscription.Tags),
+		Metadata:           makeMetadata(subscription.Metadata),
+		AutoRenew:          subscription.AutoRenew,
+		BillingPeriod:      subscription.Period(),
+		Renewals:           makeRenewalItems(subscription.Renewals),
+		CreatedAt:          subscription.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:          subscription.UpdatedAt.UTC().Format(time.RFC3339),
+		Response:           RespOK(),
+	}
+}
+
+func makeMetadata(metadata json.RawMessage) json.RawMessage {
+	if metadata == nil {
+		return json.RawMessage("{}")
 	}
+
+	return metadata
 }
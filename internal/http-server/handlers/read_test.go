@@ -5,14 +5,15 @@ import (
 	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
 	"em_golang_rest_service_example/internal/model"
 	"em_golang_rest_service_example/internal/storage"
-	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
@@ -61,7 +62,7 @@ func TestReadHandler(t *testing.T) {
 
 			id, err := strconv.Atoi(tc.id)
 			if err == nil {
-				readerMock.On("GetSubscription", int64(id)).Return(model.Subscription{}, tc.mockError)
+				readerMock.On("GetSubscriptionFields", int64(id), model.FieldSet(nil)).Return(model.Subscription{}, tc.mockError)
 			}
 
 			readRespCheck(t, logger, readerMock, tc.id, tc.respCode, &tc.respError)
@@ -88,10 +89,132 @@ func readRespCheck(t *testing.T, l *slog.Logger, r Reader, id string, expCode in
 
 	assert.Equal(t, expCode, rr.Code)
 
-	body := rr.Body.String()
+	var resp ReadResponse
+	assert.Equal(t, *expRespErr, decodeResp(t, rr, &resp))
+}
+
+func TestReadHandlerConditional(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	updatedAt := time.Date(2026, 3, 1, 12, 30, 15, 500, time.UTC)
+
+	readerMock := mocks.NewReader(t)
+	readerMock.On("GetSubscriptionFields", int64(1), model.FieldSet(nil)).Return(model.Subscription{ID: 1, UpdatedAt: updatedAt}, nil)
+
+	router := chi.NewRouter()
+	router.Get("/subscription/{id}", NewReadHandler(logger, readerMock))
+
+	get := func(headers map[string]string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, "/subscription/1", nil)
+		assert.NoError(t, err)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	// 1.Unconditional request gets validators
+	rr := get(nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "Sun, 01 Mar 2026 12:30:15 GMT", rr.Header().Get("Last-Modified"))
+
+	etag := rr.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{64}"$`, etag)
+
+	cases := []struct {
+		name     string
+		headers  map[string]string
+		respCode int
+	}{
+		{
+			name:     "Same ETag",
+			headers:  map[string]string{"If-None-Match": etag},
+			respCode: http.StatusNotModified,
+		},
+		{
+			name:     "Weak ETag in list",
+			headers:  map[string]string{"If-None-Match": `"other", W/` + etag},
+			respCode: http.StatusNotModified,
+		},
+		{
+			name:     "Another ETag",
+			headers:  map[string]string{"If-None-Match": `"other"`},
+			respCode: http.StatusOK,
+		},
+		{
+			name:     "Not modified since",
+			headers:  map[string]string{"If-Modified-Since": "Sun, 01 Mar 2026 12:30:15 GMT"},
+			respCode: http.StatusNotModified,
+		},
+		{
+			name:     "Modified since",
+			headers:  map[string]string{"If-Modified-Since": "Sun, 01 Mar 2026 12:30:14 GMT"},
+			respCode: http.StatusOK,
+		},
+		{
+			name:     "If-None-Match wins over If-Modified-Since",
+			headers:  map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": "Sun, 01 Mar 2026 12:30:15 GMT"},
+			respCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := get(tc.headers)
+
+			assert.Equal(t, tc.respCode, rr.Code)
+			assert.Equal(t, etag, rr.Header().Get("ETag"))
+
+			if tc.respCode == http.StatusNotModified {
+				assert.Empty(t, rr.Body.String())
+			} else {
+				var resp ReadResponse
+				assert.Equal(t, "", decodeResp(t, rr, &resp))
+				assert.Equal(t, "2026-03-01T12:30:15Z", resp.UpdatedAt)
+			}
+		})
+	}
+}
+
+func TestReadHandlerFields(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	fields := model.FieldSet{model.FieldServiceName: true, model.FieldServiceID: true, model.FieldPrice: true, model.FieldTags: true}
+
+	readerMock := mocks.NewReader(t)
+	readerMock.On("GetSubscriptionFields", int64(1), fields).Return(model.Subscription{ID: 1, SubscriptionSpec: model.SubscriptionSpec{ServiceName: "Netflix", Price: 999}}, nil)
+
+	router := chi.NewRouter()
+	router.Get("/subscription/{id}", NewReadHandler(logger, readerMock))
+
+	// 1.Response has requested fields only (empty service id is omitted as in full response)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/subscription/1?fields=service_name,%20service_id,price,tags", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"service_name":"Netflix","price":"9.99","tags":[]}`, rr.Body.String())
+	assert.NotEmpty(t, rr.Header().Get("ETag"))
+
+	// 2.Unknown fields are rejected with allowed ones listed
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/subscription/1?fields=service_name,colour", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
 
 	var resp ReadResponse
+	assert.Equal(t, `unknown field "colour", allowed fields: `+strings.Join(model.ReadFields, ", "), decodeResp(t, rr, &resp))
+
+	// 3.Subscription status is read from status column
+	statusFields := model.FieldSet{model.FieldSubscriptionStatus: true, model.FieldStatus: true}
+	readerMock.On("GetSubscriptionFields", int64(2), statusFields).Return(model.Subscription{ID: 2, Status: model.StatusPaused}, nil)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/subscription/2?fields=subscription_status", nil))
 
-	assert.Nil(t, json.Unmarshal([]byte(body), &resp))
-	assert.Equal(t, *expRespErr, resp.Error)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"subscription_status":"paused"}`, rr.Body.String())
 }
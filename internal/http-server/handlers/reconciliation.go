@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/model"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+// Max size of uploaded statement in bytes
+const maxStatementSize = 2 << 20
+
+// ReconciliationResponse represents result of bank statement reconciliation
+// swagger:model ReconciliationResponse
+// @ID ReconciliationResponse
+type ReconciliationResponse struct {
+	// First month of statement payments
+	From string `json:"from"`
+
+	// Last month of statement payments
+	To string `json:"to"`
+
+	// Expected charges found in statement
+	Matched []ReconciledItem `json:"matched"`
+
+	// Expected charges not found in statement
+	Missing []ExpectedChargeItem `json:"missing"`
+
+	// Untracked payments repeating in several months (probably subscriptions not added yet)
+	Unknown []RecurringPaymentItem `json:"unknown"`
+
+	Response
+}
+
+// ReconciledItem represents expected charge matched with statement payment
+// swagger:model ReconciledItem
+// @ID ReconciledItem
+type ReconciledItem struct {
+	ExpectedChargeItem
+
+	// Matched statement payment
+	Payment StatementPaymentItem `json:"payment"`
+}
+
+// ExpectedChargeItem represents monthly charge of subscription expected in statement
+// swagger:model ExpectedChargeItem
+// @ID ExpectedChargeItem
+type ExpectedChargeItem struct {
+	// Subscription id
+	SubscriptionID int64 `json:"subscription_id"`
+
+	// Subscription service name
+	ServiceName string `json:"service_name"`
+
+	// Billed month
+	Month string `json:"month"`
+
+	// Expected charge (decimal string)
+	Amount model.Money `json:"amount" swaggertype:"string" example:"9.99"`
+}
+
+// RecurringPaymentItem represents payments of one untracked merchant
+// swagger:model RecurringPaymentItem
+// @ID RecurringPaymentItem
+type RecurringPaymentItem struct {
+	// Merchant as written in first payment
+	Merchant string `json:"merchant"`
+
+	// Payments ordered as in statement
+	Payments []StatementPaymentItem `json:"payments"`
+}
+
+// StatementPaymentItem represents line of bank statement
+// swagger:model StatementPaymentItem
+// @ID StatementPaymentItem
+type StatementPaymentItem struct {
+	// Line number in statement (from 1)
+	Line int `json:"line"`
+
+	// Payment date in YYYY-MM-DD format
+	Date string `json:"date"`
+
+	// Merchant description
+	Merchant string `json:"merchant"`
+
+	// Paid amount (decimal string)
+	Amount model.Money `json:"amount" swaggertype:"string" example:"9.99"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=Reconciler
+type Reconciler interface {
+	FilterSubscriptions(startDate, endDate model.Date, userId uuid.UUID, serviceName *string, filter model.SubscriptionFilter) ([]model.Subscription, error)
+	GetServices() ([]model.Service, error)
+}
+
+// NewReconciliationHandler godoc
+// @Summary Reconcile bank statement
+// @Description Match CSV statement (date, merchant and amount columns; YYYY-MM-DD or DD.MM.YYYY dates) against charges expected in statement months:
+// @Description payment matches charge of the same month if merchant contains service name or alias and amount differs by tolerance at most
+// @Tags v1
+// @Accept text/csv
+// @Produce json
+// @Param statement body string true "CSV statement"
+// @Param user_id query string false "Id of user whose subscriptions are expected"
+// @Param tolerance query string false "Max difference of payment and charge amounts (decimal string, 0 by default)"
+// @Param status query string false "Subscription status filter"
+// @Param category query string false "Subscription category filter"
+// @Param tag query string false "Subscription tag filter"
+// @Param filter query string false "Filter expression, e.g. price>=300 and service_name~\"yan*\" and active_in(2025-01,2025-06); amounts are integers in minor units or quoted decimals (price>=\"3.00\")"
+// @Param Idempotency-Key header string false "Key making retries safe (response of the first request is replayed)"
+// @Success 200 {object} ReconciliationResponse
+// @Failure 400 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /reconciliation [post]
+func NewReconciliationHandler(logger *slog.Logger, reconciler Reconciler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.reconciliation"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		// 1.Parse and validate URL data
+		userId := uuid.Nil
+		if userIdStr := r.URL.Query().Get("user_id"); userIdStr != "" {
+			var err error
+
+			userId, err = uuid.Parse(userIdStr)
+			if err != nil {
+				logger.Error("user id filter is invalid", "details", err)
+
+				renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "user_id", "user id filter is invalid")
+
+				return
+			}
+		}
+
+		var tolerance model.Money
+		if toleranceStr := r.URL.Query().Get("tolerance"); toleranceStr != "" {
+			var err error
+
+			tolerance, err = model.MoneyFromString(toleranceStr, model.DefaultCurrency)
+			if err != nil || tolerance < 0 {
+				logger.Error("tolerance is invalid", "tolerance", toleranceStr)
+
+				renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "tolerance", "tolerance is invalid")
+
+				return
+			}
+		}
+
+		filter, err := getSubscriptionFilter(r)
+		if err != nil {
+			logger.Error("invalid filter", "details", err)
+
+			renderFieldError(w, r, err)
+
+			return
+		}
+
+		// 2.Parse statement
+		lines, err := model.ParseStatement(http.MaxBytesReader(w, r.Body, maxStatementSize))
+		if ok := checkStatement(r, w, logger, err); !ok {
+			return
+		}
+
+		// 3.Get subscriptions active in statement months and catalog (date bounds of storage select subscriptions
+		// within them, so statement period is added to filter as active_in condition)
+		from, to := model.StatementPeriod(lines)
+
+		var period model.FilterExpr = &model.FilterActiveIn{From: from, To: to}
+		if filter.Expr != nil {
+			period = &model.FilterAnd{Left: filter.Expr, Right: period}
+		}
+		filter.Expr = period
+
+		subscriptions, err := reconciler.FilterSubscriptions(
+			model.Date{Month: 1, Year: 1000}, model.Date{Month: 12, Year: 9999}, userId, nil, filter,
+		)
+		if err != nil {
+			logger.Error("failed to get subscription", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to get subscription")
+
+			return
+		}
+
+		services, err := reconciler.GetServices()
+		if err != nil {
+			logger.Error("failed to get services", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to get services")
+
+			return
+		}
+
+		// 4.Match statement and render report
+		report := model.Reconcile(lines, subscriptions, services, tolerance)
+
+		logger.Info(
+			"statement reconciled",
+			"lines", len(lines), "matched", len(report.Matched), "missing", len(report.Missing), "unknown", len(report.Unknown),
+		)
+
+		render.JSON(w, r, makeReconciliationResponse(&report))
+	}
+}
+
+func checkStatement(r *http.Request, w http.ResponseWriter, logger *slog.Logger, err error) bool {
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, model.ErrStatementEmpty):
+		logger.Error("statement is empty")
+
+		renderError(w, r, http.StatusBadRequest, CodeEmptyBody, "", "empty statement")
+	case errors.Is(err, model.ErrStatementTooLarge) || errors.As(err, &maxBytesErr):
+		logger.Error("statement is too large", "details", err)
+
+		renderError(w, r, http.StatusBadRequest, CodeStatementTooLarge, "", "statement is too large")
+	default:
+		logger.Error("statement is invalid", "details", err)
+
+		renderError(w, r, http.StatusBadRequest, CodeMalformedBody, "", "statement is invalid: "+err.Error())
+	}
+
+	return false
+}
+
+func makeReconciliationResponse(report *model.ReconciliationReport) ReconciliationResponse {
+	resp := ReconciliationResponse{
+		From:     report.From.ToString(),
+		To:       report.To.ToString(),
+		Matched:  []ReconciledItem{},
+		Missing:  []ExpectedChargeItem{},
+		Unknown:  []RecurringPaymentItem{},
+		Response: RespOK(),
+	}
+
+	for i := range report.Matched {
+		resp.Matched = append(resp.Matched, ReconciledItem{
+			ExpectedChargeItem: makeExpectedChargeItem(&report.Matched[i].ExpectedCharge),
+			Payment:            makeStatementPaymentItem(&report.Matched[i].Payment),
+		})
+	}
+
+	for i := range report.Missing {
+		resp.Missing = append(resp.Missing, makeExpectedChargeItem(&report.Missing[i]))
+	}
+
+	for _, recurring := range report.Unknown {
+		item := RecurringPaymentItem{Merchant: recurring.Merchant, Payments: []StatementPaymentItem{}}
+		for i := range recurring.Payments {
+			item.Payments = append(item.Payments, makeStatementPaymentItem(&recurring.Payments[i]))
+		}
+
+		resp.Unknown = append(resp.Unknown, item)
+	}
+
+	return resp
+}
+
+func makeExpectedChargeItem(charge *model.ExpectedCharge) ExpectedChargeItem {
+	return ExpectedChargeItem{
+		SubscriptionID: charge.SubscriptionID,
+		ServiceName:    charge.ServiceName,
+		Month:          charge.Month.ToString(),
+		Amount:         charge.Amount,
+	}
+}
+
+func makeStatementPaymentItem(line *model.StatementLine) StatementPaymentItem {
+	return StatementPaymentItem{
+		Line:     line.Line,
+		Date:     line.Date.Format(time.DateOnly),
+		Merchant: line.Merchant,
+		Amount:   line.Amount,
+	}
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
+	"em_golang_rest_service_example/internal/model"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReconciliationHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	userId := uuid.New()
+
+	subscription := model.Subscription{
+		ID: 1,
+		SubscriptionSpec: model.SubscriptionSpec{
+			ServiceName: "Netflix",
+			Price:       99900,
+			UserID:      userId,
+			StartDate:   model.Date{Month: 1, Year: 2026},
+			EndDate:     model.Date{Month: 1, Year: 2027},
+		},
+	}
+
+	statement := "date;merchant;amount\n" +
+		"03.01.2026;NETFLIX.COM;-999,00\n" +
+		"10.01.2026;KINOPOISK;-399,00\n" +
+		"10.02.2026;KINOPOISK;-399,00\n"
+
+	// Only subscriptions active in statement months are read
+	statementPeriod := &model.FilterActiveIn{From: model.Date{Month: 1, Year: 2026}, To: model.Date{Month: 2, Year: 2026}}
+
+	priceExpr, err := model.ParseFilterExpr("price >= 50000")
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name      string
+		query     string
+		body      string
+		userId    uuid.UUID
+		filter    model.SubscriptionFilter
+		tolerance model.Money
+		respCode  int
+		respError string
+		matched   int
+		missing   int
+		unknown   int
+		mockError error
+		skipMock  bool
+	}{
+		{
+			name:     "Success",
+			query:    "?user_id=" + userId.String(),
+			body:     statement,
+			userId:   userId,
+			filter:   model.SubscriptionFilter{Expr: statementPeriod},
+			respCode: http.StatusOK,
+			matched:  1,
+			missing:  1,
+			unknown:  1,
+		},
+		{
+			name:     "Success with tolerance",
+			query:    "?tolerance=1.50",
+			body:     "2026-01-03,Netflix,1000.00\n",
+			filter:   model.SubscriptionFilter{Expr: &model.FilterActiveIn{From: model.Date{Month: 1, Year: 2026}, To: model.Date{Month: 1, Year: 2026}}},
+			respCode: http.StatusOK,
+			matched:  1,
+		},
+		{
+			name:     "Success with filter expression",
+			query:    "?filter=" + url.QueryEscape("price >= 50000"),
+			body:     statement,
+			filter:   model.SubscriptionFilter{Expr: &model.FilterAnd{Left: priceExpr, Right: statementPeriod}},
+			respCode: http.StatusOK,
+			matched:  1,
+			missing:  1,
+			unknown:  1,
+		},
+		{
+			name:      "Invalid user id",
+			query:     "?user_id=trash",
+			body:      statement,
+			respCode:  http.StatusBadRequest,
+			respError: "user id filter is invalid",
+			skipMock:  true,
+		},
+		{
+			name:      "Negative tolerance",
+			query:     "?tolerance=-1",
+			body:      statement,
+			respCode:  http.StatusBadRequest,
+			respError: "tolerance is invalid",
+			skipMock:  true,
+		},
+		{
+			name:      "Invalid filter",
+			query:     "?status=trash",
+			body:      statement,
+			respCode:  http.StatusBadRequest,
+			respError: "status filter is invalid",
+			skipMock:  true,
+		},
+		{
+			name:      "Empty statement",
+			body:      "date,merchant,amount\n",
+			respCode:  http.StatusBadRequest,
+			respError: "empty statement",
+			skipMock:  true,
+		},
+		{
+			name:      "Invalid statement",
+			body:      "2026-01-03,Netflix,999\n2026-01-03,Netflix,trash\n",
+			respCode:  http.StatusBadRequest,
+			respError: "statement is invalid: line 2: amount is invalid: invalid money format",
+			skipMock:  true,
+		},
+		{
+			name:      "Too large statement",
+			body:      strings.Repeat("2026-01-03,Netflix,999\n", maxStatementSize/23+1),
+			respCode:  http.StatusBadRequest,
+			respError: "statement is too large",
+			skipMock:  true,
+		},
+		{
+			name:      "Any other reconciler error case",
+			body:      statement,
+			respCode:  http.StatusInternalServerError,
+			filter:    model.SubscriptionFilter{Expr: statementPeriod},
+			respError: "failed to get subscription",
+			mockError: errors.New("any error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reconcilerMock := mocks.NewReconciler(t)
+
+			if !tc.skipMock {
+				reconcilerMock.On("FilterSubscriptions", mock.Anything, mock.Anything, tc.userId, (*string)(nil), tc.filter).
+					Return([]model.Subscription{subscription}, tc.mockError)
+
+				if tc.mockError == nil {
+					reconcilerMock.On("GetServices").Return([]model.Service{}, nil)
+				}
+			}
+
+			router := chi.NewRouter()
+			router.Post("/reconciliation", NewReconciliationHandler(logger, reconcilerMock))
+
+			req, err := http.NewRequest(http.MethodPost, "/reconciliation"+tc.query, strings.NewReader(tc.body))
+			assert.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.respCode, rr.Code)
+
+			var resp ReconciliationResponse
+			assert.Equal(t, tc.respError, decodeResp(t, rr, &resp))
+
+			if tc.respError == "" {
+				assert.Len(t, resp.Matched, tc.matched)
+				assert.Len(t, resp.Missing, tc.missing)
+				assert.Len(t, resp.Unknown, tc.unknown)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// RenewalItem represents billing period added by auto-renewal
+// swagger:model RenewalItem
+// @ID RenewalItem
+type RenewalItem struct {
+	// Renewal id
+	Id int64 `json:"id"`
+
+	// First month of period
+	PeriodStart string `json:"period_start"`
+
+	// Month after last month of period (new end date of subscription)
+	PeriodEnd string `json:"period_end"`
+
+	// Monthly price effective at period start (decimal string)
+	Price model.Money `json:"price" swaggertype:"string" example:"9.99"`
+
+	// Moment of renewal in RFC 3339 format
+	RenewedAt string `json:"renewed_at"`
+}
+
+// RenewalRequest represents auto-renewal settings of subscription
+// swagger:model RenewalRequest
+// @ID RenewalRequest
+type RenewalRequest struct {
+	// Extend subscription when it passes end date
+	AutoRenew bool `json:"auto_renew"`
+
+	// Billing period in months (from 1 to 12), subscription is extended by it; one month if not set
+	BillingPeriod int `json:"billing_period,omitempty"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=AutoRenewSetter
+type AutoRenewSetter interface {
+	SetAutoRenew(id int64, autoRenew bool, billingPeriod int) error
+}
+
+// NewSetAutoRenewHandler godoc
+// @Summary Set auto-renewal of subscription
+// @Description Turn auto-renewal on or off and set billing period renewals extend subscription by
+// @Tags v1
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param request body RenewalRequest true "Auto-renewal settings"
+// @Success 200 {object} Response
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /subscription/{id}/renewal [put]
+func NewSetAutoRenewHandler(logger *slog.Logger, setter AutoRenewSetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.set_auto_renew"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		// 1.Get subscription id from request
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			logger.Info("invalid subscription id format", "details", err)
+
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "id", "invalid subscription id format")
+
+			return
+		}
+
+		// 2.Parse and validate request
+		var req RenewalRequest
+		if ok := parseReq(r, w, logger, &req); !ok {
+			return
+		}
+
+		if req.BillingPeriod == 0 {
+			req.BillingPeriod = 1
+		}
+
+		if err := model.ValidateBillingPeriod(req.BillingPeriod); err != nil {
+			logger.Info("request billing period is invalid", "details", err)
+
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "billing_period", "request billing period is invalid")
+
+			return
+		}
+
+		// 3.Update settings
+		err = setter.SetAutoRenew(id, req.AutoRenew, req.BillingPeriod)
+		if errors.Is(err, storage.ErrSubscribtionNotFound) {
+			logger.Info("subscription not found", "id", id)
+
+			renderError(w, r, http.StatusNotFound, CodeSubscriptionNotFound, "", "subscription not found")
+
+			return
+		}
+		if err != nil {
+			logger.Error("failed to set auto-renewal", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to set auto-renewal")
+
+			return
+		}
+
+		logger.Info("subscription auto-renewal set", "id", id, "auto_renew", req.AutoRenew, "billing_period", req.BillingPeriod)
+
+		render.JSON(w, r, RespOK())
+	}
+}
+
+func makeRenewalItems(renewals []model.Renewal) []RenewalItem {
+	items := []RenewalItem{}
+
+	for i := 0; i < len(renewals); i++ {
+		items = append(items, RenewalItem{
+			Id:          renewals[i].ID,
+			PeriodStart: renewals[i].PeriodStart.ToString(),
+			PeriodEnd:   renewals[i].PeriodEnd.ToString(),
+			Price:       renewals[i].Price,
+			RenewedAt:   renewals[i].RenewedAt.Format(time.RFC3339),
+		})
+	}
+
+	return items
+}
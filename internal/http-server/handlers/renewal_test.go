@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetAutoRenewHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	cases := []struct {
+		name          string
+		id            string
+		body          string
+		autoRenew     bool
+		billingPeriod int
+		respCode      int
+		respError     string
+		mockError     error
+	}{
+		{
+			name:          "Success",
+			id:            "1",
+			body:          `{"auto_renew": true, "billing_period": 12}`,
+			autoRenew:     true,
+			billingPeriod: 12,
+			respCode:      http.StatusOK,
+		},
+		{
+			name:          "Default billing period",
+			id:            "1",
+			body:          `{"auto_renew": false}`,
+			billingPeriod: 1,
+			respCode:      http.StatusOK,
+		},
+		{
+			name:      "Invalid id",
+			id:        "trash",
+			body:      `{"auto_renew": true}`,
+			respCode:  http.StatusBadRequest,
+			respError: "invalid subscription id format",
+		},
+		{
+			name:      "Billing period out of range",
+			id:        "1",
+			body:      `{"auto_renew": true, "billing_period": 24}`,
+			respCode:  http.StatusBadRequest,
+			respError: "request billing period is invalid",
+		},
+		{
+			name:          "Not found subscription",
+			id:            "1",
+			body:          `{"auto_renew": true}`,
+			autoRenew:     true,
+			billingPeriod: 1,
+			respCode:      http.StatusNotFound,
+			respError:     "subscription not found",
+			mockError:     storage.ErrSubscribtionNotFound,
+		},
+		{
+			name:          "Any other setter error case",
+			id:            "1",
+			body:          `{"auto_renew": true}`,
+			autoRenew:     true,
+			billingPeriod: 1,
+			respCode:      http.StatusInternalServerError,
+			respError:     "failed to set auto-renewal",
+			mockError:     errors.New("any error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			setterMock := mocks.NewAutoRenewSetter(t)
+
+			if tc.respError == "" || tc.mockError != nil {
+				setterMock.On("SetAutoRenew", int64(1), tc.autoRenew, tc.billingPeriod).Return(tc.mockError)
+			}
+
+			router := chi.NewRouter()
+			router.Put("/subscription/{id}/renewal", NewSetAutoRenewHandler(logger, setterMock))
+
+			req, err := http.NewRequest(http.MethodPut, "/subscription/"+tc.id+"/renewal", bytes.NewReader([]byte(tc.body)))
+			assert.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.respCode, rr.Code)
+
+			var resp Response
+			assert.Equal(t, tc.respError, decodeResp(t, rr, &resp))
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/model"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+const (
+	// Longest search query (in characters)
+	maxSearchQueryLength = 100
+
+	// Number of found subscriptions returned by default and at most
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=SubscriptionSearcher
+type SubscriptionSearcher interface {
+	SearchSubscriptions(query string, limit int) ([]model.Subscription, error)
+}
+
+// NewSearchHandler godoc
+// @Summary Search subscriptions by service name
+// @Description Search subscriptions whose service name is close to query (misspelled names are found too),
+// @Description the closest ones go first; only service name is searched (subscription has no notes)
+// @Tags v1
+// @Produce json
+// @Param q query string true "Search query, e.g. yandx (up to 100 characters)"
+// @Param limit query int false "Number of found subscriptions (20 by default, up to 100)"
+// @Success 200 {object} ListResponse
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /subscriptions/search [get]
+func NewSearchHandler(logger *slog.Logger, searcher SubscriptionSearcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.search"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		// 1.Get query and limit and validate it
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" {
+			logger.Info("no search query")
+
+			renderError(w, r, http.StatusBadRequest, CodeMissingValue, "q", "no search query")
+
+			return
+		}
+		if utf8.RuneCountInString(query) > maxSearchQueryLength {
+			logger.Info("search query is too long")
+
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "q", fmt.Sprintf("search query is longer than %d characters", maxSearchQueryLength))
+
+			return
+		}
+
+		limit := defaultSearchLimit
+		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+			var err error
+			limit, err = strconv.Atoi(limitStr)
+			if err != nil || limit < 1 || limit > maxSearchLimit {
+				logger.Info("invalid limit value", "limit", limitStr)
+
+				renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "limit", fmt.Sprintf("invalid limit value (expected 1 to %d)", maxSearchLimit))
+
+				return
+			}
+		}
+
+		// 2.Search subscriptions
+		subscriptions, err := searcher.SearchSubscriptions(query, limit)
+		if err != nil {
+			logger.Error("failed to search subscriptions", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to search subscriptions")
+
+			return
+		}
+
+		logger.Info("subscriptions found", "query", query, "count", len(subscriptions))
+
+		// 3.Prepare response and render it
+		render.JSON(w, r, makeListResp(subscriptions))
+	}
+}
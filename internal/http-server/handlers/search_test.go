@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
+	"em_golang_rest_service_example/internal/model"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	subscriptions := []model.Subscription{
+		{ID: 3, SubscriptionSpec: model.SubscriptionSpec{ServiceName: "Yandex Plus", UserID: uuid.New()}, Status: model.StatusActive},
+		{ID: 1, SubscriptionSpec: model.SubscriptionSpec{ServiceName: "Yandex Music", UserID: uuid.New()}, Status: model.StatusActive},
+	}
+
+	cases := []struct {
+		name      string
+		query     string
+		respCode  int
+		respError string
+		mockQuery string
+		mockLimit int
+		mockError error
+	}{
+		{
+			name:      "Success",
+			query:     "?q=yandx",
+			respCode:  http.StatusOK,
+			mockQuery: "yandx",
+			mockLimit: defaultSearchLimit,
+		},
+		{
+			name:      "Success with limit and spaces around query",
+			query:     "?q=" + url.QueryEscape(" yandex muzic ") + "&limit=5",
+			respCode:  http.StatusOK,
+			mockQuery: "yandex muzic",
+			mockLimit: 5,
+		},
+		{
+			name:      "No query",
+			query:     "?q=%20",
+			respCode:  http.StatusBadRequest,
+			respError: "no search query",
+		},
+		{
+			name:      "Too long query",
+			query:     "?q=" + strings.Repeat("я", maxSearchQueryLength+1),
+			respCode:  http.StatusBadRequest,
+			respError: "search query is longer than 100 characters",
+		},
+		{
+			name:      "Invalid limit",
+			query:     "?q=yandx&limit=trash",
+			respCode:  http.StatusBadRequest,
+			respError: "invalid limit value (expected 1 to 100)",
+		},
+		{
+			name:      "Limit out of range",
+			query:     "?q=yandx&limit=101",
+			respCode:  http.StatusBadRequest,
+			respError: "invalid limit value (expected 1 to 100)",
+		},
+		{
+			name:      "Any searcher error case",
+			query:     "?q=yandx",
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to search subscriptions",
+			mockQuery: "yandx",
+			mockLimit: defaultSearchLimit,
+			mockError: errors.New("any error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			searcherMock := mocks.NewSubscriptionSearcher(t)
+
+			if tc.mockQuery != "" {
+				searcherMock.On("SearchSubscriptions", tc.mockQuery, tc.mockLimit).Return(subscriptions, tc.mockError)
+			}
+
+			router := chi.NewRouter()
+			router.Get("/subscriptions/search", NewSearchHandler(logger, searcherMock))
+
+			rr := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, "/subscriptions/search"+tc.query, nil)
+			assert.NoError(t, err)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.respCode, rr.Code)
+
+			var resp ListResponse
+			assert.Equal(t, tc.respError, decodeResp(t, rr, &resp))
+			if tc.respError == "" {
+				// Order of found subscriptions is kept
+				assert.Equal(t, makeListResp(subscriptions), resp)
+			}
+		})
+	}
+}
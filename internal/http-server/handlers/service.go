@@ -0,0 +1,472 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// ServiceRequest represents catalog service model
+// swagger:model ServiceRequest
+// @ID ServiceRequest
+type ServiceRequest struct {
+	// Canonical service name (required)
+	Name string `json:"name"`
+
+	// Other names of service, case and separators insensitive (optional)
+	Aliases []string `json:"aliases,omitempty"`
+
+	// Monthly price for new subscriptions without price (optional)
+	DefaultPrice model.Money `json:"default_price,omitempty" swaggertype:"string" example:"9.99"`
+
+	// Service category (optional)
+	Category string `json:"category,omitempty"`
+}
+
+// ServiceItem represents one catalog service
+// swagger:model ServiceItem
+// @ID ServiceItem
+type ServiceItem struct {
+	// Service id
+	Id int64 `json:"id"`
+
+	// Canonical service name
+	Name string `json:"name"`
+
+	// Other names of service
+	Aliases []string `json:"aliases"`
+
+	// Monthly price for new subscriptions without price (decimal string)
+	DefaultPrice model.Money `json:"default_price" swaggertype:"string" example:"9.99"`
+
+	// Service category
+	Category string `json:"category"`
+}
+
+// ServiceResponse represents response with catalog service was read
+// swagger:model ServiceResponse
+// @ID ServiceResponse
+type ServiceResponse struct {
+	ServiceItem
+
+	Response
+}
+
+// ServiceListResponse represents catalog services list model
+// swagger:model ServiceListResponse
+// @ID ServiceListResponse
+type ServiceListResponse struct {
+	// All catalog services
+	Items []ServiceItem `json:"items"`
+
+	Response
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=ServiceCreator
+type ServiceCreator interface {
+	CreateService(service model.Service) (int64, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=ServiceReader
+type ServiceReader interface {
+	GetService(id int64) (model.Service, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=ServiceListReader
+type ServiceListReader interface {
+	GetServices() ([]model.Service, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=ServiceUpdater
+type ServiceUpdater interface {
+	UpdateService(id int64, service model.Service) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=ServiceDeleter
+type ServiceDeleter interface {
+	DeleteService(id int64) error
+}
+
+// NewCreateServiceHandler godoc
+// @Summary Create catalog service
+// @Description Create catalog service with canonical name and aliases
+// @Tags v1
+// @Accept json
+// @Produce json
+// @Param request body ServiceRequest true "Service data"
+// @Param Idempotency-Key header string false "Key making retries safe (response of the first request is replayed)"
+// @Success 201 {object} CreateResponse
+// @Failure 400 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /services [post]
+func NewCreateServiceHandler(logger *slog.Logger, creator ServiceCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.create_service"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		// 1.Parse request
+		var req ServiceRequest
+		if ok := parseReq(r, w, logger, &req); !ok {
+			return
+		}
+
+		// 2.Validate request data
+		service, validateOk := validateServiceReq(r, w, &req, logger)
+		if !validateOk {
+			return
+		}
+
+		// 3.Create
+		id, err := creator.CreateService(service)
+		if errors.Is(err, storage.ErrServiceExists) {
+			logger.Info("service name or alias already exists", "name", req.Name)
+
+			renderError(w, r, http.StatusConflict, CodeServiceExists, "", "service name or alias already exists")
+
+			return
+		}
+		if err != nil {
+			logger.Error("failed to create service", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to create service")
+
+			return
+		}
+
+		logger.Info("service created", "id", id)
+
+		w.WriteHeader(http.StatusCreated)
+		render.JSON(w, r, CreateResponse{ID: id, Response: RespOK()})
+	}
+}
+
+// NewReadServiceHandler godoc
+// @Summary Read catalog service
+// @Description Read catalog service
+// @Tags v1
+// @Produce json
+// @Param id path int true "Service ID"
+// @Success 200 {object} ServiceResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /services/{id} [get]
+func NewReadServiceHandler(logger *slog.Logger, reader ServiceReader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.read_service"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		// 1.Get service id from request
+		id, ok := getServiceId(r, w, logger)
+		if !ok {
+			return
+		}
+
+		// 2.Get service
+		service, err := reader.GetService(id)
+		if errors.Is(err, storage.ErrServiceNotFound) {
+			logger.Info("service not found", "id", id)
+
+			renderError(w, r, http.StatusNotFound, CodeServiceNotFound, "", "service not found")
+
+			return
+		}
+		if err != nil {
+			logger.Error("failed to get service", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to get service")
+
+			return
+		}
+
+		logger.Info("got service", "id", service.ID, "name", service.Name)
+
+		// 3.Prepare response and render it
+		render.JSON(w, r, ServiceResponse{ServiceItem: makeServiceItem(&service), Response: RespOK()})
+	}
+}
+
+// NewListServicesHandler godoc
+// @Summary Get all catalog services
+// @Description Get all catalog services
+// @Tags v1
+// @Produce json
+// @Success 200 {object} ServiceListResponse
+// @Failure 500 {object} Problem
+// @Router /services [get]
+func NewListServicesHandler(logger *slog.Logger, listReader ServiceListReader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.list_services"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		// 1.Get services
+		services, err := listReader.GetServices()
+		if err != nil {
+			logger.Error("failed to get services", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to get services")
+
+			return
+		}
+
+		logger.Info("got services")
+
+		// 2.Prepare response and render it
+		resp := ServiceListResponse{Items: []ServiceItem{}, Response: RespOK()}
+		for i := 0; i < len(services); i++ {
+			resp.Items = append(resp.Items, makeServiceItem(&services[i]))
+		}
+
+		render.JSON(w, r, resp)
+	}
+}
+
+// NewUpdateServiceHandler godoc
+// @Summary Update catalog service
+// @Description Replace catalog service data and aliases, subscriptions of service get new name
+// @Tags v1
+// @Accept json
+// @Produce json
+// @Param id path int true "Service ID"
+// @Param request body ServiceRequest true "Service new data"
+// @Success 200 {object} Response
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /services/{id} [patch]
+func NewUpdateServiceHandler(logger *slog.Logger, updater ServiceUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.update_service"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		// 1.Get service id from request
+		id, ok := getServiceId(r, w, logger)
+		if !ok {
+			return
+		}
+
+		// 2.Parse request
+		var req ServiceRequest
+		if ok := parseReq(r, w, logger, &req); !ok {
+			return
+		}
+
+		// 3.Validate request data
+		service, validateOk := validateServiceReq(r, w, &req, logger)
+		if !validateOk {
+			return
+		}
+
+		// 4.Update
+		err := updater.UpdateService(id, service)
+		if errors.Is(err, storage.ErrServiceNotFound) {
+			logger.Info("service not found", "id", id)
+
+			renderError(w, r, http.StatusNotFound, CodeServiceNotFound, "", "service not found")
+
+			return
+		}
+		if errors.Is(err, storage.ErrServiceExists) {
+			logger.Info("service name or alias already exists", "name", req.Name)
+
+			renderError(w, r, http.StatusConflict, CodeServiceExists, "", "service name or alias already exists")
+
+			return
+		}
+		if errors.Is(err, storage.ErrSubscriptionExists) {
+			logger.Info("renaming leads to duplicate subscriptions", "name", req.Name)
+
+			renderError(w, r, http.StatusConflict, CodeSubscriptionExists, "name", "subscription with such service name already exists")
+
+			return
+		}
+		if err != nil {
+			logger.Error("failed to update service", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to update service")
+
+			return
+		}
+
+		logger.Info("service updated", "id", id)
+
+		render.JSON(w, r, RespOK())
+	}
+}
+
+// NewDeleteServiceHandler godoc
+// @Summary Delete catalog service
+// @Description Delete catalog service which is not used by subscriptions
+// @Tags v1
+// @Produce json
+// @Param id path int true "Service ID"
+// @Success 200 {object} Response
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /services/{id} [delete]
+func NewDeleteServiceHandler(logger *slog.Logger, deleter ServiceDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.delete_service"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		// 1.Get service id from request
+		id, ok := getServiceId(r, w, logger)
+		if !ok {
+			return
+		}
+
+		// 2.Delete
+		err := deleter.DeleteService(id)
+		if errors.Is(err, storage.ErrServiceNotFound) {
+			logger.Info("service not found", "id", id)
+
+			renderError(w, r, http.StatusNotFound, CodeServiceNotFound, "", "service not found")
+
+			return
+		}
+		if errors.Is(err, storage.ErrServiceInUse) {
+			logger.Info("service is used by subscriptions", "id", id)
+
+			renderError(w, r, http.StatusConflict, CodeServiceInUse, "", "service is used by subscriptions")
+
+			return
+		}
+		if err != nil {
+			logger.Error("failed to delete service", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to delete service")
+
+			return
+		}
+
+		logger.Info("service deleted", "id", id)
+
+		render.JSON(w, r, RespOK())
+	}
+}
+
+func getServiceId(r *http.Request, w http.ResponseWriter, logger *slog.Logger) (int64, bool) {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		logger.Info("no service id in request")
+
+		renderError(w, r, http.StatusBadRequest, CodeMissingValue, "id", "no service id in request")
+
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		logger.Info("invalid service id format", "details", err)
+
+		renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "id", "invalid service id format")
+
+		return 0, false
+	}
+
+	return id, true
+}
+
+func validateServiceReq(r *http.Request, w http.ResponseWriter, req *ServiceRequest, logger *slog.Logger) (model.Service, bool) {
+	// 1.Name
+	if req.Name == "" {
+		logger.Error("request service name is empty")
+		renderError(w, r, http.StatusBadRequest, CodeMissingValue, "name", "empty service name")
+		return model.Service{}, false
+	}
+
+	nameKey := model.ServiceNameKey(req.Name)
+	if nameKey == "" {
+		logger.Error("request service name has no letters or digits")
+		renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "name", "request service name is invalid")
+		return model.Service{}, false
+	}
+
+	// 2.Aliases must differ from name and each other
+	keys := map[string]bool{nameKey: true}
+	for _, alias := range req.Aliases {
+		key := model.ServiceNameKey(alias)
+		if key == "" || keys[key] {
+			logger.Error("request service alias is empty or duplicated", "alias", alias)
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "aliases", "request service aliases are invalid")
+			return model.Service{}, false
+		}
+		keys[key] = true
+	}
+
+	// 3.Default price
+	if req.DefaultPrice < 0 {
+		logger.Error("request default price cannot be lower than 0")
+		renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "default_price", "request default price is invalid")
+		return model.Service{}, false
+	}
+
+	aliases := req.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+
+	return model.Service{
+		Name:         req.Name,
+		Aliases:      aliases,
+		DefaultPrice: req.DefaultPrice,
+		Category:     req.Category,
+	}, true
+}
+
+func makeServiceItem(service *model.Service) ServiceItem {
+	aliases := service.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+
+	return ServiceItem{
+		Id:           service.ID,
+		Name:         service.Name,
+		Aliases:      aliases,
+		DefaultPrice: service.DefaultPrice,
+		Category:     service.Category,
+	}
+}
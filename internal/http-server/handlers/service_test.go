@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"bytes"
+	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateServiceHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	cases := []struct {
+		name      string
+		body      string
+		respCode  int
+		respError string
+		mockError error
+	}{
+		{
+			name:     "Success",
+			body:     `{"name": "Yandex Plus", "aliases": ["YPlus", "Яндекс Плюс"], "default_price": "399.00", "category": "music"}`,
+			respCode: http.StatusCreated,
+		},
+		{
+			name:      "Empty name",
+			body:      `{"aliases": ["yandex-plus"]}`,
+			respCode:  http.StatusBadRequest,
+			respError: "empty service name",
+		},
+		{
+			name:      "Name without letters",
+			body:      `{"name": " - "}`,
+			respCode:  http.StatusBadRequest,
+			respError: "request service name is invalid",
+		},
+		{
+			name:      "Alias equals name",
+			body:      `{"name": "Yandex Plus", "aliases": ["YANDEX_PLUS"]}`,
+			respCode:  http.StatusBadRequest,
+			respError: "request service aliases are invalid",
+		},
+		{
+			name:      "Negative default price",
+			body:      `{"name": "Yandex Plus", "default_price": -1}`,
+			respCode:  http.StatusBadRequest,
+			respError: "request default price is invalid",
+		},
+		{
+			name:      "Already exists",
+			body:      `{"name": "Yandex Plus"}`,
+			respCode:  http.StatusConflict,
+			respError: "service name or alias already exists",
+			mockError: storage.ErrServiceExists,
+		},
+		{
+			name:      "Any other creator error case",
+			body:      `{"name": "Yandex Plus"}`,
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to create service",
+			mockError: errors.New("any error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			creatorMock := mocks.NewServiceCreator(t)
+
+			if tc.respError == "" || tc.mockError != nil {
+				creatorMock.On("CreateService", mock.AnythingOfType("model.Service")).Return(int64(1), tc.mockError)
+			}
+
+			router := chi.NewRouter()
+			router.Post("/services", NewCreateServiceHandler(logger, creatorMock))
+
+			serviceRespCheck(t, router, http.MethodPost, "/services", tc.body, tc.respCode, tc.respError)
+		})
+	}
+}
+
+func TestReadServiceHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	service := model.Service{ID: 2, Name: "Yandex Plus", Aliases: []string{"yandex-plus"}, DefaultPrice: 39900, Category: "music"}
+
+	readerMock := mocks.NewServiceReader(t)
+	readerMock.On("GetService", int64(2)).Return(service, nil)
+	readerMock.On("GetService", int64(532)).Return(model.Service{}, storage.ErrServiceNotFound)
+
+	router := chi.NewRouter()
+	router.Get("/services/{id}", NewReadServiceHandler(logger, readerMock))
+
+	// 1.Found service
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, "/services/2", nil)
+	assert.NoError(t, err)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp ServiceResponse
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, makeServiceItem(&service), resp.ServiceItem)
+
+	// 2.Not found and invalid id
+	serviceRespCheck(t, router, http.MethodGet, "/services/532", "", http.StatusNotFound, "service not found")
+	serviceRespCheck(t, router, http.MethodGet, "/services/trash", "", http.StatusBadRequest, "invalid service id format")
+}
+
+func TestListServicesHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	listMock := mocks.NewServiceListReader(t)
+	listMock.On("GetServices").Return([]model.Service{{ID: 1, Name: "Netflix"}, {ID: 2, Name: "Okko"}}, nil)
+
+	handler := NewListServicesHandler(logger, listMock)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, "/services", nil)
+	assert.NoError(t, err)
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp ServiceListResponse
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Len(t, resp.Items, 2)
+	assert.Equal(t, []string{}, resp.Items[0].Aliases)
+}
+
+func TestUpdateServiceHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	cases := []struct {
+		name      string
+		id        string
+		body      string
+		respCode  int
+		respError string
+		mockError error
+	}{
+		{
+			name:     "Success",
+			id:       "1",
+			body:     `{"name": "Netflix", "aliases": ["Нетфликс"]}`,
+			respCode: http.StatusOK,
+		},
+		{
+			name:      "Duplicated aliases",
+			id:        "1",
+			body:      `{"name": "Netflix", "aliases": ["Нетфликс", "нетфликс"]}`,
+			respCode:  http.StatusBadRequest,
+			respError: "request service aliases are invalid",
+		},
+		{
+			name:      "Not found",
+			id:        "532",
+			body:      `{"name": "Netflix"}`,
+			respCode:  http.StatusNotFound,
+			respError: "service not found",
+			mockError: storage.ErrServiceNotFound,
+		},
+		{
+			name:      "Alias of other service",
+			id:        "1",
+			body:      `{"name": "Netflix"}`,
+			respCode:  http.StatusConflict,
+			respError: "service name or alias already exists",
+			mockError: storage.ErrServiceExists,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			updaterMock := mocks.NewServiceUpdater(t)
+
+			if tc.respError == "" || tc.mockError != nil {
+				updaterMock.On("UpdateService", mock.AnythingOfType("int64"), mock.AnythingOfType("model.Service")).Return(tc.mockError)
+			}
+
+			router := chi.NewRouter()
+			router.Patch("/services/{id}", NewUpdateServiceHandler(logger, updaterMock))
+
+			serviceRespCheck(t, router, http.MethodPatch, "/services/"+tc.id, tc.body, tc.respCode, tc.respError)
+		})
+	}
+}
+
+func TestDeleteServiceHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	deleterMock := mocks.NewServiceDeleter(t)
+	deleterMock.On("DeleteService", int64(1)).Return(nil)
+	deleterMock.On("DeleteService", int64(2)).Return(storage.ErrServiceInUse)
+	deleterMock.On("DeleteService", int64(532)).Return(storage.ErrServiceNotFound)
+
+	router := chi.NewRouter()
+	router.Delete("/services/{id}", NewDeleteServiceHandler(logger, deleterMock))
+
+	serviceRespCheck(t, router, http.MethodDelete, "/services/1", "", http.StatusOK, "")
+	serviceRespCheck(t, router, http.MethodDelete, "/services/2", "", http.StatusConflict, "service is used by subscriptions")
+	serviceRespCheck(t, router, http.MethodDelete, "/services/532", "", http.StatusNotFound, "service not found")
+}
+
+// Helper for check
+func serviceRespCheck(t *testing.T, router *chi.Mux, method, url, body string, expCode int, expRespErr string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, bytes.NewReader([]byte(body)))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, expCode, rr.Code)
+
+	var resp Response
+	assert.Equal(t, expRespErr, decodeResp(t, rr, &resp))
+}
@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+const (
+	CancelImmediate   = "immediate"
+	CancelEndOfPeriod = "end_of_period"
+)
+
+// ChangeStatusRequest represents subscription pause/resume model
+// swagger:model ChangeStatusRequest
+// @ID ChangeStatusRequest
+type ChangeStatusRequest struct {
+	// Month since which new status applies, current month by default (optional)
+	EffectiveFrom string `json:"effective_from,omitempty"`
+}
+
+// CancelRequest represents subscription cancellation model
+// swagger:model CancelRequest
+// @ID CancelRequest
+type CancelRequest struct {
+	// Cancellation mode: "immediate" (default) stops billing since effective month,
+	// "end_of_period" keeps billing till subscription end date (optional)
+	Mode string `json:"mode,omitempty" enums:"immediate,end_of_period"`
+
+	// Month since which immediate cancellation applies (not billed), current month by default (optional)
+	EffectiveFrom string `json:"effective_from,omitempty"`
+}
+
+// ChangeStatusResponse represents subscription status after change
+// swagger:model ChangeStatusResponse
+// @ID ChangeStatusResponse
+type ChangeStatusResponse struct {
+	// Id of stored status transition
+	ID int64 `json:"id,omitempty"`
+
+	// New subscription status
+	SubscriptionStatus string `json:"subscription_status,omitempty"`
+
+	// Subscription end date after change
+	EndDate string `json:"end_date,omitempty"`
+
+	Response
+}
+
+// StatusTransitionItem represents one status change of subscription
+// swagger:model StatusTransitionItem
+// @ID StatusTransitionItem
+type StatusTransitionItem struct {
+	// Status transition id
+	Id int64 `json:"id"`
+
+	// Previous status
+	From string `json:"from"`
+
+	// New status
+	To string `json:"to"`
+
+	// Month since which new status applies
+	EffectiveFrom string `json:"effective_from"`
+
+	// Moment of change in RFC 3339 format
+	ChangedAt string `json:"changed_at"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=StatusChanger
+type StatusChanger interface {
+	GetSubscription(id int64) (model.Subscription, error)
+	ChangeStatus(id int64, transition model.StatusTransition, newEnd *model.Date) (int64, error)
+}
+
+// NewPauseHandler godoc
+// @Summary Pause subscription
+// @Description Pause active subscription since specified month, paused months are not billed
+// @Tags v1
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param request body ChangeStatusRequest false "Pause data"
+// @Param Idempotency-Key header string false "Key making retries safe (response of the first request is replayed)"
+// @Success 200 {object} ChangeStatusResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /subscription/{id}/pause [post]
+func NewPauseHandler(logger *slog.Logger, changer StatusChanger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.pause"
+
+		var req ChangeStatusRequest
+		changeStatus(logger.With(slog.String("op", op)), changer, w, r, &req, func(sub *model.Subscription, logger *slog.Logger) (model.Date, *model.Date, bool) {
+			effectiveFrom, ok := validateEffectiveFrom(r, w, req.EffectiveFrom, sub, logger)
+			return effectiveFrom, nil, ok
+		}, model.StatusPaused)
+	}
+}
+
+// NewResumeHandler godoc
+// @Summary Resume subscription
+// @Description Resume paused subscription since specified month
+// @Tags v1
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param request body ChangeStatusRequest false "Resume data"
+// @Param Idempotency-Key header string false "Key making retries safe (response of the first request is replayed)"
+// @Success 200 {object} ChangeStatusResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /subscription/{id}/resume [post]
+func NewResumeHandler(logger *slog.Logger, changer StatusChanger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.resume"
+
+		var req ChangeStatusRequest
+		changeStatus(logger.With(slog.String("op", op)), changer, w, r, &req, func(sub *model.Subscription, logger *slog.Logger) (model.Date, *model.Date, bool) {
+			effectiveFrom, ok := validateEffectiveFrom(r, w, req.EffectiveFrom, sub, logger)
+			return effectiveFrom, nil, ok
+		}, model.StatusActive)
+	}
+}
+
+// NewCancelHandler godoc
+// @Summary Cancel subscription
+// @Description Cancel active or paused subscription immediately (end date is moved to effective month) or at end of period
+// @Tags v1
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param request body CancelRequest false "Cancellation data"
+// @Param Idempotency-Key header string false "Key making retries safe (response of the first request is replayed)"
+// @Success 200 {object} ChangeStatusResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /subscription/{id}/cancel [post]
+func NewCancelHandler(logger *slog.Logger, changer StatusChanger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.cancel"
+
+		var req CancelRequest
+		changeStatus(logger.With(slog.String("op", op)), changer, w, r, &req, func(sub *model.Subscription, logger *slog.Logger) (model.Date, *model.Date, bool) {
+			switch req.Mode {
+			case CancelEndOfPeriod:
+				// Billing goes on till end date, so cancellation applies since it
+				return sub.EndDate, nil, true
+
+			case "", CancelImmediate:
+				effectiveFrom, ok := validateEffectiveFrom(r, w, req.EffectiveFrom, sub, logger)
+				if !ok {
+					return model.Date{}, nil, false
+				}
+
+				// End date must stay greater than start date
+				if !effectiveFrom.GreaterThan(sub.StartDate) {
+					logger.Error("immediate cancellation must apply after first month of subscription")
+					renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "effective_from", "request effective date is out of subscription period")
+					return model.Date{}, nil, false
+				}
+
+				return effectiveFrom, &effectiveFrom, true
+
+			default:
+				logger.Error("request cancellation mode is invalid", "mode", req.Mode)
+				renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "mode", "request cancellation mode is invalid")
+				return model.Date{}, nil, false
+			}
+		}, model.StatusCancelled)
+	}
+}
+
+// Common flow of status change: validate transition with request data got by validate func and store it
+func changeStatus[T any](
+	logger *slog.Logger,
+	changer StatusChanger,
+	w http.ResponseWriter,
+	r *http.Request,
+	req *T,
+	validate func(sub *model.Subscription, logger *slog.Logger) (model.Date, *model.Date, bool),
+	to model.Status,
+) {
+	logger = logger.With(slog.String("request_id", middleware.GetReqID(r.Context())))
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	// 1.Get subscription id from request
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		logger.Info("no subscription id in request")
+
+		renderError(w, r, http.StatusBadRequest, CodeMissingValue, "id", "no subscription id in request")
+
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		logger.Info("invalid subscription id format", "details", err)
+
+		renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "id", "invalid subscription id format")
+
+		return
+	}
+
+	// 2.Parse optional request body
+	if ok := parseOptReq(r, w, logger, req); !ok {
+		return
+	}
+
+	// 3.Get subscription for validation against its status and period
+	subscription, err := changer.GetSubscription(int64(id))
+	if errors.Is(err, storage.ErrSubscribtionNotFound) {
+		logger.Info("subscription not found", "id", id)
+
+		renderError(w, r, http.StatusNotFound, CodeSubscriptionNotFound, "", "subscription not found")
+
+		return
+	}
+	if err != nil {
+		logger.Error("failed to get subscription", "details", err)
+
+		renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to get subscription")
+
+		return
+	}
+
+	// 4.Validate transition and request data
+	if !subscription.Status.CanTransitionTo(to) {
+		logger.Info("status transition is not allowed", "from", subscription.Status, "to", to)
+
+		renderError(w, r, http.StatusConflict, CodeStatusTransitionInvalid, "", "subscription status transition is invalid")
+
+		return
+	}
+
+	effectiveFrom, newEnd, ok := validate(&subscription, logger)
+	if !ok {
+		return
+	}
+
+	// 5.Change status
+	transition := model.StatusTransition{From: subscription.Status, To: to, EffectiveFrom: effectiveFrom}
+
+	transitionId, err := changer.ChangeStatus(int64(id), transition, newEnd)
+	if errors.Is(err, storage.ErrSubscribtionNotFound) {
+		logger.Info("subscription not found", "id", id)
+
+		renderError(w, r, http.StatusNotFound, CodeSubscriptionNotFound, "", "subscription not found")
+
+		return
+	}
+	if errors.Is(err, storage.ErrStatusChanged) {
+		logger.Info("subscription status was changed concurrently", "id", id)
+
+		renderError(w, r, http.StatusConflict, CodeStatusConflict, "", "subscription status was changed concurrently")
+
+		return
+	}
+	if err != nil {
+		logger.Error("failed to change subscription status", "details", err)
+
+		renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to change subscription status")
+
+		return
+	}
+
+	logger.Info("subscription status changed", "id", id, "from", transition.From, "to", transition.To)
+
+	// 6.Prepare response and render it
+	endDate := subscription.EndDate
+	if newEnd != nil {
+		endDate = *newEnd
+	}
+
+	render.JSON(w, r, ChangeStatusResponse{
+		ID:                 transitionId,
+		SubscriptionStatus: string(to),
+		EndDate:            endDate.ToString(),
+		Response:           RespOK(),
+	})
+}
+
+// Validate optional effective month: it must be inside subscription period and not before last status change
+func validateEffectiveFrom(r *http.Request, w http.ResponseWriter, str string, sub *model.Subscription, logger *slog.Logger) (model.Date, bool) {
+	effectiveFrom := model.CurrentMonth()
+
+	if str != "" {
+		var err error
+
+		effectiveFrom, err = model.DateFromString(str)
+		if err != nil {
+			logger.Error("request effective date is invalid", "details", err)
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "effective_from", "request effective date is invalid")
+			return model.Date{}, false
+		}
+	}
+
+	if sub.StartDate.GreaterThan(effectiveFrom) || !sub.EndDate.GreaterThan(effectiveFrom) {
+		logger.Error("request effective date is out of subscription period")
+		renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "effective_from", "request effective date is out of subscription period")
+		return model.Date{}, false
+	}
+
+	lastChange := sub.LastStatusChange()
+	if lastChange.GreaterThan(effectiveFrom) {
+		logger.Error("request effective date is before last status change")
+		renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "effective_from", "request effective date is before last status change")
+		return model.Date{}, false
+	}
+
+	return effectiveFrom, true
+}
+
+func makeStatusTransitionItems(history []model.StatusTransition) []StatusTransitionItem {
+	items := []StatusTransitionItem{}
+
+	for i := 0; i < len(history); i++ {
+		items = append(items, StatusTransitionItem{
+			Id:            history[i].ID,
+			From:          string(history[i].From),
+			To:            string(history[i].To),
+			EffectiveFrom: history[i].EffectiveFrom.ToString(),
+			ChangedAt:     history[i].ChangedAt.Format(time.RFC3339),
+		})
+	}
+
+	return items
+}
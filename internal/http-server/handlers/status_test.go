@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"bytes"
+	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangeStatusHandlers(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	active := model.Subscription{
+		ID: 1,
+		SubscriptionSpec: model.SubscriptionSpec{
+			ServiceName: "Yandex",
+			Price:       40000,
+			StartDate:   model.Date{Month: 1, Year: 2020},
+			EndDate:     model.Date{Month: 1, Year: 2100},
+		},
+		Status: model.StatusActive,
+	}
+
+	paused := active
+	paused.Status = model.StatusPaused
+	paused.StatusHistory = []model.StatusTransition{
+		{ID: 1, From: model.StatusActive, To: model.StatusPaused, EffectiveFrom: model.Date{Month: 6, Year: 2026}},
+	}
+
+	cancelled := active
+	cancelled.Status = model.StatusCancelled
+
+	endDate := model.Date{Month: 9, Year: 2026}
+	currentMonth := model.CurrentMonth()
+
+	cases := []struct {
+		name             string
+		action           string
+		id               string
+		input            string
+		respCode         int
+		respError        string
+		respEndDate      string
+		getNeedCall      bool
+		getRet           model.Subscription
+		getError         error
+		changeNeedCall   bool
+		changeError      error
+		expectTransition model.StatusTransition
+		expectEnd        *model.Date
+	}{
+		{
+			name:             "Pause success",
+			action:           "pause",
+			id:               "1",
+			input:            `{"effective_from": "06-2026"}`,
+			respCode:         http.StatusOK,
+			respEndDate:      "01-2100",
+			getNeedCall:      true,
+			getRet:           active,
+			changeNeedCall:   true,
+			expectTransition: model.StatusTransition{From: model.StatusActive, To: model.StatusPaused, EffectiveFrom: model.Date{Month: 6, Year: 2026}},
+		},
+		{
+			name:             "Pause with empty body since current month",
+			action:           "pause",
+			id:               "1",
+			respCode:         http.StatusOK,
+			respEndDate:      "01-2100",
+			getNeedCall:      true,
+			getRet:           active,
+			changeNeedCall:   true,
+			expectTransition: model.StatusTransition{From: model.StatusActive, To: model.StatusPaused, EffectiveFrom: currentMonth},
+		},
+		{
+			name:        "Pause already paused",
+			action:      "pause",
+			id:          "1",
+			respCode:    http.StatusConflict,
+			respError:   "subscription status transition is invalid",
+			getNeedCall: true,
+			getRet:      paused,
+		},
+		{
+			name:             "Resume success",
+			action:           "resume",
+			id:               "1",
+			input:            `{"effective_from": "08-2026"}`,
+			respCode:         http.StatusOK,
+			respEndDate:      "01-2100",
+			getNeedCall:      true,
+			getRet:           paused,
+			changeNeedCall:   true,
+			expectTransition: model.StatusTransition{From: model.StatusPaused, To: model.StatusActive, EffectiveFrom: model.Date{Month: 8, Year: 2026}},
+		},
+		{
+			name:        "Resume before pause",
+			action:      "resume",
+			id:          "1",
+			input:       `{"effective_from": "05-2026"}`,
+			respCode:    http.StatusBadRequest,
+			respError:   "request effective date is before last status change",
+			getNeedCall: true,
+			getRet:      paused,
+		},
+		{
+			name:        "Resume active",
+			action:      "resume",
+			id:          "1",
+			respCode:    http.StatusConflict,
+			respError:   "subscription status transition is invalid",
+			getNeedCall: true,
+			getRet:      active,
+		},
+		{
+			name:             "Cancel immediately",
+			action:           "cancel",
+			id:               "1",
+			input:            `{"mode": "immediate", "effective_from": "09-2026"}`,
+			respCode:         http.StatusOK,
+			respEndDate:      "09-2026",
+			getNeedCall:      true,
+			getRet:           paused,
+			changeNeedCall:   true,
+			expectTransition: model.StatusTransition{From: model.StatusPaused, To: model.StatusCancelled, EffectiveFrom: endDate},
+			expectEnd:        &endDate,
+		},
+		{
+			name:             "Cancel at end of period",
+			action:           "cancel",
+			id:               "1",
+			input:            `{"mode": "end_of_period"}`,
+			respCode:         http.StatusOK,
+			respEndDate:      "01-2100",
+			getNeedCall:      true,
+			getRet:           active,
+			changeNeedCall:   true,
+			expectTransition: model.StatusTransition{From: model.StatusActive, To: model.StatusCancelled, EffectiveFrom: active.EndDate},
+		},
+		{
+			name:        "Cancel in first month",
+			action:      "cancel",
+			id:          "1",
+			input:       `{"effective_from": "01-2020"}`,
+			respCode:    http.StatusBadRequest,
+			respError:   "request effective date is out of subscription period",
+			getNeedCall: true,
+			getRet:      active,
+		},
+		{
+			name:        "Cancel with invalid mode",
+			action:      "cancel",
+			id:          "1",
+			input:       `{"mode": "trash"}`,
+			respCode:    http.StatusBadRequest,
+			respError:   "request cancellation mode is invalid",
+			getNeedCall: true,
+			getRet:      active,
+		},
+		{
+			name:        "Cancel cancelled",
+			action:      "cancel",
+			id:          "1",
+			respCode:    http.StatusConflict,
+			respError:   "subscription status transition is invalid",
+			getNeedCall: true,
+			getRet:      cancelled,
+		},
+		{
+			name:      "Invalid id",
+			action:    "pause",
+			id:        "trash",
+			respCode:  http.StatusBadRequest,
+			respError: "invalid subscription id format",
+		},
+		{
+			name:      "Invalid body",
+			action:    "pause",
+			id:        "1",
+			input:     `{"effective_from": 6}`,
+			respCode:  http.StatusBadRequest,
+			respError: "failed to decode request",
+		},
+		{
+			name:        "Invalid effective date",
+			action:      "pause",
+			id:          "1",
+			input:       `{"effective_from": "trash"}`,
+			respCode:    http.StatusBadRequest,
+			respError:   "request effective date is invalid",
+			getNeedCall: true,
+			getRet:      active,
+		},
+		{
+			name:        "Effective date out of period",
+			action:      "pause",
+			id:          "1",
+			input:       `{"effective_from": "01-2100"}`,
+			respCode:    http.StatusBadRequest,
+			respError:   "request effective date is out of subscription period",
+			getNeedCall: true,
+			getRet:      active,
+		},
+		{
+			name:        "Subscription not found",
+			action:      "pause",
+			id:          "532",
+			respCode:    http.StatusNotFound,
+			respError:   "subscription not found",
+			getNeedCall: true,
+			getError:    storage.ErrSubscribtionNotFound,
+		},
+		{
+			name:             "Status changed concurrently",
+			action:           "pause",
+			id:               "1",
+			input:            `{"effective_from": "06-2026"}`,
+			respCode:         http.StatusConflict,
+			respError:        "subscription status was changed concurrently",
+			getNeedCall:      true,
+			getRet:           active,
+			changeNeedCall:   true,
+			changeError:      storage.ErrStatusChanged,
+			expectTransition: model.StatusTransition{From: model.StatusActive, To: model.StatusPaused, EffectiveFrom: model.Date{Month: 6, Year: 2026}},
+		},
+		{
+			name:             "Storage error",
+			action:           "pause",
+			id:               "1",
+			input:            `{"effective_from": "06-2026"}`,
+			respCode:         http.StatusInternalServerError,
+			respError:        "failed to change subscription status",
+			getNeedCall:      true,
+			getRet:           active,
+			changeNeedCall:   true,
+			changeError:      errors.New("any error"),
+			expectTransition: model.StatusTransition{From: model.StatusActive, To: model.StatusPaused, EffectiveFrom: model.Date{Month: 6, Year: 2026}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			changerMock := mocks.NewStatusChanger(t)
+
+			if tc.getNeedCall {
+				id, err := strconv.Atoi(tc.id)
+				assert.NoError(t, err)
+
+				changerMock.On("GetSubscription", int64(id)).Return(tc.getRet, tc.getError)
+			}
+			if tc.changeNeedCall {
+				changerMock.On("ChangeStatus", tc.getRet.ID, tc.expectTransition, tc.expectEnd).Return(int64(7), tc.changeError)
+			}
+
+			router := chi.NewRouter()
+			router.Post("/subscription/{id}/pause", NewPauseHandler(logger, changerMock))
+			router.Post("/subscription/{id}/resume", NewResumeHandler(logger, changerMock))
+			router.Post("/subscription/{id}/cancel", NewCancelHandler(logger, changerMock))
+
+			req, err := http.NewRequest(http.MethodPost, "/subscription/"+tc.id+"/"+tc.action, bytes.NewReader([]byte(tc.input)))
+			assert.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.respCode, rr.Code)
+
+			var resp ChangeStatusResponse
+			assert.Equal(t, tc.respError, decodeResp(t, rr, &resp))
+			if tc.respError == "" {
+				assert.Equal(t, StatusOK, resp.Status)
+				assert.Equal(t, int64(7), resp.ID)
+				assert.Equal(t, string(tc.expectTransition.To), resp.SubscriptionStatus)
+				assert.Equal(t, tc.respEndDate, resp.EndDate)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// TagsRequest represents new category and tags of subscription
+// swagger:model TagsRequest
+// @ID TagsRequest
+type TagsRequest struct {
+	// Spending category, case insensitive (empty removes category)
+	Category string `json:"category"`
+
+	// All tags of subscription, case insensitive (empty list removes tags)
+	Tags []string `json:"tags"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=TagSetter
+type TagSetter interface {
+	SetTags(id int64, category string, tags []string) error
+}
+
+// NewSetTagsHandler godoc
+// @Summary Set category and tags of subscription
+// @Description Replace spending category and free-form tags of subscription
+// @Tags v1
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param request body TagsRequest true "Category and tags"
+// @Success 200 {object} Response
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /subscription/{id}/tags [put]
+func NewSetTagsHandler(logger *slog.Logger, setter TagSetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.set_tags"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		// 1.Get subscription id from request
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			logger.Info("invalid subscription id format", "details", err)
+
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "id", "invalid subscription id format")
+
+			return
+		}
+
+		// 2.Parse and validate request
+		var req TagsRequest
+		if ok := parseReq(r, w, logger, &req); !ok {
+			return
+		}
+
+		category, tags, err := parseTags(req.Category, req.Tags)
+		if err != nil {
+			logger.Info("request category or tags are invalid", "details", err)
+
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, tagsField(req.Category), "request category or tags are invalid")
+
+			return
+		}
+
+		// 3.Replace category and tags
+		err = setter.SetTags(id, category, tags)
+		if errors.Is(err, storage.ErrSubscribtionNotFound) {
+			logger.Info("subscription not found", "id", id)
+
+			renderError(w, r, http.StatusNotFound, CodeSubscriptionNotFound, "", "subscription not found")
+
+			return
+		}
+		if err != nil {
+			logger.Error("failed to set tags", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to set tags")
+
+			return
+		}
+
+		logger.Info("subscription tags set", "id", id, "category", category, "count", len(tags))
+
+		render.JSON(w, r, RespOK())
+	}
+}
+
+// Normalize category and tags from request
+func parseTags(category string, tags []string) (string, []string, error) {
+	category, err := model.NormalizeLabel(category)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tags, err = model.NormalizeTags(tags)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return category, tags, nil
+}
+
+// Field failed by parseTags
+func tagsField(category string) string {
+	if _, err := model.NormalizeLabel(category); err != nil {
+		return "category"
+	}
+	return "tags"
+}
+
+func makeTagItems(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+
+	return tags
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"bytes"
+	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetTagsHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	validBody := `{"category": " Dev-Tools", "tags": ["Work", "ci", "work"]}`
+
+	cases := []struct {
+		name         string
+		id           string
+		body         string
+		respCode     int
+		respError    string
+		mockCategory string
+		mockTags     []string
+		mockError    error
+	}{
+		{
+			name:         "Success",
+			id:           "1",
+			body:         validBody,
+			respCode:     http.StatusOK,
+			mockCategory: "dev-tools",
+			mockTags:     []string{"work", "ci"},
+		},
+		{
+			name:     "Success clear",
+			id:       "1",
+			body:     `{"category": "", "tags": []}`,
+			respCode: http.StatusOK,
+		},
+		{
+			name:      "Invalid id",
+			id:        "trash",
+			body:      validBody,
+			respCode:  http.StatusBadRequest,
+			respError: "invalid subscription id format",
+		},
+		{
+			name:      "Empty tag",
+			id:        "1",
+			body:      `{"tags": ["work", ""]}`,
+			respCode:  http.StatusBadRequest,
+			respError: "request category or tags are invalid",
+		},
+		{
+			name:      "Too long category",
+			id:        "1",
+			body:      `{"category": "` + strings.Repeat("c", model.MaxLabelLength+1) + `"}`,
+			respCode:  http.StatusBadRequest,
+			respError: "request category or tags are invalid",
+		},
+		{
+			name:         "Not found subscription",
+			id:           "1",
+			body:         validBody,
+			respCode:     http.StatusNotFound,
+			respError:    "subscription not found",
+			mockCategory: "dev-tools",
+			mockTags:     []string{"work", "ci"},
+			mockError:    storage.ErrSubscribtionNotFound,
+		},
+		{
+			name:         "Any other setter error case",
+			id:           "1",
+			body:         validBody,
+			respCode:     http.StatusInternalServerError,
+			respError:    "failed to set tags",
+			mockCategory: "dev-tools",
+			mockTags:     []string{"work", "ci"},
+			mockError:    errors.New("any error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			setterMock := mocks.NewTagSetter(t)
+
+			if tc.respError == "" || tc.mockError != nil {
+				setterMock.On("SetTags", int64(1), tc.mockCategory, tc.mockTags).Return(tc.mockError)
+			}
+
+			router := chi.NewRouter()
+			router.Put("/subscription/{id}/tags", NewSetTagsHandler(logger, setterMock))
+
+			req, err := http.NewRequest(http.MethodPut, "/subscription/"+tc.id+"/tags", bytes.NewReader([]byte(tc.body)))
+			assert.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.respCode, rr.Code)
+
+			var resp Response
+			assert.Equal(t, tc.respError, decodeResp(t, rr, &resp))
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"em_golang_rest_service_example/internal/model"
 	"log/slog"
 	"net/http"
+	"sort"
+	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
@@ -25,32 +27,90 @@ type TotalCostRequest struct {
 
 	// Subscription service name (optional)
 	ServiceName string `json:"service_name,omitempty"`
+
+	// Subscription status: active, paused, cancelled or expired (optional)
+	Status string `json:"status,omitempty"`
+
+	// Subscription category (optional)
+	Category string `json:"category,omitempty"`
+
+	// Subscription tag (optional)
+	Tag string `json:"tag,omitempty"`
+
+	// Filter expression, e.g. price>=300 and service_name~"yan*" and active_in(2025-01,2025-06); amounts are integers
+	// in minor units or quoted decimals (price>="3.00") (optional)
+	Filter string `json:"filter,omitempty"`
+
+	// Report cost by category or tag (optional)
+	GroupBy string `json:"group_by,omitempty"`
+
+	// Count only charges posted before the moment in RFC 3339 format to get report as it was then (optional)
+	AsOf string `json:"as_of,omitempty"`
 }
 
 // TotalCostResponse contains calculated total cost
 // swagger:model TotalCostResponse
 // @ID TotalCostResponse
 type TotalCostResponse struct {
-	// Calculated total cost
-	TotalCost int `json:"total_cost"`
+	// Calculated total cost (decimal string) summed over charges ledger; shared subscriptions are counted once,
+	// if user filter is set only user share of them is counted
+	TotalCost model.Money `json:"total_cost" swaggertype:"string" example:"99.90"`
+
+	// Cost attributed to every user (owners and members of shared subscriptions)
+	Breakdown []UserCostItem `json:"breakdown"`
+
+	// Cost of every category or tag (ordered by key, omitted if grouping is not requested);
+	// subscription with several tags is counted in every tag group
+	Groups []CostGroupItem `json:"groups,omitempty"`
 
 	Response
 }
 
+// CostGroupItem represents cost of subscriptions with the same category or tag
+// swagger:model CostGroupItem
+// @ID CostGroupItem
+type CostGroupItem struct {
+	// Category or tag (empty for subscriptions without category or tags)
+	Key string `json:"key"`
+
+	// Cost of group subscriptions (decimal string)
+	Cost model.Money `json:"cost" swaggertype:"string" example:"19.98"`
+}
+
+// Values of group_by parameter
+const (
+	groupByCategory = "category"
+	groupByTag      = "tag"
+)
+
+// UserCostItem represents cost attributed to user
+// swagger:model UserCostItem
+// @ID UserCostItem
+type UserCostItem struct {
+	// Id of user
+	UserID string `json:"user_id"`
+
+	// User share of total cost (decimal string)
+	Cost model.Money `json:"cost" swaggertype:"string" example:"49.95"`
+}
+
 //go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=FilteredDataReader
 type FilteredDataReader interface {
-	FilterSubscriptions(startDate, endDate model.Date, userId uuid.UUID, serviceName *string) ([]model.Subscription, error)
+	FilterSubscriptions(startDate, endDate model.Date, userId uuid.UUID, serviceName *string, filter model.SubscriptionFilter) ([]model.Subscription, error)
+	GetCharges(ids []int64, asOf *time.Time) (map[int64][]model.Charge, error)
 }
 
 // NewTotalCostHandler godoc
 // @Summary Calculate total cost with specified filters
-// @Description Calculate total cost with specified filters
+// @Description Calculate total cost with specified filters as sum of ledger charges (paused months are not billed, shared subscriptions are split between members)
+// @Tags v1
 // @Accept json
 // @Produce json
 // @Param request body TotalCostRequest true "filters data"
 // @Success 200 {object} TotalCostResponse
-// @Failure 400 {object} TotalCostResponse
-// @Failure 500 {object} TotalCostResponse
+// @Failure 400 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /subscriptions/total-cost [get]
 func NewTotalCostHandler(logger *slog.Logger, dataReader FilteredDataReader) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -69,106 +129,253 @@ func NewTotalCostHandler(logger *slog.Logger, dataReader FilteredDataReader) htt
 			return
 		}
 
-		// 3.Get filtered subscriptions
+		filter, err := getSubscriptionFilter(r)
+		if err != nil {
+			logger.Error("invalid filter", "details", err)
+
+			renderFieldError(w, r, err)
+
+			return
+		}
+
+		// 2.Get grouping and report moment
+		groupBy := r.URL.Query().Get("group_by")
+		if groupBy != "" && groupBy != groupByCategory && groupBy != groupByTag {
+			logger.Error("group by is invalid", "group_by", groupBy)
+
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "group_by", "group by is invalid")
+
+			return
+		}
+
+		var asOf *time.Time
+		if asOfStr := r.URL.Query().Get("as_of"); asOfStr != "" {
+			moment, err := time.Parse(time.RFC3339, asOfStr)
+			if err != nil {
+				logger.Error("as of is invalid", "details", err)
+
+				renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "as_of", "as of is invalid")
+
+				return
+			}
+			asOf = &moment
+		}
+
+		// 3.Get filtered subscriptions and their charges
 		var sNamePtr *string
 		if serviceName != "" {
 			sNamePtr = &serviceName
 		}
 
-		subscriptions, err := dataReader.FilterSubscriptions(start, end, uid, sNamePtr)
+		subscriptions, err := dataReader.FilterSubscriptions(start, end, uid, sNamePtr, filter)
 		if err != nil {
 			logger.Error("failed to get subscription", "details", err)
 
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, TotalCostResponse{Response: RespError("failed to get subscription")})
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to get subscription")
+
+			return
+		}
+
+		ids := make([]int64, 0, len(subscriptions))
+		for i := 0; i < len(subscriptions); i++ {
+			ids = append(ids, subscriptions[i].ID)
+		}
+
+		charges, err := dataReader.GetCharges(ids, asOf)
+		if err != nil {
+			logger.Error("failed to get charges", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to get charges")
 
 			return
 		}
 
 		// 4.Calculate
-		totalCost := calculateTotalCostFiltered(subscriptions)
+		totalCost, err := calculateTotalCostFiltered(subscriptions, charges)
+		if err != nil {
+			logger.Error("failed to calculate total cost", "details", err)
+
+			renderError(w, r, http.StatusUnprocessableEntity, CodeTotalTooLarge, "", "total cost is too large")
+
+			return
+		}
+
+		breakdown, err := calculateCostBreakdown(subscriptions, charges)
+		if err != nil {
+			logger.Error("failed to calculate cost breakdown", "details", err)
+
+			renderError(w, r, http.StatusUnprocessableEntity, CodeTotalTooLarge, "", "total cost is too large")
+
+			return
+		}
+
+		var groups []CostGroupItem
+		if groupBy != "" {
+			groups, err = calculateCostGroups(subscriptions, charges, groupBy, uid)
+			if err != nil {
+				logger.Error("failed to calculate cost groups", "details", err)
+
+				renderError(w, r, http.StatusUnprocessableEntity, CodeTotalTooLarge, "", "total cost is too large")
+
+				return
+			}
+		}
 
 		logger.Info("got filtered subscriptions total cost", "value", totalCost)
 
 		// 5.Prepare response and render it
 		resp := TotalCostResponse{
 			TotalCost: totalCost,
+			Breakdown: []UserCostItem{},
+			Groups:    groups,
 			Response:  RespOK(),
 		}
+
+		for _, item := range breakdown {
+			if uid != uuid.Nil && item.UserID != uid {
+				continue
+			}
+			resp.Breakdown = append(resp.Breakdown, UserCostItem{UserID: item.UserID.String(), Cost: item.Amount})
+		}
+
+		// Only user share of shared subscriptions is user total cost
+		if uid != uuid.Nil {
+			resp.TotalCost = 0
+			for _, item := range resp.Breakdown {
+				resp.TotalCost, err = resp.TotalCost.Add(item.Cost)
+				if err != nil {
+					logger.Error("failed to calculate user total cost", "details", err)
+
+					renderError(w, r, http.StatusUnprocessableEntity, CodeTotalTooLarge, "", "total cost is too large")
+
+					return
+				}
+			}
+		}
 		render.JSON(w, r, resp)
 	}
 }
 
 func getValidatedReqData(r *http.Request, w http.ResponseWriter, logger *slog.Logger) (model.Date, model.Date, uuid.UUID, string, bool) {
+	var v model.Validator
+	query := r.URL.Query()
+
 	// 1.Dates
-	startDateStr := r.URL.Query().Get("start_date")
-	if startDateStr == "" {
-		logger.Error("request start date is empty")
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, TotalCostResponse{Response: RespError("empty start date")})
-		return model.Date{}, model.Date{}, uuid.Nil, "", false
+	var startDate, endDate model.Date
+	startOk := v.Required("start_date", query.Get("start_date"), "empty start date")
+	if startOk {
+		startDate, startOk = v.Date("start_date", query.Get("start_date"), "request start date is invalid")
 	}
 
-	startDate, err := model.DateFromString(startDateStr)
-	if err != nil {
-		logger.Error("request start date is invalid", "details", err)
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, TotalCostResponse{Response: RespError("request start date is invalid")})
-		return model.Date{}, model.Date{}, uuid.Nil, "", false
+	endOk := v.Required("end_date", query.Get("end_date"), "empty end date")
+	if endOk {
+		endDate, endOk = v.Date("end_date", query.Get("end_date"), "request end date is invalid")
 	}
 
-	endDateStr := r.URL.Query().Get("end_date")
-	if endDateStr == "" {
-		logger.Error("request end date is empty")
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, TotalCostResponse{Response: RespError("empty end date")})
-		return model.Date{}, model.Date{}, uuid.Nil, "", false
+	if startOk && endOk {
+		v.DateOrder("end_date", startDate, endDate, "request start date greater than end date")
 	}
 
-	endDate, err := model.DateFromString(endDateStr)
-	if err != nil {
-		logger.Error("request end date is invalid", "details", err)
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, TotalCostResponse{Response: RespError("request end date is invalid")})
-		return model.Date{}, model.Date{}, uuid.Nil, "", false
+	// 2.User ID if have
+	userId := uuid.Nil
+	if userIdStr := query.Get("user_id"); userIdStr != "" {
+		userId, _ = v.UUID("user_id", userIdStr, "user id filter is invalid")
 	}
 
-	if startDate.GreaterThan(endDate) {
-		logger.Error("request start date greater than end date")
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, TotalCostResponse{Response: RespError("request start date greater than end date")})
+	if err := v.Err(); err != nil {
+		logger.Error(err.Error(), "details", validationDetails(err))
+		renderFieldError(w, r, err)
 		return model.Date{}, model.Date{}, uuid.Nil, "", false
 	}
 
-	// 2.User ID if have
-	userId := uuid.Nil
+	// 3.Service name if have
+	serviceName := query.Get("service_name")
+
+	return startDate, endDate, userId, serviceName, true
+}
+
+// Sum ledger charges of subscriptions in integer minor units (returns model.ErrMoneyOverflow if too large)
+func calculateTotalCostFiltered(subs []model.Subscription, charges map[int64][]model.Charge) (model.Money, error) {
+	var cost model.Money
+
+	for i := 0; i < len(subs); i++ {
+		subCost, err := model.SumCharges(charges[subs[i].ID], uuid.Nil)
+		if err != nil {
+			return 0, err
+		}
 
-	userIdStr := r.URL.Query().Get("user_id")
-	if userIdStr != "" {
-		userId, err = uuid.Parse(userIdStr)
+		cost, err = cost.Add(subCost)
 		if err != nil {
-			logger.Error("user id filter is invalid", "details", err)
+			return 0, err
+		}
+	}
+
+	return cost, nil
+}
+
+// Sum ledger charges of subscriptions billed to every user (ordered by user id)
+func calculateCostBreakdown(subs []model.Subscription, charges map[int64][]model.Charge) ([]model.Share, error) {
+	costs := make(map[uuid.UUID]model.Money)
 
-			w.WriteHeader(http.StatusBadRequest)
-			render.JSON(w, r, TotalCostResponse{Response: RespError("user id filter is invalid")})
+	for i := 0; i < len(subs); i++ {
+		for _, charge := range charges[subs[i].ID] {
+			var err error
 
-			return model.Date{}, model.Date{}, uuid.Nil, "", false
+			costs[charge.UserID], err = costs[charge.UserID].Add(charge.Amount)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	// 3.Service name if have
-	serviceName := r.URL.Query().Get("service_name")
+	breakdown := make([]model.Share, 0, len(costs))
+	for userId, cost := range costs {
+		// Users whose charges were reversed completely are not billed
+		if cost == 0 {
+			continue
+		}
+		breakdown = append(breakdown, model.Share{UserID: userId, Amount: cost})
+	}
+	sort.Slice(breakdown, func(i, j int) bool {
+		return breakdown[i].UserID.String() < breakdown[j].UserID.String()
+	})
 
-	return startDate, endDate, userId, serviceName, true
+	return breakdown, nil
 }
 
-func calculateTotalCostFiltered(subs []model.Subscription) int {
-	cost := 0
+// Calculate cost of every category or tag (ordered by key); only charges billed to user are counted if user is set
+func calculateCostGroups(subs []model.Subscription, charges map[int64][]model.Charge, groupBy string, userId uuid.UUID) ([]CostGroupItem, error) {
+	costs := make(map[string]model.Money)
 
 	for i := 0; i < len(subs); i++ {
-		monthDiff := model.MonthsBetween(subs[i].StartDate, subs[i].EndDate)
-		cost += subs[i].Price * monthDiff
+		subCost, err := model.SumCharges(charges[subs[i].ID], userId)
+		if err != nil {
+			return nil, err
+		}
+
+		keys := []string{subs[i].Category}
+		if groupBy == groupByTag {
+			keys = subs[i].Tags
+			if len(keys) == 0 {
+				keys = []string{""}
+			}
+		}
+
+		for _, key := range keys {
+			costs[key], err = costs[key].Add(subCost)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	groups := make([]CostGroupItem, 0, len(costs))
+	for key, cost := range costs {
+		groups = append(groups, CostGroupItem{Key: key, Cost: cost})
 	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Key < groups[j].Key
+	})
 
-	return cost
+	return groups, nil
 }
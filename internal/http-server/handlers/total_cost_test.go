@@ -4,15 +4,16 @@ import (
 	"bytes"
 	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
 	"em_golang_rest_service_example/internal/model"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -74,20 +75,120 @@ var (
 			EndDate:     model.Date{Month: 8, Year: 2026},
 		},
 	}
+
+	subScheduled = model.Subscription{
+		ID: int64(7),
+		SubscriptionSpec: model.SubscriptionSpec{
+			ServiceName: "Kinopoisk",
+			Price:       300,
+			UserID:      uuid.New(),
+			StartDate:   model.Date{Month: 1, Year: 2026},
+			EndDate:     model.Date{Month: 5, Year: 2026},
+		},
+		PriceSchedule: []model.PriceChange{
+			{ID: 1, Price: 400, EffectiveFrom: model.Date{Month: 3, Year: 2026}},
+		},
+	}
+
+	subTrial = model.Subscription{
+		ID: int64(8),
+		SubscriptionSpec: model.SubscriptionSpec{
+			ServiceName: "Okko",
+			Price:       400,
+			UserID:      uuid.New(),
+			StartDate:   model.Date{Month: 1, Year: 2026},
+			EndDate:     model.Date{Month: 7, Year: 2026},
+			TrialMonths: 3,
+		},
+		Discounts: []model.Discount{
+			{ID: 1, Kind: model.DiscountFixed, Amount: 100},
+		},
+	}
+
+	subPaused = model.Subscription{
+		ID: int64(9),
+		SubscriptionSpec: model.SubscriptionSpec{
+			ServiceName: "Ivi",
+			Price:       500,
+			UserID:      uuid.New(),
+			StartDate:   model.Date{Month: 1, Year: 2026},
+			EndDate:     model.Date{Month: 7, Year: 2026},
+		},
+		Status: model.StatusActive,
+		StatusHistory: []model.StatusTransition{
+			{ID: 1, From: model.StatusActive, To: model.StatusPaused, EffectiveFrom: model.Date{Month: 3, Year: 2026}},
+			{ID: 2, From: model.StatusPaused, To: model.StatusActive, EffectiveFrom: model.Date{Month: 5, Year: 2026}},
+		},
+	}
+
+	sharedMemberId = uuid.New()
+
+	sharedOwnerId = uuid.New()
+
+	subShared = model.Subscription{
+		ID: int64(10),
+		SubscriptionSpec: model.SubscriptionSpec{
+			ServiceName: "YouTube Family",
+			Price:       1000,
+			UserID:      sharedOwnerId,
+			StartDate:   model.Date{Month: 1, Year: 2026},
+			EndDate:     model.Date{Month: 2, Year: 2026},
+			Members:     []model.Member{{UserID: sharedOwnerId, Weight: 3}, {UserID: sharedMemberId, Weight: 1}},
+		},
+	}
+
+	subStreaming = model.Subscription{
+		ID: int64(11),
+		SubscriptionSpec: model.SubscriptionSpec{
+			ServiceName: "Kion",
+			Price:       200,
+			UserID:      uuid.New(),
+			StartDate:   model.Date{Month: 1, Year: 2026},
+			EndDate:     model.Date{Month: 2, Year: 2026},
+			Category:    "streaming",
+			Tags:        []string{"family", "work"},
+		},
+	}
+
+	subCloud = model.Subscription{
+		ID: int64(12),
+		SubscriptionSpec: model.SubscriptionSpec{
+			ServiceName: "Yandex Cloud",
+			Price:       700,
+			UserID:      uuid.New(),
+			StartDate:   model.Date{Month: 1, Year: 2026},
+			EndDate:     model.Date{Month: 2, Year: 2026},
+			Category:    "cloud",
+			Tags:        []string{"work"},
+		},
+	}
+
+	subHuge = model.Subscription{
+		ID: int64(6),
+		SubscriptionSpec: model.SubscriptionSpec{
+			ServiceName: "Huge",
+			Price:       model.Money(math.MaxInt64/2 + 1),
+			UserID:      uuid.New(),
+			StartDate:   model.Date{Month: 1, Year: 2026},
+			EndDate:     model.Date{Month: 2, Year: 2026},
+		},
+	}
 )
 
 func TestTotalCostHandler(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
 
 	cases := []struct {
-		name         string
-		url          string
-		expectedCost int
-		respCode     int
-		respError    string
-		mockNeedCall bool
-		mockRet      []model.Subscription
-		mockError    error
+		name           string
+		url            string
+		expectedCost   model.Money
+		expectedGroups []CostGroupItem
+		respCode       int
+		respError      string
+		mockNeedCall   bool
+		mockRet        []model.Subscription
+		mockError      error
+		chargesError   error
 	}{
 		{
 			name:         "Success no optional params",
@@ -121,6 +222,160 @@ func TestTotalCostHandler(t *testing.T) {
 			mockNeedCall: true,
 			mockRet:      []model.Subscription{sub4},
 		},
+		{
+			name:         "Success with price schedule",
+			url:          "/subscriptions/total-cost?start_date=12-2025&end_date=08-2026",
+			expectedCost: 300*2 + 400*2,
+			respCode:     http.StatusOK,
+			mockNeedCall: true,
+			mockRet:      []model.Subscription{subScheduled},
+		},
+		{
+			name:         "Success with trial and discount",
+			url:          "/subscriptions/total-cost?start_date=12-2025&end_date=08-2026",
+			expectedCost: 300 * 3,
+			respCode:     http.StatusOK,
+			mockNeedCall: true,
+			mockRet:      []model.Subscription{subTrial},
+		},
+		{
+			name:         "Success with paused months",
+			url:          "/subscriptions/total-cost?start_date=12-2025&end_date=08-2026",
+			expectedCost: 500 * 4,
+			respCode:     http.StatusOK,
+			mockNeedCall: true,
+			mockRet:      []model.Subscription{subPaused},
+		},
+		{
+			name:         "Success with status opt param",
+			url:          "/subscriptions/total-cost?start_date=12-2025&end_date=08-2026&status=active",
+			expectedCost: 500 * 4,
+			respCode:     http.StatusOK,
+			mockNeedCall: true,
+			mockRet:      []model.Subscription{subPaused},
+		},
+		{
+			name:         "Shared subscription is counted once",
+			url:          "/subscriptions/total-cost?start_date=12-2025&end_date=08-2026",
+			expectedCost: 1000 + sub1.Price,
+			respCode:     http.StatusOK,
+			mockNeedCall: true,
+			mockRet:      []model.Subscription{subShared, sub1},
+		},
+		{
+			name:         "Member pays split share",
+			url:          fmt.Sprintf("/subscriptions/total-cost?start_date=12-2025&end_date=08-2026&user_id=%s", sharedMemberId.String()),
+			expectedCost: 250,
+			respCode:     http.StatusOK,
+			mockNeedCall: true,
+			mockRet:      []model.Subscription{subShared},
+		},
+		{
+			name:         "Owner pays split share",
+			url:          fmt.Sprintf("/subscriptions/total-cost?start_date=12-2025&end_date=08-2026&user_id=%s", subShared.UserID.String()),
+			expectedCost: 750,
+			respCode:     http.StatusOK,
+			mockNeedCall: true,
+			mockRet:      []model.Subscription{subShared},
+		},
+		{
+			name:         "Success with category and tag filters",
+			url:          "/subscriptions/total-cost?start_date=12-2025&end_date=08-2026&category=Streaming&tag=WORK",
+			expectedCost: 200,
+			respCode:     http.StatusOK,
+			mockNeedCall: true,
+			mockRet:      []model.Subscription{subStreaming},
+		},
+		{
+			name:         "Success with filter expression",
+			url:          "/subscriptions/total-cost?start_date=12-2025&end_date=08-2026&filter=" + url.QueryEscape(`category = streaming or tag ~ "wo*"`),
+			expectedCost: 200,
+			respCode:     http.StatusOK,
+			mockNeedCall: true,
+			mockRet:      []model.Subscription{subStreaming},
+		},
+		{
+			name:           "Cost grouped by category",
+			url:            "/subscriptions/total-cost?start_date=12-2025&end_date=08-2026&group_by=category",
+			expectedCost:   200 + 700 + sub1.Price,
+			expectedGroups: []CostGroupItem{{Key: "", Cost: sub1.Price}, {Key: "cloud", Cost: 700}, {Key: "streaming", Cost: 200}},
+			respCode:       http.StatusOK,
+			mockNeedCall:   true,
+			mockRet:        []model.Subscription{subStreaming, subCloud, sub1},
+		},
+		{
+			name:           "Cost grouped by tag",
+			url:            "/subscriptions/total-cost?start_date=12-2025&end_date=08-2026&group_by=tag",
+			expectedCost:   200 + 700 + sub1.Price,
+			expectedGroups: []CostGroupItem{{Key: "", Cost: sub1.Price}, {Key: "family", Cost: 200}, {Key: "work", Cost: 900}},
+			respCode:       http.StatusOK,
+			mockNeedCall:   true,
+			mockRet:        []model.Subscription{subStreaming, subCloud, sub1},
+		},
+		{
+			name:           "Member share grouped by category",
+			url:            fmt.Sprintf("/subscriptions/total-cost?start_date=12-2025&end_date=08-2026&user_id=%s&group_by=category", sharedMemberId.String()),
+			expectedCost:   250,
+			expectedGroups: []CostGroupItem{{Key: "", Cost: 250}},
+			respCode:       http.StatusOK,
+			mockNeedCall:   true,
+			mockRet:        []model.Subscription{subShared},
+		},
+		{
+			name:         "Report as of moment",
+			url:          "/subscriptions/total-cost?start_date=12-2025&end_date=08-2026&as_of=2026-03-01T10:00:00Z",
+			expectedCost: 2700,
+			respCode:     http.StatusOK,
+			mockNeedCall: true,
+			mockRet:      []model.Subscription{sub1, sub2, sub3, sub4, sub5},
+		},
+		{
+			name:      "Invalid as of",
+			url:       "/subscriptions/total-cost?start_date=12-2025&end_date=08-2026&as_of=01-03-2026",
+			respCode:  http.StatusBadRequest,
+			respError: "as of is invalid",
+		},
+		{
+			name:         "Cannot get charges",
+			url:          "/subscriptions/total-cost?start_date=12-2025&end_date=08-2026",
+			respCode:     http.StatusInternalServerError,
+			respError:    "failed to get charges",
+			mockNeedCall: true,
+			mockRet:      []model.Subscription{sub1},
+			chargesError: errors.New("some error"),
+		},
+		{
+			name:      "Invalid group by",
+			url:       "/subscriptions/total-cost?start_date=07-2027&end_date=09-2027&group_by=service",
+			respCode:  http.StatusBadRequest,
+			respError: "group by is invalid",
+		},
+		{
+			name:      "Invalid tag",
+			url:       "/subscriptions/total-cost?start_date=07-2027&end_date=09-2027&tag=%20",
+			respCode:  http.StatusBadRequest,
+			respError: "tag filter is invalid",
+		},
+		{
+			name:      "Invalid filter expression",
+			url:       "/subscriptions/total-cost?start_date=07-2027&end_date=09-2027&filter=" + url.QueryEscape("price > cheap"),
+			respCode:  http.StatusBadRequest,
+			respError: "filter is invalid: invalid value 'cheap' for price, expected integer amount in minor units or quoted decimal amount at position 9",
+		},
+		{
+			name:      "Invalid status",
+			url:       "/subscriptions/total-cost?start_date=07-2027&end_date=09-2027&status=trash",
+			respCode:  http.StatusBadRequest,
+			respError: "status filter is invalid",
+		},
+		{
+			name:         "Total cost overflow",
+			url:          "/subscriptions/total-cost?start_date=12-2025&end_date=08-2026",
+			respCode:     http.StatusUnprocessableEntity,
+			respError:    "total cost is too large",
+			mockNeedCall: true,
+			mockRet:      []model.Subscription{subHuge, subHuge},
+		},
 		{
 			name:      "Empty start date",
 			url:       "/subscriptions/total-cost?start_date=&end_date=04-2026",
@@ -171,14 +426,19 @@ func TestTotalCostHandler(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			filterMock := mocks.NewFilteredDataReader(t)
 			if tc.mockNeedCall {
-				start, end, uid, sName := getParamsFromTotalCostReqUrl(t, &tc.url)
+				start, end, uid, sName, filter := getParamsFromTotalCostReqUrl(t, &tc.url)
 
 				var sNamePtr *string
 				if sName != "" {
 					sNamePtr = &sName
 				}
 
-				filterMock.On("FilterSubscriptions", start, end, uid, sNamePtr).Return(tc.mockRet, tc.mockError)
+				filterMock.On("FilterSubscriptions", start, end, uid, sNamePtr, filter).Return(tc.mockRet, tc.mockError)
+
+				if tc.mockError == nil {
+					ids, charges := ledgerOf(tc.mockRet)
+					filterMock.On("GetCharges", ids, getAsOfFromTotalCostReqUrl(t, &tc.url)).Return(charges, tc.chargesError)
+				}
 			}
 
 			router := chi.NewRouter()
@@ -196,21 +456,56 @@ func TestTotalCostHandler(t *testing.T) {
 
 			assert.Equal(t, tc.respCode, rr.Code)
 
-			body := rr.Body.String()
-
 			var resp TotalCostResponse
-
-			assert.Nil(t, json.Unmarshal([]byte(body), &resp))
-			assert.Equal(t, tc.respError, resp.Error)
+			assert.Equal(t, tc.respError, decodeResp(t, rr, &resp))
 			if tc.respError == "" {
 				assert.Equal(t, tc.expectedCost, resp.TotalCost)
+
+				var breakdownSum model.Money
+				for _, item := range resp.Breakdown {
+					breakdownSum += item.Cost
+				}
+				assert.Equal(t, tc.expectedCost, breakdownSum)
+
+				assert.Equal(t, tc.expectedGroups, resp.Groups)
 			}
 		})
 	}
 }
 
+// Helper building ledger of subscriptions charges as storage generates it
+func ledgerOf(subs []model.Subscription) ([]int64, map[int64][]model.Charge) {
+	ids := make([]int64, 0, len(subs))
+	charges := make(map[int64][]model.Charge)
+
+	for i := 0; i < len(subs); i++ {
+		ids = append(ids, subs[i].ID)
+		charges[subs[i].ID] = subs[i].ExpectedCharges()
+	}
+
+	return ids, charges
+}
+
+// Helper for get report moment from URL
+func getAsOfFromTotalCostReqUrl(t *testing.T, rawUrl *string) *time.Time {
+	t.Helper()
+
+	parsed, err := url.Parse(*rawUrl)
+	assert.NoError(t, err)
+
+	asOfStr := parsed.Query().Get("as_of")
+	if asOfStr == "" {
+		return nil
+	}
+
+	asOf, err := time.Parse(time.RFC3339, asOfStr)
+	assert.NoError(t, err)
+
+	return &asOf
+}
+
 // Helper for get total cost calculating params from URL
-func getParamsFromTotalCostReqUrl(t *testing.T, rawUrl *string) (model.Date, model.Date, uuid.UUID, string) {
+func getParamsFromTotalCostReqUrl(t *testing.T, rawUrl *string) (model.Date, model.Date, uuid.UUID, string, model.SubscriptionFilter) {
 	t.Helper()
 
 	parsed, err := url.Parse(*rawUrl)
@@ -239,5 +534,26 @@ func getParamsFromTotalCostReqUrl(t *testing.T, rawUrl *string) (model.Date, mod
 		sName = serviceName[0]
 	}
 
-	return start, end, uid, sName
+	var filter model.SubscriptionFilter
+	if status := query["status"]; len(status) > 0 {
+		s := model.Status(status[0])
+		filter.Status = &s
+	}
+	if category := query["category"]; len(category) > 0 {
+		c, err := model.NormalizeLabel(category[0])
+		assert.NoError(t, err)
+		filter.Category = &c
+	}
+	if tag := query["tag"]; len(tag) > 0 {
+		tg, err := model.NormalizeLabel(tag[0])
+		assert.NoError(t, err)
+		filter.Tag = &tg
+	}
+	if expr := query["filter"]; len(expr) > 0 {
+		e, err := model.ParseFilterExpr(expr[0])
+		assert.NoError(t, err)
+		filter.Expr = e
+	}
+
+	return start, end, uid, sName, filter
 }
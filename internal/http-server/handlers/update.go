@@ -3,6 +3,7 @@ package handlers
 import (
 	"em_golang_rest_service_example/internal/model"
 	"em_golang_rest_service_example/internal/storage"
+	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
@@ -13,40 +14,68 @@ import (
 	"github.com/go-chi/render"
 )
 
-// UpdateRequest represents subscription for update model
+// UpdateRequest represents subscription for replace model, omitted optional fields are reset to defaults as on creation
 // swagger:model UpdateRequest
 // @ID UpdateRequest
 type UpdateRequest struct {
 	// New service name (required)
 	ServiceName string `json:"service_name"`
 
-	// New price (required)
-	Price int `json:"price"`
+	// New price as decimal string ("9.99") or integer of minor units (999) (required)
+	Price model.Money `json:"price" swaggertype:"string" example:"9.99"`
 
-	// New start date
+	// New start date (required)
 	StartDate string `json:"start_date"`
 
-	// New end date (optional)
+	// New end date (optional, one billing period after start date if omitted)
 	EndDate string `json:"end_date,omitempty"`
+
+	// New number of first months billed with trial price (optional, no trial if omitted)
+	TrialMonths int `json:"trial_months,omitempty"`
+
+	// New monthly price during trial (optional, free if omitted)
+	TrialPrice model.Money `json:"trial_price,omitempty" swaggertype:"string" example:"0.00"`
+
+	// New users sharing the cost with split weights (optional, owner pays alone if omitted)
+	Members []MemberItem `json:"members,omitempty"`
+
+	// New spending category, case insensitive (optional, category of catalog service if omitted)
+	Category string `json:"category,omitempty"`
+
+	// New free-form labels, case insensitive (optional, removed if omitted)
+	Tags []string `json:"tags,omitempty"`
+
+	// New custom attributes (optional, removed if omitted)
+	Metadata json.RawMessage `json:"metadata,omitempty" swaggertype:"object"`
+
+	// Extend subscription by billing period when it passes end date
+	// (optional, true if end date is omitted and false otherwise)
+	AutoRenew *bool `json:"auto_renew,omitempty"`
+
+	// New billing period in months from 1 to 12 (optional, one month if omitted)
+	BillingPeriod int `json:"billing_period,omitempty"`
 }
 
 //go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=Updater
 type Updater interface {
-	UpdateSubscription(id int64, newServiceName string, newPrice int, newStart, newEnd model.Date) error
+	UpdateSubscription(id int64, update model.SubscriptionUpdate) error
 }
 
 // NewUpdateHandler godoc
-// @Summary Update subscription
-// @Description Update subscription
+// @Summary Replace subscription
+// @Description Replace all editable subscription fields, omitted optional ones are reset to defaults as on creation
+// @Description (user, price schedule, discounts, status and renewals are kept, they are changed by own endpoints)
+// @Tags v1
 // @Accept json
 // @Produce json
 // @Param id path int true "Subscription ID"
 // @Param request body UpdateRequest true "Subscription new data"
 // @Success 200 {object} Response
-// @Failure 400 {object} Response
-// @Failure 404 {object} Response
-// @Failure 500 {object} Response
-// @Router /subscription/{id} [patch]
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /subscription/{id} [put]
 func NewUpdateHandler(logger *slog.Logger, updater Updater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.update"
@@ -63,8 +92,7 @@ func NewUpdateHandler(logger *slog.Logger, updater Updater) http.HandlerFunc {
 		if idStr == "" {
 			logger.Info("no subscription id in request")
 
-			w.WriteHeader(http.StatusBadRequest)
-			render.JSON(w, r, RespError("no subscription id in request"))
+			renderError(w, r, http.StatusBadRequest, CodeMissingValue, "id", "no subscription id in request")
 
 			return
 		}
@@ -72,8 +100,7 @@ func NewUpdateHandler(logger *slog.Logger, updater Updater) http.HandlerFunc {
 		if err != nil {
 			logger.Info("invalid subscription id format", "details", err)
 
-			w.WriteHeader(http.StatusBadRequest)
-			render.JSON(w, r, RespError("invalid subscription id format"))
+			renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "id", "invalid subscription id format")
 
 			return
 		}
@@ -90,29 +117,10 @@ func NewUpdateHandler(logger *slog.Logger, updater Updater) http.HandlerFunc {
 			return
 		}
 
-		// 3.Fill end_date with value if need
-		startDate, _ := model.DateFromString(req.StartDate)
-
-		endDate := model.Date{}
-		if req.EndDate != "" {
-			endDate, _ = model.DateFromString(req.EndDate)
-		}
-
-		// 4.Update
-		err = updater.UpdateSubscription(int64(id), req.ServiceName, req.Price, startDate, endDate)
-		if errors.Is(err, storage.ErrSubscribtionNotFound) {
-			logger.Info("subscription not found", "id", id)
-
-			w.WriteHeader(http.StatusNotFound)
-			render.JSON(w, r, RespError("subscription not found"))
-
-			return
-		}
+		// 4.Replace all editable fields
+		err = updater.UpdateSubscription(int64(id), prepareReplaceUpdate(&req))
 		if err != nil {
-			logger.Error("failed to update subscription", "details", err)
-
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, RespError("failed to get subscription"))
+			renderUpdateError(w, r, logger, int64(id), err)
 
 			return
 		}
@@ -123,53 +131,116 @@ func NewUpdateHandler(logger *slog.Logger, updater Updater) http.HandlerFunc {
 			"new_end_date", req.EndDate,
 		)
 
-		// 3.Prepare response and render it
+		// 5.Prepare response and render it
 		render.JSON(w, r, RespOK())
 	}
 }
 
-func validateUpdateReq(r *http.Request, w http.ResponseWriter, req *UpdateRequest, logger *slog.Logger) bool {
-	// 1.Service name
-	if req.ServiceName == "" {
-		logger.Error("request service name is empty")
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, RespError("request service name is empty"))
-		return false
+// Update replacing all editable fields, omitted ones get defaults as on creation (see prepareSubscriptionSpec)
+func prepareReplaceUpdate(req *UpdateRequest) model.SubscriptionUpdate {
+	startDate, _ := model.DateFromString(req.StartDate)
+
+	endDate := model.Date{}
+	if req.EndDate != "" {
+		endDate, _ = model.DateFromString(req.EndDate)
 	}
 
-	// 2.Price
-	if req.Price < 0 {
-		logger.Error("request price cannot be lower than 0")
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, RespError("request price is invalid"))
-		return false
+	billingPeriod := req.BillingPeriod
+	if billingPeriod == 0 {
+		billingPeriod = 1
 	}
 
-	// 3.Start date
-	if req.StartDate == "" {
-		logger.Error("request start date is empty")
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, RespError("request start date is empty"))
-		return false
+	autoRenew := req.EndDate == ""
+	if req.AutoRenew != nil {
+		autoRenew = *req.AutoRenew
 	}
-	_, err := model.DateFromString(req.StartDate)
-	if err != nil {
-		logger.Error("request start date is invalid", "details", err)
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, RespError("request start date is invalid"))
-		return false
+
+	members, _ := parseMembers(req.Members)
+	category, tags, _ := parseTags(req.Category, req.Tags)
+	metadata, _ := model.NormalizeMetadata(req.Metadata)
+
+	return model.SubscriptionUpdate{
+		ServiceName:   &req.ServiceName,
+		Price:         &req.Price,
+		StartDate:     &startDate,
+		EndDate:       &endDate,
+		Metadata:      &metadata,
+		TrialMonths:   &req.TrialMonths,
+		TrialPrice:    &req.TrialPrice,
+		Members:       &members,
+		Category:      &category,
+		Tags:          &tags,
+		AutoRenew:     &autoRenew,
+		BillingPeriod: &billingPeriod,
 	}
+}
 
-	// 4.End date
-	if req.EndDate != "" {
-		_, err := model.DateFromString(req.EndDate)
-		if err != nil {
-			logger.Error("request end date is invalid", "details", err)
-			w.WriteHeader(http.StatusBadRequest)
-			render.JSON(w, r, RespError("request end date is invalid"))
-			return false
-		}
+// Write error response of failed update (merged subscription is invalid, taken service name etc)
+func renderUpdateError(w http.ResponseWriter, r *http.Request, logger *slog.Logger, id int64, err error) {
+	var validationErr *model.ValidationError
+
+	switch {
+	case errors.Is(err, storage.ErrSubscribtionNotFound):
+		logger.Info("subscription not found", "id", id)
+
+		renderError(w, r, http.StatusNotFound, CodeSubscriptionNotFound, "", "subscription not found")
+	case errors.As(err, &validationErr):
+		logger.Info("updated subscription is invalid", "details", validationErr.Details())
+
+		renderFieldError(w, r, validationErr)
+	case errors.Is(err, storage.ErrSubscriptionExists):
+		logger.Info("subscription already exists", "id", id)
+
+		renderError(w, r, http.StatusConflict, CodeSubscriptionExists, "service_name", "subscription already exists")
+	default:
+		logger.Error("failed to update subscription", "details", err)
+
+		renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to update subscription")
+	}
+}
+
+func validateUpdateReq(r *http.Request, w http.ResponseWriter, req *UpdateRequest, logger *slog.Logger) bool {
+	err := checkUpdateReq(req)
+	if err == nil {
+		return true
+	}
+
+	logger.Error(err.Error(), "details", validationDetails(err))
+	renderFieldError(w, r, err)
+	return false
+}
+
+// Validate new subscription data, get *model.ValidationError with all invalid fields (nil if data is valid)
+func checkUpdateReq(req *UpdateRequest) error {
+	var v model.Validator
+
+	// 1.Service name
+	v.Required("service_name", req.ServiceName, "request service name is empty")
+
+	// 2.Price
+	v.NotNegative("price", int64(req.Price), "request price is invalid")
+	v.NotNegative("trial_months", int64(req.TrialMonths), "request trial months is invalid")
+	v.NotNegative("trial_price", int64(req.TrialPrice), "request trial price is invalid")
+
+	// 3.Dates
+	checkPeriod(&v, req.StartDate, req.EndDate, "request start date is empty")
+
+	// 4.Members of shared subscription
+	if _, err := parseMembers(req.Members); err != nil {
+		v.Fail("members", model.CodeInvalidValue, "request members are invalid", err)
+	}
+
+	// 5.Category and tags
+	v.Label("category", req.Category, "request category is invalid")
+	v.Tags("tags", req.Tags, "request tags are invalid")
+
+	// 6.Metadata
+	v.Metadata("metadata", req.Metadata, "request metadata is invalid")
+
+	// 7.Billing period
+	if req.BillingPeriod != 0 {
+		v.BillingPeriod("billing_period", req.BillingPeriod, "request billing period is invalid")
 	}
 
-	return true
+	return v.Err()
 }
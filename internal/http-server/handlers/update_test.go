@@ -16,6 +16,7 @@ import (
 	"testing"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -23,9 +24,12 @@ type updateTCase struct {
 	name           string
 	id             string
 	newServiceName string
-	newPrice       int
+	newPrice       model.Money
 	newStartDate   string
 	newEndDate     string
+	newMetadata    string
+	newOptional    string                            // other optional fields as JSON members
+	expectUpdate   func(u *model.SubscriptionUpdate) // changes expected update with other optional fields
 	respCode       int
 	respError      string
 	mockError      error
@@ -45,6 +49,59 @@ func TestUpdateHandler(t *testing.T) {
 			newEndDate:     "04-2026",
 			respCode:       http.StatusOK,
 		},
+		{
+			name:           "Success with metadata",
+			id:             "1",
+			newServiceName: "Яндекс",
+			newPrice:       350,
+			newStartDate:   "03-2026",
+			newEndDate:     "04-2026",
+			newMetadata:    `{"cost_center": "R&D", "seats": 5}`,
+			respCode:       http.StatusOK,
+		},
+		{
+			name:           "Success with all optional fields",
+			id:             "1",
+			newServiceName: "Яндекс",
+			newPrice:       350,
+			newStartDate:   "03-2026",
+			newOptional: `"trial_months": 2, "trial_price": "1.50", "members": [{"user_id": "60601fee-2bf1-4721-ae6f-7636e79a0cba", "weight": 1}],` +
+				` "category": " Video ", "tags": ["Family", "family"], "auto_renew": false, "billing_period": 3`,
+			expectUpdate: func(u *model.SubscriptionUpdate) {
+				trialMonths, trialPrice, category, autoRenew, billingPeriod := 2, model.Money(150), "video", false, 3
+				members := []model.Member{{UserID: uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba"), Weight: 1}}
+				tags := []string{"family"}
+
+				u.EndDate = &model.Date{}
+				u.TrialMonths, u.TrialPrice, u.Members, u.Category = &trialMonths, &trialPrice, &members, &category
+				u.Tags, u.AutoRenew, u.BillingPeriod = &tags, &autoRenew, &billingPeriod
+			},
+			respCode: http.StatusOK,
+		},
+		{
+			name:           "Omitted end date turns on renewal",
+			id:             "1",
+			newServiceName: "Яндекс",
+			newPrice:       350,
+			newStartDate:   "03-2026",
+			expectUpdate: func(u *model.SubscriptionUpdate) {
+				autoRenew := true
+
+				u.EndDate = &model.Date{}
+				u.AutoRenew = &autoRenew
+			},
+			respCode: http.StatusOK,
+		},
+		{
+			name:           "Validation error on optional fields",
+			id:             "2",
+			newServiceName: "Амедиатека",
+			newPrice:       155,
+			newStartDate:   "01-2027",
+			newOptional:    `"trial_months": -1, "members": [{"user_id": "trash", "weight": 1}], "tags": [""], "billing_period": 13`,
+			respCode:       http.StatusBadRequest,
+			respError:      "request trial months is invalid; request members are invalid; request tags are invalid; request billing period is invalid",
+		},
 		{
 			name:      "Invalid id",
 			id:        "trash",
@@ -55,7 +112,7 @@ func TestUpdateHandler(t *testing.T) {
 			name:      "Validation error on service name",
 			id:        "2",
 			respCode:  http.StatusBadRequest,
-			respError: "request service name is empty",
+			respError: "request service name is empty; request start date is empty",
 		},
 		{
 			name:           "Validation error on price",
@@ -63,7 +120,7 @@ func TestUpdateHandler(t *testing.T) {
 			newServiceName: "Гугл",
 			newPrice:       -5,
 			respCode:       http.StatusBadRequest,
-			respError:      "request price is invalid",
+			respError:      "request price is invalid; request start date is empty",
 		},
 		{
 			name:           "Validation error on start date (empty)",
@@ -92,6 +149,16 @@ func TestUpdateHandler(t *testing.T) {
 			respCode:       http.StatusBadRequest,
 			respError:      "request end date is invalid",
 		},
+		{
+			name:           "Validation error on metadata depth",
+			id:             "2",
+			newServiceName: "Амедиатека",
+			newPrice:       155,
+			newStartDate:   "01-2027",
+			newMetadata:    `{"a": {"b": {"c": {}}}}`,
+			respCode:       http.StatusBadRequest,
+			respError:      "request metadata is invalid: metadata is nested too deep",
+		},
 		{
 			name:           "Not found subscription",
 			id:             "3",
@@ -111,9 +178,33 @@ func TestUpdateHandler(t *testing.T) {
 			newStartDate:   "04-2025",
 			newEndDate:     "05-2025",
 			respCode:       http.StatusInternalServerError,
-			respError:      "failed to get subscription",
+			respError:      "failed to update subscription",
 			mockError:      errors.New("some error"),
 		},
+		{
+			name:           "Updated subscription is invalid",
+			id:             "3",
+			newServiceName: "Кинопоиск",
+			newPrice:       155,
+			newStartDate:   "04-2025",
+			newEndDate:     "05-2025",
+			respCode:       http.StatusBadRequest,
+			respError:      "start date greater than end date",
+			mockError: fmt.Errorf("storage: %w", &model.ValidationError{Fields: []model.FieldError{
+				{Field: "end_date", Code: model.CodeInvalidValue, Message: "start date greater than end date"},
+			}}),
+		},
+		{
+			name:           "Service name is taken",
+			id:             "3",
+			newServiceName: "Кинопоиск",
+			newPrice:       155,
+			newStartDate:   "04-2025",
+			newEndDate:     "05-2025",
+			respCode:       http.StatusConflict,
+			respError:      "subscription already exists",
+			mockError:      storage.ErrSubscriptionExists,
+		},
 	}
 
 	for _, tc := range commonCases {
@@ -126,10 +217,34 @@ func TestUpdateHandler(t *testing.T) {
 					newStartDate, err := model.DateFromString(tc.newStartDate)
 					assert.NoError(t, err)
 
-					newEndDate, err := model.DateFromString(tc.newEndDate)
+					newEndDate, _ := model.DateFromString(tc.newEndDate)
+
+					newMetadata, err := model.NormalizeMetadata(json.RawMessage(tc.newMetadata))
 					assert.NoError(t, err)
 
-					updaterMock.On("UpdateSubscription", int64(id), tc.newServiceName, tc.newPrice, newStartDate, newEndDate).Return(tc.mockError)
+					// Omitted optional fields are reset as on creation
+					trialMonths, trialPrice, category, autoRenew, billingPeriod := 0, model.Money(0), "", false, 1
+					var members []model.Member
+					var tags []string
+
+					update := model.SubscriptionUpdate{
+						ServiceName:   &tc.newServiceName,
+						Price:         &tc.newPrice,
+						StartDate:     &newStartDate,
+						EndDate:       &newEndDate,
+						Metadata:      &newMetadata,
+						TrialMonths:   &trialMonths,
+						TrialPrice:    &trialPrice,
+						Members:       &members,
+						Category:      &category,
+						Tags:          &tags,
+						AutoRenew:     &autoRenew,
+						BillingPeriod: &billingPeriod,
+					}
+					if tc.expectUpdate != nil {
+						tc.expectUpdate(&update)
+					}
+					updaterMock.On("UpdateSubscription", int64(id), update).Return(tc.mockError)
 				}
 
 			}
@@ -158,8 +273,8 @@ func TestUpdateHandler(t *testing.T) {
 			respCode:  http.StatusBadRequest,
 			respError: "failed to decode request",
 			input: fmt.Sprintf(
-				`{"price": "%d", "end_date": "%s"}`,
-				900, "07-2027",
+				`{"price": "%s", "end_date": "%s"}`,
+				"9,00", "07-2027",
 			),
 		},
 	}
@@ -180,10 +295,10 @@ func updateRespCheck(t *testing.T, l *slog.Logger, u Updater, tc *updateTCase, i
 	t.Helper()
 
 	router := chi.NewRouter()
-	router.Patch("/subscription/{id}", NewUpdateHandler(l, u))
+	router.Put("/subscription/{id}", NewUpdateHandler(l, u))
 
 	req, err := http.NewRequest(
-		http.MethodPatch,
+		http.MethodPut,
 		"/subscription/"+tc.id,
 		bytes.NewReader([]byte(*in)),
 	)
@@ -194,12 +309,8 @@ func updateRespCheck(t *testing.T, l *slog.Logger, u Updater, tc *updateTCase, i
 
 	assert.Equal(t, expRespCode, rr.Code)
 
-	body := rr.Body.String()
-
 	var resp Response
-
-	assert.Nil(t, json.Unmarshal([]byte(body), &resp))
-	assert.Equal(t, *expectedRespErr, resp.Error)
+	assert.Equal(t, *expectedRespErr, decodeResp(t, rr, &resp))
 }
 
 // Transform test case data to string
@@ -208,5 +319,11 @@ func updateTCaseToStr(tc *updateTCase) string {
 		`{"service_name": "%s", "price": %d, "start_date": "%s", "end_date": "%s"}`, tc.newServiceName,
 		tc.newPrice, tc.newStartDate, tc.newEndDate,
 	)
+	if tc.newMetadata != "" {
+		input = input[:len(input)-1] + `, "metadata": ` + tc.newMetadata + `}`
+	}
+	if tc.newOptional != "" {
+		input = input[:len(input)-1] + `, ` + tc.newOptional + `}`
+	}
 	return input
 }
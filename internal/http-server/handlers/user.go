@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/model"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+// UserSummaryResponse represents user subscriptions summary model
+// swagger:model UserSummaryResponse
+// @ID UserSummaryResponse
+type UserSummaryResponse struct {
+	// Month summary is calculated at
+	Month string `json:"month"`
+
+	// Number of subscriptions active in the month
+	ActiveCount int64 `json:"active_count"`
+
+	// Charge for the month over all user subscriptions (decimal string)
+	MonthlyRunRate model.Money `json:"monthly_run_rate" swaggertype:"string" example:"1299.00"`
+
+	// Spend from January up to the month inclusive (decimal string)
+	YearToDateSpend model.Money `json:"year_to_date_spend" swaggertype:"string" example:"5196.00"`
+
+	// Subscription which ends first after the month (null if no one)
+	NextExpiring *ListItem `json:"next_expiring"`
+
+	Response
+}
+
+// DeleteUserResponse represents response on user subscriptions deletion
+// swagger:model DeleteUserResponse
+// @ID DeleteUserResponse
+type DeleteUserResponse struct {
+	// Number of deleted subscriptions
+	Deleted int64 `json:"deleted"`
+
+	Response
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=UserListReader
+type UserListReader interface {
+	GetUserSubscriptions(userId uuid.UUID, limit, offset *int) ([]model.Subscription, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=UserSummaryReader
+type UserSummaryReader interface {
+	GetUserSubscriptions(userId uuid.UUID, limit, offset *int) ([]model.Subscription, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --name=UserDeleter
+type UserDeleter interface {
+	DeleteUserSubscriptions(userId uuid.UUID) (int64, error)
+}
+
+// NewUserListHandler godoc
+// @Summary Get user subscriptions
+// @Description Get all subscriptions of user ordered by id
+// @Tags v1
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param limit query int false "Page size (requires offset)"
+// @Param offset query int false "Page offset (requires limit)"
+// @Success 200 {object} ListResponse
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /users/{user_id}/subscriptions [get]
+func NewUserListHandler(logger *slog.Logger, listReader UserListReader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.user_list"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		// 1.Get user id and optional params
+		userId, ok := getUserId(r, w, logger)
+		if !ok {
+			return
+		}
+
+		limit, offset, ok := getValidatedOptParams(r, w, logger)
+		if !ok {
+			return
+		}
+
+		// 2.Get subscriptions
+		var subscriptions []model.Subscription
+		var err error
+
+		if limit == 0 && offset == 0 {
+			subscriptions, err = listReader.GetUserSubscriptions(userId, nil, nil)
+		} else {
+			subscriptions, err = listReader.GetUserSubscriptions(userId, &limit, &offset)
+		}
+
+		if err != nil {
+			logger.Error("failed to get user subscriptions", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to get user subscriptions")
+
+			return
+		}
+
+		logger.Info("got user subscriptions", "user_id", userId)
+
+		// 3.Prepare response and render it
+		render.JSON(w, r, makeListResp(subscriptions))
+	}
+}
+
+// NewUserSummaryHandler godoc
+// @Summary Get user summary
+// @Description Get active subscriptions count, monthly run-rate, spend year to date and next expiring subscription
+// @Tags v1
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param month query string false "Month in MM-YYYY format (current month by default)"
+// @Success 200 {object} UserSummaryResponse
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /users/{user_id}/summary [get]
+func NewUserSummaryHandler(logger *slog.Logger, reader UserSummaryReader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.user_summary"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		// 1.Get user id and month
+		userId, ok := getUserId(r, w, logger)
+		if !ok {
+			return
+		}
+
+		month := model.CurrentMonth()
+		if monthStr := r.URL.Query().Get("month"); monthStr != "" {
+			var err error
+
+			month, err = model.DateFromString(monthStr)
+			if err != nil {
+				logger.Info("invalid month", "details", err)
+
+				renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "month", "month is invalid")
+
+				return
+			}
+		}
+
+		// 2.Get all user subscriptions
+		subscriptions, err := reader.GetUserSubscriptions(userId, nil, nil)
+		if err != nil {
+			logger.Error("failed to get user subscriptions", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to get user subscriptions")
+
+			return
+		}
+
+		// 3.Summarize
+		summary, err := model.Summarize(subscriptions, userId, month)
+		if err != nil {
+			logger.Error("failed to summarize user subscriptions", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to summarize user subscriptions")
+
+			return
+		}
+
+		logger.Info("got user summary", "user_id", userId, "active_count", summary.ActiveCount)
+
+		// 4.Prepare response and render it
+		resp := UserSummaryResponse{
+			Month:           month.ToString(),
+			ActiveCount:     summary.ActiveCount,
+			MonthlyRunRate:  summary.MonthlyRunRate,
+			YearToDateSpend: summary.YearToDateSpend,
+			Response:        RespOK(),
+		}
+		if summary.NextExpiring != nil {
+			item := makeListItem(summary.NextExpiring)
+			resp.NextExpiring = &item
+		}
+
+		render.JSON(w, r, resp)
+	}
+}
+
+// NewDeleteUserHandler godoc
+// @Summary Delete user subscriptions
+// @Description Delete all subscriptions of user
+// @Tags v1
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} DeleteUserResponse
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /users/{user_id}/subscriptions [delete]
+func NewDeleteUserHandler(logger *slog.Logger, deleter UserDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.delete_user"
+
+		logger := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		// 1.Get user id
+		userId, ok := getUserId(r, w, logger)
+		if !ok {
+			return
+		}
+
+		// 2.Delete
+		deleted, err := deleter.DeleteUserSubscriptions(userId)
+		if err != nil {
+			logger.Error("failed to delete user subscriptions", "details", err)
+
+			renderError(w, r, http.StatusInternalServerError, CodeInternalError, "", "failed to delete user subscriptions")
+
+			return
+		}
+
+		logger.Info("user subscriptions deleted", "user_id", userId, "deleted", deleted)
+
+		render.JSON(w, r, DeleteUserResponse{Deleted: deleted, Response: RespOK()})
+	}
+}
+
+func getUserId(r *http.Request, w http.ResponseWriter, logger *slog.Logger) (uuid.UUID, bool) {
+	userId, err := uuid.Parse(chi.URLParam(r, "user_id"))
+	if err != nil {
+		logger.Info("invalid user id format", "details", err)
+
+		renderError(w, r, http.StatusBadRequest, CodeInvalidValue, "user_id", "invalid user id format")
+
+		return uuid.Nil, false
+	}
+
+	return userId, true
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"em_golang_rest_service_example/internal/http-server/handlers/mocks"
+	"em_golang_rest_service_example/internal/model"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserListHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	userId := uuid.New()
+	subscriptions := []model.Subscription{
+		{ID: 1, SubscriptionSpec: model.SubscriptionSpec{ServiceName: "Okko", UserID: userId}, Status: model.StatusActive},
+	}
+
+	one, zero := 1, 0
+
+	cases := []struct {
+		name      string
+		url       string
+		respCode  int
+		respError string
+		mockLimit *int
+		mockError error
+	}{
+		{
+			name:     "Success",
+			url:      "/users/" + userId.String() + "/subscriptions",
+			respCode: http.StatusOK,
+		},
+		{
+			name:      "Success with pagination",
+			url:       "/users/" + userId.String() + "/subscriptions?limit=1&offset=0",
+			respCode:  http.StatusOK,
+			mockLimit: &one,
+		},
+		{
+			name:      "Invalid user id",
+			url:       "/users/trash/subscriptions",
+			respCode:  http.StatusBadRequest,
+			respError: "invalid user id format",
+		},
+		{
+			name:      "Limit without offset",
+			url:       "/users/" + userId.String() + "/subscriptions?limit=1",
+			respCode:  http.StatusBadRequest,
+			respError: "no offset value while limit is set",
+		},
+		{
+			name:      "Any reader error case",
+			url:       "/users/" + userId.String() + "/subscriptions",
+			respCode:  http.StatusInternalServerError,
+			respError: "failed to get user subscriptions",
+			mockError: errors.New("any error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			readerMock := mocks.NewUserListReader(t)
+
+			if tc.respError == "" || tc.mockError != nil {
+				var offset *int
+				if tc.mockLimit != nil {
+					offset = &zero
+				}
+				readerMock.On("GetUserSubscriptions", userId, tc.mockLimit, offset).Return(subscriptions, tc.mockError)
+			}
+
+			router := chi.NewRouter()
+			router.Get("/users/{user_id}/subscriptions", NewUserListHandler(logger, readerMock))
+
+			rr := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			assert.NoError(t, err)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.respCode, rr.Code)
+
+			var resp ListResponse
+			assert.Equal(t, tc.respError, decodeResp(t, rr, &resp))
+			if tc.respError == "" {
+				assert.Equal(t, makeListResp(subscriptions), resp)
+			}
+		})
+	}
+}
+
+func TestUserSummaryHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	userId := uuid.New()
+	subscriptions := []model.Subscription{
+		{
+			ID: 1,
+			SubscriptionSpec: model.SubscriptionSpec{
+				ServiceName: "Okko",
+				Price:       39900,
+				UserID:      userId,
+				StartDate:   model.Date{Month: 1, Year: 2026},
+				EndDate:     model.Date{Month: 6, Year: 2026},
+			},
+			Status: model.StatusActive,
+		},
+	}
+
+	readerMock := mocks.NewUserSummaryReader(t)
+	readerMock.On("GetUserSubscriptions", userId, (*int)(nil), (*int)(nil)).Return(subscriptions, nil)
+
+	router := chi.NewRouter()
+	router.Get("/users/{user_id}/summary", NewUserSummaryHandler(logger, readerMock))
+
+	// 1.Summary at specified month
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, "/users/"+userId.String()+"/summary?month=03-2026", nil)
+	assert.NoError(t, err)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp UserSummaryResponse
+	assert.Nil(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+	nextExpiring := makeListItem(&subscriptions[0])
+	assert.Equal(t, UserSummaryResponse{
+		Month:           "03-2026",
+		ActiveCount:     1,
+		MonthlyRunRate:  39900,
+		YearToDateSpend: 3 * 39900,
+		NextExpiring:    &nextExpiring,
+		Response:        RespOK(),
+	}, resp)
+
+	// 2.Invalid month
+	rr = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodGet, "/users/"+userId.String()+"/summary?month=trash", nil)
+	assert.NoError(t, err)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestDeleteUserHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	userId := uuid.New()
+	failedUserId := uuid.New()
+
+	deleterMock := mocks.NewUserDeleter(t)
+	deleterMock.On("DeleteUserSubscriptions", userId).Return(int64(3), nil)
+	deleterMock.On("DeleteUserSubscriptions", failedUserId).Return(int64(0), errors.New("any error"))
+
+	router := chi.NewRouter()
+	router.Delete("/users/{user_id}/subscriptions", NewDeleteUserHandler(logger, deleterMock))
+
+	cases := []struct {
+		userId    string
+		respCode  int
+		respError string
+		deleted   int64
+	}{
+		{userId: userId.String(), respCode: http.StatusOK, deleted: 3},
+		{userId: failedUserId.String(), respCode: http.StatusInternalServerError, respError: "failed to delete user subscriptions"},
+		{userId: "trash", respCode: http.StatusBadRequest, respError: "invalid user id format"},
+	}
+
+	for _, tc := range cases {
+		rr := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodDelete, "/users/"+tc.userId+"/subscriptions", nil)
+		assert.NoError(t, err)
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, tc.respCode, rr.Code)
+
+		var resp DeleteUserResponse
+		assert.Equal(t, tc.respError, decodeResp(t, rr, &resp))
+		assert.Equal(t, tc.deleted, resp.Deleted)
+	}
+}
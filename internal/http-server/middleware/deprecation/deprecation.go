@@ -0,0 +1,32 @@
+package deprecation
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// New returns middleware marking responses of deprecated routes with Deprecation (RFC 9745) and Sunset (RFC 8594)
+// headers; successor is prefix of the same route in supported API version (linked as successor-version)
+func New(deprecatedAt, sunset time.Time, successor string) func(next http.Handler) http.Handler {
+	deprecation := fmt.Sprintf("@%d", deprecatedAt.Unix())
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecation)
+			w.Header().Set("Sunset", sunsetDate)
+
+			link := strings.TrimSuffix(successor, "/") + r.URL.Path
+			if r.URL.RawQuery != "" {
+				link += "?" + r.URL.RawQuery
+			}
+			w.Header().Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", link))
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,28 @@
+package deprecation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	deprecatedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	handler := New(deprecatedAt, sunset, "/api/v1")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/subscriptions/export.csv?status=active", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, "@1790812800", rr.Header().Get("Deprecation"))
+	assert.Equal(t, "Thu, 01 Apr 2027 00:00:00 GMT", rr.Header().Get("Sunset"))
+	assert.Equal(t, `</api/v1/subscriptions/export.csv?status=active>; rel="successor-version"`, rr.Header().Get("Link"))
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Number of months calendar feed looks ahead (current month included)
+const CalendarHorizon = 12
+
+// CalendarEventKind is type of subscription calendar event
+type CalendarEventKind string
+
+const (
+	// Auto-renewing subscription is extended by billing period
+	CalendarRenewal CalendarEventKind = "renewal"
+
+	// Subscription ends (end date is exclusive, so event is on the first day of end month)
+	CalendarExpiry CalendarEventKind = "expiry"
+)
+
+// CalendarEvent is upcoming renewal or end of subscription
+type CalendarEvent struct {
+	Kind           CalendarEventKind `json:"kind"`
+	SubscriptionID int64             `json:"subscription_id"`
+	ServiceName    string            `json:"service_name"`
+	Month          Date              `json:"month"`
+
+	// Monthly price: price of renewed period or price of last month before expiry
+	Price Money `json:"price"`
+
+	// User part of price (equal to price if subscription is not shared)
+	Share Money `json:"share"`
+
+	// Months added by renewal (zero for expiry)
+	Period int `json:"period"`
+}
+
+// Get events of user subscriptions within CalendarHorizon months since specified one
+// (ordered by month and subscription id); cancelled and expired subscriptions have no events
+func CalendarEvents(subscriptions []Subscription, userId uuid.UUID, from Date) []CalendarEvent {
+	var events []CalendarEvent
+
+	last := from.AddDate(0, CalendarHorizon-1)
+
+	for i := 0; i < len(subscriptions); i++ {
+		sub := &subscriptions[i]
+
+		if sub.Status != StatusActive && sub.Status != StatusPaused {
+			continue
+		}
+
+		event := CalendarEvent{SubscriptionID: sub.ID, ServiceName: sub.ServiceName}
+
+		// 1.Renewals (the one of end date and every next period)
+		if sub.AutoRenew {
+			for _, renewal := range sub.PendingRenewals(last) {
+				if from.GreaterThan(renewal.PeriodStart) {
+					continue
+				}
+
+				event.Kind, event.Month, event.Price, event.Period = CalendarRenewal, renewal.PeriodStart, renewal.Price, sub.Period()
+				event.Share = sub.ShareOf(userId, event.Price)
+				events = append(events, event)
+			}
+
+			continue
+		}
+
+		// 2.End of subscription
+		if from.GreaterThan(sub.EndDate) || sub.EndDate.GreaterThan(last) {
+			continue
+		}
+
+		event.Kind, event.Month = CalendarExpiry, sub.EndDate
+		event.Price = sub.PriceAt(sub.EndDate.AddDate(0, -1))
+		event.Share = sub.ShareOf(userId, event.Price)
+		events = append(events, event)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].Month.EqualTo(events[j].Month) {
+			return events[j].Month.GreaterThan(events[i].Month)
+		}
+		return events[i].SubscriptionID < events[j].SubscriptionID
+	})
+
+	return events
+}
+
+// Get token of user calendar feed (hex HMAC-SHA256 of user id), so feed URL can be shared without exposing other feeds
+func CalendarToken(secret string, userId uuid.UUID) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(userId.String()))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Check token of user calendar feed in constant time
+func CheckCalendarToken(secret string, userId uuid.UUID, token string) bool {
+	return hmac.Equal([]byte(CalendarToken(secret, userId)), []byte(token))
+}
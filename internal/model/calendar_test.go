@@ -0,0 +1,85 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarEvents(t *testing.T) {
+	userId, memberId := uuid.New(), uuid.New()
+	from := Date{Month: 3, Year: 2026}
+
+	subscriptions := []Subscription{
+		// Renewed every 6 months, price rises before second renewal
+		{
+			ID: 1,
+			SubscriptionSpec: SubscriptionSpec{
+				ServiceName: "Netflix", Price: 1000, UserID: userId,
+				StartDate: Date{Month: 1, Year: 2026}, EndDate: Date{Month: 4, Year: 2026},
+				AutoRenew: true, BillingPeriod: 6,
+			},
+			Status:        StatusActive,
+			PriceSchedule: []PriceChange{{Price: 1200, EffectiveFrom: Date{Month: 9, Year: 2026}}},
+		},
+		// Shared subscription ending in horizon
+		{
+			ID: 2,
+			SubscriptionSpec: SubscriptionSpec{
+				ServiceName: "Apple One", Price: 900, UserID: userId,
+				StartDate: Date{Month: 1, Year: 2026}, EndDate: Date{Month: 5, Year: 2026},
+				Members: []Member{{UserID: userId, Weight: 2}, {UserID: memberId, Weight: 1}},
+			},
+			Status: StatusPaused,
+		},
+		// Ends after horizon
+		{
+			ID: 3,
+			SubscriptionSpec: SubscriptionSpec{
+				ServiceName: "Wink", Price: 300, UserID: userId,
+				StartDate: Date{Month: 1, Year: 2026}, EndDate: Date{Month: 3, Year: 2027},
+			},
+			Status: StatusActive,
+		},
+		// Cancelled
+		{
+			ID: 4,
+			SubscriptionSpec: SubscriptionSpec{
+				ServiceName: "Spotify", Price: 200, UserID: userId,
+				StartDate: Date{Month: 1, Year: 2026}, EndDate: Date{Month: 4, Year: 2026},
+				AutoRenew: true,
+			},
+			Status: StatusCancelled,
+		},
+	}
+
+	assert.Equal(t, []CalendarEvent{
+		{Kind: CalendarRenewal, SubscriptionID: 1, ServiceName: "Netflix", Month: Date{Month: 4, Year: 2026}, Price: 1000, Share: 1000, Period: 6},
+		{Kind: CalendarExpiry, SubscriptionID: 2, ServiceName: "Apple One", Month: Date{Month: 5, Year: 2026}, Price: 900, Share: 600},
+		{Kind: CalendarRenewal, SubscriptionID: 1, ServiceName: "Netflix", Month: Date{Month: 10, Year: 2026}, Price: 1200, Share: 1200, Period: 6},
+	}, CalendarEvents(subscriptions, userId, from))
+
+	// Member sees own share
+	events := CalendarEvents(subscriptions[1:2], memberId, from)
+	assert.Len(t, events, 1)
+	assert.Equal(t, Money(300), events[0].Share)
+
+	// Past events are dropped, horizon moves
+	events = CalendarEvents(subscriptions, userId, Date{Month: 5, Year: 2026})
+	assert.Len(t, events, 4)
+	assert.Equal(t, Date{Month: 3, Year: 2027}, events[2].Month)
+	assert.Equal(t, Date{Month: 4, Year: 2027}, events[3].Month)
+}
+
+func TestCalendarToken(t *testing.T) {
+	userId := uuid.New()
+
+	token := CalendarToken("secret", userId)
+	assert.Len(t, token, 64)
+
+	assert.True(t, CheckCalendarToken("secret", userId, token))
+	assert.False(t, CheckCalendarToken("another secret", userId, token))
+	assert.False(t, CheckCalendarToken("secret", uuid.New(), token))
+	assert.False(t, CheckCalendarToken("secret", userId, ""))
+}
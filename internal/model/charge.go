@@ -0,0 +1,121 @@
+package model
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Charge is billing ledger entry: amount billed to user for one month of subscription.
+// Ledger is append-only, so changed charges are corrected by entries with amount difference
+type Charge struct {
+	ID             int64     `json:"id"`
+	SubscriptionID int64     `json:"subscription_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	Month          Date      `json:"month"`
+	Amount         Money     `json:"amount"`
+	PostedAt       time.Time `json:"posted_at"`
+}
+
+// LedgerReport describes charges ledger rebuild
+type LedgerReport struct {
+	// Subscriptions (existing and deleted ones) checked against ledger
+	Subscriptions int64 `json:"subscriptions"`
+
+	// Correcting entries appended to ledger
+	Appended int64 `json:"appended"`
+}
+
+// Get charges of every billed month split between members (nothing is charged for free months and zero shares)
+func (s *Subscription) ExpectedCharges() []Charge {
+	var charges []Charge
+
+	for month := s.StartDate; s.EndDate.GreaterThan(month); month = month.AddDate(0, 1) {
+		amount := s.ChargeAt(month)
+		if amount == 0 {
+			continue
+		}
+
+		for _, share := range s.Split(amount) {
+			if share.Amount == 0 {
+				continue
+			}
+
+			charges = append(charges, Charge{SubscriptionID: s.ID, UserID: share.UserID, Month: month, Amount: share.Amount})
+		}
+	}
+
+	return charges
+}
+
+type chargeKey struct {
+	month  Date
+	userId uuid.UUID
+}
+
+// Get entries to append to ledger so that posted charges sum up to expected ones
+// for every month and user (ordered by month and user id)
+func ChargeAdjustments(posted, expected []Charge) ([]Charge, error) {
+	diff := make(map[chargeKey]Money)
+
+	for _, charge := range expected {
+		key := chargeKey{month: charge.Month, userId: charge.UserID}
+
+		var err error
+
+		diff[key], err = diff[key].Add(charge.Amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, charge := range posted {
+		key := chargeKey{month: charge.Month, userId: charge.UserID}
+
+		var err error
+
+		diff[key], err = diff[key].Add(-charge.Amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var adjustments []Charge
+	for key, amount := range diff {
+		if amount == 0 {
+			continue
+		}
+
+		adjustments = append(adjustments, Charge{UserID: key.userId, Month: key.month, Amount: amount})
+	}
+
+	sort.Slice(adjustments, func(i, j int) bool {
+		if !adjustments[i].Month.EqualTo(adjustments[j].Month) {
+			return adjustments[j].Month.GreaterThan(adjustments[i].Month)
+		}
+		return adjustments[i].UserID.String() < adjustments[j].UserID.String()
+	})
+
+	return adjustments, nil
+}
+
+// Sum charges billed to user (to all users if user is not set)
+func SumCharges(charges []Charge, userId uuid.UUID) (Money, error) {
+	var sum Money
+
+	for _, charge := range charges {
+		if userId != uuid.Nil && charge.UserID != userId {
+			continue
+		}
+
+		var err error
+
+		sum, err = sum.Add(charge.Amount)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return sum, nil
+}
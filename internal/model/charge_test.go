@@ -0,0 +1,83 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpectedCharges(t *testing.T) {
+	owner, member := uuid.New(), uuid.New()
+
+	subscription := Subscription{
+		ID: 1,
+		SubscriptionSpec: SubscriptionSpec{
+			Price:       1000,
+			UserID:      owner,
+			StartDate:   Date{Month: 1, Year: 2026},
+			EndDate:     Date{Month: 4, Year: 2026},
+			TrialMonths: 1,
+			Members:     []Member{{UserID: owner, Weight: 3}, {UserID: member, Weight: 1}},
+		},
+	}
+
+	// Free trial month is not charged, every other month is split between members
+	assert.Equal(t, []Charge{
+		{SubscriptionID: 1, UserID: owner, Month: Date{Month: 2, Year: 2026}, Amount: 750},
+		{SubscriptionID: 1, UserID: member, Month: Date{Month: 2, Year: 2026}, Amount: 250},
+		{SubscriptionID: 1, UserID: owner, Month: Date{Month: 3, Year: 2026}, Amount: 750},
+		{SubscriptionID: 1, UserID: member, Month: Date{Month: 3, Year: 2026}, Amount: 250},
+	}, subscription.ExpectedCharges())
+
+	cost, err := subscription.Cost()
+	assert.NoError(t, err)
+
+	sum, err := SumCharges(subscription.ExpectedCharges(), uuid.Nil)
+	assert.NoError(t, err)
+	assert.Equal(t, cost, sum)
+
+	sum, err = SumCharges(subscription.ExpectedCharges(), member)
+	assert.NoError(t, err)
+	assert.Equal(t, Money(500), sum)
+}
+
+func TestChargeAdjustments(t *testing.T) {
+	userId := uuid.New()
+	jan, feb, mar := Date{Month: 1, Year: 2026}, Date{Month: 2, Year: 2026}, Date{Month: 3, Year: 2026}
+
+	posted := []Charge{
+		{UserID: userId, Month: jan, Amount: 100},
+		{UserID: userId, Month: feb, Amount: 100},
+		// Earlier correction
+		{UserID: userId, Month: feb, Amount: 50},
+	}
+
+	// 1.Nothing to append if ledger is up to date
+	adjustments, err := ChargeAdjustments(posted, []Charge{
+		{UserID: userId, Month: jan, Amount: 100},
+		{UserID: userId, Month: feb, Amount: 150},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, adjustments)
+
+	// 2.Changed, new and removed months are corrected
+	adjustments, err = ChargeAdjustments(posted, []Charge{
+		{UserID: userId, Month: feb, Amount: 120},
+		{UserID: userId, Month: mar, Amount: 120},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []Charge{
+		{UserID: userId, Month: jan, Amount: -100},
+		{UserID: userId, Month: feb, Amount: -30},
+		{UserID: userId, Month: mar, Amount: 120},
+	}, adjustments)
+
+	// 3.Deleted subscription charges are reversed
+	adjustments, err = ChargeAdjustments(posted, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []Charge{
+		{UserID: userId, Month: jan, Amount: -100},
+		{UserID: userId, Month: feb, Amount: -150},
+	}, adjustments)
+}
@@ -0,0 +1,60 @@
+package model
+
+type DiscountKind string
+
+const (
+	DiscountPercent DiscountKind = "percent"
+	DiscountFixed   DiscountKind = "fixed"
+)
+
+// Discount describes promotional discount of subscription monthly price
+type Discount struct {
+	ID   int64        `json:"id"`
+	Kind DiscountKind `json:"kind"`
+
+	// Percent off price (1-100), only for percent discounts
+	Percent int `json:"percent,omitempty"`
+
+	// Amount off price, only for fixed discounts
+	Amount Money `json:"amount,omitempty"`
+
+	// Optional validity range: first month inclusive, last month exclusive (like subscription end date)
+	ValidFrom *Date `json:"valid_from,omitempty"`
+	ValidTo   *Date `json:"valid_to,omitempty"`
+}
+
+// Check if discount is valid in specified month
+func (d *Discount) ValidAt(month Date) bool {
+	if d.ValidFrom != nil && d.ValidFrom.GreaterThan(month) {
+		return false
+	}
+	if d.ValidTo != nil && !d.ValidTo.GreaterThan(month) {
+		return false
+	}
+	return true
+}
+
+// Apply discount to price of specified month (result is never negative)
+func (d *Discount) Apply(price Money, month Date) Money {
+	if !d.ValidAt(month) || price <= 0 {
+		return price
+	}
+
+	var off Money
+
+	switch d.Kind {
+	case DiscountPercent:
+		// Split to avoid overflow: price * percent / 100 without big intermediate value
+		off = price/100*Money(d.Percent) + price%100*Money(d.Percent)/100
+	case DiscountFixed:
+		off = d.Amount
+	}
+
+	if off >= price {
+		return 0
+	}
+	if off < 0 {
+		return price
+	}
+	return price - off
+}
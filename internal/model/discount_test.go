@@ -0,0 +1,68 @@
+package model
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscountApply(t *testing.T) {
+	march := Date{Month: 3, Year: 2026}
+	june := Date{Month: 6, Year: 2026}
+
+	tests := []struct {
+		name     string
+		discount Discount
+		price    Money
+		month    Date
+		expected Money
+	}{
+		{name: "Percent", discount: Discount{Kind: DiscountPercent, Percent: 25}, price: 40000, month: march, expected: 30000},
+		{name: "Percent rounds discount down", discount: Discount{Kind: DiscountPercent, Percent: 50}, price: 999, month: march, expected: 500},
+		{name: "Percent of huge price", discount: Discount{Kind: DiscountPercent, Percent: 50}, price: math.MaxInt64, month: march, expected: math.MaxInt64 - math.MaxInt64/2},
+		{name: "Full percent", discount: Discount{Kind: DiscountPercent, Percent: 100}, price: 40000, month: march, expected: 0},
+		{name: "Fixed", discount: Discount{Kind: DiscountFixed, Amount: 5000}, price: 40000, month: march, expected: 35000},
+		{name: "Fixed greater than price", discount: Discount{Kind: DiscountFixed, Amount: 50000}, price: 40000, month: march, expected: 0},
+		{name: "Before valid range", discount: Discount{Kind: DiscountFixed, Amount: 5000, ValidFrom: &june}, price: 40000, month: march, expected: 40000},
+		{name: "Inside valid range", discount: Discount{Kind: DiscountFixed, Amount: 5000, ValidFrom: &march, ValidTo: &june}, price: 40000, month: march, expected: 35000},
+		{name: "Valid to is exclusive", discount: Discount{Kind: DiscountFixed, Amount: 5000, ValidTo: &june}, price: 40000, month: june, expected: 40000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.discount.Apply(tt.price, tt.month))
+		})
+	}
+}
+
+func TestCostWithTrialAndDiscounts(t *testing.T) {
+	validTo := Date{Month: 7, Year: 2026}
+
+	sub := Subscription{
+		SubscriptionSpec: SubscriptionSpec{
+			Price:       40000,
+			StartDate:   Date{Month: 1, Year: 2026},
+			EndDate:     Date{Month: 1, Year: 2027},
+			TrialMonths: 3,
+		},
+		Discounts: []Discount{
+			{Kind: DiscountPercent, Percent: 50, ValidTo: &validTo},
+		},
+	}
+
+	// 3 free months, 3 months with 50% off and 6 months of full price
+	cost, err := sub.Cost()
+	assert.NoError(t, err)
+	assert.Equal(t, Money(3*20000+6*40000), cost)
+
+	// Discounted trial
+	sub.TrialPrice = 10000
+	cost, err = sub.Cost()
+	assert.NoError(t, err)
+	assert.Equal(t, Money(3*10000+3*20000+6*40000), cost)
+
+	assert.True(t, sub.InTrial(Date{Month: 3, Year: 2026}))
+	assert.False(t, sub.InTrial(Date{Month: 4, Year: 2026}))
+	assert.False(t, sub.InTrial(Date{Month: 12, Year: 2025}))
+}
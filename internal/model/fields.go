@@ -0,0 +1,94 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Names of subscription fields which can be requested in response (sparse fieldset)
+const (
+	FieldID            = "id"
+	FieldServiceName   = "service_name"
+	FieldServiceID     = "service_id"
+	FieldPrice         = "price"
+	FieldUserID        = "user_id"
+	FieldStartDate     = "start_date"
+	FieldEndDate       = "end_date"
+	FieldTrialMonths   = "trial_months"
+	FieldTrialPrice    = "trial_price"
+	FieldStatus        = "status"
+	FieldStatusHistory = "status_history"
+	FieldPriceSchedule = "price_schedule"
+	FieldDiscounts     = "discounts"
+	FieldMembers       = "members"
+	FieldCategory      = "category"
+	FieldTags          = "tags"
+	FieldMetadata      = "metadata"
+	FieldAutoRenew     = "auto_renew"
+	FieldBillingPeriod = "billing_period"
+	FieldRenewals      = "renewals"
+	FieldCreatedAt     = "created_at"
+	FieldUpdatedAt     = "updated_at"
+
+	// Lifecycle status in read response (status field there is response status)
+	FieldSubscriptionStatus = "subscription_status"
+)
+
+// Fields of subscription read by id (in response order)
+var ReadFields = []string{
+	FieldID, FieldServiceName, FieldServiceID, FieldPrice, FieldUserID, FieldStartDate, FieldEndDate,
+	FieldTrialMonths, FieldTrialPrice, FieldSubscriptionStatus, FieldStatusHistory, FieldPriceSchedule, FieldDiscounts,
+	FieldMembers, FieldCategory, FieldTags, FieldMetadata, FieldAutoRenew, FieldBillingPeriod, FieldRenewals,
+	FieldCreatedAt, FieldUpdatedAt,
+}
+
+// Fields of subscription in list (in response order)
+var ListFields = []string{
+	FieldID, FieldServiceName, FieldPrice, FieldUserID, FieldStartDate, FieldEndDate, FieldTrialMonths,
+	FieldTrialPrice, FieldStatus, FieldCategory, FieldTags, FieldMetadata, FieldAutoRenew, FieldBillingPeriod,
+	FieldCreatedAt, FieldUpdatedAt,
+}
+
+// FieldSet is set of subscription fields requested by client, nil means all fields
+type FieldSet map[string]bool
+
+// Has checks if field is requested
+func (f FieldSet) Has(field string) bool {
+	return f == nil || f[field]
+}
+
+// ParseFieldSet parses comma separated field names (empty value means all fields);
+// error is *ValidationError listing allowed fields for every unknown one
+func ParseFieldSet(param, value string, allowed []string) (FieldSet, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	known := make(map[string]bool, len(allowed))
+	for _, field := range allowed {
+		known[field] = true
+	}
+
+	var v Validator
+	fields := FieldSet{}
+
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+
+		switch {
+		case field == "":
+			v.Fail(param, CodeInvalidValue, "empty field name in fields list", nil)
+		case !known[field]:
+			v.Fail(param, CodeInvalidValue,
+				fmt.Sprintf("unknown field %q, allowed fields: %s", field, strings.Join(allowed, ", ")), nil)
+		default:
+			fields[field] = true
+		}
+	}
+
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
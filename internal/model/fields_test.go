@@ -0,0 +1,37 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFieldSet(t *testing.T) {
+	fields, err := ParseFieldSet("fields", "", ListFields)
+	require.NoError(t, err)
+	assert.Nil(t, fields)
+	assert.True(t, fields.Has(FieldTags))
+
+	fields, err = ParseFieldSet("fields", "id, service_name,price,id", ListFields)
+	require.NoError(t, err)
+	assert.Equal(t, FieldSet{FieldID: true, FieldServiceName: true, FieldPrice: true}, fields)
+	assert.True(t, fields.Has(FieldPrice))
+	assert.False(t, fields.Has(FieldTags))
+
+	// Details are not listed
+	_, err = ParseFieldSet("fields", "id,renewals,,colour", ListFields)
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Len(t, validationErr.Fields, 3)
+	assert.Equal(t, "fields", validationErr.Fields[0].Field)
+	assert.Equal(t, CodeInvalidValue, validationErr.Fields[0].Code)
+	assert.Contains(t, validationErr.Fields[0].Message, `unknown field "renewals", allowed fields: id, service_name, price,`)
+	assert.Equal(t, "empty field name in fields list", validationErr.Fields[1].Message)
+	assert.Contains(t, validationErr.Fields[2].Message, `unknown field "colour"`)
+
+	fields, err = ParseFieldSet("fields", "renewals", ReadFields)
+	require.NoError(t, err)
+	assert.True(t, fields.Has(FieldRenewals))
+}
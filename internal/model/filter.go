@@ -0,0 +1,18 @@
+package model
+
+// SubscriptionFilter contains optional criteria of subscriptions selection (nil means no restriction)
+type SubscriptionFilter struct {
+	Status *Status
+
+	// Normalized category
+	Category *string
+
+	// Normalized tag, subscription matches if it has the tag among others
+	Tag *string
+
+	// Values of top level metadata keys (scalar value is matched by its JSON text without quotes for strings)
+	Metadata map[string]string
+
+	// Parsed filter expression (see ParseFilterExpr)
+	Expr FilterExpr
+}
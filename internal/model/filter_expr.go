@@ -0,0 +1,709 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// Filter expression selects subscriptions by conditions combined with and, or, not and parentheses, e.g.
+//
+//	price >= 300 and service_name ~ "yan*" and active_in(2025-01, 2025-06)
+//
+// Grammar (keywords are case insensitive):
+//
+//	expr       = term { "or" term }
+//	term       = factor { "and" factor }
+//	factor     = "not" factor | "(" expr ")" | comparison | call
+//	comparison = field operator value
+//	operator   = "=" | "!=" | "<" | "<=" | ">" | ">=" | "~"
+//	call       = function "(" [ value { "," value } ] ")"
+//	value      = quoted string (with \" and \\ escapes) | word (number, date, identifier)
+//
+// Amounts (price, trial_price) are written as in request body: integer is amount in minor units and
+// quoted string is decimal amount, so price >= 300 and price >= "3.00" are the same.
+//
+// Operator ~ matches glob pattern ignoring case: * is any string, ? is any character.
+
+// Limits of filter expression, so parsing and compiled SQL stay small
+const (
+	MaxFilterExprLength = 1000
+	maxFilterExprDepth  = 32
+)
+
+// FilterExprError is syntax or validation error of filter expression at position (1-based, in characters)
+type FilterExprError struct {
+	Pos int
+	Msg string
+}
+
+func (e *FilterExprError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
+// FilterOp is comparison operator of filter expression
+type FilterOp string
+
+const (
+	FilterEq    FilterOp = "="
+	FilterNe    FilterOp = "!="
+	FilterLt    FilterOp = "<"
+	FilterLe    FilterOp = "<="
+	FilterGt    FilterOp = ">"
+	FilterGe    FilterOp = ">="
+	FilterMatch FilterOp = "~"
+)
+
+// FilterExpr is node of parsed and validated filter expression
+type FilterExpr interface {
+	// Canonical text of expression (it is parsed to the same expression)
+	String() string
+}
+
+// FilterAnd matches subscriptions matched by both expressions
+type FilterAnd struct {
+	Left, Right FilterExpr
+}
+
+// FilterOr matches subscriptions matched by any of expressions
+type FilterOr struct {
+	Left, Right FilterExpr
+}
+
+// FilterNot matches subscriptions not matched by expression
+type FilterNot struct {
+	Expr FilterExpr
+}
+
+// FilterComparison compares field with value
+type FilterComparison struct {
+	Field string
+	Op    FilterOp
+
+	// Value of field type: int64, Money, Date, string, bool, Status or uuid.UUID (glob pattern string for ~)
+	Value any
+}
+
+// FilterActiveIn matches subscriptions whose period overlaps months From..To (both included)
+type FilterActiveIn struct {
+	From, To Date
+}
+
+// Kinds of filter fields values
+type filterFieldKind int
+
+const (
+	filterInt filterFieldKind = iota
+	filterMoney
+	filterDate
+	filterText
+	filterLabel
+	filterBool
+	filterStatus
+	filterUUID
+)
+
+// Fields allowed in filter expression (tag matches subscriptions having such tag among others)
+var filterFields = map[string]filterFieldKind{
+	"id":             filterInt,
+	"service_name":   filterText,
+	"service_id":     filterInt,
+	"price":          filterMoney,
+	"user_id":        filterUUID,
+	"start_date":     filterDate,
+	"end_date":       filterDate,
+	"trial_months":   filterInt,
+	"trial_price":    filterMoney,
+	"status":         filterStatus,
+	"category":       filterLabel,
+	"tag":            filterLabel,
+	"auto_renew":     filterBool,
+	"billing_period": filterInt,
+}
+
+// Functions allowed in filter expression
+const filterActiveIn = "active_in"
+
+// Operators allowed for kind of field
+func filterOps(kind filterFieldKind) []FilterOp {
+	switch kind {
+	case filterInt, filterMoney, filterDate:
+		return []FilterOp{FilterEq, FilterNe, FilterLt, FilterLe, FilterGt, FilterGe}
+	case filterText, filterLabel:
+		return []FilterOp{FilterEq, FilterNe, FilterMatch}
+	default:
+		return []FilterOp{FilterEq, FilterNe}
+	}
+}
+
+// FilterExprFields returns names of fields allowed in filter expression (sorted)
+func FilterExprFields() []string {
+	names := make([]string, 0, len(filterFields))
+	for name := range filterFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ParseFilterExpr parses filter expression and validates its fields, operators and values;
+// error is *FilterExprError with position of the problem
+func ParseFilterExpr(input string) (FilterExpr, error) {
+	if len([]rune(input)) > MaxFilterExprLength {
+		return nil, &FilterExprError{Pos: MaxFilterExprLength + 1, Msg: fmt.Sprintf("filter is longer than %d characters", MaxFilterExprLength)}
+	}
+
+	return parseFilterExpr(input)
+}
+
+func parseFilterExpr(input string) (FilterExpr, error) {
+	tokens, err := lexFilterExpr(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := filterParser{tokens: tokens}
+
+	if p.peek().kind == tokenEOF {
+		return nil, &FilterExprError{Pos: p.peek().pos, Msg: "empty filter"}
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, &FilterExprError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s", tok.describe())}
+	}
+
+	return expr, nil
+}
+
+// LikePattern converts glob pattern of ~ operator to SQL LIKE pattern with \ escape
+func LikePattern(glob string) string {
+	var b strings.Builder
+
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteByte('%')
+		case '?':
+			b.WriteByte('_')
+		case '%', '_', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// Lexer
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOp
+	tokenLParen
+	tokenRParen
+	tokenComma
+	tokenAnd
+	tokenOr
+	tokenNot
+)
+
+type filterToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// Token description for error messages
+func (t filterToken) describe() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of filter"
+	case tokenString:
+		return strconv.Quote(t.text)
+	default:
+		return "'" + t.text + "'"
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.' || r == ':'
+}
+
+func lexFilterExpr(input string) ([]filterToken, error) {
+	runes := []rune(input)
+	tokens := []filterToken{}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: tokenLParen, text: "(", pos: pos})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: tokenRParen, text: ")", pos: pos})
+			i++
+
+		case r == ',':
+			tokens = append(tokens, filterToken{kind: tokenComma, text: ",", pos: pos})
+			i++
+
+		case r == '=' || r == '~':
+			tokens = append(tokens, filterToken{kind: tokenOp, text: string(r), pos: pos})
+			i++
+
+		case r == '<' || r == '>' || r == '!':
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, &FilterExprError{Pos: pos, Msg: "unexpected '!', did you mean '!='"}
+			}
+
+			tokens = append(tokens, filterToken{kind: tokenOp, text: op, pos: pos})
+			i += len(op)
+
+		case r == '"':
+			var b strings.Builder
+
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, &FilterExprError{Pos: pos, Msg: "unterminated string"}
+				}
+				if runes[i] == '"' {
+					i++
+					break
+				}
+				if runes[i] == '\\' {
+					if i+1 >= len(runes) || (runes[i+1] != '"' && runes[i+1] != '\\') {
+						return nil, &FilterExprError{Pos: i + 1, Msg: `invalid escape in string, only \" and \\ are allowed`}
+					}
+					i++
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+
+			tokens = append(tokens, filterToken{kind: tokenString, text: b.String(), pos: pos})
+
+		case isWordRune(r):
+			start := i
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+
+			word := string(runes[start:i])
+
+			kind := tokenWord
+			switch strings.ToLower(word) {
+			case "and":
+				kind = tokenAnd
+			case "or":
+				kind = tokenOr
+			case "not":
+				kind = tokenNot
+			}
+
+			tokens = append(tokens, filterToken{kind: kind, text: word, pos: pos})
+
+		default:
+			return nil, &FilterExprError{Pos: pos, Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+
+	return append(tokens, filterToken{kind: tokenEOF, pos: len(runes) + 1}), nil
+}
+
+// Parser
+
+type filterParser struct {
+	tokens []filterToken
+	next   int
+	depth  int
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.next]
+}
+
+func (p *filterParser) take() filterToken {
+	tok := p.tokens[p.next]
+	if tok.kind != tokenEOF {
+		p.next++
+	}
+
+	return tok
+}
+
+func (p *filterParser) expect(kind tokenKind, what string) (filterToken, error) {
+	tok := p.take()
+	if tok.kind != kind {
+		return tok, &FilterExprError{Pos: tok.pos, Msg: fmt.Sprintf("expected %s, got %s", what, tok.describe())}
+	}
+
+	return tok, nil
+}
+
+func (p *filterParser) parseOr() (FilterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOr {
+		p.take()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &FilterOr{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (FilterExpr, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenAnd {
+		p.take()
+
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = &FilterAnd{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseFactor() (FilterExpr, error) {
+	tok := p.peek()
+
+	// Nested not and parentheses are limited to keep recursion shallow
+	if tok.kind == tokenNot || tok.kind == tokenLParen {
+		if p.depth == maxFilterExprDepth {
+			return nil, &FilterExprError{Pos: tok.pos, Msg: fmt.Sprintf("filter is nested deeper than %d levels", maxFilterExprDepth)}
+		}
+
+		p.depth++
+		defer func() { p.depth-- }()
+	}
+
+	switch tok.kind {
+	case tokenNot:
+		p.take()
+
+		expr, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return &FilterNot{Expr: expr}, nil
+
+	case tokenLParen:
+		p.take()
+
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+
+	case tokenWord:
+		p.take()
+
+		if p.peek().kind == tokenLParen {
+			return p.parseCall(tok)
+		}
+		return p.parseComparison(tok)
+
+	default:
+		return nil, &FilterExprError{Pos: tok.pos, Msg: fmt.Sprintf("expected field, function or '(', got %s", tok.describe())}
+	}
+}
+
+func (p *filterParser) parseComparison(field filterToken) (FilterExpr, error) {
+	kind, ok := filterFields[field.text]
+	if !ok {
+		return nil, &FilterExprError{
+			Pos: field.pos,
+			Msg: fmt.Sprintf("unknown field %q, allowed fields: %s", field.text, strings.Join(FilterExprFields(), ", ")),
+		}
+	}
+
+	opTok, err := p.expect(tokenOp, "operator")
+	if err != nil {
+		return nil, err
+	}
+
+	op := FilterOp(opTok.text)
+	allowed := filterOps(kind)
+	if !containsOp(allowed, op) {
+		names := make([]string, 0, len(allowed))
+		for _, op := range allowed {
+			names = append(names, string(op))
+		}
+
+		return nil, &FilterExprError{
+			Pos: opTok.pos,
+			Msg: fmt.Sprintf("operator %s is not allowed for %s, allowed operators: %s", op, field.text, strings.Join(names, " ")),
+		}
+	}
+
+	valueTok, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := filterValue(field.text, kind, op, valueTok)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FilterComparison{Field: field.text, Op: op, Value: value}, nil
+}
+
+func (p *filterParser) parseCall(name filterToken) (FilterExpr, error) {
+	if name.text != filterActiveIn {
+		return nil, &FilterExprError{Pos: name.pos, Msg: fmt.Sprintf("unknown function %q, allowed functions: %s", name.text, filterActiveIn)}
+	}
+
+	p.take()
+
+	var args []filterToken
+	if p.peek().kind != tokenRParen {
+		for {
+			arg, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.take()
+		}
+	}
+
+	if _, err := p.expect(tokenRParen, "')'"); err != nil {
+		return nil, err
+	}
+
+	if len(args) != 2 {
+		return nil, &FilterExprError{Pos: name.pos, Msg: fmt.Sprintf("%s expects 2 arguments (from and to months), got %d", filterActiveIn, len(args))}
+	}
+
+	from, err := filterValue(filterActiveIn, filterDate, FilterEq, args[0])
+	if err != nil {
+		return nil, err
+	}
+	to, err := filterValue(filterActiveIn, filterDate, FilterEq, args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	fromDate, toDate := from.(Date), to.(Date)
+	if fromDate.GreaterThan(toDate) {
+		return nil, &FilterExprError{Pos: args[1].pos, Msg: fmt.Sprintf("%s period ends before it starts", filterActiveIn)}
+	}
+
+	return &FilterActiveIn{From: fromDate, To: toDate}, nil
+}
+
+func (p *filterParser) parseValue() (filterToken, error) {
+	tok := p.take()
+	if tok.kind != tokenWord && tok.kind != tokenString {
+		return tok, &FilterExprError{Pos: tok.pos, Msg: fmt.Sprintf("expected value, got %s", tok.describe())}
+	}
+
+	return tok, nil
+}
+
+func containsOp(ops []FilterOp, op FilterOp) bool {
+	for _, allowed := range ops {
+		if allowed == op {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Expected format of amount value in error messages
+const moneyFormat = "integer amount in minor units or quoted decimal amount"
+
+// Convert value token to type of field
+func filterValue(field string, kind filterFieldKind, op FilterOp, tok filterToken) (any, error) {
+	invalid := func(format string) error {
+		return &FilterExprError{Pos: tok.pos, Msg: fmt.Sprintf("invalid value %s for %s, expected %s", tok.describe(), field, format)}
+	}
+
+	// Patterns are compared with normalized values ignoring case
+	if op == FilterMatch {
+		return strings.ToLower(tok.text), nil
+	}
+
+	switch kind {
+	case filterInt:
+		n, err := strconv.ParseInt(tok.text, 10, 64)
+		if err != nil {
+			return nil, invalid("integer")
+		}
+		return n, nil
+
+	case filterMoney:
+		if tok.kind == tokenString {
+			m, err := MoneyFromString(tok.text, DefaultCurrency)
+			if err != nil {
+				return nil, invalid(moneyFormat)
+			}
+			return m, nil
+		}
+
+		n, err := strconv.ParseInt(tok.text, 10, 64)
+		if err != nil {
+			return nil, invalid(moneyFormat)
+		}
+		return Money(n), nil
+
+	case filterDate:
+		t, err := time.Parse("2006-01", tok.text)
+		if err != nil {
+			return nil, invalid("month in YYYY-MM format")
+		}
+		return Date{Month: int(t.Month()), Year: t.Year()}, nil
+
+	case filterLabel:
+		label, err := NormalizeLabel(tok.text)
+		if err != nil {
+			return nil, invalid("label")
+		}
+		return label, nil
+
+	case filterBool:
+		switch strings.ToLower(tok.text) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, invalid("true or false")
+
+	case filterStatus:
+		status, err := StatusFromString(tok.text)
+		if err != nil {
+			return nil, invalid("active, paused, cancelled or expired")
+		}
+		return status, nil
+
+	case filterUUID:
+		id, err := uuid.Parse(tok.text)
+		if err != nil {
+			return nil, invalid("UUID")
+		}
+		return id, nil
+
+	default:
+		return tok.text, nil
+	}
+}
+
+// Canonical text
+
+func (e *FilterAnd) String() string {
+	_, leftOr := e.Left.(*FilterOr)
+	return filterOperand(e.Left, leftOr) + " and " + filterOperand(e.Right, !isFilterComparison(e.Right))
+}
+
+func (e *FilterOr) String() string {
+	_, rightOr := e.Right.(*FilterOr)
+	return filterOperand(e.Left, false) + " or " + filterOperand(e.Right, rightOr)
+}
+
+func (e *FilterNot) String() string {
+	return "not " + filterOperand(e.Expr, !isFilterComparison(e.Expr))
+}
+
+func (e *FilterComparison) String() string {
+	return e.Field + " " + string(e.Op) + " " + formatFilterValue(e.Value)
+}
+
+func (e *FilterActiveIn) String() string {
+	return fmt.Sprintf("%s(%04d-%02d, %04d-%02d)", filterActiveIn, e.From.Year, e.From.Month, e.To.Year, e.To.Month)
+}
+
+// Check if expression is not and/or, so it never needs parentheses
+func isFilterComparison(expr FilterExpr) bool {
+	switch expr.(type) {
+	case *FilterAnd, *FilterOr:
+		return false
+	default:
+		return true
+	}
+}
+
+// Text of and/or operand; operand of lower precedence and right operand of the same kind are parenthesized,
+// as parser groups chains from the left
+func filterOperand(expr FilterExpr, parenthesize bool) string {
+	if parenthesize {
+		return "(" + expr.String() + ")"
+	}
+
+	return expr.String()
+}
+
+func formatFilterValue(value any) string {
+	switch v := value.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case Money:
+		return quoteFilterString(v.String())
+	case Date:
+		return fmt.Sprintf("%04d-%02d", v.Year, v.Month)
+	case bool:
+		return strconv.FormatBool(v)
+	case Status:
+		return quoteFilterString(string(v))
+	case uuid.UUID:
+		return quoteFilterString(v.String())
+	default:
+		return quoteFilterString(fmt.Sprint(v))
+	}
+}
+
+func quoteFilterString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
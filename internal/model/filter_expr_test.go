@@ -0,0 +1,145 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilterExpr(t *testing.T) {
+	expr, err := ParseFilterExpr(`price>=300 and service_name~"yan*" and active_in(2025-01,2025-06)`)
+	require.NoError(t, err)
+
+	assert.Equal(t, &FilterAnd{
+		Left: &FilterAnd{
+			Left:  &FilterComparison{Field: "price", Op: FilterGe, Value: Money(300)},
+			Right: &FilterComparison{Field: "service_name", Op: FilterMatch, Value: "yan*"},
+		},
+		Right: &FilterActiveIn{From: Date{Month: 1, Year: 2025}, To: Date{Month: 6, Year: 2025}},
+	}, expr)
+	assert.Equal(t, `price >= "3.00" and service_name ~ "yan*" and active_in(2025-01, 2025-06)`, expr.String())
+
+	// Precedence: not, and, or; keywords ignore case
+	expr, err = ParseFilterExpr(`NOT status = paused OR tag = Family AND auto_renew = TRUE`)
+	require.NoError(t, err)
+
+	assert.Equal(t, &FilterOr{
+		Left: &FilterNot{Expr: &FilterComparison{Field: "status", Op: FilterEq, Value: StatusPaused}},
+		Right: &FilterAnd{
+			Left:  &FilterComparison{Field: "tag", Op: FilterEq, Value: "family"},
+			Right: &FilterComparison{Field: "auto_renew", Op: FilterEq, Value: true},
+		},
+	}, expr)
+
+	userID := uuid.New()
+
+	cases := []struct {
+		input string
+		value any
+	}{
+		{input: `id != 7`, value: int64(7)},
+		{input: `end_date < 2026-03`, value: Date{Month: 3, Year: 2026}},
+		{input: `user_id = ` + userID.String(), value: userID},
+		{input: `service_name = "Yandex \"Plus\""`, value: `Yandex "Plus"`},
+		{input: `category ~ "Vid?o"`, value: "vid?o"},
+		{input: `trial_price = 0`, value: Money(0)},
+		{input: `price < "4.99"`, value: Money(499)},
+	}
+
+	for _, tc := range cases {
+		expr, err := ParseFilterExpr(tc.input)
+		require.NoError(t, err, tc.input)
+
+		comparison, ok := expr.(*FilterComparison)
+		require.True(t, ok, tc.input)
+		assert.Equal(t, tc.value, comparison.Value, tc.input)
+	}
+}
+
+func TestParseFilterExprErrors(t *testing.T) {
+	cases := []struct {
+		input string
+		pos   int
+		msg   string
+	}{
+		{input: ``, pos: 1, msg: "empty filter"},
+		{input: `price >= `, pos: 10, msg: "expected value, got end of filter"},
+		{input: `price 300`, pos: 7, msg: "expected operator, got '300'"},
+		{input: `colour = red`, pos: 1, msg: `unknown field "colour", allowed fields: auto_renew, billing_period,`},
+		{input: `price ~ "3*"`, pos: 7, msg: "operator ~ is not allowed for price, allowed operators: = != < <= > >="},
+		{input: `id = 1 and price > cheap`, pos: 20, msg: `invalid value 'cheap' for price, expected integer amount in minor units or quoted decimal amount`},
+		{input: `price > 3.00`, pos: 9, msg: `invalid value '3.00' for price`},
+		{input: `price > "3.001"`, pos: 9, msg: `invalid value "3.001" for price`},
+		{input: `status = gone`, pos: 10, msg: "invalid value 'gone' for status"},
+		{input: `start_date > 03-2025`, pos: 14, msg: "expected month in YYYY-MM format"},
+		{input: `(id = 1 or id = 2`, pos: 18, msg: "expected ')', got end of filter"},
+		{input: `id = 1 id = 2`, pos: 8, msg: "unexpected 'id'"},
+		{input: `id = 1 & id = 2`, pos: 8, msg: "unexpected character '&'"},
+		{input: `id ! 1`, pos: 4, msg: "unexpected '!'"},
+		{input: `service_name = "Netflix`, pos: 16, msg: "unterminated string"},
+		{input: `service_name = "a\n"`, pos: 18, msg: "invalid escape in string"},
+		{input: `recent(2025-01)`, pos: 1, msg: `unknown function "recent", allowed functions: active_in`},
+		{input: `active_in(2025-01)`, pos: 1, msg: "active_in expects 2 arguments"},
+		{input: `active_in(2025-06, 2025-01)`, pos: 20, msg: "active_in period ends before it starts"},
+		{input: `and id = 1`, pos: 1, msg: "expected field, function or '(', got 'and'"},
+		{input: strings.Repeat("(", 40) + "id = 1" + strings.Repeat(")", 40), pos: 33, msg: "nested deeper than 32 levels"},
+		{input: `id = ` + strings.Repeat("1", MaxFilterExprLength), pos: MaxFilterExprLength + 1, msg: "filter is longer than"},
+	}
+
+	for _, tc := range cases {
+		_, err := ParseFilterExpr(tc.input)
+
+		var exprErr *FilterExprError
+		require.ErrorAs(t, err, &exprErr, tc.input)
+		assert.Equal(t, tc.pos, exprErr.Pos, tc.input)
+		assert.Contains(t, exprErr.Msg, tc.msg, tc.input)
+	}
+}
+
+func TestLikePattern(t *testing.T) {
+	assert.Equal(t, `yan%`, LikePattern("yan*"))
+	assert.Equal(t, `_0\%\_off\\`, LikePattern(`?0%_off\`))
+}
+
+func FuzzParseFilterExpr(f *testing.F) {
+	seeds := []string{
+		`price>=300 and service_name~"yan*" and active_in(2025-01,2025-06)`,
+		`not (status = paused or status = cancelled) and tag = family`,
+		`a or b and (c or d)`,
+		`service_name = "say \"hi\" \\ bye"`,
+		`user_id != 4b6f1d7e-2a8e-4f55-9d1a-5b7c0e0f1a2b or auto_renew = false`,
+		`trial_price < "0.5" or not not id = -1`,
+		`category ~ "?ideo" and end_date <= 2030-12`,
+		`id = 1 or (id = 2 or id = 3)`,
+		`(((id = 1)))`,
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		expr, err := ParseFilterExpr(input)
+		if err != nil {
+			var exprErr *FilterExprError
+			if !assert.ErrorAs(t, err, &exprErr) {
+				return
+			}
+
+			// Position points into filter or just after it
+			assert.GreaterOrEqual(t, exprErr.Pos, 1)
+			assert.LessOrEqual(t, exprErr.Pos, len([]rune(input))+1)
+
+			return
+		}
+
+		// Canonical text is parsed to the same expression (it may be longer than original one)
+		reparsed, err := parseFilterExpr(expr.String())
+		if assert.NoError(t, err, expr.String()) {
+			assert.Equal(t, expr, reparsed)
+			assert.Equal(t, expr.String(), reparsed.String())
+		}
+	})
+}
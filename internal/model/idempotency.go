@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+)
+
+// IdempotencyRecord is request made with Idempotency-Key and its response replayed on retries
+type IdempotencyRecord struct {
+	Key string
+
+	// Hash of the first request (retries must have the same one)
+	RequestHash string
+
+	// Saved response, Status is 0 while the first request is in progress
+	Status      int
+	ContentType string
+	Body        []byte
+
+	ExpiresAt time.Time
+}
+
+// Check if response of the first request is saved
+func (r *IdempotencyRecord) Completed() bool {
+	return r.Status != 0
+}
@@ -0,0 +1,50 @@
+package model
+
+import "errors"
+
+var ErrConflictPolicyInvalid = errors.New("conflict policy is invalid")
+
+// ConflictPolicy defines handling of imported subscription which already exists (same service and user)
+type ConflictPolicy string
+
+const (
+	// Existing subscription is kept, row is skipped
+	ConflictSkip ConflictPolicy = "skip"
+
+	// Existing subscription gets row data (price schedule, discounts and history are kept)
+	ConflictUpdate ConflictPolicy = "update"
+
+	// Row fails, other rows are imported
+	ConflictFail ConflictPolicy = "fail"
+)
+
+// Construct from string
+func ConflictPolicyFromString(str string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(str); policy {
+	case ConflictSkip, ConflictUpdate, ConflictFail:
+		return policy, nil
+	}
+
+	return "", ErrConflictPolicyInvalid
+}
+
+// ImportOutcome is result of one imported row
+type ImportOutcome string
+
+const (
+	ImportCreated ImportOutcome = "created"
+	ImportUpdated ImportOutcome = "updated"
+	ImportSkipped ImportOutcome = "skipped"
+	ImportFailed  ImportOutcome = "failed"
+)
+
+// ImportResult is outcome of importing one subscription
+type ImportResult struct {
+	Outcome ImportOutcome
+
+	// Id of created or updated subscription (zero otherwise)
+	ID int64
+
+	// Reason of failure
+	Err error
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMemberWeightInvalid = errors.New("member weight is out of range")
+	ErrMemberDuplicated    = errors.New("member is listed more than once")
+)
+
+// Max split weight of one member
+const MaxMemberWeight = 1000
+
+// Member shares subscription cost with other members in proportion to weight
+type Member struct {
+	UserID uuid.UUID `json:"user_id"`
+	Weight int64     `json:"weight"`
+}
+
+// Share is part of amount attributed to user
+type Share struct {
+	UserID uuid.UUID `json:"user_id"`
+	Amount Money     `json:"amount"`
+}
+
+// Check members list: weights are in (0, MaxMemberWeight], every user is listed once
+func ValidateMembers(members []Member) error {
+	seen := make(map[uuid.UUID]bool, len(members))
+
+	for _, member := range members {
+		if member.Weight <= 0 || member.Weight > MaxMemberWeight {
+			return ErrMemberWeightInvalid
+		}
+		if seen[member.UserID] {
+			return ErrMemberDuplicated
+		}
+		seen[member.UserID] = true
+	}
+
+	return nil
+}
+
+// Split amount between members by weights (whole amount goes to owner if subscription is not shared);
+// shares sum is always equal to amount, remainder minor units go to members with largest fractional parts
+func (s *Subscription) Split(amount Money) []Share {
+	if len(s.Members) == 0 {
+		return []Share{{UserID: s.UserID, Amount: amount}}
+	}
+
+	var totalWeight int64
+	for _, member := range s.Members {
+		totalWeight += member.Weight
+	}
+
+	// amount = q*totalWeight + r, so q*weight + r*weight/totalWeight cannot overflow
+	q, r := int64(amount)/totalWeight, int64(amount)%totalWeight
+
+	shares := make([]Share, 0, len(s.Members))
+	fractions := make([]int64, 0, len(s.Members))
+	rest := amount
+
+	for _, member := range s.Members {
+		share := Money(q*member.Weight + r*member.Weight/totalWeight)
+		shares = append(shares, Share{UserID: member.UserID, Amount: share})
+		fractions = append(fractions, r*member.Weight%totalWeight)
+		rest -= share
+	}
+
+	order := make([]int, len(shares))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return abs(fractions[order[i]]) > abs(fractions[order[j]])
+	})
+
+	for i := 0; rest != 0; i = (i + 1) % len(order) {
+		if rest > 0 {
+			shares[order[i]].Amount++
+			rest--
+		} else {
+			shares[order[i]].Amount--
+			rest++
+		}
+	}
+
+	return shares
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Get part of amount attributed to user
+func (s *Subscription) ShareOf(userId uuid.UUID, amount Money) Money {
+	for _, share := range s.Split(amount) {
+		if share.UserID == userId {
+			return share.Amount
+		}
+	}
+
+	return 0
+}
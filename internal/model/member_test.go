@@ -0,0 +1,50 @@
+package model
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	owner, first, second := uuid.New(), uuid.New(), uuid.New()
+
+	// 1.Not shared subscription is paid by owner
+	sub := Subscription{SubscriptionSpec: SubscriptionSpec{UserID: owner}}
+	assert.Equal(t, []Share{{UserID: owner, Amount: 1000}}, sub.Split(1000))
+
+	// 2.Shares are proportional to weights, remainder goes to first members
+	sub.Members = []Member{{UserID: owner, Weight: 2}, {UserID: first, Weight: 1}, {UserID: second, Weight: 1}}
+
+	assert.Equal(t, []Share{
+		{UserID: owner, Amount: 500},
+		{UserID: first, Amount: 250},
+		{UserID: second, Amount: 250},
+	}, sub.Split(1000))
+
+	assert.Equal(t, []Share{
+		{UserID: owner, Amount: 501},
+		{UserID: first, Amount: 251},
+		{UserID: second, Amount: 250},
+	}, sub.Split(1002))
+
+	assert.Equal(t, Money(0), sub.ShareOf(uuid.New(), 1000))
+
+	// 3.Shares sum is equal to amount even for huge amounts
+	var sum Money
+	for _, share := range sub.Split(Money(math.MaxInt64)) {
+		sum += share.Amount
+	}
+	assert.Equal(t, Money(math.MaxInt64), sum)
+}
+
+func TestValidateMembers(t *testing.T) {
+	userId := uuid.New()
+
+	assert.NoError(t, ValidateMembers(nil))
+	assert.NoError(t, ValidateMembers([]Member{{UserID: userId, Weight: 1}, {UserID: uuid.New(), Weight: MaxMemberWeight}}))
+	assert.ErrorIs(t, ValidateMembers([]Member{{UserID: userId, Weight: 0}}), ErrMemberWeightInvalid)
+	assert.ErrorIs(t, ValidateMembers([]Member{{UserID: userId, Weight: 1}, {UserID: userId, Weight: 2}}), ErrMemberDuplicated)
+}
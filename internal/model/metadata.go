@@ -0,0 +1,150 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+)
+
+var (
+	ErrMetadataInvalid  = errors.New("metadata is not a JSON object")
+	ErrMetadataKey      = errors.New("metadata key is invalid")
+	ErrMetadataTooLarge = errors.New("metadata is too large")
+	ErrMetadataTooDeep  = errors.New("metadata is nested too deep")
+)
+
+const (
+	// Max size of compacted metadata JSON in bytes
+	MaxMetadataSize = 4096
+
+	// Max nesting of objects and arrays (metadata object itself is level 1)
+	MaxMetadataDepth = 3
+)
+
+// Top level metadata keys are used in filters and JSON paths, so they are restricted to safe characters
+var metadataKeyRe = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Check if string can be top level metadata key
+func IsMetadataKey(key string) bool {
+	return metadataKeyRe.MatchString(key)
+}
+
+// Validate metadata (JSON object within size and depth limits with valid top level keys) and compact it;
+// empty input and JSON null give nil
+func NormalizeMetadata(raw json.RawMessage) (json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil, nil
+	}
+
+	// 1.Object with valid keys
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &object); err != nil {
+		return nil, ErrMetadataInvalid
+	}
+
+	for key := range object {
+		if !IsMetadataKey(key) {
+			return nil, ErrMetadataKey
+		}
+	}
+
+	// 2.Size
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, trimmed); err != nil {
+		return nil, ErrMetadataInvalid
+	}
+
+	if compacted.Len() > MaxMetadataSize {
+		return nil, ErrMetadataTooLarge
+	}
+
+	// 3.Depth
+	decoder := json.NewDecoder(bytes.NewReader(compacted.Bytes()))
+	depth := 0
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, ErrMetadataInvalid
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+			if depth > MaxMetadataDepth {
+				return nil, ErrMetadataTooDeep
+			}
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+
+	return compacted.Bytes(), nil
+}
+
+// Apply JSON Merge Patch (RFC 7396) to metadata: null members remove keys, objects are merged recursively
+// and other values replace current ones; result is not normalized (nil if patch removes metadata)
+func MergeMetadata(target, patch json.RawMessage) (json.RawMessage, error) {
+	var patchValue any
+	if err := unmarshalNumbers(patch, &patchValue); err != nil {
+		return nil, ErrMetadataInvalid
+	}
+
+	var targetValue any
+	if len(bytes.TrimSpace(target)) > 0 {
+		if err := unmarshalNumbers(target, &targetValue); err != nil {
+			return nil, ErrMetadataInvalid
+		}
+	}
+
+	merged := mergePatch(targetValue, patchValue)
+	if merged == nil {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(merged); err != nil {
+		return nil, ErrMetadataInvalid
+	}
+
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+func mergePatch(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any, len(patchObject))
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+// Decode JSON keeping numbers as is
+func unmarshalNumbers(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(v)
+}
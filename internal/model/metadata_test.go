@@ -0,0 +1,62 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeMetadata(t *testing.T) {
+	cases := []struct {
+		name     string
+		raw      string
+		expected string
+		err      error
+	}{
+		{name: "Compacted", raw: ` { "cost_center": "R&D",  "seats": [1, {"a": 2}] } `, expected: `{"cost_center":"R&D","seats":[1,{"a":2}]}`},
+		{name: "Empty object", raw: `{}`, expected: `{}`},
+		{name: "Empty", raw: ``},
+		{name: "Null", raw: `null`},
+		{name: "Not object", raw: `[1, 2]`, err: ErrMetadataInvalid},
+		{name: "Malformed", raw: `{"a": `, err: ErrMetadataInvalid},
+		{name: "Invalid key", raw: `{"cost center": 1}`, err: ErrMetadataKey},
+		{name: "Too deep", raw: `{"a": {"b": [{"c": 1}]}}`, err: ErrMetadataTooDeep},
+		{name: "Too large", raw: `{"a": "` + strings.Repeat("x", MaxMetadataSize) + `"}`, err: ErrMetadataTooLarge},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			metadata, err := NormalizeMetadata(json.RawMessage(tc.raw))
+			assert.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.expected, string(metadata))
+		})
+	}
+}
+
+func TestMergeMetadata(t *testing.T) {
+	cases := []struct {
+		name     string
+		target   string
+		patch    string
+		expected string
+		err      error
+	}{
+		{name: "Add and replace", target: `{"a":"b","c":1}`, patch: `{"a":"z","d":1.50}`, expected: `{"a":"z","c":1,"d":1.50}`},
+		{name: "Remove key", target: `{"a":"b","c":1}`, patch: `{"a":null}`, expected: `{"c":1}`},
+		{name: "Nested merge", target: `{"a":{"b":1,"c":2}}`, patch: `{"a":{"c":null,"d":"R&D"}}`, expected: `{"a":{"b":1,"d":"R&D"}}`},
+		{name: "Array replaced", target: `{"a":[1,2]}`, patch: `{"a":[3]}`, expected: `{"a":[3]}`},
+		{name: "No target", patch: `{"a":{"b":null}}`, expected: `{"a":{}}`},
+		{name: "Remove all", target: `{"a":1}`, patch: `null`},
+		{name: "Malformed patch", target: `{"a":1}`, patch: `{"a":`, err: ErrMetadataInvalid},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			merged, err := MergeMetadata(json.RawMessage(tc.target), json.RawMessage(tc.patch))
+			assert.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.expected, string(merged))
+		})
+	}
+}
@@ -1,10 +1,12 @@
 package model
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -12,14 +14,58 @@ import (
 type Subscription struct {
 	ID int64 `json:"id"`
 	SubscriptionSpec
+
+	// Catalog service id (zero if subscription is not linked to catalog yet)
+	ServiceID int64 `json:"service_id,omitempty"`
+
+	// Lifecycle status
+	Status Status `json:"status"`
+
+	// Status changes (ordered by effective date)
+	StatusHistory []StatusTransition `json:"status_history,omitempty"`
+
+	// Price changes after start date (ordered by effective date)
+	PriceSchedule []PriceChange `json:"price_schedule,omitempty"`
+
+	// Promotional discounts
+	Discounts []Discount `json:"discounts,omitempty"`
+
+	// Periods added by auto-renewal (ordered by period start)
+	Renewals []Renewal `json:"renewals,omitempty"`
+
+	// Moments of creation and last change (of subscription itself or its details)
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type SubscriptionSpec struct {
 	ServiceName string    `json:"service_name"`
-	Price       int       `json:"price"`
+	Price       Money     `json:"price"`
 	UserID      uuid.UUID `json:"user_id"`
 	StartDate   Date      `json:"start_date"`
 	EndDate     Date      `json:"end_date"`
+
+	// Number of first months billed with trial price (zero means no trial)
+	TrialMonths int   `json:"trial_months"`
+	TrialPrice  Money `json:"trial_price"`
+
+	// Users sharing the cost (empty if owner pays alone)
+	Members []Member `json:"members,omitempty"`
+
+	// Spending category for reports (empty if not categorized, default is category of catalog service)
+	Category string `json:"category,omitempty"`
+
+	// Free-form labels
+	Tags []string `json:"tags,omitempty"`
+
+	// Custom attributes as compacted JSON object (nil if not set)
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+
+	// Extend subscription by billing period when it passes end date
+	AutoRenew bool `json:"auto_renew"`
+
+	// Billing period in months (zero means one month)
+	BillingPeriod int `json:"billing_period,omitempty"`
 }
 
 type Date struct {
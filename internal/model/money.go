@@ -0,0 +1,172 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrMoneyOverflow = errors.New("money overflow")
+	ErrMoneyInvalid  = errors.New("invalid money format")
+)
+
+// Money represents amount of money in minor units (kopecks, cents etc)
+type Money int64
+
+// Currency describes currency code and number of its minor unit digits
+type Currency struct {
+	Code     string
+	Exponent int
+}
+
+var (
+	CurrencyRUB = Currency{Code: "RUB", Exponent: 2}
+	CurrencyUSD = Currency{Code: "USD", Exponent: 2}
+	CurrencyEUR = Currency{Code: "EUR", Exponent: 2}
+	CurrencyJPY = Currency{Code: "JPY", Exponent: 0}
+
+	// Currency of all service prices
+	DefaultCurrency = CurrencyRUB
+)
+
+// Sum two values with overflow check
+func (m Money) Add(other Money) (Money, error) {
+	sum := m + other
+	if (other > 0 && sum < m) || (other < 0 && sum > m) {
+		return 0, ErrMoneyOverflow
+	}
+	return sum, nil
+}
+
+// Multiply on integer factor with overflow check
+func (m Money) Mul(factor int64) (Money, error) {
+	if m == 0 || factor == 0 {
+		return 0, nil
+	}
+	if (m == math.MinInt64 && factor == -1) || (factor == math.MinInt64 && m == -1) {
+		return 0, ErrMoneyOverflow
+	}
+
+	product := m * Money(factor)
+	if product/Money(factor) != m {
+		return 0, ErrMoneyOverflow
+	}
+	return product, nil
+}
+
+// Convert to decimal string in according with currency, e.g. 999 -> "9.99"
+func (m Money) Decimal(c Currency) string {
+	if c.Exponent <= 0 {
+		return strconv.FormatInt(int64(m), 10)
+	}
+
+	sign := ""
+	abs := strconv.FormatUint(uint64(m), 10)
+	if m < 0 {
+		sign = "-"
+		abs = strconv.FormatUint(uint64(-(m+1))+1, 10)
+	}
+
+	if len(abs) <= c.Exponent {
+		abs = strings.Repeat("0", c.Exponent-len(abs)+1) + abs
+	}
+
+	point := len(abs) - c.Exponent
+	return sign + abs[:point] + "." + abs[point:]
+}
+
+// Convert to human readable string with currency code, e.g. "9.99 USD"
+func (m Money) Format(c Currency) string {
+	return fmt.Sprintf("%s %s", m.Decimal(c), c.Code)
+}
+
+// Convert to decimal string in default currency
+func (m Money) String() string {
+	return m.Decimal(DefaultCurrency)
+}
+
+// Construct from decimal string like "9.99" or "400" in according with currency
+func MoneyFromString(str string, c Currency) (Money, error) {
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return 0, ErrMoneyInvalid
+	}
+
+	negative := false
+	if str[0] == '-' || str[0] == '+' {
+		negative = str[0] == '-'
+		str = str[1:]
+	}
+
+	intPart, fracPart, hasPoint := strings.Cut(str, ".")
+	if intPart == "" || (hasPoint && fracPart == "") {
+		return 0, ErrMoneyInvalid
+	}
+	if len(fracPart) > c.Exponent {
+		return 0, fmt.Errorf("%w: too many fraction digits for %s", ErrMoneyInvalid, c.Code)
+	}
+	fracPart += strings.Repeat("0", c.Exponent-len(fracPart))
+
+	for _, r := range intPart + fracPart {
+		if r < '0' || r > '9' {
+			return 0, ErrMoneyInvalid
+		}
+	}
+
+	value, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, ErrMoneyOverflow
+	}
+	if err != nil {
+		return 0, ErrMoneyInvalid
+	}
+
+	if negative {
+		value = -value
+	}
+	return Money(value), nil
+}
+
+// Marshal as decimal string in default currency
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// Unmarshal from decimal string (e.g. "9.99") or integer amount of minor units (e.g. 999)
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var str string
+		if err := json.Unmarshal(data, &str); err != nil {
+			return err
+		}
+
+		value, err := MoneyFromString(str, DefaultCurrency)
+		if err != nil {
+			return err
+		}
+		*m = value
+
+		return nil
+	}
+
+	value, err := strconv.ParseInt(string(data), 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return ErrMoneyOverflow
+	}
+	if err != nil {
+		return ErrMoneyInvalid
+	}
+	*m = Money(value)
+
+	return nil
+}
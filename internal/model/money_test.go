@@ -0,0 +1,115 @@
+package model
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoneyFromString(t *testing.T) {
+	tests := []struct {
+		name     string
+		str      string
+		currency Currency
+		expected Money
+		err      error
+	}{
+		{name: "Integer", str: "400", currency: CurrencyRUB, expected: 40000},
+		{name: "Two fraction digits", str: "9.99", currency: CurrencyRUB, expected: 999},
+		{name: "One fraction digit", str: "9.9", currency: CurrencyRUB, expected: 990},
+		{name: "Less than one", str: "0.05", currency: CurrencyUSD, expected: 5},
+		{name: "Negative", str: "-1.50", currency: CurrencyEUR, expected: -150},
+		{name: "Zero exponent", str: "500", currency: CurrencyJPY, expected: 500},
+		{name: "Too many fraction digits", str: "9.999", currency: CurrencyRUB, err: ErrMoneyInvalid},
+		{name: "Fraction for zero exponent", str: "5.5", currency: CurrencyJPY, err: ErrMoneyInvalid},
+		{name: "Empty", str: "", currency: CurrencyRUB, err: ErrMoneyInvalid},
+		{name: "No integer part", str: ".99", currency: CurrencyRUB, err: ErrMoneyInvalid},
+		{name: "No fraction part", str: "9.", currency: CurrencyRUB, err: ErrMoneyInvalid},
+		{name: "Comma separator", str: "9,99", currency: CurrencyRUB, err: ErrMoneyInvalid},
+		{name: "Overflow", str: "92233720368547758.08", currency: CurrencyRUB, err: ErrMoneyOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := MoneyFromString(tt.str, tt.currency)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, m)
+		})
+	}
+}
+
+func TestMoneyDecimal(t *testing.T) {
+	tests := []struct {
+		name     string
+		money    Money
+		currency Currency
+		expected string
+	}{
+		{name: "Zero", money: 0, currency: CurrencyRUB, expected: "0.00"},
+		{name: "Minor units only", money: 5, currency: CurrencyRUB, expected: "0.05"},
+		{name: "Regular", money: 999, currency: CurrencyUSD, expected: "9.99"},
+		{name: "Negative", money: -150, currency: CurrencyEUR, expected: "-1.50"},
+		{name: "Zero exponent", money: 500, currency: CurrencyJPY, expected: "500"},
+		{name: "Min value", money: math.MinInt64, currency: CurrencyRUB, expected: "-92233720368547758.08"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.money.Decimal(tt.currency))
+		})
+	}
+
+	assert.Equal(t, "9.99 USD", Money(999).Format(CurrencyUSD))
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	sum, err := Money(100).Add(250)
+	assert.NoError(t, err)
+	assert.Equal(t, Money(350), sum)
+
+	_, err = Money(math.MaxInt64).Add(1)
+	assert.ErrorIs(t, err, ErrMoneyOverflow)
+
+	_, err = Money(math.MinInt64).Add(-1)
+	assert.ErrorIs(t, err, ErrMoneyOverflow)
+
+	product, err := Money(999).Mul(12)
+	assert.NoError(t, err)
+	assert.Equal(t, Money(11988), product)
+
+	_, err = Money(math.MaxInt64/2 + 1).Mul(2)
+	assert.ErrorIs(t, err, ErrMoneyOverflow)
+
+	_, err = Money(math.MinInt64).Mul(-1)
+	assert.ErrorIs(t, err, ErrMoneyOverflow)
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var v struct {
+		Price Money `json:"price"`
+	}
+
+	// 1.Decimal string
+	assert.NoError(t, json.Unmarshal([]byte(`{"price": "9.99"}`), &v))
+	assert.Equal(t, Money(999), v.Price)
+
+	// 2.Integer of minor units
+	assert.NoError(t, json.Unmarshal([]byte(`{"price": 999}`), &v))
+	assert.Equal(t, Money(999), v.Price)
+
+	// 3.Invalid values
+	assert.Error(t, json.Unmarshal([]byte(`{"price": 9.99}`), &v))
+	assert.Error(t, json.Unmarshal([]byte(`{"price": "abc"}`), &v))
+	assert.Error(t, json.Unmarshal([]byte(`{"price": 92233720368547758070}`), &v))
+
+	// 4.Marshal
+	data, err := json.Marshal(v)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"price": "9.99"}`, string(data))
+}
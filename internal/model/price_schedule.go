@@ -0,0 +1,83 @@
+package model
+
+// PriceChange describes new subscription price starting from some month
+type PriceChange struct {
+	ID            int64 `json:"id"`
+	Price         Money `json:"price"`
+	EffectiveFrom Date  `json:"effective_from"`
+}
+
+// Get price effective in specified month in according with price schedule
+func (s *Subscription) PriceAt(month Date) Money {
+	price := s.Price
+
+	var effectiveFrom *Date
+	for i := 0; i < len(s.PriceSchedule); i++ {
+		change := &s.PriceSchedule[i]
+
+		if change.EffectiveFrom.GreaterThan(month) {
+			continue
+		}
+		if effectiveFrom != nil && effectiveFrom.GreaterThan(change.EffectiveFrom) {
+			continue
+		}
+
+		price = change.Price
+		effectiveFrom = &change.EffectiveFrom
+	}
+
+	return price
+}
+
+// Calculate charge for specified month: nothing while paused, trial price during trial,
+// otherwise effective price with discounts
+func (s *Subscription) ChargeAt(month Date) Money {
+	if s.PausedAt(month) {
+		return 0
+	}
+	if s.InTrial(month) {
+		return s.TrialPrice
+	}
+
+	price := s.PriceAt(month)
+	for i := 0; i < len(s.Discounts); i++ {
+		price = s.Discounts[i].Apply(price, month)
+	}
+
+	return price
+}
+
+// Check if month is inside trial period
+func (s *Subscription) InTrial(month Date) bool {
+	if s.TrialMonths <= 0 {
+		return false
+	}
+	trialEnd := s.StartDate.AddDate(0, s.TrialMonths)
+	return !s.StartDate.GreaterThan(month) && trialEnd.GreaterThan(month)
+}
+
+// Calculate cost of all billed months (from start date inclusive to end date exclusive)
+func (s *Subscription) Cost() (Money, error) {
+	return s.CostBetween(s.StartDate, s.EndDate)
+}
+
+// Calculate cost of billed months within [from, to) period
+func (s *Subscription) CostBetween(from, to Date) (Money, error) {
+	var cost Money
+
+	month := s.StartDate
+	if from.GreaterThan(month) {
+		month = from
+	}
+
+	for ; s.EndDate.GreaterThan(month) && to.GreaterThan(month); month = month.AddDate(0, 1) {
+		var err error
+
+		cost, err = cost.Add(s.ChargeAt(month))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return cost, nil
+}
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceAt(t *testing.T) {
+	sub := Subscription{
+		SubscriptionSpec: SubscriptionSpec{
+			Price:     400,
+			StartDate: Date{Month: 1, Year: 2026},
+			EndDate:   Date{Month: 1, Year: 2027},
+		},
+		PriceSchedule: []PriceChange{
+			{Price: 600, EffectiveFrom: Date{Month: 9, Year: 2026}},
+			{Price: 500, EffectiveFrom: Date{Month: 4, Year: 2026}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		month    Date
+		expected Money
+	}{
+		{name: "Before any change", month: Date{Month: 1, Year: 2026}, expected: 400},
+		{name: "Month of first change", month: Date{Month: 4, Year: 2026}, expected: 500},
+		{name: "Between changes", month: Date{Month: 8, Year: 2026}, expected: 500},
+		{name: "After last change", month: Date{Month: 12, Year: 2026}, expected: 600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, sub.PriceAt(tt.month))
+		})
+	}
+}
+
+func TestCost(t *testing.T) {
+	tests := []struct {
+		name     string
+		sub      Subscription
+		expected Money
+		err      error
+	}{
+		{
+			name: "No schedule",
+			sub: Subscription{SubscriptionSpec: SubscriptionSpec{
+				Price: 400, StartDate: Date{Month: 1, Year: 2026}, EndDate: Date{Month: 4, Year: 2026},
+			}},
+			expected: 1200,
+		},
+		{
+			name: "Price raised in the middle",
+			sub: Subscription{
+				SubscriptionSpec: SubscriptionSpec{
+					Price: 400, StartDate: Date{Month: 11, Year: 2025}, EndDate: Date{Month: 3, Year: 2026},
+				},
+				PriceSchedule: []PriceChange{{Price: 500, EffectiveFrom: Date{Month: 1, Year: 2026}}},
+			},
+			expected: 400*2 + 500*2,
+		},
+		{
+			name: "Change after end date is ignored",
+			sub: Subscription{
+				SubscriptionSpec: SubscriptionSpec{
+					Price: 400, StartDate: Date{Month: 1, Year: 2026}, EndDate: Date{Month: 3, Year: 2026},
+				},
+				PriceSchedule: []PriceChange{{Price: 900, EffectiveFrom: Date{Month: 5, Year: 2026}}},
+			},
+			expected: 800,
+		},
+		{
+			name: "Overflow",
+			sub: Subscription{SubscriptionSpec: SubscriptionSpec{
+				Price: math.MaxInt64/2 + 1, StartDate: Date{Month: 1, Year: 2026}, EndDate: Date{Month: 3, Year: 2026},
+			}},
+			err: ErrMoneyOverflow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cost, err := tt.sub.Cost()
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, cost)
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package model
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrStatementEmpty    = errors.New("statement has no payments")
+	ErrStatementTooLarge = errors.New("statement has too many lines")
+)
+
+// Max number of statement lines
+const MaxStatementLines = 10000
+
+// Min length of service name key matched in merchant (shorter names match too many merchants)
+const minMerchantKeyLength = 3
+
+// Max spread of amounts of recurring payment in percents of the smallest one
+const recurringAmountSpread = 10
+
+// StatementLine is payment from bank or card statement
+type StatementLine struct {
+	// Line number in statement file (from 1)
+	Line     int       `json:"line"`
+	Date     time.Time `json:"date"`
+	Merchant string    `json:"merchant"`
+
+	// Payment amount (always positive, debit sign is dropped)
+	Amount Money `json:"amount"`
+}
+
+// ExpectedCharge is monthly subscription charge which should be in statement
+type ExpectedCharge struct {
+	SubscriptionID int64  `json:"subscription_id"`
+	ServiceName    string `json:"service_name"`
+	Month          Date   `json:"month"`
+	Amount         Money  `json:"amount"`
+}
+
+// MatchedCharge is expected charge found in statement
+type MatchedCharge struct {
+	ExpectedCharge
+	Payment StatementLine `json:"payment"`
+}
+
+// RecurringPayment is untracked merchant charging in several months (looks like subscription)
+type RecurringPayment struct {
+	Merchant string          `json:"merchant"`
+	Payments []StatementLine `json:"payments"`
+}
+
+// ReconciliationReport is result of statement matching against expected charges
+type ReconciliationReport struct {
+	// Statement period (first and last months of payments)
+	From Date `json:"from"`
+	To   Date `json:"to"`
+
+	Matched []MatchedCharge    `json:"matched"`
+	Missing []ExpectedCharge   `json:"missing"`
+	Unknown []RecurringPayment `json:"unknown"`
+}
+
+// Parse CSV statement with date, merchant and amount columns; header row, ";" delimiter, dates
+// in YYYY-MM-DD or DD.MM.YYYY format and decimal comma (as in exports of local banks) are allowed
+func ParseStatement(r io.Reader) ([]StatementLine, error) {
+	// 1.Read records (delimiter is detected by first line)
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	firstLine, _, _ := strings.Cut(string(data), "\n")
+
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+
+	var lines []StatementLine
+
+	for n := 1; ; n++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(lines) >= MaxStatementLines {
+			return nil, ErrStatementTooLarge
+		}
+
+		// 2.Parse payment (first line which is not a payment is header)
+		line, err := parseStatementRecord(n, record)
+		if err != nil {
+			if n == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		return nil, ErrStatementEmpty
+	}
+
+	return lines, nil
+}
+
+func parseStatementRecord(n int, record []string) (StatementLine, error) {
+	line := StatementLine{Line: n, Merchant: strings.TrimSpace(record[1])}
+
+	dateStr := strings.TrimSpace(record[0])
+
+	var err error
+
+	line.Date, err = time.Parse(time.DateOnly, dateStr)
+	if err != nil {
+		line.Date, err = time.Parse("02.01.2006", dateStr)
+	}
+	if err != nil {
+		return StatementLine{}, errors.New("date is invalid")
+	}
+
+	if line.Merchant == "" {
+		return StatementLine{}, errors.New("merchant is empty")
+	}
+
+	amountStr := strings.NewReplacer(" ", "", " ", "", ",", ".").Replace(record[2])
+
+	line.Amount, err = MoneyFromString(amountStr, DefaultCurrency)
+	if err != nil {
+		return StatementLine{}, fmt.Errorf("amount is invalid: %w", err)
+	}
+	if line.Amount < 0 {
+		line.Amount = -line.Amount
+	}
+
+	return line, nil
+}
+
+// Get month of statement line
+func (l *StatementLine) Month() Date {
+	return Date{Month: int(l.Date.Month()), Year: l.Date.Year()}
+}
+
+// Get months of the earliest and the latest payments of statement (lines must not be empty)
+func StatementPeriod(lines []StatementLine) (Date, Date) {
+	from, to := lines[0].Month(), lines[0].Month()
+	for i := 1; i < len(lines); i++ {
+		month := lines[i].Month()
+		if from.GreaterThan(month) {
+			from = month
+		}
+		if month.GreaterThan(to) {
+			to = month
+		}
+	}
+
+	return from, to
+}
+
+// Match statement payments against charges expected in statement period: payment matches charge of the same month
+// if merchant contains service name (or alias of catalog service) and amount differs by tolerance at most;
+// payments not matched are reported if merchant charged similar amounts in several months
+func Reconcile(lines []StatementLine, subscriptions []Subscription, services []Service, tolerance Money) ReconciliationReport {
+	report := ReconciliationReport{
+		Matched: []MatchedCharge{},
+		Missing: []ExpectedCharge{},
+		Unknown: []RecurringPayment{},
+	}
+
+	if len(lines) == 0 {
+		return report
+	}
+
+	// 1.Statement period
+	report.From, report.To = StatementPeriod(lines)
+
+	// 2.Match expected charges
+	serviceKeys := make(map[int64][]string, len(services))
+	for _, service := range services {
+		serviceKeys[service.ID] = append(serviceKeys[service.ID], ServiceNameKey(service.Name))
+		for _, alias := range service.Aliases {
+			serviceKeys[service.ID] = append(serviceKeys[service.ID], ServiceNameKey(alias))
+		}
+	}
+
+	merchantKeys := make([]string, len(lines))
+	months := make([]Date, len(lines))
+	for i := range lines {
+		merchantKeys[i] = ServiceNameKey(lines[i].Merchant)
+		months[i] = lines[i].Month()
+	}
+
+	matched := make([]bool, len(lines))
+
+	for _, charge := range expectedCharges(subscriptions, report.From, report.To) {
+		keys := []string{ServiceNameKey(charge.sub.ServiceName)}
+		keys = append(keys, serviceKeys[charge.sub.ServiceID]...)
+
+		best := -1
+		var bestDiff Money
+
+		for i := range lines {
+			if matched[i] || !months[i].EqualTo(charge.Month) || !merchantMatches(merchantKeys[i], keys) {
+				continue
+			}
+
+			diff := Money(abs(int64(lines[i].Amount - charge.Amount)))
+			if diff > tolerance {
+				continue
+			}
+
+			if best < 0 || diff < bestDiff {
+				best, bestDiff = i, diff
+			}
+		}
+
+		if best < 0 {
+			report.Missing = append(report.Missing, charge.ExpectedCharge)
+			continue
+		}
+
+		matched[best] = true
+		report.Matched = append(report.Matched, MatchedCharge{ExpectedCharge: charge.ExpectedCharge, Payment: lines[best]})
+	}
+
+	// 3.Find recurring payments among not matched ones
+	var order []string
+	groups := make(map[string][]StatementLine)
+
+	for i := range lines {
+		if matched[i] || merchantKeys[i] == "" {
+			continue
+		}
+		if _, ok := groups[merchantKeys[i]]; !ok {
+			order = append(order, merchantKeys[i])
+		}
+		groups[merchantKeys[i]] = append(groups[merchantKeys[i]], lines[i])
+	}
+
+	for _, key := range order {
+		if isRecurring(groups[key], tolerance) {
+			report.Unknown = append(report.Unknown, RecurringPayment{Merchant: groups[key][0].Merchant, Payments: groups[key]})
+		}
+	}
+
+	return report
+}
+
+type subscriptionCharge struct {
+	ExpectedCharge
+	sub *Subscription
+}
+
+// Get charges of subscriptions billed within [from, to] months (ordered by month and subscription id)
+func expectedCharges(subscriptions []Subscription, from, to Date) []subscriptionCharge {
+	var charges []subscriptionCharge
+
+	for i := range subscriptions {
+		sub := &subscriptions[i]
+
+		for month := from; !month.GreaterThan(to); month = month.AddDate(0, 1) {
+			if !sub.covers(month) {
+				continue
+			}
+
+			amount := sub.ChargeAt(month)
+			if amount == 0 {
+				continue
+			}
+
+			charges = append(charges, subscriptionCharge{
+				ExpectedCharge: ExpectedCharge{SubscriptionID: sub.ID, ServiceName: sub.ServiceName, Month: month, Amount: amount},
+				sub:            sub,
+			})
+		}
+	}
+
+	sort.SliceStable(charges, func(i, j int) bool {
+		if !charges[i].Month.EqualTo(charges[j].Month) {
+			return charges[j].Month.GreaterThan(charges[i].Month)
+		}
+		return charges[i].SubscriptionID < charges[j].SubscriptionID
+	})
+
+	return charges
+}
+
+// Check if merchant contains one of service name keys
+func merchantMatches(merchantKey string, keys []string) bool {
+	for _, key := range keys {
+		if len([]rune(key)) >= minMerchantKeyLength && strings.Contains(merchantKey, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if payments are made in several months with similar amounts
+func isRecurring(payments []StatementLine, tolerance Money) bool {
+	months := make(map[Date]bool)
+	minAmount, maxAmount := payments[0].Amount, payments[0].Amount
+
+	for _, payment := range payments {
+		months[payment.Month()] = true
+		minAmount = min(minAmount, payment.Amount)
+		maxAmount = max(maxAmount, payment.Amount)
+	}
+
+	return len(months) >= 2 && maxAmount-minAmount <= max(tolerance, minAmount*recurringAmountSpread/100)
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatement(t *testing.T) {
+	// 1.Header row, semicolon delimiter, local date and amount formats
+	lines, err := ParseStatement(strings.NewReader(
+		"Дата;Описание;Сумма\n" +
+			"05.01.2026;NETFLIX.COM;-1 299,00\n" +
+			"2026-02-05; Netflix.com ;1299\n",
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []StatementLine{
+		{Line: 2, Date: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), Merchant: "NETFLIX.COM", Amount: 129900},
+		{Line: 3, Date: time.Date(2026, 2, 5, 0, 0, 0, 0, time.UTC), Merchant: "Netflix.com", Amount: 129900},
+	}, lines)
+
+	// 2.No header
+	lines, err = ParseStatement(strings.NewReader("2026-01-05,Spotify,\"199.50\"\n"))
+	require.NoError(t, err)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, Money(19950), lines[0].Amount)
+
+	// 3.Invalid rows
+	_, err = ParseStatement(strings.NewReader("2026-01-05,Spotify,199\n2026-13-05,Spotify,199\n"))
+	assert.ErrorContains(t, err, "line 2: date is invalid")
+
+	_, err = ParseStatement(strings.NewReader("2026-01-05,Spotify,199\n2026-01-05,Spotify,1.2.3\n"))
+	assert.ErrorContains(t, err, "line 2: amount is invalid")
+
+	_, err = ParseStatement(strings.NewReader("2026-01-05,Spotify\n"))
+	assert.Error(t, err)
+
+	_, err = ParseStatement(strings.NewReader("date,merchant,amount\n"))
+	assert.ErrorIs(t, err, ErrStatementEmpty)
+}
+
+func TestReconcile(t *testing.T) {
+	userId := uuid.New()
+
+	day := func(month, day int) time.Time {
+		return time.Date(2026, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	}
+
+	subscriptions := []Subscription{
+		{
+			ID: 1,
+			SubscriptionSpec: SubscriptionSpec{
+				ServiceName: "Yandex Plus", Price: 29900, UserID: userId,
+				StartDate: Date{Month: 1, Year: 2026}, EndDate: Date{Month: 1, Year: 2027},
+			},
+			ServiceID: 10,
+		},
+		{
+			ID: 2,
+			SubscriptionSpec: SubscriptionSpec{
+				ServiceName: "Spotify", Price: 19900, UserID: userId,
+				StartDate: Date{Month: 2, Year: 2026}, EndDate: Date{Month: 1, Year: 2027},
+			},
+		},
+	}
+	services := []Service{{ID: 10, Name: "Yandex Plus", Aliases: []string{"Plus"}}}
+
+	lines := []StatementLine{
+		// Alias of catalog service, amount within tolerance
+		{Line: 1, Date: day(1, 3), Merchant: "PLUS.YANDEX.RU", Amount: 29950},
+		{Line: 2, Date: day(2, 3), Merchant: "YANDEX*PLUS", Amount: 29900},
+		// Amount out of tolerance
+		{Line: 3, Date: day(2, 7), Merchant: "SPOTIFY P1234", Amount: 39900},
+		// Untracked recurring payments
+		{Line: 4, Date: day(1, 15), Merchant: "KINOPOISK", Amount: 39900},
+		{Line: 5, Date: day(2, 15), Merchant: "Kinopoisk", Amount: 42900},
+		// One-time payment
+		{Line: 6, Date: day(2, 20), Merchant: "Coffee", Amount: 300},
+	}
+
+	report := Reconcile(lines, subscriptions, services, 100)
+
+	assert.Equal(t, Date{Month: 1, Year: 2026}, report.From)
+	assert.Equal(t, Date{Month: 2, Year: 2026}, report.To)
+
+	assert.Equal(t, []MatchedCharge{
+		{
+			ExpectedCharge: ExpectedCharge{SubscriptionID: 1, ServiceName: "Yandex Plus", Month: Date{Month: 1, Year: 2026}, Amount: 29900},
+			Payment:        lines[0],
+		},
+		{
+			ExpectedCharge: ExpectedCharge{SubscriptionID: 1, ServiceName: "Yandex Plus", Month: Date{Month: 2, Year: 2026}, Amount: 29900},
+			Payment:        lines[1],
+		},
+	}, report.Matched)
+
+	assert.Equal(t, []ExpectedCharge{
+		{SubscriptionID: 2, ServiceName: "Spotify", Month: Date{Month: 2, Year: 2026}, Amount: 19900},
+	}, report.Missing)
+
+	assert.Equal(t, []RecurringPayment{
+		{Merchant: "KINOPOISK", Payments: []StatementLine{lines[3], lines[4]}},
+	}, report.Unknown)
+
+	// Wider tolerance matches Spotify payment
+	report = Reconcile(lines, subscriptions, services, 20000)
+	assert.Len(t, report.Matched, 3)
+	assert.Empty(t, report.Missing)
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrBillingPeriodInvalid = errors.New("billing period is out of range")
+
+// Max billing period in months
+const MaxBillingPeriod = 12
+
+// Renewal is billing period added to auto-renewing subscription after its end date
+type Renewal struct {
+	ID          int64 `json:"id"`
+	PeriodStart Date  `json:"period_start"`
+	PeriodEnd   Date  `json:"period_end"`
+
+	// Price effective at period start (scheduled price change is applied)
+	Price Money `json:"price"`
+
+	RenewedAt time.Time `json:"renewed_at"`
+}
+
+// Check billing period is in [1, MaxBillingPeriod] months
+func ValidateBillingPeriod(months int) error {
+	if months < 1 || months > MaxBillingPeriod {
+		return ErrBillingPeriodInvalid
+	}
+	return nil
+}
+
+// Get billing period in months (one month if not set)
+func (s *SubscriptionSpec) Period() int {
+	if s.BillingPeriod <= 0 {
+		return 1
+	}
+	return s.BillingPeriod
+}
+
+// Get renewals needed for subscription to be billed in specified month (ordered by period start);
+// nothing is renewed if auto-renewal is off or subscription is cancelled or expired
+func (s *Subscription) PendingRenewals(month Date) []Renewal {
+	if !s.AutoRenew || (s.Status != StatusActive && s.Status != StatusPaused) {
+		return nil
+	}
+
+	var renewals []Renewal
+
+	for end := s.EndDate; !end.GreaterThan(month); end = end.AddDate(0, s.Period()) {
+		renewals = append(renewals, Renewal{
+			PeriodStart: end,
+			PeriodEnd:   end.AddDate(0, s.Period()),
+			Price:       s.PriceAt(end),
+		})
+	}
+
+	return renewals
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingRenewals(t *testing.T) {
+	subscription := Subscription{
+		SubscriptionSpec: SubscriptionSpec{
+			Price:         100,
+			StartDate:     Date{Month: 11, Year: 2025},
+			EndDate:       Date{Month: 1, Year: 2026},
+			AutoRenew:     true,
+			BillingPeriod: 2,
+		},
+		Status: StatusActive,
+		PriceSchedule: []PriceChange{
+			{ID: 1, Price: 150, EffectiveFrom: Date{Month: 3, Year: 2026}},
+		},
+	}
+
+	// 1.Not ended yet
+	assert.Empty(t, subscription.PendingRenewals(Date{Month: 12, Year: 2025}))
+
+	// 2.Several periods are caught up, scheduled price is applied
+	assert.Equal(t, []Renewal{
+		{PeriodStart: Date{Month: 1, Year: 2026}, PeriodEnd: Date{Month: 3, Year: 2026}, Price: 100},
+		{PeriodStart: Date{Month: 3, Year: 2026}, PeriodEnd: Date{Month: 5, Year: 2026}, Price: 150},
+	}, subscription.PendingRenewals(Date{Month: 4, Year: 2026}))
+
+	// 3.Only active and paused auto-renewing subscriptions are renewed
+	subscription.Status = StatusCancelled
+	assert.Empty(t, subscription.PendingRenewals(Date{Month: 4, Year: 2026}))
+
+	subscription.Status = StatusActive
+	subscription.AutoRenew = false
+	assert.Empty(t, subscription.PendingRenewals(Date{Month: 4, Year: 2026}))
+}
+
+func TestValidateBillingPeriod(t *testing.T) {
+	assert.NoError(t, ValidateBillingPeriod(1))
+	assert.NoError(t, ValidateBillingPeriod(MaxBillingPeriod))
+	assert.ErrorIs(t, ValidateBillingPeriod(0), ErrBillingPeriodInvalid)
+	assert.ErrorIs(t, ValidateBillingPeriod(MaxBillingPeriod+1), ErrBillingPeriodInvalid)
+}
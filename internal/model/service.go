@@ -0,0 +1,48 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Service is catalog entry which subscriptions refer to
+type Service struct {
+	ID int64 `json:"id"`
+
+	// Canonical name (subscriptions of service use it as service name)
+	Name string `json:"name"`
+
+	// Other names resolved to the service
+	Aliases []string `json:"aliases"`
+
+	// Monthly price used for new subscriptions without price
+	DefaultPrice Money `json:"default_price"`
+
+	Category string `json:"category"`
+}
+
+// Get key for case and separators insensitive comparison of service names
+// ("Yandex Plus", "yandex-plus" and "YandexPlus" have the same key)
+func ServiceNameKey(name string) string {
+	var b strings.Builder
+
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// Stats of service names normalization
+type NormalizeReport struct {
+	// Subscriptions linked to catalog services
+	Linked int64 `json:"linked"`
+
+	// Services added to catalog
+	Registered int64 `json:"registered"`
+
+	// Subscriptions not linked because user already has subscription of the same service
+	Conflicts []int64 `json:"conflicts"`
+}
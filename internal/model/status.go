@@ -0,0 +1,94 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrStatusInvalid     = errors.New("invalid subscription status")
+	ErrTransitionInvalid = errors.New("invalid subscription status transition")
+)
+
+// Status is subscription lifecycle state
+type Status string
+
+const (
+	StatusActive    Status = "active"
+	StatusPaused    Status = "paused"
+	StatusCancelled Status = "cancelled"
+	StatusExpired   Status = "expired"
+)
+
+// Allowed lifecycle transitions (cancelled and expired are final states)
+var statusTransitions = map[Status][]Status{
+	StatusActive:    {StatusPaused, StatusCancelled, StatusExpired},
+	StatusPaused:    {StatusActive, StatusCancelled, StatusExpired},
+	StatusCancelled: {},
+	StatusExpired:   {},
+}
+
+// Construct from string with validation
+func StatusFromString(str string) (Status, error) {
+	status := Status(str)
+	if _, ok := statusTransitions[status]; !ok {
+		return "", ErrStatusInvalid
+	}
+	return status, nil
+}
+
+// Check if transition to another status is allowed
+func (s Status) CanTransitionTo(to Status) bool {
+	for _, allowed := range statusTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusTransition is audit record of subscription status change
+type StatusTransition struct {
+	ID   int64  `json:"id"`
+	From Status `json:"from"`
+	To   Status `json:"to"`
+
+	// Month since which new status applies
+	EffectiveFrom Date `json:"effective_from"`
+
+	// Moment of change
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// Get status applied in specified month in according with status history (ordered by effective date)
+func (s *Subscription) StatusAt(month Date) Status {
+	status := StatusActive
+
+	for i := 0; i < len(s.StatusHistory); i++ {
+		if s.StatusHistory[i].EffectiveFrom.GreaterThan(month) {
+			break
+		}
+		status = s.StatusHistory[i].To
+	}
+
+	return status
+}
+
+// Check if subscription is paused in specified month (paused months are not billed)
+func (s *Subscription) PausedAt(month Date) bool {
+	return s.StatusAt(month) == StatusPaused
+}
+
+// Get effective date of the last status change (zero date if there were no changes)
+func (s *Subscription) LastStatusChange() Date {
+	if len(s.StatusHistory) == 0 {
+		return Date{}
+	}
+	return s.StatusHistory[len(s.StatusHistory)-1].EffectiveFrom
+}
+
+// Current month date
+func CurrentMonth() Date {
+	now := time.Now().UTC()
+	return Date{Month: int(now.Month()), Year: now.Year()}
+}
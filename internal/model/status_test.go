@@ -0,0 +1,65 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusTransitions(t *testing.T) {
+	tests := []struct {
+		from     Status
+		to       Status
+		expected bool
+	}{
+		{from: StatusActive, to: StatusPaused, expected: true},
+		{from: StatusActive, to: StatusCancelled, expected: true},
+		{from: StatusActive, to: StatusExpired, expected: true},
+		{from: StatusActive, to: StatusActive, expected: false},
+		{from: StatusPaused, to: StatusActive, expected: true},
+		{from: StatusPaused, to: StatusCancelled, expected: true},
+		{from: StatusPaused, to: StatusPaused, expected: false},
+		{from: StatusCancelled, to: StatusActive, expected: false},
+		{from: StatusExpired, to: StatusActive, expected: false},
+		{from: Status("trash"), to: StatusActive, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.from.CanTransitionTo(tt.to))
+		})
+	}
+
+	status, err := StatusFromString("paused")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusPaused, status)
+
+	_, err = StatusFromString("trash")
+	assert.ErrorIs(t, err, ErrStatusInvalid)
+}
+
+func TestCostWithPauses(t *testing.T) {
+	sub := Subscription{
+		SubscriptionSpec: SubscriptionSpec{
+			Price:     100,
+			StartDate: Date{Month: 1, Year: 2026},
+			EndDate:   Date{Month: 1, Year: 2027},
+		},
+		StatusHistory: []StatusTransition{
+			{From: StatusActive, To: StatusPaused, EffectiveFrom: Date{Month: 3, Year: 2026}},
+			{From: StatusPaused, To: StatusActive, EffectiveFrom: Date{Month: 5, Year: 2026}},
+			{From: StatusActive, To: StatusPaused, EffectiveFrom: Date{Month: 10, Year: 2026}},
+		},
+	}
+
+	assert.Equal(t, StatusActive, sub.StatusAt(Date{Month: 2, Year: 2026}))
+	assert.Equal(t, StatusPaused, sub.StatusAt(Date{Month: 4, Year: 2026}))
+	assert.Equal(t, StatusActive, sub.StatusAt(Date{Month: 5, Year: 2026}))
+	assert.Equal(t, StatusPaused, sub.StatusAt(Date{Month: 12, Year: 2026}))
+	assert.Equal(t, Date{Month: 10, Year: 2026}, sub.LastStatusChange())
+
+	// Billed: 01-02, 05-09 (paused 03-04 and since 10)
+	cost, err := sub.Cost()
+	assert.NoError(t, err)
+	assert.Equal(t, Money(700), cost)
+}
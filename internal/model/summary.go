@@ -0,0 +1,73 @@
+package model
+
+import "github.com/google/uuid"
+
+// UserSummary describes user subscriptions state at some month
+type UserSummary struct {
+	// Subscriptions active in the month
+	ActiveCount int64 `json:"active_count"`
+
+	// User share of charge for the month over all subscriptions
+	MonthlyRunRate Money `json:"monthly_run_rate"`
+
+	// User share of spend from January up to the month inclusive
+	YearToDateSpend Money `json:"year_to_date_spend"`
+
+	// Not cancelled subscription which ends first after the month (nil if there is no one)
+	NextExpiring *Subscription `json:"next_expiring"`
+}
+
+// Summarize user subscriptions (own and shared with user) at specified month
+func Summarize(subscriptions []Subscription, userId uuid.UUID, month Date) (UserSummary, error) {
+	var summary UserSummary
+
+	yearStart := Date{Month: 1, Year: month.Year}
+	nextMonth := month.AddDate(0, 1)
+
+	for i := 0; i < len(subscriptions); i++ {
+		sub := &subscriptions[i]
+
+		// 1.Current month
+		if sub.covers(month) {
+			if sub.StatusAt(month) == StatusActive {
+				summary.ActiveCount++
+			}
+
+			var err error
+
+			summary.MonthlyRunRate, err = summary.MonthlyRunRate.Add(sub.ShareOf(userId, sub.ChargeAt(month)))
+			if err != nil {
+				return UserSummary{}, err
+			}
+		}
+
+		// 2.Year to date
+		spend, err := sub.CostBetween(yearStart, nextMonth)
+		if err != nil {
+			return UserSummary{}, err
+		}
+
+		summary.YearToDateSpend, err = summary.YearToDateSpend.Add(sub.ShareOf(userId, spend))
+		if err != nil {
+			return UserSummary{}, err
+		}
+
+		// 3.Next expiring
+		if sub.Status != StatusActive && sub.Status != StatusPaused {
+			continue
+		}
+		if !sub.EndDate.GreaterThan(month) {
+			continue
+		}
+		if summary.NextExpiring == nil || summary.NextExpiring.EndDate.GreaterThan(sub.EndDate) {
+			summary.NextExpiring = sub
+		}
+	}
+
+	return summary, nil
+}
+
+// Check if month is billed period of subscription
+func (s *Subscription) covers(month Date) bool {
+	return !s.StartDate.GreaterThan(month) && s.EndDate.GreaterThan(month)
+}
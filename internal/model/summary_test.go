@@ -0,0 +1,54 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	month := Date{Month: 4, Year: 2026}
+
+	subscriptions := []Subscription{
+		// Billed since previous year, 4 months of current year
+		{
+			ID:               1,
+			SubscriptionSpec: SubscriptionSpec{Price: 100, StartDate: Date{Month: 10, Year: 2025}, EndDate: Date{Month: 1, Year: 2027}},
+			Status:           StatusActive,
+		},
+		// Paused since March
+		{
+			ID:               2,
+			SubscriptionSpec: SubscriptionSpec{Price: 1000, StartDate: Date{Month: 1, Year: 2026}, EndDate: Date{Month: 8, Year: 2026}},
+			Status:           StatusPaused,
+			StatusHistory: []StatusTransition{
+				{From: StatusActive, To: StatusPaused, EffectiveFrom: Date{Month: 3, Year: 2026}},
+			},
+		},
+		// Ended in February
+		{
+			ID:               3,
+			SubscriptionSpec: SubscriptionSpec{Price: 10000, StartDate: Date{Month: 1, Year: 2026}, EndDate: Date{Month: 3, Year: 2026}},
+			Status:           StatusExpired,
+		},
+		// Starts in future
+		{
+			ID:               4,
+			SubscriptionSpec: SubscriptionSpec{Price: 100000, StartDate: Date{Month: 5, Year: 2026}, EndDate: Date{Month: 6, Year: 2026}},
+			Status:           StatusActive,
+		},
+	}
+
+	summary, err := Summarize(subscriptions, uuid.Nil, month)
+	assert.NoError(t, err)
+
+	assert.Equal(t, int64(1), summary.ActiveCount)
+	assert.Equal(t, Money(100), summary.MonthlyRunRate)
+	assert.Equal(t, Money(4*100+2*1000+2*10000), summary.YearToDateSpend)
+	assert.Equal(t, int64(4), summary.NextExpiring.ID)
+
+	summary, err = Summarize([]Subscription{}, uuid.Nil, month)
+	assert.NoError(t, err)
+	assert.Nil(t, summary.NextExpiring)
+}
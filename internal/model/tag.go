@@ -0,0 +1,68 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrLabelInvalid = errors.New("tag or category is invalid")
+	ErrTooManyTags  = errors.New("too many tags")
+)
+
+const (
+	// Max length (in characters) of tag or category
+	MaxLabelLength = 64
+
+	// Max number of tags of one subscription
+	MaxTags = 20
+)
+
+// Normalize tag or category (trim spaces, lower case) and check it is not too long
+// and has no control characters; empty string stays empty
+func NormalizeLabel(label string) (string, error) {
+	label = strings.ToLower(strings.TrimSpace(label))
+
+	if utf8.RuneCountInString(label) > MaxLabelLength {
+		return "", ErrLabelInvalid
+	}
+
+	for _, r := range label {
+		if unicode.IsControl(r) {
+			return "", ErrLabelInvalid
+		}
+	}
+
+	return label, nil
+}
+
+// Normalize tags keeping their order and dropping duplicates (empty tags are not allowed)
+func NormalizeTags(tags []string) ([]string, error) {
+	var normalized []string
+	seen := make(map[string]bool, len(tags))
+
+	for _, tag := range tags {
+		tag, err := NormalizeLabel(tag)
+		if err != nil {
+			return nil, err
+		}
+		if tag == "" {
+			return nil, ErrLabelInvalid
+		}
+
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > MaxTags {
+		return nil, ErrTooManyTags
+	}
+
+	return normalized, nil
+}
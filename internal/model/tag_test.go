@@ -0,0 +1,44 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLabel(t *testing.T) {
+	label, err := NormalizeLabel("  Dev-Tools ")
+	assert.NoError(t, err)
+	assert.Equal(t, "dev-tools", label)
+
+	label, err = NormalizeLabel("")
+	assert.NoError(t, err)
+	assert.Equal(t, "", label)
+
+	_, err = NormalizeLabel(strings.Repeat("a", MaxLabelLength+1))
+	assert.ErrorIs(t, err, ErrLabelInvalid)
+
+	_, err = NormalizeLabel("work\nhome")
+	assert.ErrorIs(t, err, ErrLabelInvalid)
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{"Work", "family", " work "})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"work", "family"}, tags)
+
+	tags, err = NormalizeTags(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, tags)
+
+	_, err = NormalizeTags([]string{"work", " "})
+	assert.ErrorIs(t, err, ErrLabelInvalid)
+
+	many := make([]string, 0, MaxTags+1)
+	for i := 0; i <= MaxTags; i++ {
+		many = append(many, strings.Repeat("t", i+1))
+	}
+	_, err = NormalizeTags(many)
+	assert.ErrorIs(t, err, ErrTooManyTags)
+}
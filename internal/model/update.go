@@ -0,0 +1,98 @@
+package model
+
+import (
+	"encoding/json"
+)
+
+// SubscriptionUpdate holds new values of editable subscription fields (nil fields are kept unchanged)
+type SubscriptionUpdate struct {
+	ServiceName *string
+	Price       *Money
+	StartDate   *Date
+
+	// Zero date resets end date to one billing period after start date (as on creation)
+	EndDate *Date
+
+	// New metadata replacing current one (pointer to nil removes metadata)
+	Metadata *json.RawMessage
+
+	// JSON Merge Patch (RFC 7396) of current metadata, used if Metadata is nil
+	MetadataPatch json.RawMessage
+
+	// Trial, members, labels and renewal settings (pointer to nil slice removes members or tags,
+	// empty category is replaced with category of catalog service by storage as on creation)
+	TrialMonths   *int
+	TrialPrice    *Money
+	Members       *[]Member
+	Category      *string
+	Tags          *[]string
+	AutoRenew     *bool
+	BillingPeriod *int
+}
+
+// Apply update to subscription and validate merged result (error is *ValidationError)
+func (u SubscriptionUpdate) Apply(sub *Subscription) error {
+	var v Validator
+
+	// 1.Plain fields
+	if u.ServiceName != nil {
+		sub.ServiceName = *u.ServiceName
+	}
+	if u.Price != nil {
+		sub.Price = *u.Price
+	}
+	if u.StartDate != nil {
+		sub.StartDate = *u.StartDate
+	}
+	if u.TrialMonths != nil {
+		sub.TrialMonths = *u.TrialMonths
+	}
+	if u.TrialPrice != nil {
+		sub.TrialPrice = *u.TrialPrice
+	}
+	if u.Members != nil {
+		sub.Members = *u.Members
+	}
+	if u.Category != nil {
+		sub.Category = *u.Category
+	}
+	if u.Tags != nil {
+		sub.Tags = *u.Tags
+	}
+	if u.AutoRenew != nil {
+		sub.AutoRenew = *u.AutoRenew
+	}
+	if u.BillingPeriod != nil {
+		sub.BillingPeriod = *u.BillingPeriod
+	}
+
+	// 2.End date (reset with new billing period)
+	if u.EndDate != nil {
+		sub.EndDate = *u.EndDate
+		if sub.EndDate.Month == 0 && sub.EndDate.Year == 0 {
+			sub.EndDate = sub.StartDate.AddDate(0, sub.Period())
+		}
+	}
+
+	// 3.Metadata
+	switch {
+	case u.Metadata != nil:
+		sub.Metadata, _ = v.Metadata("metadata", *u.Metadata, "metadata is invalid")
+	case u.MetadataPatch != nil:
+		merged, err := MergeMetadata(sub.Metadata, u.MetadataPatch)
+		if err != nil {
+			v.Fail("metadata", CodeInvalidValue, "metadata patch is invalid", err)
+			break
+		}
+		sub.Metadata, _ = v.Metadata("metadata", merged, "merged metadata is invalid")
+	}
+
+	// 4.Merged result
+	v.Required("service_name", sub.ServiceName, "empty service name")
+	v.NotNegative("price", int64(sub.Price), "price is invalid")
+	v.NotNegative("trial_months", int64(sub.TrialMonths), "trial months is invalid")
+	v.NotNegative("trial_price", int64(sub.TrialPrice), "trial price is invalid")
+	v.DateOrder("end_date", sub.StartDate, sub.EndDate, "start date greater than end date")
+
+	return v.Err()
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionUpdateApply(t *testing.T) {
+	current := func() Subscription {
+		return Subscription{SubscriptionSpec: SubscriptionSpec{
+			ServiceName:   "Netflix",
+			Price:         999,
+			StartDate:     Date{Month: 1, Year: 2026},
+			EndDate:       Date{Month: 12, Year: 2026},
+			Metadata:      json.RawMessage(`{"team":"core","seats":2}`),
+			BillingPeriod: 3,
+		}}
+	}
+
+	// 1.Only given fields are changed
+	sub := current()
+	price := Money(1099)
+	patch := json.RawMessage(`{"seats":null,"owner":"ops"}`)
+
+	require.NoError(t, SubscriptionUpdate{Price: &price, MetadataPatch: patch}.Apply(&sub))
+
+	expected := current()
+	expected.Price = price
+	expected.Metadata = json.RawMessage(`{"owner":"ops","team":"core"}`)
+	assert.Equal(t, expected, sub)
+
+	// 2.Metadata is replaced or removed
+	sub = current()
+	metadata := json.RawMessage(`{"owner":"ops"}`)
+	require.NoError(t, SubscriptionUpdate{Metadata: &metadata}.Apply(&sub))
+	assert.Equal(t, `{"owner":"ops"}`, string(sub.Metadata))
+
+	var removed json.RawMessage
+	require.NoError(t, SubscriptionUpdate{Metadata: &removed}.Apply(&sub))
+	assert.Nil(t, sub.Metadata)
+
+	// 3.Zero end date resets it to one billing period after start date
+	sub = current()
+	start := Date{Month: 6, Year: 2026}
+	require.NoError(t, SubscriptionUpdate{StartDate: &start, EndDate: &Date{}}.Apply(&sub))
+	assert.Equal(t, Date{Month: 9, Year: 2026}, sub.EndDate)
+
+	// 4.Trial, members, labels and renewal settings are replaced (end date is reset with new billing period)
+	sub = current()
+	trialMonths, trialPrice, category, autoRenew, billingPeriod := 2, Money(0), "video", true, 1
+	members := []Member{{Weight: 1}}
+	var noTags []string
+	require.NoError(t, SubscriptionUpdate{
+		EndDate:       &Date{},
+		TrialMonths:   &trialMonths,
+		TrialPrice:    &trialPrice,
+		Members:       &members,
+		Category:      &category,
+		Tags:          &noTags,
+		AutoRenew:     &autoRenew,
+		BillingPeriod: &billingPeriod,
+	}.Apply(&sub))
+
+	expected = current()
+	expected.EndDate = Date{Month: 2, Year: 2026}
+	expected.TrialMonths = 2
+	expected.Members = members
+	expected.Category = "video"
+	expected.AutoRenew = true
+	expected.BillingPeriod = 1
+	assert.Equal(t, expected, sub)
+
+	// 5.Merged result is validated
+	sub = current()
+	start = Date{Month: 1, Year: 2027}
+	err := SubscriptionUpdate{StartDate: &start, MetadataPatch: json.RawMessage(`{"bad key":1}`)}.Apply(&sub)
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Len(t, validationErr.Fields, 2)
+	assert.Equal(t, "metadata", validationErr.Fields[0].Field)
+	assert.Equal(t, "end_date", validationErr.Fields[1].Field)
+}
@@ -0,0 +1,175 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Codes of field validation errors
+const (
+	CodeMissingValue = "missing_value"
+	CodeInvalidValue = "invalid_value"
+)
+
+// FieldError is validation error of one request field or query parameter
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+
+	// Details for log (optional)
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError holds all field errors of request in order they were found
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Details of field errors for log (nil if there are no details)
+func (e *ValidationError) Details() error {
+	var details []error
+	for _, field := range e.Fields {
+		if field.Err != nil {
+			details = append(details, fmt.Errorf("%s: %w", field.Field, field.Err))
+		}
+	}
+
+	return errors.Join(details...)
+}
+
+// Validator checks request fields collecting errors of all of them instead of stopping on first one.
+// Rules return false (or zero value) when field is invalid, so dependent checks can be skipped
+type Validator struct {
+	fields []FieldError
+}
+
+// Fail records error of field
+func (v *Validator) Fail(field, code, message string, err error) {
+	v.fields = append(v.fields, FieldError{Field: field, Code: code, Message: message, Err: err})
+}
+
+// Err returns *ValidationError with all recorded errors, nil if request is valid
+func (v *Validator) Err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Fields: v.fields}
+}
+
+// Required checks value is not empty
+func (v *Validator) Required(field, value, message string) bool {
+	if value == "" {
+		v.Fail(field, CodeMissingValue, message, nil)
+		return false
+	}
+
+	return true
+}
+
+// NotNegative checks number (price, count of months) is not lower than 0
+func (v *Validator) NotNegative(field string, value int64, message string) bool {
+	if value < 0 {
+		v.Fail(field, CodeInvalidValue, message, fmt.Errorf("%s cannot be lower than 0", field))
+		return false
+	}
+
+	return true
+}
+
+// Date parses date in MM-YYYY format
+func (v *Validator) Date(field, value, message string) (Date, bool) {
+	date, err := DateFromString(value)
+	if err != nil {
+		v.Fail(field, CodeInvalidValue, message, err)
+		return Date{}, false
+	}
+
+	return date, true
+}
+
+// DateOrder checks start date is not greater than end date (error is reported for end date field)
+func (v *Validator) DateOrder(field string, start, end Date, message string) bool {
+	if start.GreaterThan(end) {
+		v.Fail(field, CodeInvalidValue, message, nil)
+		return false
+	}
+
+	return true
+}
+
+// UUID parses user id
+func (v *Validator) UUID(field, value, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		v.Fail(field, CodeInvalidValue, message, err)
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
+
+// Label normalizes category (see NormalizeLabel)
+func (v *Validator) Label(field, value, message string) (string, bool) {
+	label, err := NormalizeLabel(value)
+	if err != nil {
+		v.Fail(field, CodeInvalidValue, message, err)
+		return "", false
+	}
+
+	return label, true
+}
+
+// Tags normalizes tags (see NormalizeTags)
+func (v *Validator) Tags(field string, tags []string, message string) ([]string, bool) {
+	tags, err := NormalizeTags(tags)
+	if err != nil {
+		v.Fail(field, CodeInvalidValue, message, err)
+		return nil, false
+	}
+
+	return tags, true
+}
+
+// Metadata normalizes custom attributes (see NormalizeMetadata), reason is appended to message
+func (v *Validator) Metadata(field string, raw json.RawMessage, message string) (json.RawMessage, bool) {
+	metadata, err := NormalizeMetadata(raw)
+	if err != nil {
+		v.Fail(field, CodeInvalidValue, message+": "+err.Error(), err)
+		return nil, false
+	}
+
+	return metadata, true
+}
+
+// BillingPeriod checks billing period in months (see ValidateBillingPeriod)
+func (v *Validator) BillingPeriod(field string, months int, message string) bool {
+	if err := ValidateBillingPeriod(months); err != nil {
+		v.Fail(field, CodeInvalidValue, message, err)
+		return false
+	}
+
+	return true
+}
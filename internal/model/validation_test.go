@@ -0,0 +1,59 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatorCollectsAllErrors(t *testing.T) {
+	var v Validator
+
+	v.Required("service_name", "", "empty service name")
+	v.NotNegative("price", -1, "price is invalid")
+	start, ok := v.Date("start_date", "07-2025", "start date is invalid")
+	assert.True(t, ok)
+	end, ok := v.Date("end_date", "06-2025", "end date is invalid")
+	assert.True(t, ok)
+	v.DateOrder("end_date", start, end, "start date greater than end date")
+	v.UUID("user_id", "not-uuid", "user id is invalid")
+	v.Metadata("metadata", json.RawMessage(`[1]`), "metadata is invalid")
+
+	err := v.Err()
+	require.Error(t, err)
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+
+	fields := make([]string, 0, len(validationErr.Fields))
+	codes := make([]string, 0, len(validationErr.Fields))
+	for _, field := range validationErr.Fields {
+		fields = append(fields, field.Field)
+		codes = append(codes, field.Code)
+	}
+	assert.Equal(t, []string{"service_name", "price", "end_date", "user_id", "metadata"}, fields)
+	assert.Equal(t, []string{CodeMissingValue, CodeInvalidValue, CodeInvalidValue, CodeInvalidValue, CodeInvalidValue}, codes)
+
+	assert.Contains(t, err.Error(), "empty service name; price is invalid; start date greater than end date; user id is invalid; metadata is invalid: ")
+	assert.Error(t, validationErr.Details())
+}
+
+func TestValidatorValid(t *testing.T) {
+	var v Validator
+
+	assert.True(t, v.Required("service_name", "Netflix", "empty service name"))
+	assert.True(t, v.NotNegative("price", 0, "price is invalid"))
+	assert.True(t, v.BillingPeriod("billing_period", 12, "billing period is invalid"))
+
+	label, ok := v.Label("category", " Video ", "category is invalid")
+	assert.True(t, ok)
+	assert.Equal(t, "video", label)
+
+	tags, ok := v.Tags("tags", []string{"Work", "work"}, "tags are invalid")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"work"}, tags)
+
+	assert.NoError(t, v.Err())
+}
@@ -0,0 +1,210 @@
+package pg
+
+import (
+	"context"
+	"em_golang_rest_service_example/internal/model"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Get ledger charges of subscriptions with specified ids (ordered by month); charges posted
+// after asOf are skipped if it is set, so report can be reproduced as it was at that moment
+func (s *PostgresStorage) GetCharges(ids []int64, asOf *time.Time) (map[int64][]model.Charge, error) {
+	const op = "storage.postgres.GetCharges"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	return s.getCharges(context.Background(), s.pool, &loggerMsg, op, ids, asOf)
+}
+
+// Regenerate charges of all subscriptions (including deleted ones still having charges in ledger)
+func (s *PostgresStorage) RebuildCharges() (model.LedgerReport, error) {
+	const op = "storage.postgres.RebuildCharges"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	ctx := context.Background()
+
+	var report model.LedgerReport
+
+	// 1.Get subscriptions ids
+	rows, err := s.pool.Query(ctx, "SELECT id FROM subscription UNION SELECT subscription_id FROM charge ORDER BY 1")
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return report, fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return report, fmt.Errorf("%s: scan rows: %w", op, err)
+	}
+
+	// 2.Regenerate charges (each subscription in its own transaction)
+	for _, id := range ids {
+		appended, err := s.rebuildSubscriptionCharges(ctx, &loggerMsg, op, id)
+		if err != nil {
+			return report, err
+		}
+
+		report.Subscriptions++
+		report.Appended += appended
+	}
+
+	return report, nil
+}
+
+// Regenerate charges of one subscription in separate transaction
+func (s *PostgresStorage) rebuildSubscriptionCharges(ctx context.Context, loggerMsg *string, op string, id int64) (int64, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	appended, err := s.syncCharges(ctx, tx, loggerMsg, op, id)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return appended, nil
+}
+
+// Regenerate ledger charges of changed subscriptions within transaction of the change,
+// so change is not committed without its charges
+func (s *PostgresStorage) refreshCharges(ctx context.Context, tx pgx.Tx, loggerMsg *string, op string, ids ...int64) error {
+	for _, id := range ids {
+		if _, err := s.syncCharges(ctx, tx, loggerMsg, op, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Append charges correcting ledger to current state of subscription (all charges are reversed
+// if subscription is deleted) within transaction; returns number of appended charges
+func (s *PostgresStorage) syncCharges(ctx context.Context, tx pgx.Tx, loggerMsg *string, op string, id int64) (int64, error) {
+	// 1.Lock subscription charges, so concurrent regenerations cannot append the same correction
+	// twice (subscription is read after lock, so change committed meanwhile is seen)
+	_, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", id)
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: lock charges: %w", op, err)
+	}
+
+	// 2.Get subscription with details
+	rows, err := tx.Query(ctx, "SELECT "+subscriptionColumns+" FROM subscription WHERE id = $1", id)
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: get subscription: %w", op, err)
+	}
+
+	subscriptions, err := s.getSubscriptionsFromPgRows(loggerMsg, op, rows, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.getSubscriptionsDetails(ctx, tx, loggerMsg, op, subscriptions, nil); err != nil {
+		return 0, err
+	}
+
+	var expected []model.Charge
+	if len(subscriptions) > 0 {
+		expected = subscriptions[0].ExpectedCharges()
+	}
+
+	// 3.Compare with posted charges
+	posted, err := s.getCharges(ctx, tx, loggerMsg, op, []int64{id}, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	adjustments, err := model.ChargeAdjustments(posted[id], expected)
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// 4.Append corrections
+	for _, charge := range adjustments {
+		_, err := tx.Exec(
+			ctx,
+			"INSERT INTO charge (subscription_id,user_id,month,amount) values ($1,$2,$3,$4)",
+			id, charge.UserID.String(), charge.Month.ToStringISO(), int64(charge.Amount),
+		)
+		if err != nil {
+			s.logger.Error(*loggerMsg, "details", err)
+			return 0, fmt.Errorf("%s: insert charge: %w", op, err)
+		}
+	}
+
+	return int64(len(adjustments)), nil
+}
+
+// Query runner: pool or transaction
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Get ledger charges (ordered by month and posting) of subscriptions with specified ids,
+// charges posted after asOf are skipped if it is set
+func (s *PostgresStorage) getCharges(ctx context.Context, q querier, loggerMsg *string, op string, ids []int64, asOf *time.Time) (map[int64][]model.Charge, error) {
+	charges := make(map[int64][]model.Charge)
+
+	// 1.Run query
+	query := `
+		SELECT id, subscription_id, user_id, month::text, amount, posted_at
+		FROM charge
+		WHERE subscription_id = ANY($1) AND ($2::timestamptz IS NULL OR posted_at <= $2)
+		ORDER BY month, id
+	`
+
+	rows, err := q.Query(ctx, query, ids, asOf)
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: query charges: %w", op, err)
+	}
+	defer rows.Close()
+
+	// 2.Get data
+	for rows.Next() {
+		var charge model.Charge
+		var userId, month string
+
+		err := rows.Scan(&charge.ID, &charge.SubscriptionID, &userId, &month, &charge.Amount, &charge.PostedAt)
+		if err != nil {
+			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while parsing db data: %w", err))
+			return nil, fmt.Errorf("%s: scan charge row: %w", op, err)
+		}
+
+		charge.UserID, err = uuid.Parse(userId)
+		if err != nil {
+			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while parsing charge user id: %w", err))
+			return nil, fmt.Errorf("%s: parse charge user id: %w", op, err)
+		}
+
+		charge.Month, err = model.DateFromStringISO(month)
+		if err != nil {
+			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while getting charge month: %w", err))
+			return nil, fmt.Errorf("%s: getting charge month: %w", op, err)
+		}
+
+		charges[charge.SubscriptionID] = append(charges[charge.SubscriptionID], charge)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: iterate charges: %w", op, err)
+	}
+
+	return charges, nil
+}
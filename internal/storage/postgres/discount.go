@@ -0,0 +1,152 @@
+package pg
+
+import (
+	"context"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func (s *PostgresStorage) AddDiscount(id int64, discount model.Discount) (int64, error) {
+	const op = "storage.postgres.AddDiscount"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	ctx := context.Background()
+
+	// 1.Prepare transaction
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	// 2.Run query
+	query := `
+	    INSERT INTO subscription_discount (subscription_id,kind,percent,amount,valid_from,valid_to)
+		values ($1,$2,$3,$4,$5,$6)
+		RETURNING id
+	`
+
+	var discountId int64
+	err = tx.QueryRow(
+		ctx, query,
+		id,
+		string(discount.Kind),
+		discount.Percent,
+		int64(discount.Amount),
+		optDateToISO(discount.ValidFrom),
+		optDateToISO(discount.ValidTo),
+	).Scan(&discountId)
+
+	// 3.Handle errors
+	if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == pgErrConstraintForeignKey {
+		s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrSubscribtionNotFound)
+	}
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	if err := touchSubscription(ctx, tx, id); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// 4.Regenerate charges and commit changes
+	if err := s.refreshCharges(ctx, tx, &loggerMsg, op, id); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return discountId, nil
+}
+
+// Get discounts (ordered by id) for subscriptions with specified ids
+func (s *PostgresStorage) getDiscounts(ctx context.Context, q querier, loggerMsg *string, op string, ids []int64) (map[int64][]model.Discount, error) {
+	discounts := make(map[int64][]model.Discount)
+	if len(ids) == 0 {
+		return discounts, nil
+	}
+
+	// 1.Run query
+	query := `
+		SELECT id, subscription_id, kind, percent, amount, valid_from::text, valid_to::text
+		FROM subscription_discount
+		WHERE subscription_id = ANY($1)
+		ORDER BY id
+	`
+
+	rows, err := q.Query(ctx, query, ids)
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: query discounts: %w", op, err)
+	}
+	defer rows.Close()
+
+	// 2.Get data
+	for rows.Next() {
+		var discount model.Discount
+		var subscriptionId int64
+		var kind string
+		var validFrom, validTo *string
+
+		err := rows.Scan(&discount.ID, &subscriptionId, &kind, &discount.Percent, &discount.Amount, &validFrom, &validTo)
+		if err != nil {
+			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while parsing db data: %w", err))
+			return nil, fmt.Errorf("%s: scan discount row: %w", op, err)
+		}
+		discount.Kind = model.DiscountKind(kind)
+
+		discount.ValidFrom, err = optDateFromISO(validFrom)
+		if err != nil {
+			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while getting valid from date: %w", err))
+			return nil, fmt.Errorf("%s: getting valid from date: %w", op, err)
+		}
+
+		discount.ValidTo, err = optDateFromISO(validTo)
+		if err != nil {
+			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while getting valid to date: %w", err))
+			return nil, fmt.Errorf("%s: getting valid to date: %w", op, err)
+		}
+
+		discounts[subscriptionId] = append(discounts[subscriptionId], discount)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: iterate discounts: %w", op, err)
+	}
+
+	return discounts, nil
+}
+
+// Convert optional date to nullable ISO string
+func optDateToISO(d *model.Date) *string {
+	if d == nil {
+		return nil
+	}
+	str := d.ToStringISO()
+	return &str
+}
+
+// Convert nullable ISO string to optional date
+func optDateFromISO(str *string) (*model.Date, error) {
+	if str == nil {
+		return nil, nil
+	}
+
+	d, err := model.DateFromStringISO(*str)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
@@ -0,0 +1,122 @@
+package pg
+
+import (
+	"context"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Reserve idempotency key for request in progress; expired key is taken over,
+// storage.ErrIdempotencyKeyExists is returned if key is used by another request
+func (s *PostgresStorage) ReserveIdempotencyKey(key, requestHash string, ttl time.Duration) error {
+	const op = "storage.postgres.ReserveIdempotencyKey"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	ctx := context.Background()
+
+	// 1.Insert key (or replace expired one), concurrent inserts of the same key wait for each other
+	res, err := s.pool.Exec(
+		ctx,
+		`INSERT INTO idempotency_key (key,request_hash,status,content_type,body,created_at,expires_at)
+		values ($1,$2,0,'',NULL,now(),now() + make_interval(secs => $3))
+		ON CONFLICT (key) DO UPDATE SET
+			request_hash = excluded.request_hash, status = 0, content_type = '', body = NULL,
+			created_at = excluded.created_at, expires_at = excluded.expires_at
+		WHERE idempotency_key.expires_at <= excluded.created_at`,
+		key, requestHash, ttl.Seconds(),
+	)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	// 2.Check if was reserved
+	if res.RowsAffected() == 0 {
+		return storage.ErrIdempotencyKeyExists
+	}
+
+	return nil
+}
+
+// Get not expired idempotency key
+func (s *PostgresStorage) GetIdempotencyKey(key string) (model.IdempotencyRecord, error) {
+	const op = "storage.postgres.GetIdempotencyKey"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	var record model.IdempotencyRecord
+
+	err := s.pool.QueryRow(
+		context.Background(),
+		`SELECT key, request_hash, status, content_type, body, expires_at
+		FROM idempotency_key
+		WHERE key = $1 AND expires_at > now()`,
+		key,
+	).Scan(&record.Key, &record.RequestHash, &record.Status, &record.ContentType, &record.Body, &record.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.IdempotencyRecord{}, storage.ErrIdempotencyKeyNotFound
+	}
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return model.IdempotencyRecord{}, fmt.Errorf("%s: query idempotency key: %w", op, err)
+	}
+
+	return record, nil
+}
+
+// Save response of request reserved idempotency key
+func (s *PostgresStorage) SaveIdempotentResponse(key string, status int, contentType string, body []byte) error {
+	const op = "storage.postgres.SaveIdempotentResponse"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Update
+	res, err := s.pool.Exec(
+		context.Background(),
+		"UPDATE idempotency_key SET status = $1, content_type = $2, body = $3 WHERE key = $4",
+		status, contentType, body, key,
+	)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	// 2.Check if was updated
+	if res.RowsAffected() == 0 {
+		s.logger.Error(loggerMsg, "details", storage.ErrIdempotencyKeyNotFound)
+		return storage.ErrIdempotencyKeyNotFound
+	}
+
+	return nil
+}
+
+// Release idempotency key (request can be retried as new one)
+func (s *PostgresStorage) DeleteIdempotencyKey(key string) error {
+	const op = "storage.postgres.DeleteIdempotencyKey"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	_, err := s.pool.Exec(context.Background(), "DELETE FROM idempotency_key WHERE key = $1", key)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return nil
+}
+
+// Delete expired idempotency keys; returns number of deleted keys
+func (s *PostgresStorage) DeleteExpiredIdempotencyKeys() (int64, error) {
+	const op = "storage.postgres.DeleteExpiredIdempotencyKeys"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	res, err := s.pool.Exec(context.Background(), "DELETE FROM idempotency_key WHERE expires_at <= now()")
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return res.RowsAffected(), nil
+}
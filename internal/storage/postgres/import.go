@@ -0,0 +1,165 @@
+package pg
+
+import (
+	"context"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Import chunk of subscriptions in one transaction (rolled back in dry run); existing subscriptions are handled
+// according to policy, every row is isolated by savepoint so failed row does not affect others
+func (s *PostgresStorage) ImportSubscriptions(specs []model.SubscriptionSpec, policy model.ConflictPolicy, dryRun bool) ([]model.ImportResult, error) {
+	const op = "storage.postgres.ImportSubscriptions"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Prepare transaction
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	// 2.Import rows
+	results := make([]model.ImportResult, 0, len(specs))
+	var changed []int64
+
+	for _, spec := range specs {
+		result, err := importSubscription(ctx, tx, spec, policy)
+		if err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if result.ID != 0 {
+			changed = append(changed, result.ID)
+		}
+		results = append(results, result)
+	}
+
+	if dryRun {
+		return results, nil
+	}
+
+	// 3.Generate charges and commit changes
+	if err := s.refreshCharges(ctx, tx, &loggerMsg, op, changed...); err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return results, nil
+}
+
+// Import one row within savepoint (nested transaction); only errors breaking the whole transaction are returned
+func importSubscription(ctx context.Context, tx pgx.Tx, spec model.SubscriptionSpec, policy model.ConflictPolicy) (model.ImportResult, error) {
+	row, err := tx.Begin(ctx)
+	if err != nil {
+		return model.ImportResult{}, fmt.Errorf("create savepoint: %w", err)
+	}
+
+	defer row.Rollback(ctx)
+
+	outcome := model.ImportCreated
+
+	id, err := insertSubscription(ctx, row, spec)
+	if errors.Is(err, storage.ErrSubscriptionExists) {
+		// Failed statement aborts transaction, so row is started again
+		if err := row.Rollback(ctx); err != nil {
+			return model.ImportResult{}, fmt.Errorf("rollback to savepoint: %w", err)
+		}
+
+		switch policy {
+		case model.ConflictSkip:
+			return model.ImportResult{Outcome: model.ImportSkipped}, nil
+		case model.ConflictUpdate:
+			row, err = tx.Begin(ctx)
+			if err != nil {
+				return model.ImportResult{}, fmt.Errorf("create savepoint: %w", err)
+			}
+
+			defer row.Rollback(ctx)
+
+			outcome = model.ImportUpdated
+			id, err = replaceSubscription(ctx, row, spec)
+		default:
+			err = storage.ErrSubscriptionExists
+		}
+	}
+	if err != nil {
+		return model.ImportResult{Outcome: model.ImportFailed, Err: err}, nil
+	}
+
+	if err := row.Commit(ctx); err != nil {
+		return model.ImportResult{}, fmt.Errorf("release savepoint: %w", err)
+	}
+
+	return model.ImportResult{Outcome: outcome, ID: id}, nil
+}
+
+// Replace data of existing subscription of the same service and user with imported one,
+// price schedule, discounts, status history and renewals are kept
+func replaceSubscription(ctx context.Context, tx pgx.Tx, spec model.SubscriptionSpec) (int64, error) {
+	// 1.Find subscription
+	serviceId, serviceName, _, err := resolveOrRegisterService(ctx, tx, spec.ServiceName, spec.Price)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = tx.QueryRow(ctx, "SELECT id FROM subscription WHERE service_name = $1 AND user_id = $2", serviceName, spec.UserID.String()).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, storage.ErrSubscribtionNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("find subscription: %w", err)
+	}
+
+	// 2.Update subscription
+	query := `
+		UPDATE subscription SET
+			price = $1, start_date = $2, end_date = $3, trial_months = $4, trial_price = $5, service_id = $6,
+			category = COALESCE(NULLIF($7::text,''),(SELECT LOWER(TRIM(category)) FROM service WHERE id = $6),''),
+			metadata = $8, auto_renew = $9, billing_period = $10, updated_at = now()
+		WHERE id = $11
+	`
+
+	_, err = tx.Exec(
+		ctx, query,
+		int64(spec.Price), spec.StartDate.ToStringISO(), spec.EndDate.ToStringISO(), spec.TrialMonths, int64(spec.TrialPrice), serviceId,
+		spec.Category,
+		metadataArg(spec.Metadata), spec.AutoRenew, spec.Period(),
+		id,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("update subscription: %w", err)
+	}
+
+	// 3.Replace members and tags
+	if _, err := tx.Exec(ctx, "DELETE FROM subscription_member WHERE subscription_id = $1", id); err != nil {
+		return 0, fmt.Errorf("delete members: %w", err)
+	}
+	if err := insertMembers(ctx, tx, id, spec.Members); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM subscription_tag WHERE subscription_id = $1", id); err != nil {
+		return 0, fmt.Errorf("delete tags: %w", err)
+	}
+	if err := insertTags(ctx, tx, id, spec.Tags); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
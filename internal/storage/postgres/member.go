@@ -0,0 +1,136 @@
+package pg
+
+import (
+	"context"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Replace members of shared subscription (empty members make owner pay alone)
+func (s *PostgresStorage) SetMembers(id int64, members []model.Member) error {
+	const op = "storage.postgres.SetMembers"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	ctx := context.Background()
+
+	// 1.Prepare transaction
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	// 2.Lock subscription (concurrent replacements are applied one by one)
+	res, err := tx.Exec(ctx, "SELECT 1 FROM subscription WHERE id = $1 FOR UPDATE", id)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: lock subscription: %w", op, err)
+	}
+	if res.RowsAffected() == 0 {
+		s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
+		return storage.ErrSubscribtionNotFound
+	}
+
+	// 3.Replace members
+	_, err = tx.Exec(ctx, "DELETE FROM subscription_member WHERE subscription_id = $1", id)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: delete members: %w", op, err)
+	}
+
+	if err := insertMembers(ctx, tx, id, members); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := touchSubscription(ctx, tx, id); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// 4.Regenerate charges and commit changes
+	if err := s.refreshCharges(ctx, tx, &loggerMsg, op, id); err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+func insertMembers(ctx context.Context, tx pgx.Tx, id int64, members []model.Member) error {
+	for _, member := range members {
+		_, err := tx.Exec(
+			ctx,
+			"INSERT INTO subscription_member (subscription_id,user_id,weight) values ($1,$2,$3)",
+			id, member.UserID.String(), member.Weight,
+		)
+		if err != nil {
+			return fmt.Errorf("insert member: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Get members (ordered by id) of subscriptions with specified ids
+func (s *PostgresStorage) getMembers(ctx context.Context, q querier, loggerMsg *string, op string, ids []int64) (map[int64][]model.Member, error) {
+	members := make(map[int64][]model.Member)
+
+	// 1.Run query
+	query := `
+		SELECT subscription_id, user_id, weight
+		FROM subscription_member
+		WHERE subscription_id = ANY($1)
+		ORDER BY id
+	`
+
+	rows, err := q.Query(ctx, query, ids)
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: query members: %w", op, err)
+	}
+	defer rows.Close()
+
+	// 2.Get data
+	for rows.Next() {
+		var member model.Member
+		var subscriptionId int64
+		var userId string
+
+		if err := rows.Scan(&subscriptionId, &userId, &member.Weight); err != nil {
+			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while parsing db data: %w", err))
+			return nil, fmt.Errorf("%s: scan member row: %w", op, err)
+		}
+
+		member.UserID, err = uuid.Parse(userId)
+		if err != nil {
+			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while parsing member user id: %w", err))
+			return nil, fmt.Errorf("%s: parse member user id: %w", op, err)
+		}
+
+		members[subscriptionId] = append(members[subscriptionId], member)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: iterate members: %w", op, err)
+	}
+
+	return members, nil
+}
+
+// Condition matching subscriptions owned by user or shared with user (n is number of user id placeholder)
+func userCondition(n int) string {
+	return fmt.Sprintf("(user_id = $%d OR id IN (SELECT subscription_id FROM subscription_member WHERE user_id = $%d))", n, n)
+}
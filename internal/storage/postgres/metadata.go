@@ -0,0 +1,20 @@
+package pg
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Condition matching subscriptions with metadata value (n and m are numbers of key and value placeholders)
+func metadataCondition(n, m int) string {
+	return fmt.Sprintf("metadata ->> $%d = $%d", n, m)
+}
+
+// Get metadata as statement argument (NULL if not set)
+func metadataArg(metadata json.RawMessage) interface{} {
+	if metadata == nil {
+		return nil
+	}
+
+	return string(metadata)
+}
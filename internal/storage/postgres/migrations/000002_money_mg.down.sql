@@ -0,0 +1,2 @@
+ALTER TABLE subscription DROP CONSTRAINT IF EXISTS check_price_non_negative;
+ALTER TABLE subscription ALTER COLUMN price TYPE INTEGER USING (price / 100)::INTEGER;
@@ -0,0 +1,3 @@
+-- Prices are stored in minor units (kopecks) instead of whole rubles
+ALTER TABLE subscription ALTER COLUMN price TYPE BIGINT USING price::BIGINT * 100;
+ALTER TABLE subscription ADD CONSTRAINT check_price_non_negative CHECK (price >= 0);
@@ -0,0 +1 @@
+DROP TABLE subscription_price;
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS subscription_price(
+    id SERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES subscription(id) ON DELETE CASCADE,
+    price BIGINT NOT NULL CHECK (price >= 0),
+    effective_from DATE NOT NULL,
+    CONSTRAINT unique_subscription_price UNIQUE (subscription_id, effective_from)
+);
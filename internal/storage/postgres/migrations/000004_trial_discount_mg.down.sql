@@ -0,0 +1,4 @@
+DROP TABLE subscription_discount;
+
+ALTER TABLE subscription DROP COLUMN trial_price;
+ALTER TABLE subscription DROP COLUMN trial_months;
@@ -0,0 +1,4 @@
+DROP TABLE subscription_status_transition;
+
+DROP INDEX idx_subscription_status;
+ALTER TABLE subscription DROP COLUMN status;
@@ -0,0 +1,15 @@
+ALTER TABLE subscription ADD COLUMN status TEXT NOT NULL DEFAULT 'active'
+    CHECK (status IN ('active', 'paused', 'cancelled', 'expired'));
+
+CREATE INDEX IF NOT EXISTS idx_subscription_status ON subscription(status);
+
+CREATE TABLE IF NOT EXISTS subscription_status_transition(
+    id SERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES subscription(id) ON DELETE CASCADE,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    effective_from DATE NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_status_transition_subscription_id ON subscription_status_transition(subscription_id);
@@ -0,0 +1,5 @@
+DROP INDEX idx_subscription_service_id;
+ALTER TABLE subscription DROP COLUMN service_id;
+
+DROP TABLE service_alias;
+DROP TABLE service;
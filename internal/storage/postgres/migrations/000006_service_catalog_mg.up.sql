@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS service(
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    name_key TEXT NOT NULL UNIQUE,
+    default_price BIGINT NOT NULL DEFAULT 0 CHECK (default_price >= 0),
+    category TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS service_alias(
+    id SERIAL PRIMARY KEY,
+    service_id INTEGER NOT NULL REFERENCES service(id) ON DELETE CASCADE,
+    alias TEXT NOT NULL,
+    alias_key TEXT NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_service_alias_service_id ON service_alias(service_id);
+
+ALTER TABLE subscription ADD COLUMN service_id INTEGER REFERENCES service(id);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_service_id ON subscription(service_id);
@@ -0,0 +1,2 @@
+-- Per-user queries (user subscriptions, summary, deletion) filter by user only
+CREATE INDEX IF NOT EXISTS idx_subscription_user_id ON subscription(user_id);
@@ -0,0 +1,12 @@
+ALTER TABLE subscription ADD COLUMN category TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_subscription_category ON subscription(category);
+
+CREATE TABLE IF NOT EXISTS subscription_tag(
+    id SERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES subscription(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    CONSTRAINT unique_subscription_tag UNIQUE (subscription_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_tag_tag ON subscription_tag(tag);
@@ -0,0 +1,2 @@
+-- Custom attributes as JSON object (NULL if not set)
+ALTER TABLE subscription ADD COLUMN metadata JSONB CHECK (metadata IS NULL OR jsonb_typeof(metadata) = 'object');
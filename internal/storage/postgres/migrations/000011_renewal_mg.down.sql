@@ -0,0 +1,4 @@
+DROP TABLE subscription_renewal;
+
+ALTER TABLE subscription DROP COLUMN billing_period;
+ALTER TABLE subscription DROP COLUMN auto_renew;
@@ -0,0 +1,16 @@
+ALTER TABLE subscription ADD COLUMN auto_renew BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Months the subscription is extended by on renewal
+ALTER TABLE subscription ADD COLUMN billing_period INTEGER NOT NULL DEFAULT 1 CHECK (billing_period BETWEEN 1 AND 12);
+
+CREATE TABLE IF NOT EXISTS subscription_renewal(
+    id SERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES subscription(id) ON DELETE CASCADE,
+    -- Period end is exclusive
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    price BIGINT NOT NULL CHECK (price >= 0),
+    renewed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Period is recorded once even if renewer runs several times
+    CONSTRAINT unique_subscription_renewal UNIQUE (subscription_id, period_start)
+);
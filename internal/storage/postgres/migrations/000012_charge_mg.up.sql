@@ -0,0 +1,13 @@
+-- Billing ledger: rows are only appended, changed charges are corrected by difference entries.
+-- Subscription is not referenced, so charges of deleted subscriptions are kept with their reversals
+CREATE TABLE IF NOT EXISTS charge(
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL,
+    user_id TEXT NOT NULL,
+    month DATE NOT NULL,
+    -- Negative for corrections
+    amount BIGINT NOT NULL,
+    posted_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_charge_subscription ON charge(subscription_id, month);
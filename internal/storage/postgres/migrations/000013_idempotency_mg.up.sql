@@ -0,0 +1,14 @@
+-- Responses of requests with Idempotency-Key replayed on retries until they expire
+CREATE TABLE IF NOT EXISTS idempotency_key(
+    key TEXT PRIMARY KEY,
+    -- SHA-256 of method, path and body of the first request
+    request_hash TEXT NOT NULL,
+    -- 0 while the first request is in progress
+    status INTEGER NOT NULL DEFAULT 0,
+    content_type TEXT NOT NULL DEFAULT '',
+    body BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_key_expires ON idempotency_key(expires_at);
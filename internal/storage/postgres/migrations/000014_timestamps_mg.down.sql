@@ -0,0 +1,2 @@
+ALTER TABLE subscription DROP COLUMN updated_at;
+ALTER TABLE subscription DROP COLUMN created_at;
@@ -0,0 +1,3 @@
+-- Moments of creation and last change (changes of prices, discounts, members, tags and status move updated_at too)
+ALTER TABLE subscription ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE subscription ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
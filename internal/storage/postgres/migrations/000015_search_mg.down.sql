@@ -0,0 +1,3 @@
+DROP INDEX idx_subscription_service_name_trgm;
+DROP INDEX idx_subscription_search_vector;
+ALTER TABLE subscription DROP COLUMN search_vector;
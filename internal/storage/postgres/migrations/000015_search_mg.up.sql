@@ -0,0 +1,7 @@
+-- Full-text search by words of service name and fuzzy search by trigram similarity (for misspelled names)
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE subscription ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (to_tsvector('simple', service_name)) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_subscription_search_vector ON subscription USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_subscription_service_name_trgm ON subscription USING GIN (service_name gin_trgm_ops);
@@ -5,11 +5,14 @@ import (
 	"em_golang_rest_service_example/internal/config"
 	"em_golang_rest_service_example/internal/model"
 	"em_golang_rest_service_example/internal/storage"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,7 +25,8 @@ const (
 	pgUserEnv  = "PG_USER"
 	pgUserPass = "PG_PASS"
 
-	pgErrConstraintUnique = "23505"
+	pgErrConstraintUnique     = "23505"
+	pgErrConstraintForeignKey = "23503"
 )
 
 type PostgresStorage struct {
@@ -96,119 +100,124 @@ func (s *PostgresStorage) CreateSubscription(spec model.SubscriptionSpec) (int64
 
 	defer tx.Rollback(ctx)
 
-	// 2.Run transaction
+	// 2.Insert subscription with members and tags
+	id, err := insertSubscription(ctx, tx, spec)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// 3.Generate charges and commit changes
+	if err := s.refreshCharges(ctx, tx, &loggerMsg, op, id); err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// Insert subscription (it gets canonical name of catalog service registered if needed), its members and tags
+func insertSubscription(ctx context.Context, tx pgx.Tx, spec model.SubscriptionSpec) (int64, error) {
+	// 1.Get catalog service
+	serviceId, serviceName, _, err := resolveOrRegisterService(ctx, tx, spec.ServiceName, spec.Price)
+	if err != nil {
+		return 0, err
+	}
+
+	// 2.Insert subscription
 	query := `
-	    INSERT INTO subscription (service_name,price,user_id,start_date,end_date)
-		values ($1,$2,$3,$4,$5)
+	    INSERT INTO subscription (service_name,price,user_id,start_date,end_date,trial_months,trial_price,service_id,category,metadata,auto_renew,billing_period)
+		values ($1,$2,$3,$4,$5,$6,$7,$8,COALESCE(NULLIF($9::text,''),(SELECT LOWER(TRIM(category)) FROM service WHERE id = $8),''),$10,$11,$12)
 		RETURNING id
 	`
 
 	var idStr string
 	err = tx.QueryRow(
 		ctx, query,
-		spec.ServiceName,
-		spec.Price,
+		serviceName,
+		int64(spec.Price),
 		spec.UserID.String(),
 		spec.StartDate.ToStringISO(),
 		spec.EndDate.ToStringISO(),
+		spec.TrialMonths,
+		int64(spec.TrialPrice),
+		serviceId,
+		spec.Category,
+		metadataArg(spec.Metadata),
+		spec.AutoRenew,
+		spec.Period(),
 	).Scan(&idStr)
 
 	if err != nil {
 		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == pgErrConstraintUnique {
-			s.logger.Error(loggerMsg, "details", storage.ErrSubscriptionExists)
-			return 0, fmt.Errorf("%s: %w", op, storage.ErrSubscriptionExists)
+			return 0, storage.ErrSubscriptionExists
 		}
 
-		s.logger.Error(loggerMsg, "details", err)
-		return 0, fmt.Errorf("%s: execute statement: %w", op, err)
+		return 0, fmt.Errorf("execute statement: %w", err)
 	}
 
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		s.logger.Error(loggerMsg, "details", err)
-		return 0, fmt.Errorf("%s: failed to get id as integer: %w", op, err)
+		return 0, fmt.Errorf("failed to get id as integer: %w", err)
 	}
 
-	// 3.Commit changes
-	err = tx.Commit(ctx)
-	if err != nil {
-		s.logger.Error(loggerMsg, "details", err)
-		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	// 3.Store members of shared subscription and tags
+	if err := insertMembers(ctx, tx, id, spec.Members); err != nil {
+		return 0, err
+	}
+
+	if err := insertTags(ctx, tx, id, spec.Tags); err != nil {
+		return 0, err
 	}
 
 	return id, nil
 }
 
 func (s *PostgresStorage) GetSubscription(id int64) (model.Subscription, error) {
+	return s.GetSubscriptionFields(id, nil)
+}
+
+// Read subscription with requested fields only (nil means all), columns and details not requested are not queried
+func (s *PostgresStorage) GetSubscriptionFields(id int64, fields model.FieldSet) (model.Subscription, error) {
 	const op = "storage.postgres.GetSubscription"
 	var loggerMsg string = fmt.Sprintf("operation is %s", op)
 
 	ctx := context.Background()
 
 	// 1.Run query
-	query := `
-	    SELECT
-	        id,
-		    service_name,
-		    price,
-		    user_id,
-		    start_date::text,
-		    end_date::text
-		FROM subscription
-		WHERE id = $1
-	`
-
-	row := s.pool.QueryRow(ctx, query, id)
-
-	var subscription model.Subscription
-
-	var startDate string
-	var endDate string
+	query := "SELECT " + selectColumns(fields) + " FROM subscription WHERE id = $1"
 
-	err := row.Scan(
-		&subscription.ID,
-		&subscription.ServiceName,
-		&subscription.Price,
-		&subscription.UserID,
-		&startDate,
-		&endDate,
-	)
+	subscription, err := s.scanSubscription(&loggerMsg, op, s.pool.QueryRow(ctx, query, id), fields)
 	if errors.Is(err, pgx.ErrNoRows) {
 		s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
 		return model.Subscription{}, storage.ErrSubscribtionNotFound
 	}
 	if err != nil {
-		s.logger.Error(loggerMsg, "details", err)
-		return model.Subscription{}, fmt.Errorf("%s: execute statement: %w", op, err)
+		return model.Subscription{}, err
 	}
 
-	// 2.Return subscription model data
+	// 2.Get subscription details (price schedule etc)
+	subscriptions := []model.Subscription{subscription}
 
-	// 2.1.Start date
-	start, err := model.DateFromStringISO(startDate)
+	err = s.getSubscriptionsDetails(ctx, s.pool, &loggerMsg, op, subscriptions, fields)
 	if err != nil {
-		s.logger.Error(loggerMsg, "details", fmt.Errorf("error while getting start date: %w", err))
-		return model.Subscription{}, fmt.Errorf("%s: getting start date: %w", op, err)
+		return model.Subscription{}, err
 	}
-	subscription.StartDate = start
 
-	// 2.2.End date
-	end, err := model.DateFromStringISO(endDate)
-	if err != nil {
-		s.logger.Error(loggerMsg, "details", fmt.Errorf("error while getting end date: %w", err))
-		return model.Subscription{}, fmt.Errorf("%s: getting end date: %w", op, err)
-	}
-	subscription.EndDate = end
-
-	return subscription, nil
+	return subscriptions[0], nil
 }
 
-func (s *PostgresStorage) UpdateSubscription(id int64, newServiceName string, newPrice int, newStart, newEnd model.Date) error {
+// Update subscription fields set in update; merged result is validated before it is written
+// (error wraps *model.ValidationError if it is invalid)
+func (s *PostgresStorage) UpdateSubscription(id int64, update model.SubscriptionUpdate) error {
 	const op = "storage.postgres.UpdateSubscription"
 	var loggerMsg string = fmt.Sprintf("operation is %s", op)
 
-	var res pgconn.CommandTag
-
 	// 1.Prepare transaction
 	ctx := context.Background()
 
@@ -220,29 +229,100 @@ func (s *PostgresStorage) UpdateSubscription(id int64, newServiceName string, ne
 
 	defer tx.Rollback(ctx)
 
-	// 2.Prepare query in according with optional end_date value
-	query := "UPDATE subscription SET service_name = $1, price = $2, start_date = $3"
-	args := []interface{}{newServiceName, newPrice, newStart.ToStringISO()}
+	// 2.Get current subscription locking it until commit
+	query := "SELECT " + subscriptionColumns + " FROM subscription WHERE id = $1 FOR UPDATE"
 
-	if !(newEnd.Month == 0 && newEnd.Year == 0) {
-		query += ", end_date = $4 WHERE id = $5"
-		args = append(args, newEnd.ToStringISO())
-	} else {
-		query += " WHERE id = $4"
+	subscription, err := s.scanSubscription(&loggerMsg, op, tx.QueryRow(ctx, query, id), nil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
+		return storage.ErrSubscribtionNotFound
 	}
-	args = append(args, id)
+	if err != nil {
+		return err
+	}
+
+	// 3.Merge and validate
+	if err := update.Apply(&subscription); err != nil {
+		s.logger.Info(loggerMsg, "details", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// 4.Get catalog service for new name
+	var serviceId *int64
+	if subscription.ServiceID != 0 {
+		serviceId = &subscription.ServiceID
+	}
+	if update.ServiceName != nil {
+		catalogId, catalogName, _, err := resolveOrRegisterService(ctx, tx, subscription.ServiceName, subscription.Price)
+		if err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return fmt.Errorf("%s: %w", op, err)
+		}
 
-	// 3.Run
-	res, err = tx.Exec(ctx, query, args...)
+		serviceId = &catalogId
+		subscription.ServiceName = catalogName
+	}
+
+	// 5.Write merged subscription (cleared category is taken from catalog service as on creation)
+	query = `
+		UPDATE subscription SET
+			service_name = $1, service_id = $2, price = $3, start_date = $4, end_date = $5, trial_months = $6, trial_price = $7,
+			category = CASE WHEN $8::boolean THEN COALESCE(NULLIF($9::text,''),(SELECT LOWER(TRIM(category)) FROM service WHERE id = $2),'') ELSE $9::text END,
+			metadata = $10, auto_renew = $11, billing_period = $12, updated_at = now()
+		WHERE id = $13
+	`
+
+	_, err = tx.Exec(ctx,
+		query,
+		subscription.ServiceName,
+		serviceId,
+		int64(subscription.Price),
+		subscription.StartDate.ToStringISO(),
+		subscription.EndDate.ToStringISO(),
+		subscription.TrialMonths,
+		int64(subscription.TrialPrice),
+		update.Category != nil,
+		subscription.Category,
+		metadataArg(subscription.Metadata),
+		subscription.AutoRenew,
+		subscription.Period(),
+		id,
+	)
 	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == pgErrConstraintUnique {
+			return storage.ErrSubscriptionExists
+		}
+
 		s.logger.Error(loggerMsg, "details", err)
-		return err
+		return fmt.Errorf("%s: update subscription: %w", op, err)
 	}
 
-	// 4.Check if was updated and commit in case of success
-	if res.RowsAffected() == 0 {
-		s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
-		return storage.ErrSubscribtionNotFound
+	// 6.Replace members and tags if they are updated
+	if update.Members != nil {
+		if _, err := tx.Exec(ctx, "DELETE FROM subscription_member WHERE subscription_id = $1", id); err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return fmt.Errorf("%s: delete members: %w", op, err)
+		}
+		if err := insertMembers(ctx, tx, id, subscription.Members); err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if update.Tags != nil {
+		if _, err := tx.Exec(ctx, "DELETE FROM subscription_tag WHERE subscription_id = $1", id); err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return fmt.Errorf("%s: delete tags: %w", op, err)
+		}
+		if err := insertTags(ctx, tx, id, subscription.Tags); err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	// 7.Regenerate charges and commit changes
+	if err := s.refreshCharges(ctx, tx, &loggerMsg, op, id); err != nil {
+		return err
 	}
 
 	err = tx.Commit(ctx)
@@ -278,12 +358,17 @@ func (s *PostgresStorage) DeleteSubscription(id int64) error {
 		return err
 	}
 
-	// 3.Check if was deleted and commit in case of success
+	// 3.Check if was deleted
 	if res.RowsAffected() == 0 {
 		s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
 		return storage.ErrSubscribtionNotFound
 	}
 
+	// 4.Reverse charges and commit changes
+	if err := s.refreshCharges(ctx, tx, &loggerMsg, op, id); err != nil {
+		return err
+	}
+
 	err = tx.Commit(ctx)
 	if err != nil {
 		s.logger.Error(loggerMsg, "details", err)
@@ -293,7 +378,8 @@ func (s *PostgresStorage) DeleteSubscription(id int64) error {
 	return nil
 }
 
-func (s *PostgresStorage) GetSubscriptions(limit, offset *int) ([]model.Subscription, error) {
+// Read filtered subscriptions with requested fields only (nil means all)
+func (s *PostgresStorage) GetSubscriptions(limit, offset *int, filter model.SubscriptionFilter, fields model.FieldSet) ([]model.Subscription, error) {
 	const op = "storage.postgres.GetSubscriptions"
 	var loggerMsg string = fmt.Sprintf("operation is %s", op)
 
@@ -309,12 +395,14 @@ func (s *PostgresStorage) GetSubscriptions(limit, offset *int) ([]model.Subscrip
 	}
 
 	// 2.Prepare and exec
-	query := "SELECT id, service_name, price, user_id, start_date::text, end_date::text FROM subscription"
+	query := "SELECT " + selectColumns(fields) + " FROM subscription WHERE 1 = 1"
 	args := []interface{}{}
 
+	query, args = appendFilter(query, args, filter)
+
 	if limit != nil {
-		query += " LIMIT $1 OFFSET $2"
 		args = append(args, *limit, *offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
 	}
 
 	rows, err := s.pool.Query(ctx, query, args...)
@@ -324,44 +412,124 @@ func (s *PostgresStorage) GetSubscriptions(limit, offset *int) ([]model.Subscrip
 	}
 
 	// 3.Parse and get data
-	subscriptions, err := s.getSubscriptionsFromPgRows(&loggerMsg, op, rows)
+	subscriptions, err := s.getSubscriptionsFromPgRows(&loggerMsg, op, rows, fields)
 	if err != nil {
 		return []model.Subscription{}, err
 	}
 
+	if fields.Has(model.FieldTags) {
+		if err := s.fillTags(ctx, &loggerMsg, op, subscriptions); err != nil {
+			return []model.Subscription{}, err
+		}
+	}
+
 	return subscriptions, nil
 }
 
-func (s *PostgresStorage) FilterSubscriptions(startDate, endDate model.Date, userId uuid.UUID, serviceName *string) ([]model.Subscription, error) {
+// Read filtered subscriptions (ordered by id) with requested fields only (nil means all) one by one
+// passing every row to yield as soon as it is scanned; tags are loaded with the same query,
+// other details stored in child tables are not, reading stops on first yield error or context cancellation
+func (s *PostgresStorage) StreamSubscriptions(ctx context.Context, limit, offset *int, filter model.SubscriptionFilter, fields model.FieldSet, yield func(sub *model.Subscription) error) error {
+	const op = "storage.postgres.StreamSubscriptions"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Validation
+	if limit != nil && offset == nil {
+		s.logger.Error(loggerMsg, "details", "no offset value while limit is set")
+		return errors.New("no offset value while limit is set")
+	} else if limit == nil && offset != nil {
+		s.logger.Error(loggerMsg, "details", "no limit value while offset is set")
+		return errors.New("no limit value while offset is set")
+	}
+
+	withTags := fields.Has(model.FieldTags)
+
+	// 2.Prepare and exec
+	query := "SELECT " + selectColumns(fields)
+	if withTags {
+		query += ", " + tagsColumn
+	}
+	query += " FROM subscription WHERE 1 = 1"
+	args := []interface{}{}
+
+	query, args = appendFilter(query, args, filter)
+	query += " ORDER BY id"
+
+	if limit != nil {
+		args = append(args, *limit, *offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	// 3.Pass rows
+	for rows.Next() {
+		// Driver notices cancellation asynchronously, so it is checked before every row too
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		var tags []string
+
+		row := extraColumnsScanner{row: rows}
+		if withTags {
+			row.extra = []any{&tags}
+		}
+
+		sub, err := s.scanSubscription(&loggerMsg, op, row, fields)
+		if err != nil {
+			return err
+		}
+
+		if len(tags) > 0 {
+			sub.Tags = tags
+		}
+
+		if err := yield(&sub); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) FilterSubscriptions(startDate, endDate model.Date, userId uuid.UUID, serviceName *string, filter model.SubscriptionFilter) ([]model.Subscription, error) {
 	const op = "storage.postgres.FilterSubscriptions"
 	var loggerMsg string = fmt.Sprintf("operation is %s", op)
 
 	ctx := context.Background()
 
 	// 2.Prepare and exec
-	query := `
-		SELECT
-			id,
-			service_name,
-			price,
-			user_id,
-			start_date::text,
-			end_date::text
-		FROM subscription
-		WHERE start_date > $1 AND end_date < $2
-	`
+	query := "SELECT " + subscriptionColumns + " FROM subscription WHERE start_date > $1 AND end_date < $2"
 	args := []interface{}{startDate.ToStringISO(), endDate.ToStringISO()}
 
+	// Shared subscriptions are matched for every member
 	if userId != uuid.Nil {
 		args = append(args, userId.String())
-		query += fmt.Sprintf(" AND user_id = $%d", len(args))
+		query += " AND " + userCondition(len(args))
 	}
 
+	// Service name matches catalog service names and aliases too
 	if serviceName != nil {
-		args = append(args, *serviceName)
-		query += fmt.Sprintf(" AND user_id = $%d", len(args))
+		args = append(args, *serviceName, model.ServiceNameKey(*serviceName))
+		query += fmt.Sprintf(` AND (service_name = $%d OR service_id IN (
+			SELECT id FROM service WHERE name_key = $%d UNION SELECT service_id FROM service_alias WHERE alias_key = $%d
+		))`, len(args)-1, len(args), len(args))
 	}
 
+	query, args = appendFilter(query, args, filter)
+
 	rows, err := s.pool.Query(ctx, query, args...)
 	if err != nil {
 		s.logger.Error(loggerMsg, "details", err)
@@ -369,7 +537,13 @@ func (s *PostgresStorage) FilterSubscriptions(startDate, endDate model.Date, use
 	}
 
 	// 3.Parse and get data
-	subscriptions, err := s.getSubscriptionsFromPgRows(&loggerMsg, op, rows)
+	subscriptions, err := s.getSubscriptionsFromPgRows(&loggerMsg, op, rows, nil)
+	if err != nil {
+		return []model.Subscription{}, err
+	}
+
+	// 4.Get details (price schedules etc) for cost calculation
+	err = s.getSubscriptionsDetails(ctx, s.pool, &loggerMsg, op, subscriptions, nil)
 	if err != nil {
 		return []model.Subscription{}, err
 	}
@@ -377,46 +551,261 @@ func (s *PostgresStorage) FilterSubscriptions(startDate, endDate model.Date, use
 	return subscriptions, nil
 }
 
-func (s *PostgresStorage) getSubscriptionsFromPgRows(loggerMsg *string, op string, rows pgx.Rows) ([]model.Subscription, error) {
-	var subscriptions []model.Subscription
+// Columns of subscription table in order expected by scanSubscription
+var subscriptionColumns = selectColumns(nil)
 
-	for rows.Next() {
-		var sub model.Subscription
-
-		var startDate string
-		var endDate string
-
-		err := rows.Scan(
-			&sub.ID,
-			&sub.ServiceName,
-			&sub.Price,
-			&sub.UserID,
-			&startDate,
-			&endDate,
-		)
-		if err != nil {
-			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while parsing db data: %w", err))
-			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+// Scan destinations of subscription row (some columns are converted after scan)
+type subscriptionRow struct {
+	sub       model.Subscription
+	startDate string
+	endDate   string
+	serviceId *int64
+	metadata  []byte
+}
+
+// Columns of subscription table by fields of response
+var subscriptionFieldColumns = []struct {
+	field  string
+	column string
+	dest   func(row *subscriptionRow) any
+}{
+	{model.FieldID, "id", func(row *subscriptionRow) any { return &row.sub.ID }},
+	{model.FieldServiceName, "service_name", func(row *subscriptionRow) any { return &row.sub.ServiceName }},
+	{model.FieldPrice, "price", func(row *subscriptionRow) any { return &row.sub.Price }},
+	{model.FieldUserID, "user_id", func(row *subscriptionRow) any { return &row.sub.UserID }},
+	{model.FieldStartDate, "start_date::text", func(row *subscriptionRow) any { return &row.startDate }},
+	{model.FieldEndDate, "end_date::text", func(row *subscriptionRow) any { return &row.endDate }},
+	{model.FieldTrialMonths, "trial_months", func(row *subscriptionRow) any { return &row.sub.TrialMonths }},
+	{model.FieldTrialPrice, "trial_price", func(row *subscriptionRow) any { return &row.sub.TrialPrice }},
+	{model.FieldStatus, "status", func(row *subscriptionRow) any { return &row.sub.Status }},
+	{model.FieldServiceID, "service_id", func(row *subscriptionRow) any { return &row.serviceId }},
+	{model.FieldCategory, "category", func(row *subscriptionRow) any { return &row.sub.Category }},
+	{model.FieldMetadata, "metadata", func(row *subscriptionRow) any { return &row.metadata }},
+	{model.FieldAutoRenew, "auto_renew", func(row *subscriptionRow) any { return &row.sub.AutoRenew }},
+	{model.FieldBillingPeriod, "billing_period", func(row *subscriptionRow) any { return &row.sub.BillingPeriod }},
+	{model.FieldCreatedAt, "created_at", func(row *subscriptionRow) any { return &row.sub.CreatedAt }},
+	{model.FieldUpdatedAt, "updated_at", func(row *subscriptionRow) any { return &row.sub.UpdatedAt }},
+}
+
+// Check if column of field is selected: id is always needed to load details and updated_at for Last-Modified
+func columnSelected(field string, fields model.FieldSet) bool {
+	return field == model.FieldID || field == model.FieldUpdatedAt || fields.Has(field)
+}
+
+// Columns of subscription table for requested fields (nil means all fields)
+func selectColumns(fields model.FieldSet) string {
+	columns := make([]string, 0, len(subscriptionFieldColumns))
+	for _, c := range subscriptionFieldColumns {
+		if columnSelected(c.field, fields) {
+			columns = append(columns, c.column)
+		}
+	}
+
+	return strings.Join(columns, ", ")
+}
+
+// Statement runner: pool or transaction
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// Move moment of last change of subscription whose details (stored in other tables) are changed
+func touchSubscription(ctx context.Context, db execer, id int64) error {
+	if _, err := db.Exec(ctx, "UPDATE subscription SET updated_at = now() WHERE id = $1", id); err != nil {
+		return fmt.Errorf("update last change moment: %w", err)
+	}
+
+	return nil
+}
+
+// Scanner of row with extra columns selected after subscriptionColumns
+type extraColumnsScanner struct {
+	row   pgx.Row
+	extra []any
+}
+
+func (s extraColumnsScanner) Scan(dest ...any) error {
+	return s.row.Scan(append(dest, s.extra...)...)
+}
+
+// Scan subscription table row selected with selectColumns(fields), fields not selected are left empty
+func (s *PostgresStorage) scanSubscription(loggerMsg *string, op string, row pgx.Row, fields model.FieldSet) (model.Subscription, error) {
+	var r subscriptionRow
+
+	dest := make([]any, 0, len(subscriptionFieldColumns))
+	for _, c := range subscriptionFieldColumns {
+		if columnSelected(c.field, fields) {
+			dest = append(dest, c.dest(&r))
 		}
+	}
 
-		// Start date
-		start, err := model.DateFromStringISO(startDate)
+	err := row.Scan(dest...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Subscription{}, err
+	}
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while parsing db data: %w", err))
+		return model.Subscription{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	sub := r.sub
+
+	if r.serviceId != nil {
+		sub.ServiceID = *r.serviceId
+	}
+
+	if r.metadata != nil {
+		sub.Metadata = json.RawMessage(r.metadata)
+	}
+
+	// Start date
+	if fields.Has(model.FieldStartDate) {
+		sub.StartDate, err = model.DateFromStringISO(r.startDate)
 		if err != nil {
 			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while getting start date: %w", err))
-			return []model.Subscription{}, fmt.Errorf("%s: getting start date: %w", op, err)
+			return model.Subscription{}, fmt.Errorf("%s: getting start date: %w", op, err)
 		}
-		sub.StartDate = start
+	}
 
-		// End date
-		end, err := model.DateFromStringISO(endDate)
+	// End date
+	if fields.Has(model.FieldEndDate) {
+		sub.EndDate, err = model.DateFromStringISO(r.endDate)
 		if err != nil {
 			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while getting end date: %w", err))
-			return []model.Subscription{}, fmt.Errorf("%s: getting end date: %w", op, err)
+			return model.Subscription{}, fmt.Errorf("%s: getting end date: %w", op, err)
+		}
+	}
+
+	return sub, nil
+}
+
+func (s *PostgresStorage) getSubscriptionsFromPgRows(loggerMsg *string, op string, rows pgx.Rows, fields model.FieldSet) ([]model.Subscription, error) {
+	defer rows.Close()
+
+	var subscriptions []model.Subscription
+
+	for rows.Next() {
+		sub, err := s.scanSubscription(loggerMsg, op, rows, fields)
+		if err != nil {
+			return []model.Subscription{}, err
 		}
-		sub.EndDate = end
 
 		subscriptions = append(subscriptions, sub)
 	}
 
+	if err := rows.Err(); err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return []model.Subscription{}, fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+
 	return subscriptions, nil
 }
+
+// Add optional filter conditions to query with WHERE clause (placeholders are numbered after existing args)
+func appendFilter(query string, args []interface{}, filter model.SubscriptionFilter) (string, []interface{}) {
+	if filter.Status != nil {
+		args = append(args, string(*filter.Status))
+		query += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+
+	if filter.Category != nil {
+		args = append(args, *filter.Category)
+		query += fmt.Sprintf(" AND category = $%d", len(args))
+	}
+
+	if filter.Tag != nil {
+		args = append(args, *filter.Tag)
+		query += " AND " + tagCondition(len(args))
+	}
+
+	// Keys are sorted for the same query text on the same filter
+	keys := make([]string, 0, len(filter.Metadata))
+	for key := range filter.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		args = append(args, key, filter.Metadata[key])
+		query += " AND " + metadataCondition(len(args)-1, len(args))
+	}
+
+	if filter.Expr != nil {
+		var cond string
+		cond, args = compileFilterExpr(filter.Expr, args)
+		query += " AND " + cond
+	}
+
+	return query, args
+}
+
+// Fill subscriptions details stored in child tables (price schedules, discounts, status history, members, tags and renewals);
+// only details listed in fields are loaded (nil means all)
+func (s *PostgresStorage) getSubscriptionsDetails(ctx context.Context, q querier, loggerMsg *string, op string, subscriptions []model.Subscription, fields model.FieldSet) error {
+	ids := make([]int64, 0, len(subscriptions))
+	for i := 0; i < len(subscriptions); i++ {
+		ids = append(ids, subscriptions[i].ID)
+	}
+
+	if fields.Has(model.FieldPriceSchedule) {
+		schedules, err := s.getPriceSchedules(ctx, q, loggerMsg, op, ids)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(subscriptions); i++ {
+			subscriptions[i].PriceSchedule = schedules[subscriptions[i].ID]
+		}
+	}
+
+	if fields.Has(model.FieldDiscounts) {
+		discounts, err := s.getDiscounts(ctx, q, loggerMsg, op, ids)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(subscriptions); i++ {
+			subscriptions[i].Discounts = discounts[subscriptions[i].ID]
+		}
+	}
+
+	if fields.Has(model.FieldStatusHistory) {
+		statusHistories, err := s.getStatusHistories(ctx, q, loggerMsg, op, ids)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(subscriptions); i++ {
+			subscriptions[i].StatusHistory = statusHistories[subscriptions[i].ID]
+		}
+	}
+
+	if fields.Has(model.FieldMembers) {
+		members, err := s.getMembers(ctx, q, loggerMsg, op, ids)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(subscriptions); i++ {
+			subscriptions[i].Members = members[subscriptions[i].ID]
+		}
+	}
+
+	if fields.Has(model.FieldTags) {
+		tags, err := s.getTags(ctx, q, loggerMsg, op, ids)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(subscriptions); i++ {
+			subscriptions[i].Tags = tags[subscriptions[i].ID]
+		}
+	}
+
+	if fields.Has(model.FieldRenewals) {
+		renewals, err := s.getRenewals(ctx, q, loggerMsg, op, ids)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(subscriptions); i++ {
+			subscriptions[i].Renewals = renewals[subscriptions[i].ID]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package pg
+
+import (
+	"context"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func (s *PostgresStorage) AddPriceChange(id int64, change model.PriceChange) (int64, error) {
+	const op = "storage.postgres.AddPriceChange"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	ctx := context.Background()
+
+	// 1.Prepare transaction
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	// 2.Run query
+	query := `
+	    INSERT INTO subscription_price (subscription_id,price,effective_from)
+		values ($1,$2,$3)
+		RETURNING id
+	`
+
+	var changeId int64
+	err = tx.QueryRow(
+		ctx, query,
+		id,
+		int64(change.Price),
+		change.EffectiveFrom.ToStringISO(),
+	).Scan(&changeId)
+
+	// 3.Handle errors
+	if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == pgErrConstraintUnique {
+		s.logger.Error(loggerMsg, "details", storage.ErrPriceChangeExists)
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrPriceChangeExists)
+	}
+	if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == pgErrConstraintForeignKey {
+		s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrSubscribtionNotFound)
+	}
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	if err := touchSubscription(ctx, tx, id); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// 4.Regenerate charges and commit changes
+	if err := s.refreshCharges(ctx, tx, &loggerMsg, op, id); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return changeId, nil
+}
+
+// Get price schedules (ordered by effective date) for subscriptions with specified ids
+func (s *PostgresStorage) getPriceSchedules(ctx context.Context, q querier, loggerMsg *string, op string, ids []int64) (map[int64][]model.PriceChange, error) {
+	schedules := make(map[int64][]model.PriceChange)
+	if len(ids) == 0 {
+		return schedules, nil
+	}
+
+	// 1.Run query
+	query := `
+		SELECT id, subscription_id, price, effective_from::text
+		FROM subscription_price
+		WHERE subscription_id = ANY($1)
+		ORDER BY effective_from
+	`
+
+	rows, err := q.Query(ctx, query, ids)
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: query price schedules: %w", op, err)
+	}
+	defer rows.Close()
+
+	// 2.Get data
+	for rows.Next() {
+		var change model.PriceChange
+		var subscriptionId int64
+		var effectiveFrom string
+
+		err := rows.Scan(&change.ID, &subscriptionId, &change.Price, &effectiveFrom)
+		if err != nil {
+			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while parsing db data: %w", err))
+			return nil, fmt.Errorf("%s: scan price change row: %w", op, err)
+		}
+
+		change.EffectiveFrom, err = model.DateFromStringISO(effectiveFrom)
+		if err != nil {
+			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while getting effective date: %w", err))
+			return nil, fmt.Errorf("%s: getting effective date: %w", op, err)
+		}
+
+		schedules[subscriptionId] = append(schedules[subscriptionId], change)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: iterate price schedules: %w", op, err)
+	}
+
+	return schedules, nil
+}
@@ -0,0 +1,190 @@
+package pg
+
+import (
+	"context"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"fmt"
+)
+
+// Turn auto-renewal on or off and set billing period in months
+func (s *PostgresStorage) SetAutoRenew(id int64, autoRenew bool, billingPeriod int) error {
+	const op = "storage.postgres.SetAutoRenew"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	ctx := context.Background()
+
+	// 1.Update
+	res, err := s.pool.Exec(ctx, "UPDATE subscription SET auto_renew = $1, billing_period = $2, updated_at = now() WHERE id = $3", autoRenew, billingPeriod, id)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	// 2.Check if was updated
+	if res.RowsAffected() == 0 {
+		s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
+		return storage.ErrSubscribtionNotFound
+	}
+
+	return nil
+}
+
+// Extend auto-renewing subscriptions ended before or in specified month by their billing periods
+// and record renewal periods; returns number of recorded periods (repeated runs record nothing new)
+func (s *PostgresStorage) RenewSubscriptions(month model.Date) (int64, error) {
+	const op = "storage.postgres.RenewSubscriptions"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	ctx := context.Background()
+
+	// 1.Get subscriptions to renew with price schedules
+	rows, err := s.pool.Query(
+		ctx,
+		"SELECT "+subscriptionColumns+" FROM subscription WHERE auto_renew AND status IN ('active', 'paused') AND end_date <= $1 ORDER BY id",
+		month.ToStringISO(),
+	)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	subscriptions, err := s.getSubscriptionsFromPgRows(&loggerMsg, op, rows, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.getSubscriptionsDetails(ctx, s.pool, &loggerMsg, op, subscriptions, nil); err != nil {
+		return 0, err
+	}
+
+	// 2.Renew every subscription in own transaction
+	var renewed int64
+
+	for i := 0; i < len(subscriptions); i++ {
+		count, err := s.renewSubscription(ctx, &loggerMsg, op, &subscriptions[i], month)
+		if err != nil {
+			s.logger.Error(loggerMsg, "details", err, "id", subscriptions[i].ID)
+			return renewed, fmt.Errorf("%s: %w", op, err)
+		}
+
+		renewed += count
+	}
+
+	return renewed, nil
+}
+
+// Record pending renewals of subscription, move its end date and regenerate its charges; nothing is done
+// if end date was changed since subscription was read (by another renewer or update)
+func (s *PostgresStorage) renewSubscription(ctx context.Context, loggerMsg *string, op string, subscription *model.Subscription, month model.Date) (int64, error) {
+	renewals := subscription.PendingRenewals(month)
+	if len(renewals) == 0 {
+		return 0, nil
+	}
+
+	// 1.Prepare transaction
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("prepare transaction: %w", err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	// 2.Move end date (row stays locked until commit)
+	newEnd := renewals[len(renewals)-1].PeriodEnd
+
+	res, err := tx.Exec(
+		ctx,
+		"UPDATE subscription SET end_date = $1, updated_at = now() WHERE id = $2 AND end_date = $3",
+		newEnd.ToStringISO(), subscription.ID, subscription.EndDate.ToStringISO(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("update end date: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return 0, nil
+	}
+
+	// 3.Record periods
+	var recorded int64
+
+	for _, renewal := range renewals {
+		res, err := tx.Exec(
+			ctx,
+			`INSERT INTO subscription_renewal (subscription_id,period_start,period_end,price)
+			values ($1,$2,$3,$4)
+			ON CONFLICT (subscription_id, period_start) DO NOTHING`,
+			subscription.ID, renewal.PeriodStart.ToStringISO(), renewal.PeriodEnd.ToStringISO(), int64(renewal.Price),
+		)
+		if err != nil {
+			return 0, fmt.Errorf("insert renewal: %w", err)
+		}
+
+		recorded += res.RowsAffected()
+	}
+
+	// 4.Regenerate charges and commit changes
+	if err := s.refreshCharges(ctx, tx, loggerMsg, op, subscription.ID); err != nil {
+		return 0, fmt.Errorf("regenerate charges: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return recorded, nil
+}
+
+// Get renewals (ordered by period start) of subscriptions with specified ids
+func (s *PostgresStorage) getRenewals(ctx context.Context, q querier, loggerMsg *string, op string, ids []int64) (map[int64][]model.Renewal, error) {
+	renewals := make(map[int64][]model.Renewal)
+
+	// 1.Run query
+	query := `
+		SELECT id, subscription_id, period_start::text, period_end::text, price, renewed_at
+		FROM subscription_renewal
+		WHERE subscription_id = ANY($1)
+		ORDER BY period_start
+	`
+
+	rows, err := q.Query(ctx, query, ids)
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: query renewals: %w", op, err)
+	}
+	defer rows.Close()
+
+	// 2.Get data
+	for rows.Next() {
+		var renewal model.Renewal
+		var subscriptionId int64
+		var periodStart, periodEnd string
+
+		err := rows.Scan(&renewal.ID, &subscriptionId, &periodStart, &periodEnd, &renewal.Price, &renewal.RenewedAt)
+		if err != nil {
+			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while parsing db data: %w", err))
+			return nil, fmt.Errorf("%s: scan renewal row: %w", op, err)
+		}
+
+		renewal.PeriodStart, err = model.DateFromStringISO(periodStart)
+		if err != nil {
+			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while getting period start: %w", err))
+			return nil, fmt.Errorf("%s: getting period start: %w", op, err)
+		}
+
+		renewal.PeriodEnd, err = model.DateFromStringISO(periodEnd)
+		if err != nil {
+			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while getting period end: %w", err))
+			return nil, fmt.Errorf("%s: getting period end: %w", op, err)
+		}
+
+		renewals[subscriptionId] = append(renewals[subscriptionId], renewal)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: iterate renewals: %w", op, err)
+	}
+
+	return renewals, nil
+}
@@ -0,0 +1,71 @@
+package pg
+
+import (
+	"context"
+	"em_golang_rest_service_example/internal/model"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Lowest word similarity of query to service name (pg_trgm) for subscription to be found;
+// default threshold (0.6) misses names with more than one typo
+const searchSimilarityThreshold = "0.3"
+
+// Shortest query matched with indexes (shorter one is searched as substring of service name)
+const searchTrigramLength = 3
+
+// Search subscriptions by service name, the closest ones go first: full-text match of words and
+// trigram similarity (it finds misspelled names) are both ranked
+func (s *PostgresStorage) SearchSubscriptions(query string, limit int) ([]model.Subscription, error) {
+	const op = "storage.postgres.SearchSubscriptions"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	ctx := context.Background()
+
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	// 1.Prepare transaction (similarity threshold is set for it only)
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return []model.Subscription{}, fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, "SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)", searchSimilarityThreshold)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return []model.Subscription{}, fmt.Errorf("%s: set similarity threshold: %w", op, err)
+	}
+
+	// 2.Exec search (both conditions of full search are served by indexes)
+	stmt := `
+		SELECT ` + subscriptionColumns + ` FROM subscription
+		WHERE search_vector @@ plainto_tsquery('simple', $1) OR $1 <% service_name
+		ORDER BY ts_rank(search_vector, plainto_tsquery('simple', $1)) + word_similarity($1, service_name) DESC, id
+		LIMIT $2
+	`
+	if utf8.RuneCountInString(query) < searchTrigramLength {
+		stmt = "SELECT " + subscriptionColumns + " FROM subscription WHERE strpos(lower(service_name), $1) > 0 ORDER BY length(service_name), id LIMIT $2"
+	}
+
+	rows, err := tx.Query(ctx, stmt, query, limit)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return []model.Subscription{}, fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	// 3.Get data with tags
+	subscriptions, err := s.getSubscriptionsFromPgRows(&loggerMsg, op, rows, nil)
+	if err != nil {
+		return []model.Subscription{}, err
+	}
+
+	if err := s.fillTags(ctx, &loggerMsg, op, subscriptions); err != nil {
+		return []model.Subscription{}, err
+	}
+
+	return subscriptions, nil
+}
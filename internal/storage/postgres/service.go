@@ -0,0 +1,433 @@
+package pg
+
+import (
+	"context"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func (s *PostgresStorage) CreateService(service model.Service) (int64, error) {
+	const op = "storage.postgres.CreateService"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Prepare transaction
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	// 2.Check name and aliases are not used by other services
+	err = checkServiceKeys(ctx, tx, 0, &service)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// 3.Insert service with aliases
+	var id int64
+	err = tx.QueryRow(
+		ctx,
+		"INSERT INTO service (name,name_key,default_price,category) values ($1,$2,$3,$4) RETURNING id",
+		service.Name,
+		model.ServiceNameKey(service.Name),
+		int64(service.DefaultPrice),
+		service.Category,
+	).Scan(&id)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: %w", op, serviceExecErr(err))
+	}
+
+	err = insertServiceAliases(ctx, tx, id, service.Aliases)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: %w", op, serviceExecErr(err))
+	}
+
+	// 4.Commit changes
+	err = tx.Commit(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s *PostgresStorage) GetService(id int64) (model.Service, error) {
+	const op = "storage.postgres.GetService"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Get service
+	services, err := s.getServices(context.Background(), &loggerMsg, op, "WHERE id = $1", id)
+	if err != nil {
+		return model.Service{}, err
+	}
+	if len(services) == 0 {
+		s.logger.Error(loggerMsg, "details", storage.ErrServiceNotFound)
+		return model.Service{}, storage.ErrServiceNotFound
+	}
+
+	return services[0], nil
+}
+
+func (s *PostgresStorage) GetServices() ([]model.Service, error) {
+	const op = "storage.postgres.GetServices"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	return s.getServices(context.Background(), &loggerMsg, op, "")
+}
+
+// Find service by its name or alias (case and separators insensitive)
+func (s *PostgresStorage) ResolveService(name string) (model.Service, error) {
+	const op = "storage.postgres.ResolveService"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	services, err := s.getServices(
+		context.Background(), &loggerMsg, op,
+		"WHERE name_key = $1 OR id IN (SELECT service_id FROM service_alias WHERE alias_key = $1)",
+		model.ServiceNameKey(name),
+	)
+	if err != nil {
+		return model.Service{}, err
+	}
+	if len(services) == 0 {
+		return model.Service{}, storage.ErrServiceNotFound
+	}
+
+	return services[0], nil
+}
+
+// Replace service data and aliases; subscriptions of service get new name
+func (s *PostgresStorage) UpdateService(id int64, service model.Service) error {
+	const op = "storage.postgres.UpdateService"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Prepare transaction
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	// 2.Check name and aliases are not used by other services
+	err = checkServiceKeys(ctx, tx, id, &service)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// 3.Update service
+	res, err := tx.Exec(
+		ctx,
+		"UPDATE service SET name = $1, name_key = $2, default_price = $3, category = $4 WHERE id = $5",
+		service.Name,
+		model.ServiceNameKey(service.Name),
+		int64(service.DefaultPrice),
+		service.Category,
+		id,
+	)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: %w", op, serviceExecErr(err))
+	}
+	if res.RowsAffected() == 0 {
+		s.logger.Error(loggerMsg, "details", storage.ErrServiceNotFound)
+		return storage.ErrServiceNotFound
+	}
+
+	// 4.Replace aliases
+	_, err = tx.Exec(ctx, "DELETE FROM service_alias WHERE service_id = $1", id)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: delete aliases: %w", op, err)
+	}
+
+	err = insertServiceAliases(ctx, tx, id, service.Aliases)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: %w", op, serviceExecErr(err))
+	}
+
+	// 5.Rename subscriptions of service
+	_, err = tx.Exec(ctx, "UPDATE subscription SET service_name = $1, updated_at = now() WHERE service_id = $2", service.Name, id)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == pgErrConstraintUnique {
+			s.logger.Error(loggerMsg, "details", storage.ErrSubscriptionExists)
+			return fmt.Errorf("%s: %w", op, storage.ErrSubscriptionExists)
+		}
+
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: rename subscriptions: %w", op, err)
+	}
+
+	// 6.Commit changes
+	err = tx.Commit(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) DeleteService(id int64) error {
+	const op = "storage.postgres.DeleteService"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Prepare transaction
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	// 2.Delete (aliases are deleted by cascade, used service is protected by foreign key)
+	res, err := tx.Exec(ctx, "DELETE FROM service WHERE id = $1", id)
+	if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == pgErrConstraintForeignKey {
+		s.logger.Error(loggerMsg, "details", storage.ErrServiceInUse)
+		return fmt.Errorf("%s: %w", op, storage.ErrServiceInUse)
+	}
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: delete service: %w", op, err)
+	}
+	if res.RowsAffected() == 0 {
+		s.logger.Error(loggerMsg, "details", storage.ErrServiceNotFound)
+		return storage.ErrServiceNotFound
+	}
+
+	// 3.Commit changes
+	err = tx.Commit(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+// Link subscriptions without service to catalog (registering missing services) and replace
+// their service names with canonical ones; subscriptions which would duplicate another one are skipped
+func (s *PostgresStorage) NormalizeServiceNames() (model.NormalizeReport, error) {
+	const op = "storage.postgres.NormalizeServiceNames"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	report := model.NormalizeReport{Conflicts: []int64{}}
+
+	// 1.Prepare transaction
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return model.NormalizeReport{}, fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	// 2.Get not linked subscriptions
+	type unlinked struct {
+		id          int64
+		serviceName string
+		price       int64
+	}
+
+	rows, err := tx.Query(ctx, "SELECT id, service_name, price FROM subscription WHERE service_id IS NULL ORDER BY id FOR UPDATE")
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return model.NormalizeReport{}, fmt.Errorf("%s: query subscriptions: %w", op, err)
+	}
+
+	subs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (unlinked, error) {
+		var sub unlinked
+		err := row.Scan(&sub.id, &sub.serviceName, &sub.price)
+		return sub, err
+	})
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return model.NormalizeReport{}, fmt.Errorf("%s: scan subscription rows: %w", op, err)
+	}
+
+	// 3.Link them one by one (savepoint lets skip conflicting subscription without aborting transaction)
+	for _, sub := range subs {
+		serviceId, name, registered, err := resolveOrRegisterService(ctx, tx, sub.serviceName, model.Money(sub.price))
+		if err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return model.NormalizeReport{}, fmt.Errorf("%s: %w", op, err)
+		}
+		if registered {
+			report.Registered++
+		}
+
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return model.NormalizeReport{}, fmt.Errorf("%s: create savepoint: %w", op, err)
+		}
+
+		_, err = savepoint.Exec(ctx, "UPDATE subscription SET service_id = $1, service_name = $2, updated_at = now() WHERE id = $3", serviceId, name, sub.id)
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == pgErrConstraintUnique {
+			savepoint.Rollback(ctx)
+			report.Conflicts = append(report.Conflicts, sub.id)
+			continue
+		}
+		if err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return model.NormalizeReport{}, fmt.Errorf("%s: link subscription: %w", op, err)
+		}
+
+		err = savepoint.Commit(ctx)
+		if err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return model.NormalizeReport{}, fmt.Errorf("%s: release savepoint: %w", op, err)
+		}
+
+		report.Linked++
+	}
+
+	// 4.Commit changes
+	err = tx.Commit(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return model.NormalizeReport{}, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return report, nil
+}
+
+// Get services with aliases selected with optional WHERE clause
+func (s *PostgresStorage) getServices(ctx context.Context, loggerMsg *string, op string, where string, args ...interface{}) ([]model.Service, error) {
+	services := []model.Service{}
+
+	// 1.Run query (aliases are aggregated to array)
+	query := `
+		SELECT id, name, default_price, category,
+			COALESCE((SELECT array_agg(alias ORDER BY id) FROM service_alias WHERE service_id = service.id), '{}')
+		FROM service ` + where + `
+		ORDER BY id
+	`
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: query services: %w", op, err)
+	}
+	defer rows.Close()
+
+	// 2.Get data
+	for rows.Next() {
+		var service model.Service
+		if err := rows.Scan(&service.ID, &service.Name, &service.DefaultPrice, &service.Category, &service.Aliases); err != nil {
+			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while parsing db data: %w", err))
+			return nil, fmt.Errorf("%s: scan service row: %w", op, err)
+		}
+		services = append(services, service)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: iterate services: %w", op, err)
+	}
+
+	return services, nil
+}
+
+// Check that service name and aliases keys are not used by another service
+func checkServiceKeys(ctx context.Context, tx pgx.Tx, id int64, service *model.Service) error {
+	keys := []string{model.ServiceNameKey(service.Name)}
+	for _, alias := range service.Aliases {
+		keys = append(keys, model.ServiceNameKey(alias))
+	}
+
+	var taken int
+	err := tx.QueryRow(
+		ctx,
+		`SELECT 1 FROM service WHERE name_key = ANY($1) AND id <> $2
+		UNION ALL SELECT 1 FROM service_alias WHERE alias_key = ANY($1) AND service_id <> $2 LIMIT 1`,
+		keys, id,
+	).Scan(&taken)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("check service keys: %w", err)
+	}
+
+	return storage.ErrServiceExists
+}
+
+// Insert service aliases with their keys
+func insertServiceAliases(ctx context.Context, tx pgx.Tx, id int64, aliases []string) error {
+	for _, alias := range aliases {
+		_, err := tx.Exec(ctx, "INSERT INTO service_alias (service_id,alias,alias_key) values ($1,$2,$3)", id, alias, model.ServiceNameKey(alias))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Find service by name or alias, register new one (with specified default price) if there is no such;
+// returns service id and canonical name
+func resolveOrRegisterService(ctx context.Context, tx pgx.Tx, name string, defaultPrice model.Money) (int64, string, bool, error) {
+	key := model.ServiceNameKey(name)
+
+	const resolveQuery = "SELECT id, name FROM service WHERE name_key = $1 OR id IN (SELECT service_id FROM service_alias WHERE alias_key = $1)"
+
+	var id int64
+	var canonical string
+
+	err := tx.QueryRow(ctx, resolveQuery, key).Scan(&id, &canonical)
+	if err == nil {
+		return id, canonical, false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, "", false, fmt.Errorf("resolve service: %w", err)
+	}
+
+	// Service can be registered by concurrent transaction, so resolve it again in such case
+	err = tx.QueryRow(
+		ctx,
+		"INSERT INTO service (name,name_key,default_price) values ($1,$2,$3) ON CONFLICT (name_key) DO NOTHING RETURNING id",
+		name, key, int64(defaultPrice),
+	).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = tx.QueryRow(ctx, resolveQuery, key).Scan(&id, &canonical)
+		if err != nil {
+			return 0, "", false, fmt.Errorf("resolve service: %w", err)
+		}
+		return id, canonical, false, nil
+	}
+	if err != nil {
+		return 0, "", false, fmt.Errorf("register service: %w", err)
+	}
+
+	return id, name, true, nil
+}
+
+// Map constraint violation on service data change to storage error
+func serviceExecErr(err error) error {
+	if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == pgErrConstraintUnique {
+		return storage.ErrServiceExists
+	}
+	return err
+}
@@ -0,0 +1,175 @@
+package pg
+
+import (
+	"context"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Change subscription status (only if it is still in transition.From status) and store transition;
+// newEnd is optional new end date (for immediate cancellation)
+func (s *PostgresStorage) ChangeStatus(id int64, transition model.StatusTransition, newEnd *model.Date) (int64, error) {
+	const op = "storage.postgres.ChangeStatus"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Prepare transaction
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	// 2.Update status
+	query := "UPDATE subscription SET status = $1, updated_at = now()"
+	args := []interface{}{string(transition.To)}
+
+	if newEnd != nil {
+		args = append(args, newEnd.ToStringISO())
+		query += fmt.Sprintf(", end_date = $%d", len(args))
+	}
+	args = append(args, id, string(transition.From))
+	query += fmt.Sprintf(" WHERE id = $%d AND status = $%d", len(args)-1, len(args))
+
+	res, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: update status: %w", op, err)
+	}
+
+	// 3.Nothing updated: either no subscription or its status was changed by someone else
+	if res.RowsAffected() == 0 {
+		var exists int
+		err = tx.QueryRow(ctx, "SELECT 1 FROM subscription WHERE id = $1", id).Scan(&exists)
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
+			return 0, storage.ErrSubscribtionNotFound
+		}
+		if err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return 0, fmt.Errorf("%s: check subscription: %w", op, err)
+		}
+
+		s.logger.Error(loggerMsg, "details", storage.ErrStatusChanged)
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrStatusChanged)
+	}
+
+	// 4.Store transition
+	var transitionId int64
+	err = tx.QueryRow(
+		ctx,
+		`INSERT INTO subscription_status_transition (subscription_id,from_status,to_status,effective_from)
+		values ($1,$2,$3,$4)
+		RETURNING id`,
+		id,
+		string(transition.From),
+		string(transition.To),
+		transition.EffectiveFrom.ToStringISO(),
+	).Scan(&transitionId)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: insert transition: %w", op, err)
+	}
+
+	// 5.Regenerate charges and commit changes
+	if err := s.refreshCharges(ctx, tx, &loggerMsg, op, id); err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return transitionId, nil
+}
+
+// Mark active and paused subscriptions ended before specified month as expired (auto-renewing ones
+// are extended by renewer instead); returns number of expired
+func (s *PostgresStorage) ExpireSubscriptions(month model.Date) (int64, error) {
+	const op = "storage.postgres.ExpireSubscriptions"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Run query: lock ended subscriptions, update their statuses and store transitions (expiration applies since end date)
+	ctx := context.Background()
+
+	query := `
+		WITH ended AS (
+			SELECT id, status, end_date FROM subscription
+			WHERE status IN ('active', 'paused') AND NOT auto_renew AND end_date <= $1
+			FOR UPDATE
+		), expired AS (
+			UPDATE subscription SET status = 'expired', updated_at = now()
+			FROM ended WHERE subscription.id = ended.id
+		)
+		INSERT INTO subscription_status_transition (subscription_id,from_status,to_status,effective_from)
+		SELECT id, status, 'expired', end_date FROM ended
+	`
+
+	res, err := s.pool.Exec(ctx, query, month.ToStringISO())
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return res.RowsAffected(), nil
+}
+
+// Get status histories (ordered by effective date) for subscriptions with specified ids
+func (s *PostgresStorage) getStatusHistories(ctx context.Context, q querier, loggerMsg *string, op string, ids []int64) (map[int64][]model.StatusTransition, error) {
+	histories := make(map[int64][]model.StatusTransition)
+	if len(ids) == 0 {
+		return histories, nil
+	}
+
+	// 1.Run query
+	query := `
+		SELECT id, subscription_id, from_status, to_status, effective_from::text, changed_at
+		FROM subscription_status_transition
+		WHERE subscription_id = ANY($1)
+		ORDER BY effective_from, id
+	`
+
+	rows, err := q.Query(ctx, query, ids)
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: query status histories: %w", op, err)
+	}
+	defer rows.Close()
+
+	// 2.Get data
+	for rows.Next() {
+		var transition model.StatusTransition
+		var subscriptionId int64
+		var effectiveFrom string
+
+		err := rows.Scan(&transition.ID, &subscriptionId, &transition.From, &transition.To, &effectiveFrom, &transition.ChangedAt)
+		if err != nil {
+			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while parsing db data: %w", err))
+			return nil, fmt.Errorf("%s: scan status transition row: %w", op, err)
+		}
+
+		transition.EffectiveFrom, err = model.DateFromStringISO(effectiveFrom)
+		if err != nil {
+			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while getting effective date: %w", err))
+			return nil, fmt.Errorf("%s: getting effective date: %w", op, err)
+		}
+
+		histories[subscriptionId] = append(histories[subscriptionId], transition)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: iterate status histories: %w", op, err)
+	}
+
+	return histories, nil
+}
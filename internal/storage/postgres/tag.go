@@ -0,0 +1,137 @@
+package pg
+
+import (
+	"context"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Replace category and tags of subscription
+func (s *PostgresStorage) SetTags(id int64, category string, tags []string) error {
+	const op = "storage.postgres.SetTags"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	ctx := context.Background()
+
+	// 1.Prepare transaction
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	// 2.Set category (row stays locked until tags are replaced)
+	res, err := tx.Exec(ctx, "UPDATE subscription SET category = $1, updated_at = now() WHERE id = $2", category, id)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: update category: %w", op, err)
+	}
+	if res.RowsAffected() == 0 {
+		s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
+		return storage.ErrSubscribtionNotFound
+	}
+
+	// 3.Replace tags
+	_, err = tx.Exec(ctx, "DELETE FROM subscription_tag WHERE subscription_id = $1", id)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: delete tags: %w", op, err)
+	}
+
+	if err := insertTags(ctx, tx, id, tags); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// 4.Commit changes
+	err = tx.Commit(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+func insertTags(ctx context.Context, tx pgx.Tx, id int64, tags []string) error {
+	for _, tag := range tags {
+		_, err := tx.Exec(ctx, "INSERT INTO subscription_tag (subscription_id,tag) values ($1,$2)", id, tag)
+		if err != nil {
+			return fmt.Errorf("insert tag: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Column with array of subscription tags (in order of addition)
+const tagsColumn = "ARRAY(SELECT tag FROM subscription_tag WHERE subscription_id = subscription.id ORDER BY id)"
+
+// Get tags (in order of addition) of subscriptions with specified ids
+func (s *PostgresStorage) getTags(ctx context.Context, q querier, loggerMsg *string, op string, ids []int64) (map[int64][]string, error) {
+	tags := make(map[int64][]string)
+
+	// 1.Run query
+	query := `
+		SELECT subscription_id, tag
+		FROM subscription_tag
+		WHERE subscription_id = ANY($1)
+		ORDER BY id
+	`
+
+	rows, err := q.Query(ctx, query, ids)
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: query tags: %w", op, err)
+	}
+	defer rows.Close()
+
+	// 2.Get data
+	for rows.Next() {
+		var subscriptionId int64
+		var tag string
+
+		if err := rows.Scan(&subscriptionId, &tag); err != nil {
+			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while parsing db data: %w", err))
+			return nil, fmt.Errorf("%s: scan tag row: %w", op, err)
+		}
+
+		tags[subscriptionId] = append(tags[subscriptionId], tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: iterate tags: %w", op, err)
+	}
+
+	return tags, nil
+}
+
+// Fill tags of subscriptions
+func (s *PostgresStorage) fillTags(ctx context.Context, loggerMsg *string, op string, subscriptions []model.Subscription) error {
+	ids := make([]int64, 0, len(subscriptions))
+	for i := 0; i < len(subscriptions); i++ {
+		ids = append(ids, subscriptions[i].ID)
+	}
+
+	tags, err := s.getTags(ctx, s.pool, loggerMsg, op, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(subscriptions); i++ {
+		subscriptions[i].Tags = tags[subscriptions[i].ID]
+	}
+
+	return nil
+}
+
+// Condition matching subscriptions having tag (n is number of tag placeholder)
+func tagCondition(n int) string {
+	return fmt.Sprintf("id IN (SELECT subscription_id FROM subscription_tag WHERE tag = $%d)", n)
+}
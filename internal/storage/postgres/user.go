@@ -0,0 +1,115 @@
+package pg
+
+import (
+	"context"
+	"em_golang_rest_service_example/internal/model"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *PostgresStorage) GetUserSubscriptions(userId uuid.UUID, limit, offset *int) ([]model.Subscription, error) {
+	const op = "storage.postgres.GetUserSubscriptions"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	ctx := context.Background()
+
+	// 1.Validation
+	if limit != nil && offset == nil {
+		s.logger.Error(loggerMsg, "details", "no offset value while limit is set")
+		return []model.Subscription{}, errors.New("no offset value while limit is set")
+	} else if limit == nil && offset != nil {
+		s.logger.Error(loggerMsg, "details", "no limit value while offset is set")
+		return []model.Subscription{}, errors.New("no limit value while offset is set")
+	}
+
+	// 2.Prepare and exec (ordered to keep pages stable), shared subscriptions are included
+	query := "SELECT " + subscriptionColumns + " FROM subscription WHERE " + userCondition(1) + " ORDER BY id"
+	args := []interface{}{userId.String()}
+
+	if limit != nil {
+		query += " LIMIT $2 OFFSET $3"
+		args = append(args, *limit, *offset)
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return []model.Subscription{}, fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	// 3.Parse and get data
+	subscriptions, err := s.getSubscriptionsFromPgRows(&loggerMsg, op, rows, nil)
+	if err != nil {
+		return []model.Subscription{}, err
+	}
+
+	// 4.Get subscriptions details (needed for cost calculation)
+	err = s.getSubscriptionsDetails(ctx, s.pool, &loggerMsg, op, subscriptions, nil)
+	if err != nil {
+		return []model.Subscription{}, err
+	}
+
+	return subscriptions, nil
+}
+
+func (s *PostgresStorage) DeleteUserSubscriptions(userId uuid.UUID) (int64, error) {
+	const op = "storage.postgres.DeleteUserSubscriptions"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	ctx := context.Background()
+
+	// 1.Prepare transaction
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	// 2.Delete own subscriptions (child tables are cleaned by cascade) and leave shared ones
+	res, err := tx.Exec(ctx, "DELETE FROM subscription WHERE user_id = $1", userId.String())
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	// 3.Touch shared subscriptions left and remove user from their members
+	rows, err := tx.Query(
+		ctx,
+		"UPDATE subscription SET updated_at = now() WHERE id IN (SELECT subscription_id FROM subscription_member WHERE user_id = $1) RETURNING id",
+		userId.String(),
+	)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: update shared subscriptions: %w", op, err)
+	}
+
+	shared, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: scan rows: %w", op, err)
+	}
+
+	_, err = tx.Exec(ctx, "DELETE FROM subscription_member WHERE user_id = $1", userId.String())
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: delete memberships: %w", op, err)
+	}
+
+	// 4.Regenerate charges of shared subscriptions (their shares are split among members left) and commit changes
+	if err := s.refreshCharges(ctx, tx, &loggerMsg, op, shared...); err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return res.RowsAffected(), nil
+}
@@ -0,0 +1,215 @@
+package sqlite
+
+import (
+	"database/sql"
+	"em_golang_rest_service_example/internal/model"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Get ledger charges of subscriptions with specified ids (ordered by month); charges posted
+// after asOf are skipped if it is set, so report can be reproduced as it was at that moment
+func (s *SqliteStorage) GetCharges(ids []int64, asOf *time.Time) (map[int64][]model.Charge, error) {
+	const op = "storage.sqlite.GetCharges"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	charges, err := s.getCharges(s.db, &loggerMsg, op, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if asOf == nil {
+		return charges, nil
+	}
+
+	for id := range charges {
+		posted := charges[id][:0]
+		for _, charge := range charges[id] {
+			if !charge.PostedAt.After(*asOf) {
+				posted = append(posted, charge)
+			}
+		}
+		charges[id] = posted
+	}
+
+	return charges, nil
+}
+
+// Regenerate charges of all subscriptions (including deleted ones still having charges in ledger)
+func (s *SqliteStorage) RebuildCharges() (model.LedgerReport, error) {
+	const op = "storage.sqlite.RebuildCharges"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	var report model.LedgerReport
+
+	// 1.Get subscriptions ids
+	rows, err := s.db.Query("SELECT id FROM subscription UNION SELECT subscription_id FROM charge ORDER BY 1")
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return report, fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	var ids []int64
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			s.logger.Error(loggerMsg, "details", err)
+			return report, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return report, fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+
+	// 2.Regenerate charges (each subscription in its own transaction)
+	for _, id := range ids {
+		appended, err := s.rebuildSubscriptionCharges(&loggerMsg, op, id)
+		if err != nil {
+			return report, err
+		}
+
+		report.Subscriptions++
+		report.Appended += appended
+	}
+
+	return report, nil
+}
+
+// Regenerate charges of one subscription in separate transaction
+func (s *SqliteStorage) rebuildSubscriptionCharges(loggerMsg *string, op string, id int64) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	appended, err := s.syncCharges(tx, loggerMsg, op, id)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return appended, nil
+}
+
+// Regenerate ledger charges of changed subscriptions within transaction of the change,
+// so change is not committed without its charges
+func (s *SqliteStorage) refreshCharges(tx *sql.Tx, loggerMsg *string, op string, ids ...int64) error {
+	for _, id := range ids {
+		if _, err := s.syncCharges(tx, loggerMsg, op, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Append charges correcting ledger to current state of subscription (all charges are reversed
+// if subscription is deleted) within transaction; returns number of appended charges. Concurrent
+// regenerations cannot append the same correction twice: SQLite runs one writing transaction at a time
+func (s *SqliteStorage) syncCharges(tx *sql.Tx, loggerMsg *string, op string, id int64) (int64, error) {
+	// 1.Get subscription with details
+	rows, err := tx.Query("SELECT "+subscriptionColumns+" FROM subscription WHERE id = ?", id)
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: get subscription: %w", op, err)
+	}
+
+	subscriptions, err := s.getSubscriptionsFromSqliteRows(loggerMsg, op, rows, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.getSubscriptionsDetails(tx, loggerMsg, op, subscriptions, nil); err != nil {
+		return 0, err
+	}
+
+	var expected []model.Charge
+	if len(subscriptions) > 0 {
+		expected = subscriptions[0].ExpectedCharges()
+	}
+
+	// 2.Compare with posted charges
+	posted, err := s.getCharges(tx, loggerMsg, op, []int64{id})
+	if err != nil {
+		return 0, err
+	}
+
+	adjustments, err := model.ChargeAdjustments(posted[id], expected)
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// 3.Append corrections
+	postedAt := time.Now().UTC()
+
+	for _, charge := range adjustments {
+		_, err := tx.Exec(
+			"INSERT INTO charge (subscription_id,user_id,month,amount,posted_at) values (?,?,?,?,?)",
+			id, charge.UserID.String(), charge.Month.ToStringISO(), int64(charge.Amount), postedAt,
+		)
+		if err != nil {
+			s.logger.Error(*loggerMsg, "details", err)
+			return 0, fmt.Errorf("%s: insert charge: %w", op, err)
+		}
+	}
+
+	return int64(len(adjustments)), nil
+}
+
+// Get ledger charges (ordered by month and posting) of subscriptions with specified ids
+func (s *SqliteStorage) getCharges(q querier, loggerMsg *string, op string, ids []int64) (map[int64][]model.Charge, error) {
+	charges := make(map[int64][]model.Charge)
+
+	query := `
+		SELECT id, subscription_id, user_id, month, amount, posted_at
+		FROM charge
+		WHERE subscription_id IN (%s)
+		ORDER BY month, id
+	`
+
+	err := s.queryByIds(q, query, ids, func(rows *sql.Rows) error {
+		var charge model.Charge
+		var userId, month string
+
+		err := rows.Scan(&charge.ID, &charge.SubscriptionID, &userId, &month, &charge.Amount, &charge.PostedAt)
+		if err != nil {
+			return fmt.Errorf("scan charge row: %w", err)
+		}
+
+		charge.UserID, err = uuid.Parse(userId)
+		if err != nil {
+			return fmt.Errorf("parse charge user id: %w", err)
+		}
+
+		charge.Month, err = model.DateFromStringISO(month)
+		if err != nil {
+			return fmt.Errorf("getting charge month: %w", err)
+		}
+
+		charges[charge.SubscriptionID] = append(charges[charge.SubscriptionID], charge)
+
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: get charges: %w", op, err)
+	}
+
+	return charges, nil
+}
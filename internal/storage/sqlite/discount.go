@@ -0,0 +1,144 @@
+package sqlite
+
+import (
+	"database/sql"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"fmt"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+func (s *SqliteStorage) AddDiscount(id int64, discount model.Discount) (int64, error) {
+	const op = "storage.sqlite.AddDiscount"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Prepare transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	// 2.Prepare query
+	query := `
+	    INSERT INTO subscription_discount (subscription_id,kind,percent,amount,valid_from,valid_to)
+		values (?,?,?,?,?,?)
+	`
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: prepare statement: %w", op, err)
+	}
+	defer stmt.Close()
+
+	// 3.Run it
+	res, err := stmt.Exec(
+		id,
+		string(discount.Kind),
+		discount.Percent,
+		int64(discount.Amount),
+		optDateToISO(discount.ValidFrom),
+		optDateToISO(discount.ValidTo),
+	)
+	if err != nil {
+		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey {
+			s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrSubscribtionNotFound)
+		}
+
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	// 4.Get created item ID, regenerate charges and commit changes
+	discountId, err := res.LastInsertId()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to get last insert id: %w", op, err)
+	}
+
+	if err := touchSubscription(tx, id); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.refreshCharges(tx, &loggerMsg, op, id); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return discountId, nil
+}
+
+// Get discounts (ordered by id) for subscriptions with specified ids
+func (s *SqliteStorage) getDiscounts(q querier, loggerMsg *string, op string, ids []int64) (map[int64][]model.Discount, error) {
+	discounts := make(map[int64][]model.Discount)
+
+	query := `
+		SELECT id, subscription_id, kind, percent, amount, valid_from, valid_to
+		FROM subscription_discount
+		WHERE subscription_id IN (%s)
+		ORDER BY id
+	`
+
+	err := s.queryByIds(q, query, ids, func(rows *sql.Rows) error {
+		var discount model.Discount
+		var subscriptionId int64
+		var kind string
+		var validFrom, validTo sql.NullString
+
+		err := rows.Scan(&discount.ID, &subscriptionId, &kind, &discount.Percent, &discount.Amount, &validFrom, &validTo)
+		if err != nil {
+			return fmt.Errorf("scan discount row: %w", err)
+		}
+		discount.Kind = model.DiscountKind(kind)
+
+		discount.ValidFrom, err = optDateFromISO(validFrom)
+		if err != nil {
+			return fmt.Errorf("getting valid from date: %w", err)
+		}
+
+		discount.ValidTo, err = optDateFromISO(validTo)
+		if err != nil {
+			return fmt.Errorf("getting valid to date: %w", err)
+		}
+
+		discounts[subscriptionId] = append(discounts[subscriptionId], discount)
+
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: get discounts: %w", op, err)
+	}
+
+	return discounts, nil
+}
+
+// Convert optional date to nullable ISO string
+func optDateToISO(d *model.Date) interface{} {
+	if d == nil {
+		return nil
+	}
+	return d.ToStringISO()
+}
+
+// Convert nullable ISO string to optional date
+func optDateFromISO(str sql.NullString) (*model.Date, error) {
+	if !str.Valid {
+		return nil, nil
+	}
+
+	d, err := model.DateFromStringISO(str.String)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"em_golang_rest_service_example/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// Columns compared by filter expression fields (tag is matched with subscription_tag table);
+// missing catalog service is compared as 0, so negation matches it too
+var filterExprColumns = map[string]string{
+	"id":             "id",
+	"service_name":   "service_name",
+	"service_id":     "COALESCE(service_id, 0)",
+	"price":          "price",
+	"user_id":        "user_id",
+	"start_date":     "start_date",
+	"end_date":       "end_date",
+	"trial_months":   "trial_months",
+	"trial_price":    "trial_price",
+	"status":         "status",
+	"category":       "category",
+	"auto_renew":     "auto_renew",
+	"billing_period": "billing_period",
+}
+
+// Compile parsed filter expression to condition, values are passed as statement arguments
+func compileFilterExpr(expr model.FilterExpr, args []interface{}) (string, []interface{}) {
+	switch e := expr.(type) {
+	case *model.FilterAnd:
+		left, args := compileFilterExpr(e.Left, args)
+		right, args := compileFilterExpr(e.Right, args)
+		return "(" + left + " AND " + right + ")", args
+
+	case *model.FilterOr:
+		left, args := compileFilterExpr(e.Left, args)
+		right, args := compileFilterExpr(e.Right, args)
+		return "(" + left + " OR " + right + ")", args
+
+	case *model.FilterNot:
+		cond, args := compileFilterExpr(e.Expr, args)
+		return "NOT (" + cond + ")", args
+
+	case *model.FilterActiveIn:
+		// End month of subscription is not included in its period
+		return "(start_date <= ? AND end_date > ?)", append(args, e.To.ToStringISO(), e.From.ToStringISO())
+
+	case *model.FilterComparison:
+		return compileFilterComparison(e, args)
+	}
+
+	// Parser produces known nodes only
+	return "FALSE", args
+}
+
+func compileFilterComparison(c *model.FilterComparison, args []interface{}) (string, []interface{}) {
+	// Patterns are lowercased by parser; lower() and LIKE ignore case of ASCII letters only
+	if c.Field == "tag" {
+		switch c.Op {
+		case model.FilterMatch:
+			return `(id IN (SELECT subscription_id FROM subscription_tag WHERE tag LIKE ? ESCAPE '\'))`,
+				append(args, model.LikePattern(c.Value.(string)))
+		case model.FilterNe:
+			return "(NOT " + tagCondition + ")", append(args, c.Value)
+		default:
+			return "(" + tagCondition + ")", append(args, c.Value)
+		}
+	}
+
+	column := filterExprColumns[c.Field]
+
+	if c.Op == model.FilterMatch {
+		return "(lower(" + column + `) LIKE ? ESCAPE '\')`, append(args, model.LikePattern(c.Value.(string)))
+	}
+
+	return "(" + column + " " + string(c.Op) + " ?)", append(args, filterExprArg(c.Value))
+}
+
+// Get value of filter expression as statement argument (in the form values are stored)
+func filterExprArg(value any) interface{} {
+	switch v := value.(type) {
+	case model.Money:
+		return int64(v)
+	case model.Date:
+		return v.ToStringISO()
+	case model.Status:
+		return string(v)
+	case uuid.UUID:
+		return v.String()
+	default:
+		return v
+	}
+}
@@ -0,0 +1,133 @@
+package sqlite
+
+import (
+	"database/sql"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Reserve idempotency key for request in progress; expired key is taken over,
+// storage.ErrIdempotencyKeyExists is returned if key is used by another request
+func (s *SqliteStorage) ReserveIdempotencyKey(key, requestHash string, ttl time.Duration) error {
+	const op = "storage.sqlite.ReserveIdempotencyKey"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	now := time.Now().UTC()
+
+	// 1.Insert key (or replace expired one)
+	res, err := s.db.Exec(
+		`INSERT INTO idempotency_key (key,request_hash,status,content_type,body,created_at,expires_at)
+		values (?,?,0,'',NULL,?,?)
+		ON CONFLICT (key) DO UPDATE SET
+			request_hash = excluded.request_hash, status = 0, content_type = '', body = NULL,
+			created_at = excluded.created_at, expires_at = excluded.expires_at
+		WHERE idempotency_key.expires_at <= excluded.created_at`,
+		key, requestHash, now, now.Add(ttl),
+	)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	// 2.Check if was reserved
+	changedRows, err := res.RowsAffected()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: get affected rows: %w", op, err)
+	}
+	if changedRows == 0 {
+		return storage.ErrIdempotencyKeyExists
+	}
+
+	return nil
+}
+
+// Get not expired idempotency key
+func (s *SqliteStorage) GetIdempotencyKey(key string) (model.IdempotencyRecord, error) {
+	const op = "storage.sqlite.GetIdempotencyKey"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	var record model.IdempotencyRecord
+
+	err := s.db.QueryRow(
+		`SELECT key, request_hash, status, content_type, body, expires_at
+		FROM idempotency_key
+		WHERE key = ? AND expires_at > ?`,
+		key, time.Now().UTC(),
+	).Scan(&record.Key, &record.RequestHash, &record.Status, &record.ContentType, &record.Body, &record.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.IdempotencyRecord{}, storage.ErrIdempotencyKeyNotFound
+	}
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return model.IdempotencyRecord{}, fmt.Errorf("%s: query idempotency key: %w", op, err)
+	}
+
+	return record, nil
+}
+
+// Save response of request reserved idempotency key
+func (s *SqliteStorage) SaveIdempotentResponse(key string, status int, contentType string, body []byte) error {
+	const op = "storage.sqlite.SaveIdempotentResponse"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Update
+	res, err := s.db.Exec(
+		"UPDATE idempotency_key SET status = ?, content_type = ?, body = ? WHERE key = ?",
+		status, contentType, body, key,
+	)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	// 2.Check if was updated
+	changedRows, err := res.RowsAffected()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: get affected rows: %w", op, err)
+	}
+	if changedRows == 0 {
+		s.logger.Error(loggerMsg, "details", storage.ErrIdempotencyKeyNotFound)
+		return storage.ErrIdempotencyKeyNotFound
+	}
+
+	return nil
+}
+
+// Release idempotency key (request can be retried as new one)
+func (s *SqliteStorage) DeleteIdempotencyKey(key string) error {
+	const op = "storage.sqlite.DeleteIdempotencyKey"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	_, err := s.db.Exec("DELETE FROM idempotency_key WHERE key = ?", key)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return nil
+}
+
+// Delete expired idempotency keys; returns number of deleted keys
+func (s *SqliteStorage) DeleteExpiredIdempotencyKeys() (int64, error) {
+	const op = "storage.sqlite.DeleteExpiredIdempotencyKeys"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	res, err := s.db.Exec("DELETE FROM idempotency_key WHERE expires_at <= ?", time.Now().UTC())
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: get affected rows: %w", op, err)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,157 @@
+package sqlite
+
+import (
+	"database/sql"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Import chunk of subscriptions in one transaction (rolled back in dry run); existing subscriptions are handled
+// according to policy, every row is isolated by savepoint so failed row does not affect others
+func (s *SqliteStorage) ImportSubscriptions(specs []model.SubscriptionSpec, policy model.ConflictPolicy, dryRun bool) ([]model.ImportResult, error) {
+	const op = "storage.sqlite.ImportSubscriptions"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Prepare transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	// 2.Import rows
+	results := make([]model.ImportResult, 0, len(specs))
+	var changed []int64
+
+	for _, spec := range specs {
+		result, err := importSubscription(tx, spec, policy)
+		if err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if result.ID != 0 {
+			changed = append(changed, result.ID)
+		}
+		results = append(results, result)
+	}
+
+	if dryRun {
+		return results, nil
+	}
+
+	// 3.Generate charges and commit changes
+	if err := s.refreshCharges(tx, &loggerMsg, op, changed...); err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return results, nil
+}
+
+// Import one row within savepoint; only errors breaking the whole transaction are returned
+func importSubscription(tx *sql.Tx, spec model.SubscriptionSpec, policy model.ConflictPolicy) (model.ImportResult, error) {
+	if _, err := tx.Exec("SAVEPOINT import_row"); err != nil {
+		return model.ImportResult{}, fmt.Errorf("create savepoint: %w", err)
+	}
+
+	outcome := model.ImportCreated
+
+	id, err := insertSubscription(tx, spec)
+	if errors.Is(err, storage.ErrSubscriptionExists) {
+		switch policy {
+		case model.ConflictSkip:
+			return rollbackImportRow(tx, model.ImportResult{Outcome: model.ImportSkipped})
+		case model.ConflictUpdate:
+			outcome = model.ImportUpdated
+			id, err = replaceSubscription(tx, spec)
+		}
+	}
+	if err != nil {
+		return rollbackImportRow(tx, model.ImportResult{Outcome: model.ImportFailed, Err: err})
+	}
+
+	if _, err := tx.Exec("RELEASE import_row"); err != nil {
+		return model.ImportResult{}, fmt.Errorf("release savepoint: %w", err)
+	}
+
+	return model.ImportResult{Outcome: outcome, ID: id}, nil
+}
+
+// Undo changes of row not imported
+func rollbackImportRow(tx *sql.Tx, result model.ImportResult) (model.ImportResult, error) {
+	if _, err := tx.Exec("ROLLBACK TO import_row"); err != nil {
+		return model.ImportResult{}, fmt.Errorf("rollback to savepoint: %w", err)
+	}
+	if _, err := tx.Exec("RELEASE import_row"); err != nil {
+		return model.ImportResult{}, fmt.Errorf("release savepoint: %w", err)
+	}
+
+	return result, nil
+}
+
+// Replace data of existing subscription of the same service and user with imported one,
+// price schedule, discounts, status history and renewals are kept
+func replaceSubscription(tx *sql.Tx, spec model.SubscriptionSpec) (int64, error) {
+	// 1.Find subscription
+	serviceId, serviceName, _, err := resolveOrRegisterService(tx, spec.ServiceName, spec.Price)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = tx.QueryRow("SELECT id FROM subscription WHERE service_name = ? AND user_id = ?", serviceName, spec.UserID.String()).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, storage.ErrSubscribtionNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("find subscription: %w", err)
+	}
+
+	// 2.Update subscription
+	query := `
+		UPDATE subscription SET
+			price = ?, start_date = ?, end_date = ?, trial_months = ?, trial_price = ?, service_id = ?,
+			category = COALESCE(NULLIF(?,''),(SELECT LOWER(TRIM(category)) FROM service WHERE id = ?),''),
+			metadata = ?, auto_renew = ?, billing_period = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	_, err = tx.Exec(
+		query,
+		int64(spec.Price), spec.StartDate.ToStringISO(), spec.EndDate.ToStringISO(), spec.TrialMonths, int64(spec.TrialPrice), serviceId,
+		spec.Category, serviceId,
+		metadataArg(spec.Metadata), spec.AutoRenew, spec.Period(), time.Now().UTC(),
+		id,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("update subscription: %w", err)
+	}
+
+	// 3.Replace members and tags
+	if _, err := tx.Exec("DELETE FROM subscription_member WHERE subscription_id = ?", id); err != nil {
+		return 0, fmt.Errorf("delete members: %w", err)
+	}
+	if err := insertMembers(tx, id, spec.Members); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM subscription_tag WHERE subscription_id = ?", id); err != nil {
+		return 0, fmt.Errorf("delete tags: %w", err)
+	}
+	if err := insertTags(tx, id, spec.Tags); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
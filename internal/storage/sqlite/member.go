@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"database/sql"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Replace members of shared subscription (empty members make owner pay alone)
+func (s *SqliteStorage) SetMembers(id int64, members []model.Member) error {
+	const op = "storage.sqlite.SetMembers"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Prepare transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	// 2.Check subscription exists
+	var exists int
+	err = tx.QueryRow("SELECT 1 FROM subscription WHERE id = ?", id).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
+		return storage.ErrSubscribtionNotFound
+	}
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: check subscription: %w", op, err)
+	}
+
+	// 3.Replace members
+	_, err = tx.Exec("DELETE FROM subscription_member WHERE subscription_id = ?", id)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: delete members: %w", op, err)
+	}
+
+	if err := insertMembers(tx, id, members); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := touchSubscription(tx, id); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// 4.Regenerate charges and commit changes
+	if err := s.refreshCharges(tx, &loggerMsg, op, id); err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+func insertMembers(tx *sql.Tx, id int64, members []model.Member) error {
+	for _, member := range members {
+		_, err := tx.Exec(
+			"INSERT INTO subscription_member (subscription_id,user_id,weight) values (?,?,?)",
+			id, member.UserID.String(), member.Weight,
+		)
+		if err != nil {
+			return fmt.Errorf("insert member: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Get members (ordered by id) of subscriptions with specified ids
+func (s *SqliteStorage) getMembers(q querier, loggerMsg *string, op string, ids []int64) (map[int64][]model.Member, error) {
+	members := make(map[int64][]model.Member)
+
+	query := `
+		SELECT subscription_id, user_id, weight
+		FROM subscription_member
+		WHERE subscription_id IN (%s)
+		ORDER BY id
+	`
+
+	err := s.queryByIds(q, query, ids, func(rows *sql.Rows) error {
+		var member model.Member
+		var subscriptionId int64
+		var userId string
+
+		if err := rows.Scan(&subscriptionId, &userId, &member.Weight); err != nil {
+			return fmt.Errorf("scan member row: %w", err)
+		}
+
+		var err error
+
+		member.UserID, err = uuid.Parse(userId)
+		if err != nil {
+			return fmt.Errorf("parse member user id: %w", err)
+		}
+
+		members[subscriptionId] = append(members[subscriptionId], member)
+
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: get members: %w", op, err)
+	}
+
+	return members, nil
+}
+
+// Condition matching subscriptions owned by user or shared with user (takes user id twice)
+const userCondition = "(user_id = ? OR id IN (SELECT subscription_id FROM subscription_member WHERE user_id = ?))"
@@ -0,0 +1,21 @@
+package sqlite
+
+import "encoding/json"
+
+// Condition matching subscriptions with metadata value (takes JSON path of key twice and value);
+// booleans are compared as "true"/"false" like in JSON
+const metadataCondition = "CASE json_type(metadata, ?) WHEN 'true' THEN 'true' WHEN 'false' THEN 'false' ELSE CAST(metadata ->> ? AS TEXT) END = ?"
+
+// Get JSON path of top level metadata key (keys are validated, so quoting is enough)
+func metadataPath(key string) string {
+	return `$."` + key + `"`
+}
+
+// Get metadata as statement argument (NULL if not set)
+func metadataArg(metadata json.RawMessage) interface{} {
+	if metadata == nil {
+		return nil
+	}
+
+	return string(metadata)
+}
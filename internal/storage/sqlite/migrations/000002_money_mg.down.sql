@@ -0,0 +1 @@
+UPDATE subscription SET price = price / 100;
@@ -0,0 +1,2 @@
+-- Prices are stored in minor units (kopecks) instead of whole rubles (INTEGER is 64-bit already)
+UPDATE subscription SET price = price * 100;
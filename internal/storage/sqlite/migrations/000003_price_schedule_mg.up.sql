@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS subscription_price(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL REFERENCES subscription(id) ON DELETE CASCADE,
+    price INTEGER NOT NULL CHECK (price >= 0),
+    effective_from TEXT NOT NULL CHECK (
+        -- Check ISO date format YYYY-MM-DD
+        effective_from GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]' AND
+        CAST(substr(effective_from, 1, 4) AS INTEGER) BETWEEN 2000 AND 2100 AND
+        CAST(substr(effective_from, 6, 2) AS INTEGER) BETWEEN 1 AND 12 AND
+        CAST(substr(effective_from, 9, 2) AS INTEGER) BETWEEN 1 AND 31
+    ),
+    CONSTRAINT unique_subscription_price UNIQUE (subscription_id, effective_from)
+);
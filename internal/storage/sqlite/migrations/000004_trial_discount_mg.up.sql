@@ -0,0 +1,16 @@
+ALTER TABLE subscription ADD COLUMN trial_months INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE subscription ADD COLUMN trial_price INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS subscription_discount(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL REFERENCES subscription(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('percent', 'fixed')),
+    percent INTEGER NOT NULL DEFAULT 0 CHECK (percent BETWEEN 0 AND 100),
+    amount INTEGER NOT NULL DEFAULT 0 CHECK (amount >= 0),
+    -- Optional validity range in ISO format YYYY-MM-DD
+    valid_from TEXT,
+    valid_to TEXT,
+    CONSTRAINT check_valid_to_after_from CHECK (valid_from IS NULL OR valid_to IS NULL OR valid_to > valid_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_discount_subscription_id ON subscription_discount(subscription_id);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS service(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    -- Lowercased name without separators for case insensitive matching
+    name_key TEXT NOT NULL UNIQUE,
+    default_price INTEGER NOT NULL DEFAULT 0 CHECK (default_price >= 0),
+    category TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS service_alias(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    service_id INTEGER NOT NULL REFERENCES service(id) ON DELETE CASCADE,
+    alias TEXT NOT NULL,
+    alias_key TEXT NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_service_alias_service_id ON service_alias(service_id);
+
+-- Link is checked by application (SQLite cannot drop column used in foreign key)
+ALTER TABLE subscription ADD COLUMN service_id INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_subscription_service_id ON subscription(service_id);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_subscription_user_id;
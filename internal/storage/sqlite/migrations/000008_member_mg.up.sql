@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS subscription_member(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL REFERENCES subscription(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL,
+    -- Share of cost is weight / sum of weights of subscription members
+    weight INTEGER NOT NULL CHECK (weight > 0),
+    CONSTRAINT unique_subscription_member UNIQUE (subscription_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_member_user_id ON subscription_member(user_id);
@@ -0,0 +1,4 @@
+DROP TABLE subscription_tag;
+
+DROP INDEX idx_subscription_category;
+ALTER TABLE subscription DROP COLUMN category;
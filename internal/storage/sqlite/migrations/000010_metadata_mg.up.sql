@@ -0,0 +1,2 @@
+-- Custom attributes as JSON object text (NULL if not set)
+ALTER TABLE subscription ADD COLUMN metadata TEXT CHECK (metadata IS NULL OR json_valid(metadata));
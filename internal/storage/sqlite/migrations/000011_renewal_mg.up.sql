@@ -0,0 +1,16 @@
+ALTER TABLE subscription ADD COLUMN auto_renew INTEGER NOT NULL DEFAULT 0;
+
+-- Months the subscription is extended by on renewal
+ALTER TABLE subscription ADD COLUMN billing_period INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS subscription_renewal(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL REFERENCES subscription(id) ON DELETE CASCADE,
+    -- Period bounds in ISO format YYYY-MM-DD (end is exclusive)
+    period_start TEXT NOT NULL,
+    period_end TEXT NOT NULL,
+    price INTEGER NOT NULL CHECK (price >= 0),
+    renewed_at TIMESTAMP NOT NULL,
+    -- Period is recorded once even if renewer runs several times
+    CONSTRAINT unique_subscription_renewal UNIQUE (subscription_id, period_start)
+);
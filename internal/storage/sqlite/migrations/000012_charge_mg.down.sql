@@ -0,0 +1,2 @@
+DROP INDEX idx_charge_subscription;
+DROP TABLE charge;
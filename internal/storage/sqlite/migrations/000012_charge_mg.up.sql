@@ -0,0 +1,14 @@
+-- Billing ledger: rows are only appended, changed charges are corrected by difference entries.
+-- Subscription is not referenced, so charges of deleted subscriptions are kept with their reversals
+CREATE TABLE IF NOT EXISTS charge(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL,
+    user_id TEXT NOT NULL,
+    -- Billed month in ISO format YYYY-MM-DD
+    month TEXT NOT NULL,
+    -- Negative for corrections
+    amount INTEGER NOT NULL,
+    posted_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_charge_subscription ON charge(subscription_id, month);
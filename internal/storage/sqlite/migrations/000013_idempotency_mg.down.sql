@@ -0,0 +1,2 @@
+DROP INDEX idx_idempotency_key_expires;
+DROP TABLE idempotency_key;
@@ -0,0 +1,6 @@
+-- Moments of creation and last change (changes of prices, discounts, members, tags and status move updated_at too).
+-- Column defaults must be constant in SQLite, so existing rows get migration time
+ALTER TABLE subscription ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00';
+ALTER TABLE subscription ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00';
+
+UPDATE subscription SET created_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP;
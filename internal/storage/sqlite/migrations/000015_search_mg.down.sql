@@ -0,0 +1,4 @@
+DROP TRIGGER subscription_search_update;
+DROP TRIGGER subscription_search_delete;
+DROP TRIGGER subscription_search_insert;
+DROP TABLE subscription_search;
@@ -0,0 +1,24 @@
+-- Full-text index of service names for fuzzy search: trigram tokenizer matches misspelled names by common trigrams.
+-- Index keeps no copy of names (external content) and is synced with subscription by triggers
+CREATE VIRTUAL TABLE subscription_search USING fts5(
+    service_name,
+    content = 'subscription',
+    content_rowid = 'id',
+    tokenize = 'trigram'
+);
+
+CREATE TRIGGER subscription_search_insert AFTER INSERT ON subscription BEGIN
+    INSERT INTO subscription_search (rowid, service_name) VALUES (new.id, new.service_name);
+END;
+
+CREATE TRIGGER subscription_search_delete AFTER DELETE ON subscription BEGIN
+    INSERT INTO subscription_search (subscription_search, rowid, service_name) VALUES ('delete', old.id, old.service_name);
+END;
+
+CREATE TRIGGER subscription_search_update AFTER UPDATE OF service_name ON subscription BEGIN
+    INSERT INTO subscription_search (subscription_search, rowid, service_name) VALUES ('delete', old.id, old.service_name);
+    INSERT INTO subscription_search (rowid, service_name) VALUES (new.id, new.service_name);
+END;
+
+-- Index existing subscriptions
+INSERT INTO subscription_search (subscription_search) VALUES ('rebuild');
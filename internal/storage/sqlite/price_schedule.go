@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"database/sql"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"fmt"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+func (s *SqliteStorage) AddPriceChange(id int64, change model.PriceChange) (int64, error) {
+	const op = "storage.sqlite.AddPriceChange"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Prepare transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	// 2.Prepare query
+	query := `
+	    INSERT INTO subscription_price (subscription_id,price,effective_from)
+		values (?,?,?)
+	`
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: prepare statement: %w", op, err)
+	}
+	defer stmt.Close()
+
+	// 3.Run it
+	res, err := stmt.Exec(id, int64(change.Price), change.EffectiveFrom.ToStringISO())
+	if err != nil {
+		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			s.logger.Error(loggerMsg, "details", storage.ErrPriceChangeExists)
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrPriceChangeExists)
+		}
+		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey {
+			s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrSubscribtionNotFound)
+		}
+
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	// 4.Get created item ID, regenerate charges and commit changes
+	changeId, err := res.LastInsertId()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to get last insert id: %w", op, err)
+	}
+
+	if err := touchSubscription(tx, id); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.refreshCharges(tx, &loggerMsg, op, id); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return changeId, nil
+}
+
+// Get price schedules (ordered by effective date) for subscriptions with specified ids
+func (s *SqliteStorage) getPriceSchedules(q querier, loggerMsg *string, op string, ids []int64) (map[int64][]model.PriceChange, error) {
+	schedules := make(map[int64][]model.PriceChange)
+
+	query := "SELECT id, subscription_id, price, effective_from FROM subscription_price WHERE subscription_id IN (%s) ORDER BY effective_from"
+
+	err := s.queryByIds(q, query, ids, func(rows *sql.Rows) error {
+		var change model.PriceChange
+		var subscriptionId int64
+		var effectiveFrom string
+
+		err := rows.Scan(&change.ID, &subscriptionId, &change.Price, &effectiveFrom)
+		if err != nil {
+			return fmt.Errorf("scan price change row: %w", err)
+		}
+
+		change.EffectiveFrom, err = model.DateFromStringISO(effectiveFrom)
+		if err != nil {
+			return fmt.Errorf("getting effective date: %w", err)
+		}
+
+		schedules[subscriptionId] = append(schedules[subscriptionId], change)
+
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: get price schedules: %w", op, err)
+	}
+
+	return schedules, nil
+}
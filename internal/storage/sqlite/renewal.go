@@ -0,0 +1,191 @@
+package sqlite
+
+import (
+	"database/sql"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"fmt"
+	"time"
+)
+
+// Turn auto-renewal on or off and set billing period in months
+func (s *SqliteStorage) SetAutoRenew(id int64, autoRenew bool, billingPeriod int) error {
+	const op = "storage.sqlite.SetAutoRenew"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Update
+	res, err := s.db.Exec(
+		"UPDATE subscription SET auto_renew = ?, billing_period = ?, updated_at = ? WHERE id = ?",
+		autoRenew, billingPeriod, time.Now().UTC(), id,
+	)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	// 2.Check if was updated
+	changedRows, err := res.RowsAffected()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: get affected rows: %w", op, err)
+	}
+	if changedRows == 0 {
+		s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
+		return storage.ErrSubscribtionNotFound
+	}
+
+	return nil
+}
+
+// Extend auto-renewing subscriptions ended before or in specified month by their billing periods
+// and record renewal periods; returns number of recorded periods (repeated runs record nothing new)
+func (s *SqliteStorage) RenewSubscriptions(month model.Date) (int64, error) {
+	const op = "storage.sqlite.RenewSubscriptions"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Get subscriptions to renew with price schedules
+	rows, err := s.db.Query(
+		"SELECT "+subscriptionColumns+" FROM subscription WHERE auto_renew AND status IN ('active', 'paused') AND end_date <= ? ORDER BY id",
+		month.ToStringISO(),
+	)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	subscriptions, err := s.getSubscriptionsFromSqliteRows(&loggerMsg, op, rows, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.getSubscriptionsDetails(s.db, &loggerMsg, op, subscriptions, nil); err != nil {
+		return 0, err
+	}
+
+	// 2.Renew every subscription in own transaction
+	var renewed int64
+
+	for i := 0; i < len(subscriptions); i++ {
+		count, err := s.renewSubscription(&loggerMsg, op, &subscriptions[i], month)
+		if err != nil {
+			s.logger.Error(loggerMsg, "details", err, "id", subscriptions[i].ID)
+			return renewed, fmt.Errorf("%s: %w", op, err)
+		}
+
+		renewed += count
+	}
+
+	return renewed, nil
+}
+
+// Record pending renewals of subscription, move its end date and regenerate its charges; nothing is done
+// if end date was changed since subscription was read (by another renewer or update)
+func (s *SqliteStorage) renewSubscription(loggerMsg *string, op string, subscription *model.Subscription, month model.Date) (int64, error) {
+	renewals := subscription.PendingRenewals(month)
+	if len(renewals) == 0 {
+		return 0, nil
+	}
+
+	// 1.Prepare transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("prepare transaction: %w", err)
+	}
+
+	defer tx.Rollback()
+
+	// 2.Move end date
+	newEnd := renewals[len(renewals)-1].PeriodEnd
+	renewedAt := time.Now().UTC()
+
+	res, err := tx.Exec(
+		"UPDATE subscription SET end_date = ?, updated_at = ? WHERE id = ? AND end_date = ?",
+		newEnd.ToStringISO(), renewedAt, subscription.ID, subscription.EndDate.ToStringISO(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("update end date: %w", err)
+	}
+
+	changedRows, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("get affected rows: %w", err)
+	}
+	if changedRows == 0 {
+		return 0, nil
+	}
+
+	// 3.Record periods
+	var recorded int64
+
+	for _, renewal := range renewals {
+		res, err := tx.Exec(
+			`INSERT INTO subscription_renewal (subscription_id,period_start,period_end,price,renewed_at)
+			values (?,?,?,?,?)
+			ON CONFLICT (subscription_id, period_start) DO NOTHING`,
+			subscription.ID, renewal.PeriodStart.ToStringISO(), renewal.PeriodEnd.ToStringISO(), int64(renewal.Price), renewedAt,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("insert renewal: %w", err)
+		}
+
+		inserted, err := res.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("get affected rows: %w", err)
+		}
+		recorded += inserted
+	}
+
+	// 4.Regenerate charges and commit changes
+	if err := s.refreshCharges(tx, loggerMsg, op, subscription.ID); err != nil {
+		return 0, fmt.Errorf("regenerate charges: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return recorded, nil
+}
+
+// Get renewals (ordered by period start) of subscriptions with specified ids
+func (s *SqliteStorage) getRenewals(q querier, loggerMsg *string, op string, ids []int64) (map[int64][]model.Renewal, error) {
+	renewals := make(map[int64][]model.Renewal)
+
+	query := `
+		SELECT id, subscription_id, period_start, period_end, price, renewed_at
+		FROM subscription_renewal
+		WHERE subscription_id IN (%s)
+		ORDER BY period_start
+	`
+
+	err := s.queryByIds(q, query, ids, func(rows *sql.Rows) error {
+		var renewal model.Renewal
+		var subscriptionId int64
+		var periodStart, periodEnd string
+
+		err := rows.Scan(&renewal.ID, &subscriptionId, &periodStart, &periodEnd, &renewal.Price, &renewal.RenewedAt)
+		if err != nil {
+			return fmt.Errorf("scan renewal row: %w", err)
+		}
+
+		renewal.PeriodStart, err = model.DateFromStringISO(periodStart)
+		if err != nil {
+			return fmt.Errorf("getting period start: %w", err)
+		}
+
+		renewal.PeriodEnd, err = model.DateFromStringISO(periodEnd)
+		if err != nil {
+			return fmt.Errorf("getting period end: %w", err)
+		}
+
+		renewals[subscriptionId] = append(renewals[subscriptionId], renewal)
+
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: get renewals: %w", op, err)
+	}
+
+	return renewals, nil
+}
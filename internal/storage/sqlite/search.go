@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"em_golang_rest_service_example/internal/model"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// Shortest query matched with trigram index (shorter one is searched as substring of service name)
+	searchTrigramLength = 3
+
+	// Lowest share of query trigrams service name has to contain for subscription to be found
+	searchMinTrigramShare = 0.5
+)
+
+// Search subscriptions by service name, the closest ones go first: names containing more trigrams of query
+// are ranked higher, so misspelled names are found too (every trigram is looked up in FTS5 index)
+func (s *SqliteStorage) SearchSubscriptions(query string, limit int) ([]model.Subscription, error) {
+	const op = "storage.sqlite.SearchSubscriptions"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	// 1.Prepare query
+	var stmt string
+	var args []interface{}
+
+	if utf8.RuneCountInString(query) < searchTrigramLength {
+		stmt = "SELECT " + subscriptionColumns + " FROM subscription WHERE instr(lower(service_name), ?) > 0 ORDER BY length(service_name), id LIMIT ?"
+		args = []interface{}{query, limit}
+	} else {
+		trigrams := queryTrigrams(query)
+
+		lookups := make([]string, 0, len(trigrams))
+		for _, trigram := range trigrams {
+			lookups = append(lookups, "SELECT rowid FROM subscription_search WHERE subscription_search MATCH ?")
+			args = append(args, trigram)
+		}
+
+		stmt = `
+			SELECT ` + subscriptionColumns + ` FROM subscription
+			JOIN (
+				SELECT rowid, count(*) AS common FROM (` + strings.Join(lookups, " UNION ALL ") + `)
+				GROUP BY rowid HAVING count(*) >= ?
+			) AS found ON found.rowid = subscription.id
+			ORDER BY found.common DESC, length(service_name), id LIMIT ?
+		`
+		args = append(args, searchMinTrigramShare*float64(len(trigrams)), limit)
+	}
+
+	// 2.Run it
+	rows, err := s.db.Query(stmt, args...)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return []model.Subscription{}, fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	// 3.Get data with tags
+	subscriptions, err := s.getSubscriptionsFromSqliteRows(&loggerMsg, op, rows, nil)
+	if err != nil {
+		return []model.Subscription{}, err
+	}
+
+	if err := s.fillTags(&loggerMsg, op, subscriptions); err != nil {
+		return []model.Subscription{}, err
+	}
+
+	return subscriptions, nil
+}
+
+// Distinct trigrams of text as FTS5 queries (trigram is quoted, so query syntax in text is not interpreted)
+func queryTrigrams(text string) []string {
+	runes := []rune(text)
+	seen := make(map[string]bool, len(runes))
+	trigrams := make([]string, 0, len(runes))
+
+	for i := 0; i+searchTrigramLength <= len(runes); i++ {
+		trigram := string(runes[i : i+searchTrigramLength])
+		if seen[trigram] {
+			continue
+		}
+		seen[trigram] = true
+
+		trigrams = append(trigrams, `"`+strings.ReplaceAll(trigram, `"`, `""`)+`"`)
+	}
+
+	return trigrams
+}
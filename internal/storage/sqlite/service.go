@@ -0,0 +1,467 @@
+package sqlite
+
+import (
+	"database/sql"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+func (s *SqliteStorage) CreateService(service model.Service) (int64, error) {
+	const op = "storage.sqlite.CreateService"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Prepare transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	// 2.Check name and aliases are not used by other services
+	err = checkServiceKeys(tx, 0, &service)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// 3.Insert service with aliases
+	res, err := tx.Exec(
+		"INSERT INTO service (name,name_key,default_price,category) values (?,?,?,?)",
+		service.Name,
+		model.ServiceNameKey(service.Name),
+		int64(service.DefaultPrice),
+		service.Category,
+	)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: %w", op, serviceExecErr(err))
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to get last insert id: %w", op, err)
+	}
+
+	err = insertServiceAliases(tx, id, service.Aliases)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: %w", op, serviceExecErr(err))
+	}
+
+	// 4.Commit changes
+	err = tx.Commit()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s *SqliteStorage) GetService(id int64) (model.Service, error) {
+	const op = "storage.sqlite.GetService"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Get service
+	services, err := s.getServices(&loggerMsg, op, "WHERE id = ?", id)
+	if err != nil {
+		return model.Service{}, err
+	}
+	if len(services) == 0 {
+		s.logger.Error(loggerMsg, "details", storage.ErrServiceNotFound)
+		return model.Service{}, storage.ErrServiceNotFound
+	}
+
+	return services[0], nil
+}
+
+func (s *SqliteStorage) GetServices() ([]model.Service, error) {
+	const op = "storage.sqlite.GetServices"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	return s.getServices(&loggerMsg, op, "")
+}
+
+// Find service by its name or alias (case and separators insensitive)
+func (s *SqliteStorage) ResolveService(name string) (model.Service, error) {
+	const op = "storage.sqlite.ResolveService"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	key := model.ServiceNameKey(name)
+
+	services, err := s.getServices(
+		&loggerMsg, op,
+		"WHERE name_key = ? OR id IN (SELECT service_id FROM service_alias WHERE alias_key = ?)",
+		key, key,
+	)
+	if err != nil {
+		return model.Service{}, err
+	}
+	if len(services) == 0 {
+		return model.Service{}, storage.ErrServiceNotFound
+	}
+
+	return services[0], nil
+}
+
+// Replace service data and aliases; subscriptions of service get new name
+func (s *SqliteStorage) UpdateService(id int64, service model.Service) error {
+	const op = "storage.sqlite.UpdateService"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Prepare transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	// 2.Check name and aliases are not used by other services
+	err = checkServiceKeys(tx, id, &service)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// 3.Update service
+	res, err := tx.Exec(
+		"UPDATE service SET name = ?, name_key = ?, default_price = ?, category = ? WHERE id = ?",
+		service.Name,
+		model.ServiceNameKey(service.Name),
+		int64(service.DefaultPrice),
+		service.Category,
+		id,
+	)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: %w", op, serviceExecErr(err))
+	}
+
+	changedRows, err := res.RowsAffected()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: get affected rows: %w", op, err)
+	}
+	if changedRows == 0 {
+		s.logger.Error(loggerMsg, "details", storage.ErrServiceNotFound)
+		return storage.ErrServiceNotFound
+	}
+
+	// 4.Replace aliases
+	_, err = tx.Exec("DELETE FROM service_alias WHERE service_id = ?", id)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: delete aliases: %w", op, err)
+	}
+
+	err = insertServiceAliases(tx, id, service.Aliases)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: %w", op, serviceExecErr(err))
+	}
+
+	// 5.Rename subscriptions of service
+	_, err = tx.Exec("UPDATE subscription SET service_name = ?, updated_at = ? WHERE service_id = ?", service.Name, time.Now().UTC(), id)
+	if err != nil {
+		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			s.logger.Error(loggerMsg, "details", storage.ErrSubscriptionExists)
+			return fmt.Errorf("%s: %w", op, storage.ErrSubscriptionExists)
+		}
+
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: rename subscriptions: %w", op, err)
+	}
+
+	// 6.Commit changes
+	err = tx.Commit()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *SqliteStorage) DeleteService(id int64) error {
+	const op = "storage.sqlite.DeleteService"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Prepare transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	// 2.Check if service is used
+	var used int
+	err = tx.QueryRow("SELECT 1 FROM subscription WHERE service_id = ? LIMIT 1", id).Scan(&used)
+	if err == nil {
+		s.logger.Error(loggerMsg, "details", storage.ErrServiceInUse)
+		return fmt.Errorf("%s: %w", op, storage.ErrServiceInUse)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: check service usage: %w", op, err)
+	}
+
+	// 3.Delete (aliases are deleted by cascade)
+	res, err := tx.Exec("DELETE FROM service WHERE id = ?", id)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: delete service: %w", op, err)
+	}
+
+	deletedRows, err := res.RowsAffected()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: get affected rows: %w", op, err)
+	}
+	if deletedRows == 0 {
+		s.logger.Error(loggerMsg, "details", storage.ErrServiceNotFound)
+		return storage.ErrServiceNotFound
+	}
+
+	// 4.Commit changes
+	err = tx.Commit()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+// Link subscriptions without service to catalog (registering missing services) and replace
+// their service names with canonical ones; subscriptions which would duplicate another one are skipped
+func (s *SqliteStorage) NormalizeServiceNames() (model.NormalizeReport, error) {
+	const op = "storage.sqlite.NormalizeServiceNames"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	report := model.NormalizeReport{Conflicts: []int64{}}
+
+	// 1.Prepare transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return model.NormalizeReport{}, fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	// 2.Get not linked subscriptions
+	type unlinked struct {
+		id          int64
+		serviceName string
+		price       int64
+	}
+
+	rows, err := tx.Query("SELECT id, service_name, price FROM subscription WHERE service_id IS NULL ORDER BY id")
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return model.NormalizeReport{}, fmt.Errorf("%s: query subscriptions: %w", op, err)
+	}
+
+	var subs []unlinked
+	for rows.Next() {
+		var sub unlinked
+		if err := rows.Scan(&sub.id, &sub.serviceName, &sub.price); err != nil {
+			rows.Close()
+			s.logger.Error(loggerMsg, "details", err)
+			return model.NormalizeReport{}, fmt.Errorf("%s: scan subscription row: %w", op, err)
+		}
+		subs = append(subs, sub)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return model.NormalizeReport{}, fmt.Errorf("%s: iterate subscriptions: %w", op, err)
+	}
+
+	// 3.Link them one by one
+	for _, sub := range subs {
+		serviceId, name, registered, err := resolveOrRegisterService(tx, sub.serviceName, model.Money(sub.price))
+		if err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return model.NormalizeReport{}, fmt.Errorf("%s: %w", op, err)
+		}
+		if registered {
+			report.Registered++
+		}
+
+		_, err = tx.Exec("UPDATE subscription SET service_id = ?, service_name = ?, updated_at = ? WHERE id = ?", serviceId, name, time.Now().UTC(), sub.id)
+		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			report.Conflicts = append(report.Conflicts, sub.id)
+			continue
+		}
+		if err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return model.NormalizeReport{}, fmt.Errorf("%s: link subscription: %w", op, err)
+		}
+
+		report.Linked++
+	}
+
+	// 4.Commit changes
+	err = tx.Commit()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return model.NormalizeReport{}, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return report, nil
+}
+
+// Get services with aliases selected with optional WHERE clause
+func (s *SqliteStorage) getServices(loggerMsg *string, op string, where string, args ...interface{}) ([]model.Service, error) {
+	services := []model.Service{}
+
+	// 1.Get services
+	rows, err := s.db.Query("SELECT id, name, default_price, category FROM service "+where+" ORDER BY id", args...)
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: query services: %w", op, err)
+	}
+
+	for rows.Next() {
+		service := model.Service{Aliases: []string{}}
+		if err := rows.Scan(&service.ID, &service.Name, &service.DefaultPrice, &service.Category); err != nil {
+			rows.Close()
+			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while parsing db data: %w", err))
+			return nil, fmt.Errorf("%s: scan service row: %w", op, err)
+		}
+		services = append(services, service)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: iterate services: %w", op, err)
+	}
+
+	// 2.Get their aliases
+	ids := make([]int64, 0, len(services))
+	index := make(map[int64]int, len(services))
+	for i := 0; i < len(services); i++ {
+		ids = append(ids, services[i].ID)
+		index[services[i].ID] = i
+	}
+
+	err = s.queryByIds(s.db, "SELECT service_id, alias FROM service_alias WHERE service_id IN (%s) ORDER BY id", ids, func(rows *sql.Rows) error {
+		var serviceId int64
+		var alias string
+
+		if err := rows.Scan(&serviceId, &alias); err != nil {
+			return fmt.Errorf("scan alias row: %w", err)
+		}
+		services[index[serviceId]].Aliases = append(services[index[serviceId]].Aliases, alias)
+
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: get aliases: %w", op, err)
+	}
+
+	return services, nil
+}
+
+// Check that service name and aliases keys are not used by another service
+func checkServiceKeys(tx *sql.Tx, id int64, service *model.Service) error {
+	keys := []string{model.ServiceNameKey(service.Name)}
+	for _, alias := range service.Aliases {
+		keys = append(keys, model.ServiceNameKey(alias))
+	}
+
+	placeholders := "?" + strings.Repeat(",?", len(keys)-1)
+
+	args := []interface{}{}
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	args = append(args, id)
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	args = append(args, id)
+
+	var taken int
+	err := tx.QueryRow(
+		"SELECT 1 FROM service WHERE name_key IN ("+placeholders+") AND id <> ? "+
+			"UNION ALL SELECT 1 FROM service_alias WHERE alias_key IN ("+placeholders+") AND service_id <> ? LIMIT 1",
+		args...,
+	).Scan(&taken)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("check service keys: %w", err)
+	}
+
+	return storage.ErrServiceExists
+}
+
+// Insert service aliases with their keys
+func insertServiceAliases(tx *sql.Tx, id int64, aliases []string) error {
+	for _, alias := range aliases {
+		_, err := tx.Exec("INSERT INTO service_alias (service_id,alias,alias_key) values (?,?,?)", id, alias, model.ServiceNameKey(alias))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Find service by name or alias, register new one (with specified default price) if there is no such;
+// returns service id and canonical name
+func resolveOrRegisterService(tx *sql.Tx, name string, defaultPrice model.Money) (int64, string, bool, error) {
+	key := model.ServiceNameKey(name)
+
+	var id int64
+	var canonical string
+
+	err := tx.QueryRow(
+		"SELECT id, name FROM service WHERE name_key = ? OR id IN (SELECT service_id FROM service_alias WHERE alias_key = ?)",
+		key, key,
+	).Scan(&id, &canonical)
+	if err == nil {
+		return id, canonical, false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, "", false, fmt.Errorf("resolve service: %w", err)
+	}
+
+	res, err := tx.Exec("INSERT INTO service (name,name_key,default_price) values (?,?,?)", name, key, int64(defaultPrice))
+	if err != nil {
+		return 0, "", false, fmt.Errorf("register service: %w", err)
+	}
+
+	id, err = res.LastInsertId()
+	if err != nil {
+		return 0, "", false, fmt.Errorf("register service: %w", err)
+	}
+
+	return id, name, true, nil
+}
+
+// Map constraint violation on service data change to storage error
+func serviceExecErr(err error) error {
+	if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return storage.ErrServiceExists
+	}
+	return err
+}
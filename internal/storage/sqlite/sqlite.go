@@ -1,12 +1,17 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"em_golang_rest_service_example/internal/model"
 	"em_golang_rest_service_example/internal/storage"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/mattn/go-sqlite3"
@@ -26,14 +31,42 @@ func newStorage(db *sql.DB, logger *slog.Logger) SqliteStorage {
 func NewStorage(storagePath *string, logger *slog.Logger) (SqliteStorage, error) {
 	const op = "storage.sqlite.NewStorage"
 
-	db, err := sql.Open("sqlite3", *storagePath)
+	db, err := sql.Open("sqlite3", withForeignKeys(*storagePath))
 	if err != nil {
 		return SqliteStorage{}, fmt.Errorf("%s: %w", op, err)
 	}
 
+	if err := checkFTS5(db); err != nil {
+		db.Close()
+		return SqliteStorage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
 	return SqliteStorage{db: db, logger: logger}, nil
 }
 
+// Check that sqlite driver supports FTS5: search index is FTS5 table synced by triggers, so without it
+// every insert or update of subscription fails (driver supports FTS5 with sqlite_fts5 build tag only)
+func checkFTS5(db *sql.DB) error {
+	var enabled bool
+	if err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled); err != nil {
+		return fmt.Errorf("check FTS5 support: %w", err)
+	}
+
+	if !enabled {
+		return errors.New("sqlite driver is built without FTS5 required by search index, build with -tags sqlite_fts5 (set by make)")
+	}
+
+	return nil
+}
+
+// Add DSN param enabling foreign keys (disabled by default in SQLite)
+func withForeignKeys(dsn string) string {
+	if strings.Contains(dsn, "?") {
+		return dsn + "&_foreign_keys=on"
+	}
+	return dsn + "?_foreign_keys=on"
+}
+
 // Close db connection
 func (s *SqliteStorage) Close() {
 	s.logger.Info("closing database")
@@ -44,137 +77,229 @@ func (s *SqliteStorage) CreateSubscription(spec model.SubscriptionSpec) (int64,
 	const op = "storage.sqlite.CreateSubscription"
 	var loggerMsg string = fmt.Sprintf("operation is %s", op)
 
-	// 1.Prepare query
-	query := `
-	    INSERT INTO subscription (service_name,price,user_id,start_date,end_date)
-		values (?,?,?,?,?)
-	`
-	stmt, err := s.db.Prepare(query)
+	// 1.Prepare transaction
+	tx, err := s.db.Begin()
 	if err != nil {
 		s.logger.Error(loggerMsg, "details", err)
-		return 0, fmt.Errorf("%s: prepare statement: %w", op, err)
+		return 0, fmt.Errorf("%s: prepare transaction: %w", op, err)
 	}
 
-	// 2.Run it
+	defer tx.Rollback()
+
+	// 2.Insert subscription with members and tags
+	id, err := insertSubscription(tx, spec)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// 3.Generate charges, commit and return ID
+	if err := s.refreshCharges(tx, &loggerMsg, op, id); err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// Insert subscription (it gets canonical name of catalog service registered if needed), its members and tags
+func insertSubscription(tx *sql.Tx, spec model.SubscriptionSpec) (int64, error) {
+	// 1.Get catalog service
+	serviceId, serviceName, _, err := resolveOrRegisterService(tx, spec.ServiceName, spec.Price)
+	if err != nil {
+		return 0, err
+	}
+
+	// 2.Insert subscription
+	query := `
+	    INSERT INTO subscription (service_name,price,user_id,start_date,end_date,trial_months,trial_price,service_id,category,metadata,auto_renew,billing_period,created_at,updated_at)
+		values (?,?,?,?,?,?,?,?,COALESCE(NULLIF(?,''),(SELECT LOWER(TRIM(category)) FROM service WHERE id = ?),''),?,?,?,?,?)
+	`
+
 	startDate := spec.StartDate.ToStringISO()
 	endDate := spec.EndDate.ToStringISO()
+	now := time.Now().UTC()
 
-	res, err := stmt.Exec(spec.ServiceName, spec.Price, spec.UserID, startDate, endDate)
+	res, err := tx.Exec(query, serviceName, int64(spec.Price), spec.UserID, startDate, endDate, spec.TrialMonths, int64(spec.TrialPrice), serviceId, spec.Category, serviceId, metadataArg(spec.Metadata), spec.AutoRenew, spec.Period(), now, now)
 	if err != nil {
 		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
-			s.logger.Error(loggerMsg, "details", storage.ErrSubscriptionExists)
-			return 0, fmt.Errorf("%s: %w", op, storage.ErrSubscriptionExists)
+			return 0, storage.ErrSubscriptionExists
 		}
 
-		s.logger.Error(loggerMsg, "details", err)
-		return 0, fmt.Errorf("%s: execute statement: %w", op, err)
+		return 0, fmt.Errorf("execute statement: %w", err)
 	}
 
-	// 3.Get created item ID and return it
+	// 3.Get created item ID and store members of shared subscription and tags
 	id, err := res.LastInsertId()
 	if err != nil {
-		s.logger.Error(loggerMsg, "details", err)
-		return 0, fmt.Errorf("%s: failed to get last insert id: %w", op, err)
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	if err := insertMembers(tx, id, spec.Members); err != nil {
+		return 0, err
+	}
+
+	if err := insertTags(tx, id, spec.Tags); err != nil {
+		return 0, err
 	}
 
 	return id, nil
 }
 
 func (s *SqliteStorage) GetSubscription(id int64) (model.Subscription, error) {
+	return s.GetSubscriptionFields(id, nil)
+}
+
+// Read subscription with requested fields only (nil means all), columns and details not requested are not queried
+func (s *SqliteStorage) GetSubscriptionFields(id int64, fields model.FieldSet) (model.Subscription, error) {
 	const op = "storage.sqlite.GetSubscription"
 	var loggerMsg string = fmt.Sprintf("operation is %s", op)
 
 	// 1.Prepare query
-	query := "SELECT * FROM subscription WHERE id = ?"
+	query := "SELECT " + selectColumns(fields) + " FROM subscription WHERE id = ?"
 	stmt, err := s.db.Prepare(query)
 	if err != nil {
 		s.logger.Error(loggerMsg, "details", err)
 		return model.Subscription{}, fmt.Errorf("%s: prepare statement: %w", op, err)
 	}
+	defer stmt.Close()
 
 	// 2.Run it
-	var startDate string
-	var endDate string
-
-	var subscription model.Subscription
-
-	err = stmt.QueryRow(id).Scan(
-		&subscription.ID,
-		&subscription.ServiceName,
-		&subscription.Price,
-		&subscription.UserID,
-		&startDate,
-		&endDate,
-	)
+	subscription, err := s.scanSubscription(&loggerMsg, op, stmt.QueryRow(id), fields)
 	if errors.Is(err, sql.ErrNoRows) {
 		s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
 		return model.Subscription{}, storage.ErrSubscribtionNotFound
 	}
 	if err != nil {
-		s.logger.Error(loggerMsg, "details", err)
-		return model.Subscription{}, fmt.Errorf("%s: execute statement: %w", op, err)
+		return model.Subscription{}, err
 	}
 
-	// 3.Return subscription model data
+	// 3.Get subscription details (price schedule etc)
+	subscriptions := []model.Subscription{subscription}
 
-	// 3.1.Start date
-	start, err := model.DateFromStringISO(startDate)
+	err = s.getSubscriptionsDetails(s.db, &loggerMsg, op, subscriptions, fields)
 	if err != nil {
-		s.logger.Error(loggerMsg, "details", fmt.Errorf("error while getting start date: %w", err))
-		return model.Subscription{}, fmt.Errorf("%s: getting start date: %w", op, err)
+		return model.Subscription{}, err
 	}
-	subscription.StartDate = start
 
-	// 3.2.End date
-	end, err := model.DateFromStringISO(endDate)
-	if err != nil {
-		s.logger.Error(loggerMsg, "details", fmt.Errorf("error while getting end date: %w", err))
-		return model.Subscription{}, fmt.Errorf("%s: getting end date: %w", op, err)
-	}
-	subscription.EndDate = end
-
-	return subscription, nil
+	return subscriptions[0], nil
 }
 
-func (s *SqliteStorage) UpdateSubscription(id int64, newServiceName string, newPrice int, newStart, newEnd model.Date) error {
+// Update subscription fields set in update; merged result is validated before it is written
+// (error wraps *model.ValidationError if it is invalid)
+func (s *SqliteStorage) UpdateSubscription(id int64, update model.SubscriptionUpdate) error {
 	const op = "storage.sqlite.UpdateSubscription"
 	var loggerMsg string = fmt.Sprintf("operation is %s", op)
 
-	var res sql.Result
+	// 1.Prepare transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
 
-	// 1.Prepare query in according with end_date value
-	query := "UPDATE subscription SET service_name = ?, price = ?, start_date = ?"
-	args := []interface{}{newServiceName, newPrice, newStart.ToStringISO()}
+	defer tx.Rollback()
 
-	if !(newEnd.Month == 0 && newEnd.Year == 0) {
-		query += ", end_date = ?"
-		args = append(args, newEnd.ToStringISO())
+	// 2.Get current subscription
+	subscription, err := s.scanSubscription(&loggerMsg, op, tx.QueryRow("SELECT "+subscriptionColumns+" FROM subscription WHERE id = ?", id), nil)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
+		return storage.ErrSubscribtionNotFound
 	}
-	query += " WHERE id = ?"
-	args = append(args, id)
-
-	stmt, err := s.db.Prepare(query)
 	if err != nil {
-		s.logger.Error(loggerMsg, "details", err)
-		return fmt.Errorf("%s: prepare statement: %w", op, err)
+		return err
 	}
 
-	// 2.Run
-	res, err = stmt.Exec(args...)
+	// 3.Merge and validate
+	if err := update.Apply(&subscription); err != nil {
+		s.logger.Info(loggerMsg, "details", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// 4.Get catalog service for new name
+	serviceId := sql.NullInt64{Int64: subscription.ServiceID, Valid: subscription.ServiceID != 0}
+	if update.ServiceName != nil {
+		catalogId, catalogName, _, err := resolveOrRegisterService(tx, subscription.ServiceName, subscription.Price)
+		if err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		serviceId = sql.NullInt64{Int64: catalogId, Valid: true}
+		subscription.ServiceName = catalogName
+	}
+
+	// 5.Write merged subscription (cleared category is taken from catalog service as on creation)
+	query := `
+		UPDATE subscription SET
+			service_name = ?, service_id = ?, price = ?, start_date = ?, end_date = ?, trial_months = ?, trial_price = ?,
+			category = CASE WHEN ? THEN COALESCE(NULLIF(?,''),(SELECT LOWER(TRIM(category)) FROM service WHERE id = ?),'') ELSE ? END,
+			metadata = ?, auto_renew = ?, billing_period = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	_, err = tx.Exec(
+		query,
+		subscription.ServiceName,
+		serviceId,
+		int64(subscription.Price),
+		subscription.StartDate.ToStringISO(),
+		subscription.EndDate.ToStringISO(),
+		subscription.TrialMonths,
+		int64(subscription.TrialPrice),
+		update.Category != nil, subscription.Category, serviceId, subscription.Category,
+		metadataArg(subscription.Metadata),
+		subscription.AutoRenew,
+		subscription.Period(),
+		time.Now().UTC(),
+		id,
+	)
 	if err != nil {
+		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return storage.ErrSubscriptionExists
+		}
+
 		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: update subscription: %w", op, err)
+	}
+
+	// 6.Replace members and tags if they are updated
+	if update.Members != nil {
+		if _, err := tx.Exec("DELETE FROM subscription_member WHERE subscription_id = ?", id); err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return fmt.Errorf("%s: delete members: %w", op, err)
+		}
+		if err := insertMembers(tx, id, subscription.Members); err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if update.Tags != nil {
+		if _, err := tx.Exec("DELETE FROM subscription_tag WHERE subscription_id = ?", id); err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return fmt.Errorf("%s: delete tags: %w", op, err)
+		}
+		if err := insertTags(tx, id, subscription.Tags); err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	// 7.Regenerate charges and commit changes
+	if err := s.refreshCharges(tx, &loggerMsg, op, id); err != nil {
 		return err
 	}
 
-	// 3.Check if was updated and return corresponding status
-	changedRows, err := res.RowsAffected()
+	err = tx.Commit()
 	if err != nil {
 		s.logger.Error(loggerMsg, "details", err)
-		return err
-	}
-	if changedRows == 0 {
-		s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
-		return storage.ErrSubscribtionNotFound
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
 	}
 
 	return nil
@@ -184,26 +309,35 @@ func (s *SqliteStorage) DeleteSubscription(id int64) error {
 	const op = "storage.sqlite.DeleteSubscription"
 	var loggerMsg string = fmt.Sprintf("operation is %s", op)
 
-	// 1.Prepare query
+	// 1.Prepare transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	// 2.Prepare query
 	query := `
 	    DELETE FROM subscription
 		WHERE id = ?
 	`
 
-	stmt, err := s.db.Prepare(query)
+	stmt, err := tx.Prepare(query)
 	if err != nil {
 		s.logger.Error(loggerMsg, "details", err)
 		return fmt.Errorf("%s: prepare statement: %w", op, err)
 	}
 
-	// 2.Run it
+	// 3.Run it
 	res, err := stmt.Exec(id)
 	if err != nil {
 		s.logger.Error(loggerMsg, "details", err)
 		return err
 	}
 
-	// 3.Check if was deleted and return corresponding status
+	// 4.Check if was deleted and return corresponding status
 	deletedRows, err := res.RowsAffected()
 	if err != nil {
 		s.logger.Error(loggerMsg, "details", err)
@@ -214,10 +348,21 @@ func (s *SqliteStorage) DeleteSubscription(id int64) error {
 		return storage.ErrSubscribtionNotFound
 	}
 
+	// 5.Reverse charges and commit changes
+	if err := s.refreshCharges(tx, &loggerMsg, op, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
 	return nil
 }
 
-func (s *SqliteStorage) GetSubscriptions(limit, offset *int) ([]model.Subscription, error) {
+// Read filtered subscriptions with requested fields only (nil means all)
+func (s *SqliteStorage) GetSubscriptions(limit, offset *int, filter model.SubscriptionFilter, fields model.FieldSet) ([]model.Subscription, error) {
 	const op = "storage.sqlite.GetSubscriptions"
 	var loggerMsg string = fmt.Sprintf("operation is %s", op)
 
@@ -231,9 +376,11 @@ func (s *SqliteStorage) GetSubscriptions(limit, offset *int) ([]model.Subscripti
 	}
 
 	// 2.Prepare query
-	query := "SELECT * FROM subscription"
+	query := "SELECT " + selectColumns(fields) + " FROM subscription WHERE 1 = 1"
 	args := []interface{}{}
 
+	query, args = appendFilter(query, args, filter)
+
 	if limit != nil {
 		query += " LIMIT ? OFFSET ?"
 		args = append(args, *limit, *offset)
@@ -253,32 +400,131 @@ func (s *SqliteStorage) GetSubscriptions(limit, offset *int) ([]model.Subscripti
 	}
 
 	// 4.Get data
-	subscriptions, err := s.getSubscriptionsFromSqliteRows(&loggerMsg, op, rows)
+	subscriptions, err := s.getSubscriptionsFromSqliteRows(&loggerMsg, op, rows, fields)
 	if err != nil {
 		return []model.Subscription{}, err
 	}
 
+	if fields.Has(model.FieldTags) {
+		if err := s.fillTags(&loggerMsg, op, subscriptions); err != nil {
+			return []model.Subscription{}, err
+		}
+	}
+
 	return subscriptions, nil
 }
 
-func (s *SqliteStorage) FilterSubscriptions(startDate, endDate model.Date, userId uuid.UUID, serviceName *string) ([]model.Subscription, error) {
+// Read filtered subscriptions (ordered by id) with requested fields only (nil means all) one by one
+// passing every row to yield as soon as it is scanned; tags are loaded with the same query,
+// other details stored in child tables are not, reading stops on first yield error or context cancellation
+func (s *SqliteStorage) StreamSubscriptions(ctx context.Context, limit, offset *int, filter model.SubscriptionFilter, fields model.FieldSet, yield func(sub *model.Subscription) error) error {
+	const op = "storage.sqlite.StreamSubscriptions"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Validation
+	if limit != nil && offset == nil {
+		s.logger.Error(loggerMsg, "details", "no offset value while limit is set")
+		return errors.New("no offset value while limit is set")
+	} else if limit == nil && offset != nil {
+		s.logger.Error(loggerMsg, "details", "no limit value while offset is set")
+		return errors.New("no limit value while offset is set")
+	}
+
+	withTags := fields.Has(model.FieldTags)
+
+	// 2.Prepare query
+	query := "SELECT " + selectColumns(fields)
+	if withTags {
+		query += ", " + tagsColumn
+	}
+	query += " FROM subscription WHERE 1 = 1"
+	args := []interface{}{}
+
+	query, args = appendFilter(query, args, filter)
+	query += " ORDER BY id"
+
+	if limit != nil {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, *limit, *offset)
+	}
+
+	// 3.Run it
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	// 4.Pass rows
+	for rows.Next() {
+		// Driver notices cancellation asynchronously, so it is checked before every row too
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		var tags []byte
+
+		row := extraColumnsScanner{row: rows}
+		if withTags {
+			row.extra = []any{&tags}
+		}
+
+		sub, err := s.scanSubscription(&loggerMsg, op, row, fields)
+		if err != nil {
+			return err
+		}
+
+		if withTags {
+			if err := json.Unmarshal(tags, &sub.Tags); err != nil {
+				s.logger.Error(loggerMsg, "details", err)
+				return fmt.Errorf("%s: parse tags: %w", op, err)
+			}
+			if len(sub.Tags) == 0 {
+				sub.Tags = nil
+			}
+		}
+
+		if err := yield(&sub); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *SqliteStorage) FilterSubscriptions(startDate, endDate model.Date, userId uuid.UUID, serviceName *string, filter model.SubscriptionFilter) ([]model.Subscription, error) {
 	const op = "storage.sqlite.FilterSubscriptions"
 	var loggerMsg string = fmt.Sprintf("operation is %s", op)
 
 	// 1.Prepare query
-	query := "SELECT * FROM subscription WHERE start_date > ? AND end_date < ?"
+	query := "SELECT " + subscriptionColumns + " FROM subscription WHERE start_date > ? AND end_date < ?"
 	args := []interface{}{startDate.ToStringISO(), endDate.ToStringISO()}
 
+	// Shared subscriptions are matched for every member
 	if userId != uuid.Nil {
-		query += " AND user_id = ?"
-		args = append(args, userId.String())
+		query += " AND " + userCondition
+		args = append(args, userId.String(), userId.String())
 	}
 
+	// Service name matches catalog service names and aliases too
 	if serviceName != nil {
-		query += " AND service_name = ?"
-		args = append(args, *serviceName)
+		key := model.ServiceNameKey(*serviceName)
+
+		query += ` AND (service_name = ? OR service_id IN (
+			SELECT id FROM service WHERE name_key = ? UNION SELECT service_id FROM service_alias WHERE alias_key = ?
+		))`
+		args = append(args, *serviceName, key, key)
 	}
 
+	query, args = appendFilter(query, args, filter)
+
 	stmt, err := s.db.Prepare(query)
 	if err != nil {
 		s.logger.Error(loggerMsg, "details", err)
@@ -293,7 +539,13 @@ func (s *SqliteStorage) FilterSubscriptions(startDate, endDate model.Date, userI
 	}
 
 	// 4.Get data
-	filtered, err := s.getSubscriptionsFromSqliteRows(&loggerMsg, op, rows)
+	filtered, err := s.getSubscriptionsFromSqliteRows(&loggerMsg, op, rows, nil)
+	if err != nil {
+		return []model.Subscription{}, err
+	}
+
+	// 5.Get details (price schedules etc) for cost calculation
+	err = s.getSubscriptionsDetails(s.db, &loggerMsg, op, filtered, nil)
 	if err != nil {
 		return []model.Subscription{}, err
 	}
@@ -301,46 +553,303 @@ func (s *SqliteStorage) FilterSubscriptions(startDate, endDate model.Date, userI
 	return filtered, nil
 }
 
-func (s *SqliteStorage) getSubscriptionsFromSqliteRows(loggerMsg *string, op string, rows *sql.Rows) ([]model.Subscription, error) {
-	var subscriptions []model.Subscription
+// Columns of subscription table in order expected by scanSubscription
+var subscriptionColumns = selectColumns(nil)
 
-	for rows.Next() {
-		var sub model.Subscription
-
-		var startDate string
-		var endDate string
-
-		err := rows.Scan(
-			&sub.ID,
-			&sub.ServiceName,
-			&sub.Price,
-			&sub.UserID,
-			&startDate,
-			&endDate,
-		)
-		if err != nil {
-			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while parsing db data: %w", err))
-			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+// Scan destinations of subscription row (some columns are converted after scan)
+type subscriptionRow struct {
+	sub       model.Subscription
+	startDate string
+	endDate   string
+	serviceId sql.NullInt64
+	metadata  []byte
+}
+
+// Columns of subscription table by fields of response
+var subscriptionFieldColumns = []struct {
+	field  string
+	column string
+	dest   func(row *subscriptionRow) any
+}{
+	{model.FieldID, "id", func(row *subscriptionRow) any { return &row.sub.ID }},
+	{model.FieldServiceName, "service_name", func(row *subscriptionRow) any { return &row.sub.ServiceName }},
+	{model.FieldPrice, "price", func(row *subscriptionRow) any { return &row.sub.Price }},
+	{model.FieldUserID, "user_id", func(row *subscriptionRow) any { return &row.sub.UserID }},
+	{model.FieldStartDate, "start_date", func(row *subscriptionRow) any { return &row.startDate }},
+	{model.FieldEndDate, "end_date", func(row *subscriptionRow) any { return &row.endDate }},
+	{model.FieldTrialMonths, "trial_months", func(row *subscriptionRow) any { return &row.sub.TrialMonths }},
+	{model.FieldTrialPrice, "trial_price", func(row *subscriptionRow) any { return &row.sub.TrialPrice }},
+	{model.FieldStatus, "status", func(row *subscriptionRow) any { return &row.sub.Status }},
+	{model.FieldServiceID, "service_id", func(row *subscriptionRow) any { return &row.serviceId }},
+	{model.FieldCategory, "category", func(row *subscriptionRow) any { return &row.sub.Category }},
+	{model.FieldMetadata, "metadata", func(row *subscriptionRow) any { return &row.metadata }},
+	{model.FieldAutoRenew, "auto_renew", func(row *subscriptionRow) any { return &row.sub.AutoRenew }},
+	{model.FieldBillingPeriod, "billing_period", func(row *subscriptionRow) any { return &row.sub.BillingPeriod }},
+	{model.FieldCreatedAt, "created_at", func(row *subscriptionRow) any { return &row.sub.CreatedAt }},
+	{model.FieldUpdatedAt, "updated_at", func(row *subscriptionRow) any { return &row.sub.UpdatedAt }},
+}
+
+// Check if column of field is selected: id is always needed to load details and updated_at for Last-Modified
+func columnSelected(field string, fields model.FieldSet) bool {
+	return field == model.FieldID || field == model.FieldUpdatedAt || fields.Has(field)
+}
+
+// Columns of subscription table for requested fields (nil means all fields)
+func selectColumns(fields model.FieldSet) string {
+	columns := make([]string, 0, len(subscriptionFieldColumns))
+	for _, c := range subscriptionFieldColumns {
+		if columnSelected(c.field, fields) {
+			columns = append(columns, c.column)
+		}
+	}
+
+	return strings.Join(columns, ", ")
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// Scanner of row with extra columns selected after subscriptionColumns
+type extraColumnsScanner struct {
+	row   rowScanner
+	extra []any
+}
+
+func (s extraColumnsScanner) Scan(dest ...any) error {
+	return s.row.Scan(append(dest, s.extra...)...)
+}
+
+// Scan subscription table row selected with selectColumns(fields), fields not selected are left empty
+func (s *SqliteStorage) scanSubscription(loggerMsg *string, op string, row rowScanner, fields model.FieldSet) (model.Subscription, error) {
+	var r subscriptionRow
+
+	dest := make([]any, 0, len(subscriptionFieldColumns))
+	for _, c := range subscriptionFieldColumns {
+		if columnSelected(c.field, fields) {
+			dest = append(dest, c.dest(&r))
 		}
+	}
+
+	err := row.Scan(dest...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Subscription{}, err
+	}
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while parsing db data: %w", err))
+		return model.Subscription{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	sub := r.sub
+	sub.ServiceID = r.serviceId.Int64
+
+	if r.metadata != nil {
+		sub.Metadata = json.RawMessage(r.metadata)
+	}
 
-		// Start date handling
-		start, err := model.DateFromStringISO(startDate)
+	// Start date handling
+	if fields.Has(model.FieldStartDate) {
+		sub.StartDate, err = model.DateFromStringISO(r.startDate)
 		if err != nil {
 			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while getting start date: %w", err))
-			return []model.Subscription{}, fmt.Errorf("%s: getting start date: %w", op, err)
+			return model.Subscription{}, fmt.Errorf("%s: getting start date: %w", op, err)
 		}
-		sub.StartDate = start
+	}
 
-		// End date handling
-		end, err := model.DateFromStringISO(endDate)
+	// End date handling
+	if fields.Has(model.FieldEndDate) {
+		sub.EndDate, err = model.DateFromStringISO(r.endDate)
 		if err != nil {
 			s.logger.Error(*loggerMsg, "details", fmt.Errorf("error while getting end date: %w", err))
-			return []model.Subscription{}, fmt.Errorf("%s: getting end date: %w", op, err)
+			return model.Subscription{}, fmt.Errorf("%s: getting end date: %w", op, err)
+		}
+	}
+
+	return sub, nil
+}
+
+func (s *SqliteStorage) getSubscriptionsFromSqliteRows(loggerMsg *string, op string, rows *sql.Rows, fields model.FieldSet) ([]model.Subscription, error) {
+	defer rows.Close()
+
+	var subscriptions []model.Subscription
+
+	for rows.Next() {
+		sub, err := s.scanSubscription(loggerMsg, op, rows, fields)
+		if err != nil {
+			return []model.Subscription{}, err
 		}
-		sub.EndDate = end
 
 		subscriptions = append(subscriptions, sub)
 	}
 
+	if err := rows.Err(); err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return []model.Subscription{}, fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+
 	return subscriptions, nil
 }
+
+// Add optional filter conditions to query with WHERE clause
+func appendFilter(query string, args []interface{}, filter model.SubscriptionFilter) (string, []interface{}) {
+	if filter.Status != nil {
+		query += " AND status = ?"
+		args = append(args, string(*filter.Status))
+	}
+
+	if filter.Category != nil {
+		query += " AND category = ?"
+		args = append(args, *filter.Category)
+	}
+
+	if filter.Tag != nil {
+		query += " AND " + tagCondition
+		args = append(args, *filter.Tag)
+	}
+
+	// Keys are sorted for the same query text on the same filter
+	keys := make([]string, 0, len(filter.Metadata))
+	for key := range filter.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := metadataPath(key)
+		query += " AND " + metadataCondition
+		args = append(args, path, path, filter.Metadata[key])
+	}
+
+	if filter.Expr != nil {
+		var cond string
+		cond, args = compileFilterExpr(filter.Expr, args)
+		query += " AND " + cond
+	}
+
+	return query, args
+}
+
+// Fill subscriptions details stored in child tables (price schedules, discounts, status history, members, tags and renewals);
+// only details listed in fields are loaded (nil means all)
+func (s *SqliteStorage) getSubscriptionsDetails(q querier, loggerMsg *string, op string, subscriptions []model.Subscription, fields model.FieldSet) error {
+	ids := make([]int64, 0, len(subscriptions))
+	for i := 0; i < len(subscriptions); i++ {
+		ids = append(ids, subscriptions[i].ID)
+	}
+
+	if fields.Has(model.FieldPriceSchedule) {
+		schedules, err := s.getPriceSchedules(q, loggerMsg, op, ids)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(subscriptions); i++ {
+			subscriptions[i].PriceSchedule = schedules[subscriptions[i].ID]
+		}
+	}
+
+	if fields.Has(model.FieldDiscounts) {
+		discounts, err := s.getDiscounts(q, loggerMsg, op, ids)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(subscriptions); i++ {
+			subscriptions[i].Discounts = discounts[subscriptions[i].ID]
+		}
+	}
+
+	if fields.Has(model.FieldStatusHistory) {
+		statusHistories, err := s.getStatusHistories(q, loggerMsg, op, ids)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(subscriptions); i++ {
+			subscriptions[i].StatusHistory = statusHistories[subscriptions[i].ID]
+		}
+	}
+
+	if fields.Has(model.FieldMembers) {
+		members, err := s.getMembers(q, loggerMsg, op, ids)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(subscriptions); i++ {
+			subscriptions[i].Members = members[subscriptions[i].ID]
+		}
+	}
+
+	if fields.Has(model.FieldTags) {
+		tags, err := s.getTags(q, loggerMsg, op, ids)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(subscriptions); i++ {
+			subscriptions[i].Tags = tags[subscriptions[i].ID]
+		}
+	}
+
+	if fields.Has(model.FieldRenewals) {
+		renewals, err := s.getRenewals(q, loggerMsg, op, ids)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(subscriptions); i++ {
+			subscriptions[i].Renewals = renewals[subscriptions[i].ID]
+		}
+	}
+
+	return nil
+}
+
+// Max number of ids in one "IN (...)" clause
+const inClauseChunkSize = 500
+
+// Statement runner: db or transaction
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// Move moment of last change of subscription whose details (stored in other tables) are changed
+func touchSubscription(db execer, id int64) error {
+	if _, err := db.Exec("UPDATE subscription SET updated_at = ? WHERE id = ?", time.Now().UTC(), id); err != nil {
+		return fmt.Errorf("update last change moment: %w", err)
+	}
+
+	return nil
+}
+
+// Query runner: db or transaction
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// Run query for ids by chunks; query must contain "%s" placeholder for "IN (...)" clause params
+func (s *SqliteStorage) queryByIds(q querier, query string, ids []int64, scan func(rows *sql.Rows) error) error {
+	for len(ids) > 0 {
+		chunk := ids[:min(len(ids), inClauseChunkSize)]
+		ids = ids[len(chunk):]
+
+		args := make([]interface{}, 0, len(chunk))
+		for _, id := range chunk {
+			args = append(args, id)
+		}
+
+		rows, err := q.Query(fmt.Sprintf(query, "?"+strings.Repeat(",?", len(chunk)-1)), args...)
+		if err != nil {
+			return err
+		}
+
+		for rows.Next() {
+			if err := scan(rows); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,12 +1,21 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"em_golang_rest_service_example/internal/model"
 	"em_golang_rest_service_example/internal/storage"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -16,40 +25,37 @@ import (
 func newTestDB(t *testing.T) *sql.DB {
 	t.Helper()
 
-	// 1.Open an in-memory database using a URI filename with mode=memory and cache=shared
-	db, err := sql.Open("sqlite3", "file::memory:?cache=shared")
+	// 1.Open an in-memory database (unique for every test) using a URI filename with mode=memory and cache=shared
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=memory&cache=shared&_foreign_keys=on", url.PathEscape(t.Name())))
 	if err != nil {
 		t.Fatalf("failed to open database: %v", err)
 	}
 
 	// 2.Migrations
-	createTableSQL := `
-		CREATE TABLE IF NOT EXISTS subscription(
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			service_name TEXT NOT NULL,
-			price INTEGER NOT NULL,
-			user_id TEXT NOT NULL,
-			start_date TEXT NOT NULL CHECK (
-				start_date GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]' AND
-				CAST(substr(start_date, 1, 4) AS INTEGER) BETWEEN 2000 AND 2100 AND
-				CAST(substr(start_date, 6, 2) AS INTEGER) BETWEEN 1 AND 12 AND
-				CAST(substr(start_date, 9, 2) AS INTEGER) BETWEEN 1 AND 31
-			),
-			end_date TEXT NOT NULL CHECK (
-				-- Check ISO date format YYYY-MM-DD
-				end_date GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]' AND
-				CAST(substr(end_date, 1, 4) AS INTEGER) BETWEEN 2000 AND 2100 AND
-				CAST(substr(end_date, 6, 2) AS INTEGER) BETWEEN 1 AND 12 AND
-				CAST(substr(end_date, 9, 2) AS INTEGER) BETWEEN 1 AND 31
-			),
-			CONSTRAINT unique_subscription UNIQUE (service_name, user_id),
-			CONSTRAINT check_end_after_start CHECK (end_date > start_date)
-		);
-	`
-	_, err = db.Exec(createTableSQL)
-	if err != nil {
+	migrations, err := filepath.Glob("./migrations/*.up.sql")
+	if err != nil || len(migrations) == 0 {
 		db.Close()
-		t.Fatalf("failed to create table: %v", err)
+		t.Fatalf("failed to find migrations: %v", err)
+	}
+	sort.Strings(migrations)
+
+	for _, mg := range migrations {
+		data, err := os.ReadFile(mg)
+		if err != nil {
+			db.Close()
+			t.Fatalf("failed to read migration %s: %v", mg, err)
+		}
+
+		_, err = db.Exec(string(data))
+		if err != nil && strings.Contains(err.Error(), "no such module: fts5") {
+			// Driver is built without sqlite_fts5 tag, search index is not created (search tests are skipped)
+			t.Logf("migration %s is not applied: %v", mg, err)
+			continue
+		}
+		if err != nil {
+			db.Close()
+			t.Fatalf("failed to apply migration %s: %v", mg, err)
+		}
 	}
 
 	return db
@@ -70,7 +76,7 @@ func TestCreateSubscription(t *testing.T) {
 	cases := []struct {
 		name           string
 		serviceName    string
-		price          int
+		price          model.Money
 		userId         uuid.UUID
 		startDate      model.Date
 		endDate        model.Date
@@ -195,7 +201,7 @@ func TestUpdateSubscription(t *testing.T) {
 
 	// 2.Update some non-existen values
 	t.Run("Update non-existen", func(t *testing.T) {
-		err := sqliteStorage.UpdateSubscription(532, "Any non-existen", 350, model.Date{Month: 1, Year: 1990}, model.Date{Month: 1, Year: 1991})
+		err := sqliteStorage.UpdateSubscription(532, replaceUpdate("Any non-existen", 350, model.Date{Month: 1, Year: 1990}, model.Date{Month: 1, Year: 1991}))
 		assert.ErrorContains(t, err, storage.ErrSubscribtionNotFound.Error())
 	})
 
@@ -210,13 +216,13 @@ func TestUpdateSubscription(t *testing.T) {
 		}
 		id, _ := sqliteStorage.CreateSubscription(spec)
 
-		err := sqliteStorage.UpdateSubscription(id, "Яндекс", 350, spec.StartDate, model.Date{Month: 1, Year: 2027})
+		err := sqliteStorage.UpdateSubscription(id, replaceUpdate("Яндекс", 350, spec.StartDate, model.Date{Month: 1, Year: 2027}))
 		assert.NoError(t, err)
 
 		subscription, _ := sqliteStorage.GetSubscription(id)
 		assert.Equal(t, id, subscription.ID)
 		assert.Equal(t, "Яндекс", subscription.ServiceName)
-		assert.Equal(t, 350, subscription.Price)
+		assert.Equal(t, model.Money(350), subscription.Price)
 		assert.Equal(t, spec.UserID, subscription.UserID)
 		assert.Equal(t, spec.StartDate, subscription.StartDate)
 		assert.Equal(t, subscription.EndDate, model.Date{Month: 1, Year: 2027})
@@ -229,20 +235,92 @@ func TestUpdateSubscription(t *testing.T) {
 			Price:       400,
 			UserID:      uuid.New(),
 			StartDate:   model.Date{Month: 1, Year: 2026},
-			EndDate:     model.Date{Month: 2, Year: 2026},
+			EndDate:     model.Date{Month: 6, Year: 2026},
 		}
 		id, _ := sqliteStorage.CreateSubscription(spec)
 
-		err := sqliteStorage.UpdateSubscription(id, spec.ServiceName, 300, spec.StartDate, model.Date{})
+		err := sqliteStorage.UpdateSubscription(id, replaceUpdate(spec.ServiceName, 300, spec.StartDate, model.Date{}))
 		assert.NoError(t, err)
 
 		subscription, _ := sqliteStorage.GetSubscription(id)
 		assert.Equal(t, id, subscription.ID)
 		assert.Equal(t, spec.ServiceName, subscription.ServiceName)
-		assert.Equal(t, 300, subscription.Price)
+		assert.Equal(t, model.Money(300), subscription.Price)
 		assert.Equal(t, spec.UserID, subscription.UserID)
 		assert.Equal(t, spec.StartDate, subscription.StartDate)
-		assert.Equal(t, spec.EndDate, subscription.EndDate)
+		assert.Equal(t, model.Date{Month: 2, Year: 2026}, subscription.EndDate)
+	})
+
+	// 3.3.Partial update keeps other fields
+	t.Run("Update existen OK partial", func(t *testing.T) {
+		spec := model.SubscriptionSpec{
+			ServiceName: "Kion",
+			Price:       400,
+			UserID:      uuid.New(),
+			StartDate:   model.Date{Month: 1, Year: 2026},
+			EndDate:     model.Date{Month: 6, Year: 2026},
+		}
+		id, _ := sqliteStorage.CreateSubscription(spec)
+
+		newEnd := model.Date{Month: 9, Year: 2026}
+		err := sqliteStorage.UpdateSubscription(id, model.SubscriptionUpdate{EndDate: &newEnd})
+		assert.NoError(t, err)
+
+		subscription, _ := sqliteStorage.GetSubscription(id)
+		assert.Equal(t, spec.ServiceName, subscription.ServiceName)
+		assert.Equal(t, spec.Price, subscription.Price)
+		assert.Equal(t, spec.StartDate, subscription.StartDate)
+		assert.Equal(t, newEnd, subscription.EndDate)
+	})
+
+	// 3.4.Replace trial, members, labels and renewal settings, cleared category is taken from catalog
+	t.Run("Update existen OK replace all", func(t *testing.T) {
+		_, err := sqliteStorage.CreateService(model.Service{Name: "Wink", DefaultPrice: 300, Category: "video"})
+		assert.NoError(t, err)
+
+		ownerId, memberId := uuid.New(), uuid.New()
+		spec := model.SubscriptionSpec{
+			ServiceName: "Wink",
+			Price:       400,
+			UserID:      ownerId,
+			StartDate:   model.Date{Month: 1, Year: 2026},
+			EndDate:     model.Date{Month: 6, Year: 2026},
+			TrialMonths: 1,
+			Members:     []model.Member{{UserID: ownerId, Weight: 1}, {UserID: memberId, Weight: 1}},
+			Category:    "movies",
+			Tags:        []string{"family"},
+		}
+		id, err := sqliteStorage.CreateSubscription(spec)
+		assert.NoError(t, err)
+
+		update := replaceUpdate(spec.ServiceName, 500, spec.StartDate, model.Date{})
+		trialMonths, trialPrice, category, autoRenew, billingPeriod := 0, model.Money(0), "", true, 3
+		var members []model.Member
+		tags := []string{"work"}
+		update.TrialMonths, update.TrialPrice, update.Members, update.Category = &trialMonths, &trialPrice, &members, &category
+		update.Tags, update.AutoRenew, update.BillingPeriod = &tags, &autoRenew, &billingPeriod
+
+		assert.NoError(t, sqliteStorage.UpdateSubscription(id, update))
+
+		subscription, err := sqliteStorage.GetSubscription(id)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, subscription.TrialMonths)
+		assert.Empty(t, subscription.Members)
+		assert.Equal(t, "video", subscription.Category)
+		assert.Equal(t, []string{"work"}, subscription.Tags)
+		assert.True(t, subscription.AutoRenew)
+		assert.Equal(t, 3, subscription.BillingPeriod)
+		assert.Equal(t, model.Date{Month: 4, Year: 2026}, subscription.EndDate)
+
+		// Partial update keeps category even if it is empty
+		assert.NoError(t, sqliteStorage.SetTags(id, "", tags))
+
+		price := model.Money(600)
+		assert.NoError(t, sqliteStorage.UpdateSubscription(id, model.SubscriptionUpdate{Price: &price}))
+
+		subscription, err = sqliteStorage.GetSubscription(id)
+		assert.NoError(t, err)
+		assert.Equal(t, "", subscription.Category)
 	})
 
 	// 4.Update existen FAIL
@@ -256,11 +334,22 @@ func TestUpdateSubscription(t *testing.T) {
 		}
 		id, _ := sqliteStorage.CreateSubscription(spec)
 
-		err := sqliteStorage.UpdateSubscription(id, spec.ServiceName, 500, spec.StartDate, model.Date{Month: 12, Year: 2025})
-		assert.ErrorContains(t, err, "constraint")
+		err := sqliteStorage.UpdateSubscription(id, replaceUpdate(spec.ServiceName, 500, spec.StartDate, model.Date{Month: 12, Year: 2025}))
+
+		var validationErr *model.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+
+		subscription, _ := sqliteStorage.GetSubscription(id)
+		assert.Equal(t, spec.Price, subscription.Price)
+		assert.Equal(t, spec.EndDate, subscription.EndDate)
 	})
 }
 
+// Update of service name, price and dates
+func replaceUpdate(serviceName string, price model.Money, start, end model.Date) model.SubscriptionUpdate {
+	return model.SubscriptionUpdate{ServiceName: &serviceName, Price: &price, StartDate: &start, EndDate: &end}
+}
+
 func TestDeleteSubscription(t *testing.T) {
 	db := newTestDB(t)
 	defer db.Close()
@@ -303,7 +392,7 @@ func TestGetSubscriptions(t *testing.T) {
 	services := []string{
 		"Yandex", "Google", "Netflix", "Wink",
 	}
-	prices := []int{
+	prices := []model.Money{
 		400, 800, 700, 300,
 	}
 
@@ -348,7 +437,7 @@ func TestGetSubscriptions(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			subs, err := sqliteStorage.GetSubscriptions(tc.limit, tc.offset)
+			subs, err := sqliteStorage.GetSubscriptions(tc.limit, tc.offset, model.SubscriptionFilter{}, nil)
 
 			if tc.errMsg == "" {
 				assert.NoError(t, err)
@@ -382,7 +471,7 @@ func TestFilterSubscriptions(t *testing.T) {
 	services := []string{
 		"Yandex", "Google", "Netflix", "Wink", "VK Music", "Amediateka",
 	}
-	prices := []int{
+	prices := []model.Money{
 		400, 800, 700, 300, 150, 600,
 	}
 	users := []uuid.UUID{
@@ -463,9 +552,9 @@ func TestFilterSubscriptions(t *testing.T) {
 			var err error
 
 			if tc.sName == "" {
-				subs, err = sqliteStorage.FilterSubscriptions(tc.start, tc.end, tc.uid, nil)
+				subs, err = sqliteStorage.FilterSubscriptions(tc.start, tc.end, tc.uid, nil, model.SubscriptionFilter{})
 			} else {
-				subs, err = sqliteStorage.FilterSubscriptions(tc.start, tc.end, tc.uid, &tc.sName)
+				subs, err = sqliteStorage.FilterSubscriptions(tc.start, tc.end, tc.uid, &tc.sName, model.SubscriptionFilter{})
 			}
 
 			assert.NoError(t, err)
@@ -474,8 +563,1246 @@ func TestFilterSubscriptions(t *testing.T) {
 	}
 }
 
+func TestAddPriceChange(t *testing.T) {
+	// 1.Init
+	db := newTestDB(t)
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	st := newStorage(db, logger)
+
+	spec := model.SubscriptionSpec{
+		ServiceName: "Yandex",
+		Price:       40000,
+		UserID:      uuid.New(),
+		StartDate:   model.Date{Month: 1, Year: 2026},
+		EndDate:     model.Date{Month: 1, Year: 2027},
+	}
+	id, err := st.CreateSubscription(spec)
+	assert.NoError(t, err)
+
+	// 2.Add price changes (not in effective date order)
+	later := model.PriceChange{Price: 60000, EffectiveFrom: model.Date{Month: 9, Year: 2026}}
+	earlier := model.PriceChange{Price: 50000, EffectiveFrom: model.Date{Month: 4, Year: 2026}}
+
+	laterId, err := st.AddPriceChange(id, later)
+	assert.NoError(t, err)
+
+	earlierId, err := st.AddPriceChange(id, earlier)
+	assert.NoError(t, err)
+
+	// 3.Duplicate effective date
+	_, err = st.AddPriceChange(id, earlier)
+	assert.ErrorIs(t, err, storage.ErrPriceChangeExists)
+
+	// 4.Non-existen subscription
+	_, err = st.AddPriceChange(-532, earlier)
+	assert.ErrorIs(t, err, storage.ErrSubscribtionNotFound)
+
+	// 5.Schedule is ordered by effective date on read
+	subscription, err := st.GetSubscription(id)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.PriceChange{
+		{ID: earlierId, Price: earlier.Price, EffectiveFrom: earlier.EffectiveFrom},
+		{ID: laterId, Price: later.Price, EffectiveFrom: later.EffectiveFrom},
+	}, subscription.PriceSchedule)
+
+	// 6.Schedule is returned with filtered subscriptions too
+	filtered, err := st.FilterSubscriptions(model.Date{Month: 12, Year: 2025}, model.Date{Month: 2, Year: 2027}, uuid.Nil, nil, model.SubscriptionFilter{})
+	assert.NoError(t, err)
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, subscription.PriceSchedule, filtered[0].PriceSchedule)
+
+	// 7.Schedule is deleted with subscription
+	assert.NoError(t, st.DeleteSubscription(id))
+	_, err = st.AddPriceChange(id, later)
+	assert.ErrorIs(t, err, storage.ErrSubscribtionNotFound)
+}
+
+func TestTrialAndDiscounts(t *testing.T) {
+	// 1.Init
+	db := newTestDB(t)
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	st := newStorage(db, logger)
+
+	spec := model.SubscriptionSpec{
+		ServiceName:   "Okko",
+		Price:         39900,
+		UserID:        uuid.New(),
+		StartDate:     model.Date{Month: 1, Year: 2026},
+		EndDate:       model.Date{Month: 1, Year: 2027},
+		TrialMonths:   3,
+		TrialPrice:    100,
+		BillingPeriod: 1,
+	}
+	id, err := st.CreateSubscription(spec)
+	assert.NoError(t, err)
+
+	// 2.Add discounts
+	validFrom := model.Date{Month: 4, Year: 2026}
+	validTo := model.Date{Month: 7, Year: 2026}
+
+	percent := model.Discount{Kind: model.DiscountPercent, Percent: 50, ValidFrom: &validFrom, ValidTo: &validTo}
+	fixed := model.Discount{Kind: model.DiscountFixed, Amount: 5000}
+
+	percent.ID, err = st.AddDiscount(id, percent)
+	assert.NoError(t, err)
+
+	fixed.ID, err = st.AddDiscount(id, fixed)
+	assert.NoError(t, err)
+
+	_, err = st.AddDiscount(-532, fixed)
+	assert.ErrorIs(t, err, storage.ErrSubscribtionNotFound)
+
+	// 3.Check trial and discounts on read
+	subscription, err := st.GetSubscription(id)
+	assert.NoError(t, err)
+	assert.Equal(t, spec, subscription.SubscriptionSpec)
+	assert.Equal(t, []model.Discount{percent, fixed}, subscription.Discounts)
+
+	// 4.Check discounts are returned with filtered subscriptions
+	filtered, err := st.FilterSubscriptions(model.Date{Month: 12, Year: 2025}, model.Date{Month: 2, Year: 2027}, uuid.Nil, nil, model.SubscriptionFilter{})
+	assert.NoError(t, err)
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, subscription.Discounts, filtered[0].Discounts)
+}
+
+func TestChangeStatus(t *testing.T) {
+	// 1.Init
+	db := newTestDB(t)
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	st := newStorage(db, logger)
+
+	spec := model.SubscriptionSpec{
+		ServiceName: "Ivi",
+		Price:       50000,
+		UserID:      uuid.New(),
+		StartDate:   model.Date{Month: 1, Year: 2026},
+		EndDate:     model.Date{Month: 1, Year: 2027},
+	}
+	id, err := st.CreateSubscription(spec)
+	assert.NoError(t, err)
+
+	ended := spec
+	ended.ServiceName = "Wink"
+	ended.EndDate = model.Date{Month: 3, Year: 2026}
+	endedId, err := st.CreateSubscription(ended)
+	assert.NoError(t, err)
+
+	// 2.Pause and resume
+	pause := model.StatusTransition{From: model.StatusActive, To: model.StatusPaused, EffectiveFrom: model.Date{Month: 3, Year: 2026}}
+	pause.ID, err = st.ChangeStatus(id, pause, nil)
+	assert.NoError(t, err)
+
+	_, err = st.ChangeStatus(id, pause, nil)
+	assert.ErrorIs(t, err, storage.ErrStatusChanged)
+
+	_, err = st.ChangeStatus(-532, pause, nil)
+	assert.ErrorIs(t, err, storage.ErrSubscribtionNotFound)
+
+	paused := model.StatusPaused
+	filtered, err := st.GetSubscriptions(nil, nil, model.SubscriptionFilter{Status: &paused}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, id, filtered[0].ID)
+
+	resume := model.StatusTransition{From: model.StatusPaused, To: model.StatusActive, EffectiveFrom: model.Date{Month: 5, Year: 2026}}
+	resume.ID, err = st.ChangeStatus(id, resume, nil)
+	assert.NoError(t, err)
+
+	// 3.Cancel immediately with end date change
+	newEnd := model.Date{Month: 9, Year: 2026}
+	cancel := model.StatusTransition{From: model.StatusActive, To: model.StatusCancelled, EffectiveFrom: newEnd}
+	cancel.ID, err = st.ChangeStatus(id, cancel, &newEnd)
+	assert.NoError(t, err)
+
+	subscription, err := st.GetSubscription(id)
+	assert.NoError(t, err)
+	assert.Equal(t, model.StatusCancelled, subscription.Status)
+	assert.Equal(t, newEnd, subscription.EndDate)
+	assert.Len(t, subscription.StatusHistory, 3)
+	for i, expected := range []model.StatusTransition{pause, resume, cancel} {
+		assert.False(t, subscription.StatusHistory[i].ChangedAt.IsZero())
+		subscription.StatusHistory[i].ChangedAt = expected.ChangedAt
+		assert.Equal(t, expected, subscription.StatusHistory[i])
+	}
+
+	cost, err := subscription.Cost()
+	assert.NoError(t, err)
+	assert.Equal(t, model.Money(50000*6), cost)
+
+	// 4.Expire ended subscriptions
+	expired, err := st.ExpireSubscriptions(model.Date{Month: 3, Year: 2026})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), expired)
+
+	subscription, err = st.GetSubscription(endedId)
+	assert.NoError(t, err)
+	assert.Equal(t, model.StatusExpired, subscription.Status)
+	assert.Len(t, subscription.StatusHistory, 1)
+	assert.Equal(t, ended.EndDate, subscription.StatusHistory[0].EffectiveFrom)
+
+	expired, err = st.ExpireSubscriptions(model.Date{Month: 3, Year: 2026})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), expired)
+
+	// 5.Unknown status is rejected by database
+	unknown := model.StatusTransition{From: model.StatusExpired, To: model.Status("trash"), EffectiveFrom: model.Date{Month: 4, Year: 2026}}
+	_, err = st.ChangeStatus(endedId, unknown, nil)
+	assert.Error(t, err)
+
+	subscription, err = st.GetSubscription(endedId)
+	assert.NoError(t, err)
+	assert.Equal(t, model.StatusExpired, subscription.Status)
+}
+
+func TestServiceCatalog(t *testing.T) {
+	// 1.Init
+	db := newTestDB(t)
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	st := newStorage(db, logger)
+
+	// 2.Legacy subscriptions not linked to catalog
+	userId := uuid.New()
+	legacy := "INSERT INTO subscription (service_name,price,user_id,start_date,end_date) VALUES (?,?,?,?,?)"
+	for _, name := range []string{"yandex plus", "Okko", "YANDEX-PLUS"} {
+		_, err := db.Exec(legacy, name, 39900, userId.String(), "2026-01-01", "2026-02-01")
+		assert.NoError(t, err)
+	}
+
+	// 3.Create service with aliases
+	service := model.Service{Name: "Yandex Plus", Aliases: []string{"Яндекс Плюс"}, DefaultPrice: 39900, Category: "music"}
+	var err error
+	service.ID, err = st.CreateService(service)
+	assert.NoError(t, err)
+
+	_, err = st.CreateService(model.Service{Name: "Other", Aliases: []string{"яндекс-плюс"}})
+	assert.ErrorIs(t, err, storage.ErrServiceExists)
+
+	resolved, err := st.ResolveService("ЯНДЕКС ПЛЮС")
+	assert.NoError(t, err)
+	assert.Equal(t, service, resolved)
+
+	_, err = st.ResolveService("Netflix")
+	assert.ErrorIs(t, err, storage.ErrServiceNotFound)
+
+	// 4.Normalize legacy names (third one duplicates first one for the same user)
+	report, err := st.NormalizeServiceNames()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), report.Linked)
+	assert.Equal(t, int64(1), report.Registered)
+	assert.Len(t, report.Conflicts, 1)
+
+	services, err := st.GetServices()
+	assert.NoError(t, err)
+	assert.Len(t, services, 2)
+
+	// 5.Subscriptions get canonical name and are filtered by alias
+	id, err := st.CreateSubscription(model.SubscriptionSpec{
+		ServiceName: "яндекс плюс",
+		Price:       29900,
+		UserID:      uuid.New(),
+		StartDate:   model.Date{Month: 1, Year: 2026},
+		EndDate:     model.Date{Month: 2, Year: 2026},
+	})
+	assert.NoError(t, err)
+
+	subscription, err := st.GetSubscription(id)
+	assert.NoError(t, err)
+	assert.Equal(t, service.Name, subscription.ServiceName)
+	assert.Equal(t, service.ID, subscription.ServiceID)
+
+	alias := "Яндекс Плюс"
+	filtered, err := st.FilterSubscriptions(model.Date{Month: 12, Year: 2025}, model.Date{Month: 3, Year: 2026}, userId, &alias, model.SubscriptionFilter{})
+	assert.NoError(t, err)
+	assert.Len(t, filtered, 1)
+
+	// 6.Rename service and try to delete it
+	service.Name = "Yandex Plus Family"
+	service.Aliases = []string{}
+	assert.NoError(t, st.UpdateService(service.ID, service))
+
+	subscription, err = st.GetSubscription(id)
+	assert.NoError(t, err)
+	assert.Equal(t, service.Name, subscription.ServiceName)
+
+	assert.ErrorIs(t, st.DeleteService(service.ID), storage.ErrServiceInUse)
+	assert.ErrorIs(t, st.DeleteService(-532), storage.ErrServiceNotFound)
+}
+
+func TestUserSubscriptions(t *testing.T) {
+	// 1.Init
+	db := newTestDB(t)
+	defer db.Close()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	st := newStorage(db, logger)
+
+	userId := uuid.New()
+	otherUserId := uuid.New()
+
+	var ids []int64
+	for _, name := range []string{"Okko", "Ivi", "Wink"} {
+		id, err := st.CreateSubscription(model.SubscriptionSpec{
+			ServiceName: name,
+			Price:       10000,
+			UserID:      userId,
+			StartDate:   model.Date{Month: 1, Year: 2026},
+			EndDate:     model.Date{Month: 6, Year: 2026},
+		})
+		assert.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	otherId, err := st.CreateSubscription(model.SubscriptionSpec{
+		ServiceName: "Okko",
+		Price:       10000,
+		UserID:      otherUserId,
+		StartDate:   model.Date{Month: 1, Year: 2026},
+		EndDate:     model.Date{Month: 6, Year: 2026},
+	})
+	assert.NoError(t, err)
+
+	// 2.Get pages of user subscriptions
+	all, err := st.GetUserSubscriptions(userId, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	page, err := st.GetUserSubscriptions(userId, intPointerHelper(2), intPointerHelper(1))
+	assert.NoError(t, err)
+	assert.Len(t, page, 2)
+	assert.Equal(t, ids[1], page[0].ID)
+	assert.Equal(t, ids[2], page[1].ID)
+
+	_, err = st.GetUserSubscriptions(userId, intPointerHelper(2), nil)
+	assert.Error(t, err)
+
+	// 3.Delete only user subscriptions
+	deleted, err := st.DeleteUserSubscriptions(userId)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+
+	all, err = st.GetUserSubscriptions(userId, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, all, 0)
+
+	_, err = st.GetSubscription(otherId)
+	assert.NoError(t, err)
+
+	deleted, err = st.DeleteUserSubscriptions(userId)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), deleted)
+}
+
+func TestSharedSubscription(t *testing.T) {
+	// 1.Init
+	db := newTestDB(t)
+	defer db.Close()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	st := newStorage(db, logger)
+
+	owner, member := uuid.New(), uuid.New()
+
+	spec := model.SubscriptionSpec{
+		ServiceName: "YouTube Family",
+		Price:       79900,
+		UserID:      owner,
+		StartDate:   model.Date{Month: 1, Year: 2026},
+		EndDate:     model.Date{Month: 2, Year: 2026},
+		Members:     []model.Member{{UserID: owner, Weight: 1}, {UserID: member, Weight: 1}},
+	}
+	id, err := st.CreateSubscription(spec)
+	assert.NoError(t, err)
+
+	subscription, err := st.GetSubscription(id)
+	assert.NoError(t, err)
+	assert.Equal(t, spec.Members, subscription.Members)
+
+	// 2.Shared subscription is found for every member
+	for _, userId := range []uuid.UUID{owner, member} {
+		filtered, err := st.FilterSubscriptions(model.Date{Month: 12, Year: 2025}, model.Date{Month: 3, Year: 2026}, userId, nil, model.SubscriptionFilter{})
+		assert.NoError(t, err)
+		assert.Len(t, filtered, 1)
+		assert.Equal(t, spec.Members, filtered[0].Members)
+
+		userSubscriptions, err := st.GetUserSubscriptions(userId, nil, nil)
+		assert.NoError(t, err)
+		assert.Len(t, userSubscriptions, 1)
+	}
+
+	// 3.Replace members
+	newMembers := []model.Member{{UserID: member, Weight: 3}}
+	assert.NoError(t, st.SetMembers(id, newMembers))
+	assert.ErrorIs(t, st.SetMembers(-532, newMembers), storage.ErrSubscribtionNotFound)
+
+	subscription, err = st.GetSubscription(id)
+	assert.NoError(t, err)
+	assert.Equal(t, newMembers, subscription.Members)
+
+	// 4.Member leaves subscription when deletes own subscriptions
+	deleted, err := st.DeleteUserSubscriptions(member)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), deleted)
+
+	subscription, err = st.GetSubscription(id)
+	assert.NoError(t, err)
+	assert.Empty(t, subscription.Members)
+
+	// Charges of subscription left are billed to owner again
+	charges, err := st.GetCharges([]int64{id}, nil)
+	assert.NoError(t, err)
+
+	for userId, expected := range map[uuid.UUID]model.Money{owner: 79900, member: 0} {
+		sum, err := model.SumCharges(charges[id], userId)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, sum)
+	}
+}
+
+func TestSubscriptionTags(t *testing.T) {
+	// 1.Init
+	db := newTestDB(t)
+	defer db.Close()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	st := newStorage(db, logger)
+
+	// Category is taken from catalog service if not set
+	_, err := st.CreateService(model.Service{Name: "Yandex Cloud", DefaultPrice: 1000, Category: " Cloud"})
+	assert.NoError(t, err)
+
+	userId := uuid.New()
+	from, to := model.Date{Month: 12, Year: 2025}, model.Date{Month: 3, Year: 2026}
+
+	streaming := model.SubscriptionSpec{
+		ServiceName: "Kion",
+		Price:       200,
+		UserID:      userId,
+		StartDate:   model.Date{Month: 1, Year: 2026},
+		EndDate:     model.Date{Month: 2, Year: 2026},
+		Category:    "streaming",
+		Tags:        []string{"family", "work"},
+	}
+	streamingId, err := st.CreateSubscription(streaming)
+	assert.NoError(t, err)
+
+	cloud := model.SubscriptionSpec{
+		ServiceName: "yandex-cloud",
+		Price:       1000,
+		UserID:      userId,
+		StartDate:   model.Date{Month: 1, Year: 2026},
+		EndDate:     model.Date{Month: 2, Year: 2026},
+		Tags:        []string{"work"},
+	}
+	cloudId, err := st.CreateSubscription(cloud)
+	assert.NoError(t, err)
+
+	subscription, err := st.GetSubscription(streamingId)
+	assert.NoError(t, err)
+	assert.Equal(t, "streaming", subscription.Category)
+	assert.Equal(t, streaming.Tags, subscription.Tags)
+
+	subscription, err = st.GetSubscription(cloudId)
+	assert.NoError(t, err)
+	assert.Equal(t, "cloud", subscription.Category)
+
+	// 2.Filters
+	category, tag := "cloud", "work"
+
+	listed, err := st.GetSubscriptions(nil, nil, model.SubscriptionFilter{Category: &category}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, listed, 1)
+	assert.Equal(t, cloudId, listed[0].ID)
+	assert.Equal(t, cloud.Tags, listed[0].Tags)
+
+	filtered, err := st.FilterSubscriptions(from, to, userId, nil, model.SubscriptionFilter{Tag: &tag})
+	assert.NoError(t, err)
+	assert.Len(t, filtered, 2)
+
+	tag = "family"
+	filtered, err = st.FilterSubscriptions(from, to, uuid.Nil, nil, model.SubscriptionFilter{Tag: &tag})
+	assert.NoError(t, err)
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, streamingId, filtered[0].ID)
+
+	// 3.Replace category and tags
+	assert.NoError(t, st.SetTags(streamingId, "", []string{"kids"}))
+	assert.ErrorIs(t, st.SetTags(-532, "", nil), storage.ErrSubscribtionNotFound)
+
+	subscription, err = st.GetSubscription(streamingId)
+	assert.NoError(t, err)
+	assert.Equal(t, "", subscription.Category)
+	assert.Equal(t, []string{"kids"}, subscription.Tags)
+
+	filtered, err = st.FilterSubscriptions(from, to, uuid.Nil, nil, model.SubscriptionFilter{Tag: &tag})
+	assert.NoError(t, err)
+	assert.Empty(t, filtered)
+}
+
+func TestSubscriptionMetadata(t *testing.T) {
+	// 1.Init
+	db := newTestDB(t)
+	defer db.Close()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	st := newStorage(db, logger)
+
+	userId := uuid.New()
+
+	spec := model.SubscriptionSpec{
+		ServiceName: "AWS",
+		Price:       100000,
+		UserID:      userId,
+		StartDate:   model.Date{Month: 1, Year: 2026},
+		EndDate:     model.Date{Month: 2, Year: 2026},
+		Metadata:    json.RawMessage(`{"cost_center":"R&D","seats":5,"billable":true,"contract":{"id":"C-1"}}`),
+	}
+	id, err := st.CreateSubscription(spec)
+	assert.NoError(t, err)
+
+	plain := spec
+	plain.ServiceName = "Okko"
+	plain.Metadata = nil
+	plainId, err := st.CreateSubscription(plain)
+	assert.NoError(t, err)
+
+	subscription, err := st.GetSubscription(id)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(spec.Metadata), string(subscription.Metadata))
+
+	subscription, err = st.GetSubscription(plainId)
+	assert.NoError(t, err)
+	assert.Nil(t, subscription.Metadata)
+
+	// 2.Filters by string, number and boolean values
+	for _, metadata := range []map[string]string{
+		{"cost_center": "R&D"},
+		{"seats": "5", "billable": "true"},
+	} {
+		listed, err := st.GetSubscriptions(nil, nil, model.SubscriptionFilter{Metadata: metadata}, nil)
+		assert.NoError(t, err)
+		assert.Len(t, listed, 1)
+		assert.Equal(t, id, listed[0].ID)
+	}
+
+	for _, metadata := range []map[string]string{
+		{"cost_center": "Sales"},
+		{"billable": "1"},
+		{"missing": "R&D"},
+	} {
+		listed, err := st.GetSubscriptions(nil, nil, model.SubscriptionFilter{Metadata: metadata}, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, listed)
+	}
+
+	// 3.Metadata is kept if not set on update, merged with patch and replaced otherwise
+	assert.NoError(t, st.UpdateSubscription(id, model.SubscriptionUpdate{}))
+
+	subscription, err = st.GetSubscription(id)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(spec.Metadata), string(subscription.Metadata))
+
+	assert.NoError(t, st.UpdateSubscription(id, model.SubscriptionUpdate{MetadataPatch: json.RawMessage(`{"billable":null,"team":"core"}`)}))
+
+	subscription, err = st.GetSubscription(id)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"cost_center":"R&D","seats":5,"contract":{"id":"C-1"},"team":"core"}`, string(subscription.Metadata))
+
+	replaced := json.RawMessage(`{"team":"core"}`)
+	assert.NoError(t, st.UpdateSubscription(id, model.SubscriptionUpdate{Metadata: &replaced}))
+
+	subscription, err = st.GetSubscription(id)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"team":"core"}`, string(subscription.Metadata))
+}
+
+func TestRenewSubscriptions(t *testing.T) {
+	// 1.Init
+	db := newTestDB(t)
+	defer db.Close()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	st := newStorage(db, logger)
+
+	renewing := model.SubscriptionSpec{
+		ServiceName:   "Spotify",
+		Price:         300,
+		UserID:        uuid.New(),
+		StartDate:     model.Date{Month: 11, Year: 2025},
+		EndDate:       model.Date{Month: 1, Year: 2026},
+		AutoRenew:     true,
+		BillingPeriod: 2,
+	}
+	renewingId, err := st.CreateSubscription(renewing)
+	assert.NoError(t, err)
+
+	_, err = st.AddPriceChange(renewingId, model.PriceChange{Price: 400, EffectiveFrom: model.Date{Month: 3, Year: 2026}})
+	assert.NoError(t, err)
+
+	ending := renewing
+	ending.ServiceName = "Deezer"
+	ending.AutoRenew = false
+	endingId, err := st.CreateSubscription(ending)
+	assert.NoError(t, err)
+
+	// 2.Renewing subscription is extended instead of expiration
+	month := model.Date{Month: 4, Year: 2026}
+
+	expired, err := st.ExpireSubscriptions(month)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), expired)
+
+	renewed, err := st.RenewSubscriptions(month)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), renewed)
+
+	subscription, err := st.GetSubscription(renewingId)
+	assert.NoError(t, err)
+	assert.Equal(t, model.StatusActive, subscription.Status)
+	assert.Equal(t, model.Date{Month: 5, Year: 2026}, subscription.EndDate)
+	assert.Len(t, subscription.Renewals, 2)
+	assert.Equal(t, model.Date{Month: 1, Year: 2026}, subscription.Renewals[0].PeriodStart)
+	assert.Equal(t, model.Money(300), subscription.Renewals[0].Price)
+	assert.Equal(t, model.Date{Month: 3, Year: 2026}, subscription.Renewals[1].PeriodStart)
+	assert.Equal(t, model.Date{Month: 5, Year: 2026}, subscription.Renewals[1].PeriodEnd)
+	assert.Equal(t, model.Money(400), subscription.Renewals[1].Price)
+
+	subscription, err = st.GetSubscription(endingId)
+	assert.NoError(t, err)
+	assert.Equal(t, model.StatusExpired, subscription.Status)
+	assert.Empty(t, subscription.Renewals)
+
+	// 3.Repeated run does nothing
+	renewed, err = st.RenewSubscriptions(month)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), renewed)
+
+	// 4.Auto-renewal is turned off
+	assert.NoError(t, st.SetAutoRenew(renewingId, false, 1))
+	assert.ErrorIs(t, st.SetAutoRenew(-532, false, 1), storage.ErrSubscribtionNotFound)
+
+	renewed, err = st.RenewSubscriptions(model.Date{Month: 12, Year: 2026})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), renewed)
+
+	subscription, err = st.GetSubscription(renewingId)
+	assert.NoError(t, err)
+	assert.False(t, subscription.AutoRenew)
+	assert.Equal(t, 1, subscription.BillingPeriod)
+}
+
+func TestChargesLedger(t *testing.T) {
+	// 1.Init
+	db := newTestDB(t)
+	defer db.Close()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	st := newStorage(db, logger)
+
+	ownerId, memberId := uuid.New(), uuid.New()
+
+	spec := model.SubscriptionSpec{
+		ServiceName:   "Ledger Music",
+		Price:         400,
+		UserID:        ownerId,
+		StartDate:     model.Date{Month: 1, Year: 2026},
+		EndDate:       model.Date{Month: 4, Year: 2026},
+		BillingPeriod: 1,
+	}
+
+	// Sum of subscription charges billed to user (to all users if user is not set)
+	charged := func(id int64, userId uuid.UUID, asOf *time.Time) model.Money {
+		t.Helper()
+
+		charges, err := st.GetCharges([]int64{id}, asOf)
+		assert.NoError(t, err)
+
+		sum, err := model.SumCharges(charges[id], userId)
+		assert.NoError(t, err)
+
+		return sum
+	}
+
+	// 2.Charges are generated on creation
+	id, err := st.CreateSubscription(spec)
+	assert.NoError(t, err)
+	assert.Equal(t, model.Money(3*400), charged(id, uuid.Nil, nil))
+
+	beforeChanges := time.Now().UTC()
+	time.Sleep(10 * time.Millisecond)
+
+	// 3.Changes are corrected by appended entries
+	_, err = st.AddPriceChange(id, model.PriceChange{Price: 600, EffectiveFrom: model.Date{Month: 3, Year: 2026}})
+	assert.NoError(t, err)
+	assert.Equal(t, model.Money(2*400+600), charged(id, uuid.Nil, nil))
+
+	assert.NoError(t, st.SetMembers(id, []model.Member{{UserID: ownerId, Weight: 1}, {UserID: memberId, Weight: 1}}))
+	assert.Equal(t, model.Money(400+300), charged(id, memberId, nil))
+
+	assert.NoError(t, st.UpdateSubscription(id, replaceUpdate(spec.ServiceName, 400, spec.StartDate, model.Date{Month: 2, Year: 2026})))
+	assert.Equal(t, model.Money(400), charged(id, uuid.Nil, nil))
+
+	// Report as of moment before changes is the same
+	assert.Equal(t, model.Money(3*400), charged(id, uuid.Nil, &beforeChanges))
+
+	var count int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM charge WHERE subscription_id = ? AND amount < 0", id).Scan(&count))
+	assert.Greater(t, count, 0)
+
+	// 4.Charges of deleted subscription are reversed
+	assert.NoError(t, st.DeleteSubscription(id))
+	assert.Equal(t, model.Money(0), charged(id, uuid.Nil, nil))
+
+	// 5.Rebuild generates charges missed in ledger and then changes nothing
+	otherId, err := st.CreateSubscription(model.SubscriptionSpec{
+		ServiceName:   "Ledger Cloud",
+		Price:         100,
+		UserID:        ownerId,
+		StartDate:     model.Date{Month: 1, Year: 2026},
+		EndDate:       model.Date{Month: 3, Year: 2026},
+		BillingPeriod: 1,
+	})
+	assert.NoError(t, err)
+
+	_, err = db.Exec("DELETE FROM charge WHERE subscription_id = ?", otherId)
+	assert.NoError(t, err)
+
+	report, err := st.RebuildCharges()
+	assert.NoError(t, err)
+	assert.Equal(t, model.LedgerReport{Subscriptions: 2, Appended: 2}, report)
+	assert.Equal(t, model.Money(200), charged(otherId, uuid.Nil, nil))
+
+	report, err = st.RebuildCharges()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), report.Appended)
+
+	// 6.Change is rolled back if its charges cannot be generated
+	_, err = db.Exec("CREATE TRIGGER charge_unavailable BEFORE INSERT ON charge BEGIN SELECT RAISE(ABORT, 'ledger is unavailable'); END")
+	assert.NoError(t, err)
+
+	_, err = st.AddPriceChange(otherId, model.PriceChange{Price: 150, EffectiveFrom: model.Date{Month: 2, Year: 2026}})
+	assert.Error(t, err)
+
+	subscription, err := st.GetSubscription(otherId)
+	assert.NoError(t, err)
+	assert.Empty(t, subscription.PriceSchedule)
+	assert.Equal(t, model.Money(200), charged(otherId, uuid.Nil, nil))
+}
+
 func intPointerHelper(value int) *int {
 	p := new(int)
 	*p = value
 	return p
 }
+
+func TestStreamSubscriptions(t *testing.T) {
+	// 1.Init
+	db := newTestDB(t)
+	defer db.Close()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	st := newStorage(db, logger)
+
+	ids := []int64{}
+	for i, name := range []string{"Stream Video", "Stream Music", "Stream Cloud"} {
+		category := "media"
+		var tags []string
+		if i == 2 {
+			category = "work"
+			tags = []string{"vps", "backup"}
+		}
+
+		id, err := st.CreateSubscription(model.SubscriptionSpec{
+			ServiceName:   name,
+			Price:         model.Money(100 * (i + 1)),
+			UserID:        uuid.New(),
+			StartDate:     model.Date{Month: 1, Year: 2026},
+			EndDate:       model.Date{Month: 3, Year: 2026},
+			Category:      category,
+			Tags:          tags,
+			BillingPeriod: 1,
+		})
+		assert.NoError(t, err)
+
+		ids = append(ids, id)
+	}
+
+	// 2.Rows are passed in id order
+	var streamed []int64
+
+	err := st.StreamSubscriptions(context.Background(), nil, nil, model.SubscriptionFilter{}, nil, func(sub *model.Subscription) error {
+		streamed = append(streamed, sub.ID)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ids, streamed)
+
+	// 3.Filter is applied
+	category := "work"
+	streamed = nil
+
+	err = st.StreamSubscriptions(context.Background(), nil, nil, model.SubscriptionFilter{Category: &category}, nil, func(sub *model.Subscription) error {
+		assert.Equal(t, "Stream Cloud", sub.ServiceName)
+		assert.Equal(t, model.Money(300), sub.Price)
+		assert.Equal(t, []string{"vps", "backup"}, sub.Tags)
+		streamed = append(streamed, sub.ID)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ids[2:], streamed)
+
+	// 4.Page is read only
+	streamed = nil
+
+	err = st.StreamSubscriptions(context.Background(), intPointerHelper(1), intPointerHelper(1), model.SubscriptionFilter{}, nil, func(sub *model.Subscription) error {
+		streamed = append(streamed, sub.ID)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ids[1:2], streamed)
+
+	err = st.StreamSubscriptions(context.Background(), intPointerHelper(1), nil, model.SubscriptionFilter{}, nil, func(sub *model.Subscription) error {
+		return nil
+	})
+	assert.Error(t, err)
+
+	// 5.Reading stops on yield error
+	streamed = nil
+	stop := errors.New("client is gone")
+
+	err = st.StreamSubscriptions(context.Background(), nil, nil, model.SubscriptionFilter{}, nil, func(sub *model.Subscription) error {
+		streamed = append(streamed, sub.ID)
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, ids[:1], streamed)
+
+	// 6.Reading stops on context cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	streamed = nil
+
+	err = st.StreamSubscriptions(ctx, nil, nil, model.SubscriptionFilter{}, nil, func(sub *model.Subscription) error {
+		assert.Nil(t, sub.Tags)
+		streamed = append(streamed, sub.ID)
+		cancel()
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, ids[:1], streamed)
+}
+
+func TestImportSubscriptions(t *testing.T) {
+	// 1.Init
+	db := newTestDB(t)
+	defer db.Close()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	st := newStorage(db, logger)
+
+	userId := uuid.New()
+
+	spec := func(serviceName string, price model.Money, tags []string) model.SubscriptionSpec {
+		return model.SubscriptionSpec{
+			ServiceName:   serviceName,
+			Price:         price,
+			UserID:        userId,
+			StartDate:     model.Date{Month: 1, Year: 2026},
+			EndDate:       model.Date{Month: 3, Year: 2026},
+			Tags:          tags,
+			BillingPeriod: 1,
+		}
+	}
+
+	existingId, err := st.CreateSubscription(spec("Import Video", 500, []string{"old"}))
+	assert.NoError(t, err)
+
+	chunk := []model.SubscriptionSpec{spec("Import Music", 300, nil), spec("Import Video", 700, []string{"new"})}
+
+	count := func() int {
+		subs, err := st.GetSubscriptions(nil, nil, model.SubscriptionFilter{}, nil)
+		assert.NoError(t, err)
+		return len(subs)
+	}
+
+	// 2.Dry run writes nothing
+	results, err := st.ImportSubscriptions(chunk, model.ConflictUpdate, true)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ImportCreated, results[0].Outcome)
+	assert.Equal(t, model.ImportUpdated, results[1].Outcome)
+	assert.Equal(t, 1, count())
+
+	// 3.Conflicting row fails, other row is imported
+	results, err = st.ImportSubscriptions(chunk, model.ConflictFail, false)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ImportCreated, results[0].Outcome)
+	assert.Equal(t, model.ImportFailed, results[1].Outcome)
+	assert.ErrorIs(t, results[1].Err, storage.ErrSubscriptionExists)
+	assert.Equal(t, 2, count())
+
+	// 4.Existing rows are skipped
+	results, err = st.ImportSubscriptions(chunk, model.ConflictSkip, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.ImportResult{{Outcome: model.ImportSkipped}, {Outcome: model.ImportSkipped}}, results)
+
+	// 5.Existing row gets imported data
+	results, err = st.ImportSubscriptions(chunk[1:], model.ConflictUpdate, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.ImportResult{{Outcome: model.ImportUpdated, ID: existingId}}, results)
+
+	sub, err := st.GetSubscription(existingId)
+	assert.NoError(t, err)
+	assert.Equal(t, model.Money(700), sub.Price)
+	assert.Equal(t, []string{"new"}, sub.Tags)
+
+	charges, err := st.GetCharges([]int64{existingId}, nil)
+	assert.NoError(t, err)
+
+	total, err := model.SumCharges(charges[existingId], uuid.Nil)
+	assert.NoError(t, err)
+	assert.Equal(t, model.Money(1400), total)
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	// 1.Init
+	db := newTestDB(t)
+	defer db.Close()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	st := newStorage(db, logger)
+
+	// 2.Key is reserved once
+	assert.NoError(t, st.ReserveIdempotencyKey("retry-1", "hash-1", time.Hour))
+	assert.ErrorIs(t, st.ReserveIdempotencyKey("retry-1", "hash-2", time.Hour), storage.ErrIdempotencyKeyExists)
+
+	record, err := st.GetIdempotencyKey("retry-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "hash-1", record.RequestHash)
+	assert.False(t, record.Completed())
+
+	// 3.Response is saved
+	body := []byte(`{"status":"OK","id":1}`)
+	assert.NoError(t, st.SaveIdempotentResponse("retry-1", 201, "application/json", body))
+	assert.ErrorIs(t, st.SaveIdempotentResponse("unknown", 201, "application/json", body), storage.ErrIdempotencyKeyNotFound)
+
+	record, err = st.GetIdempotencyKey("retry-1")
+	assert.NoError(t, err)
+	assert.True(t, record.Completed())
+	assert.Equal(t, 201, record.Status)
+	assert.Equal(t, "application/json", record.ContentType)
+	assert.Equal(t, body, record.Body)
+
+	// 4.Expired key is not found and can be reserved again
+	assert.NoError(t, st.ReserveIdempotencyKey("retry-2", "hash-1", -time.Second))
+
+	_, err = st.GetIdempotencyKey("retry-2")
+	assert.ErrorIs(t, err, storage.ErrIdempotencyKeyNotFound)
+
+	assert.NoError(t, st.ReserveIdempotencyKey("retry-2", "hash-2", -time.Second))
+
+	deleted, err := st.DeleteExpiredIdempotencyKeys()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	// 5.Released key can be reserved again
+	assert.NoError(t, st.DeleteIdempotencyKey("retry-1"))
+	assert.NoError(t, st.ReserveIdempotencyKey("retry-1", "hash-2", time.Hour))
+}
+
+func TestSubscriptionTimestamps(t *testing.T) {
+	// 1.Init
+	db := newTestDB(t)
+	defer db.Close()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	st := newStorage(db, logger)
+
+	id, err := st.CreateSubscription(model.SubscriptionSpec{
+		ServiceName: "Timestamp Video",
+		Price:       500,
+		UserID:      uuid.New(),
+		StartDate:   model.Date{Month: 1, Year: 2026},
+		EndDate:     model.Date{Month: 6, Year: 2026},
+	})
+	assert.NoError(t, err)
+
+	// 2.New subscription is created and changed at the same moment
+	created, err := st.GetSubscription(id)
+	assert.NoError(t, err)
+	assert.False(t, created.CreatedAt.IsZero())
+	assert.True(t, created.CreatedAt.Equal(created.UpdatedAt))
+
+	// 3.Changes of subscription and its details move last change moment only
+	lastChange := created.UpdatedAt
+
+	changes := map[string]func() error{
+		"update": func() error {
+			price := model.Money(600)
+			return st.UpdateSubscription(id, model.SubscriptionUpdate{Price: &price})
+		},
+		"price change": func() error {
+			_, err := st.AddPriceChange(id, model.PriceChange{Price: 700, EffectiveFrom: model.Date{Month: 3, Year: 2026}})
+			return err
+		},
+		"tags": func() error {
+			return st.SetTags(id, "video", []string{"family"})
+		},
+		"members": func() error {
+			return st.SetMembers(id, []model.Member{{UserID: uuid.New(), Weight: 1}})
+		},
+	}
+
+	for name, change := range changes {
+		assert.NoError(t, change(), name)
+
+		subscription, err := st.GetSubscription(id)
+		assert.NoError(t, err)
+		assert.True(t, subscription.UpdatedAt.After(lastChange), name)
+		assert.True(t, subscription.CreatedAt.Equal(created.CreatedAt), name)
+
+		lastChange = subscription.UpdatedAt
+	}
+}
+
+func TestSubscriptionFields(t *testing.T) {
+	// 1.Init
+	db := newTestDB(t)
+	defer db.Close()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	st := newStorage(db, logger)
+
+	id, err := st.CreateSubscription(model.SubscriptionSpec{
+		ServiceName: "Sparse Video",
+		Price:       500,
+		UserID:      uuid.New(),
+		StartDate:   model.Date{Month: 1, Year: 2026},
+		EndDate:     model.Date{Month: 6, Year: 2026},
+		Category:    "video",
+		Tags:        []string{"family"},
+	})
+	assert.NoError(t, err)
+
+	_, err = st.AddPriceChange(id, model.PriceChange{Price: 700, EffectiveFrom: model.Date{Month: 3, Year: 2026}})
+	assert.NoError(t, err)
+
+	fields := model.FieldSet{model.FieldServiceName: true, model.FieldPrice: true, model.FieldStartDate: true}
+
+	// 2.Only requested columns are read, id and last change moment are always read
+	subscription, err := st.GetSubscriptionFields(id, fields)
+	assert.NoError(t, err)
+	assert.Equal(t, id, subscription.ID)
+	assert.Equal(t, "Sparse Video", subscription.ServiceName)
+	assert.Equal(t, model.Money(500), subscription.Price)
+	assert.Equal(t, model.Date{Month: 1, Year: 2026}, subscription.StartDate)
+	assert.False(t, subscription.UpdatedAt.IsZero())
+	assert.Equal(t, model.Date{}, subscription.EndDate)
+	assert.Equal(t, uuid.Nil, subscription.UserID)
+	assert.Empty(t, subscription.Category)
+	assert.True(t, subscription.CreatedAt.IsZero())
+
+	// 3.Details are loaded only if requested
+	assert.Nil(t, subscription.Tags)
+	assert.Nil(t, subscription.PriceSchedule)
+
+	subscription, err = st.GetSubscriptionFields(id, model.FieldSet{model.FieldPriceSchedule: true})
+	assert.NoError(t, err)
+	assert.Len(t, subscription.PriceSchedule, 1)
+	assert.Nil(t, subscription.Tags)
+
+	_, err = st.GetSubscriptionFields(id+1, fields)
+	assert.ErrorIs(t, err, storage.ErrSubscribtionNotFound)
+
+	// 4.List and stream are projected the same way
+	listed, err := st.GetSubscriptions(nil, nil, model.SubscriptionFilter{}, fields)
+	assert.NoError(t, err)
+	assert.Len(t, listed, 1)
+	assert.Equal(t, "Sparse Video", listed[0].ServiceName)
+	assert.Empty(t, listed[0].Category)
+	assert.Nil(t, listed[0].Tags)
+
+	listed, err = st.GetSubscriptions(nil, nil, model.SubscriptionFilter{}, model.FieldSet{model.FieldTags: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"family"}, listed[0].Tags)
+
+	var streamed []model.Subscription
+	err = st.StreamSubscriptions(context.Background(), nil, nil, model.SubscriptionFilter{}, fields, func(sub *model.Subscription) error {
+		streamed = append(streamed, *sub)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, streamed, 1)
+	assert.Equal(t, model.Money(500), streamed[0].Price)
+	assert.Empty(t, streamed[0].Category)
+	assert.Nil(t, streamed[0].Tags)
+}
+
+func TestFilterExpr(t *testing.T) {
+	// 1.Init
+	db := newTestDB(t)
+	defer db.Close()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	st := newStorage(db, logger)
+
+	userID := uuid.New()
+
+	specs := []model.SubscriptionSpec{
+		{ServiceName: "Yandex Plus", Price: 29900, UserID: userID, StartDate: model.Date{Month: 1, Year: 2025}, EndDate: model.Date{Month: 12, Year: 2025}, Category: "video", Tags: []string{"family"}},
+		{ServiceName: "Yandex Music", Price: 49900, UserID: uuid.New(), StartDate: model.Date{Month: 3, Year: 2025}, EndDate: model.Date{Month: 4, Year: 2025}, AutoRenew: true},
+		{ServiceName: "Netflix", Price: 99900, UserID: userID, StartDate: model.Date{Month: 7, Year: 2025}, EndDate: model.Date{Month: 9, Year: 2025}, Category: "video", Tags: []string{"work"}},
+		{ServiceName: "100%_Cloud", Price: 30000, UserID: uuid.New(), StartDate: model.Date{Month: 8, Year: 2025}, EndDate: model.Date{Month: 10, Year: 2026}},
+	}
+
+	ids := make([]int64, 0, len(specs))
+	for _, spec := range specs {
+		id, err := st.CreateSubscription(spec)
+		assert.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	cases := []struct {
+		filter string
+		ids    []int64
+	}{
+		{filter: `price>=30000 and service_name~"yan*" and active_in(2025-01,2025-06)`, ids: []int64{ids[1]}},
+		{filter: `service_name ~ "YANDEX*" or price = "300"`, ids: []int64{ids[0], ids[1], ids[3]}},
+		{filter: `service_name ~ "100%_*"`, ids: []int64{ids[3]}},
+		{filter: `service_name ~ "100_*"`, ids: []int64{}},
+		{filter: `not (category = video) and auto_renew = false`, ids: []int64{ids[3]}},
+		{filter: `tag = family or tag ~ "w*"`, ids: []int64{ids[0], ids[2]}},
+		{filter: `tag != family and category = video`, ids: []int64{ids[2]}},
+		{filter: `user_id = "` + userID.String() + `" and end_date < 2025-12`, ids: []int64{ids[2]}},
+		{filter: `service_id > 0 and status = active and billing_period = 1`, ids: ids},
+		{filter: `active_in(2026-01, 2026-12)`, ids: []int64{ids[3]}},
+		// Subscription is not active in its end month and is active in its start month
+		{filter: `active_in(2025-09, 2025-11)`, ids: []int64{ids[0], ids[3]}},
+		{filter: `active_in(2025-05, 2025-07)`, ids: []int64{ids[0], ids[2]}},
+	}
+
+	for _, tc := range cases {
+		expr, err := model.ParseFilterExpr(tc.filter)
+		assert.NoError(t, err, tc.filter)
+
+		listed, err := st.GetSubscriptions(nil, nil, model.SubscriptionFilter{Expr: expr}, model.FieldSet{model.FieldID: true})
+		assert.NoError(t, err, tc.filter)
+
+		got := []int64{}
+		for _, sub := range listed {
+			got = append(got, sub.ID)
+		}
+		assert.Equal(t, tc.ids, got, tc.filter)
+	}
+
+	// 2.Expression is combined with other filters in cost report too
+	expr, err := model.ParseFilterExpr(`price < "500.00"`)
+	assert.NoError(t, err)
+
+	category := "video"
+	filtered, err := st.FilterSubscriptions(model.Date{Month: 12, Year: 2024}, model.Date{Month: 1, Year: 2027}, uuid.Nil, nil, model.SubscriptionFilter{Category: &category, Expr: expr})
+	assert.NoError(t, err)
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, ids[0], filtered[0].ID)
+}
+
+func TestSearchSubscriptions(t *testing.T) {
+	// 1.Init
+	db := newTestDB(t)
+	defer db.Close()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	var indexes int
+	assert.NoError(t, db.QueryRow("SELECT count(*) FROM sqlite_master WHERE name = 'subscription_search'").Scan(&indexes))
+	if indexes == 0 {
+		t.Skip("sqlite driver is built without FTS5 (use -tags sqlite_fts5)")
+	}
+
+	st := newStorage(db, logger)
+
+	specs := []model.SubscriptionSpec{
+		{ServiceName: "Yandex Plus", Price: 29900, UserID: uuid.New(), StartDate: model.Date{Month: 1, Year: 2025}, EndDate: model.Date{Month: 12, Year: 2025}, Tags: []string{"family"}},
+		{ServiceName: "Netflix", Price: 99900, UserID: uuid.New(), StartDate: model.Date{Month: 7, Year: 2025}, EndDate: model.Date{Month: 12, Year: 2025}},
+		{ServiceName: "Yandex Music", Price: 49900, UserID: uuid.New(), StartDate: model.Date{Month: 3, Year: 2025}, EndDate: model.Date{Month: 12, Year: 2025}},
+		{ServiceName: "Кинопоиск", Price: 39900, UserID: uuid.New(), StartDate: model.Date{Month: 2, Year: 2025}, EndDate: model.Date{Month: 12, Year: 2025}},
+	}
+
+	ids := make([]int64, 0, len(specs))
+	for _, spec := range specs {
+		id, err := st.CreateSubscription(spec)
+		assert.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	searchIds := func(query string, limit int) []int64 {
+		found, err := st.SearchSubscriptions(query, limit)
+		assert.NoError(t, err)
+
+		result := make([]int64, 0, len(found))
+		for _, sub := range found {
+			result = append(result, sub.ID)
+		}
+
+		return result
+	}
+
+	// 2.Misspelled names are found, the closest first
+	assert.Equal(t, []int64{ids[0], ids[2]}, searchIds("yandx", 10))
+	assert.Equal(t, []int64{ids[2], ids[0]}, searchIds("Yandex Muzic", 10))
+	assert.Equal(t, []int64{ids[1]}, searchIds("NETFLX", 10))
+	assert.Equal(t, []int64{ids[3]}, searchIds("кинопоск", 10))
+	assert.Equal(t, []int64{ids[0]}, searchIds("yandx", 1))
+	assert.Empty(t, searchIds("spotify", 10))
+
+	// Query syntax is not interpreted
+	assert.Empty(t, searchIds(`"nope" OR *`, 10))
+
+	// Short query is matched as substring
+	assert.Equal(t, []int64{ids[1]}, searchIds("fl", 10))
+
+	// Tags are loaded
+	found, err := st.SearchSubscriptions("yandex plus", 1)
+	assert.NoError(t, err)
+	if assert.Len(t, found, 1) {
+		assert.Equal(t, []string{"family"}, found[0].Tags)
+	}
+
+	// 3.Index follows changes of subscriptions
+	name := "Okko"
+	assert.NoError(t, st.UpdateSubscription(ids[1], model.SubscriptionUpdate{ServiceName: &name}))
+	assert.Empty(t, searchIds("netflx", 10))
+	assert.Equal(t, []int64{ids[1]}, searchIds("okko", 10))
+
+	assert.NoError(t, st.DeleteSubscription(ids[0]))
+	assert.Equal(t, []int64{ids[2]}, searchIds("yandx", 10))
+}
+
+func TestNewStorageChecksFTS5(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	// FTS5 support of driver is found out independently of storage
+	db := newTestDB(t)
+	_, probeErr := db.Exec("CREATE VIRTUAL TABLE fts5_probe USING fts5(text)")
+	db.Close()
+
+	path := filepath.Join(t.TempDir(), "storage.db")
+
+	st, err := NewStorage(&path, logger)
+	if probeErr != nil {
+		// Storage is not started, so it cannot fail on every change of subscription later
+		assert.ErrorContains(t, err, "sqlite_fts5")
+		return
+	}
+
+	assert.NoError(t, err)
+	st.Close()
+}
@@ -0,0 +1,193 @@
+package sqlite
+
+import (
+	"database/sql"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Change subscription status (only if it is still in transition.From status) and store transition;
+// newEnd is optional new end date (for immediate cancellation)
+func (s *SqliteStorage) ChangeStatus(id int64, transition model.StatusTransition, newEnd *model.Date) (int64, error) {
+	const op = "storage.sqlite.ChangeStatus"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Prepare transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	// 2.Update status
+	changedAt := time.Now().UTC()
+
+	query := "UPDATE subscription SET status = ?, updated_at = ?"
+	args := []interface{}{string(transition.To), changedAt}
+
+	if newEnd != nil {
+		query += ", end_date = ?"
+		args = append(args, newEnd.ToStringISO())
+	}
+	query += " WHERE id = ? AND status = ?"
+	args = append(args, id, string(transition.From))
+
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: update status: %w", op, err)
+	}
+
+	changedRows, err := res.RowsAffected()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: get affected rows: %w", op, err)
+	}
+
+	// 3.Nothing updated: either no subscription or its status was changed by someone else
+	if changedRows == 0 {
+		var exists int
+		err = tx.QueryRow("SELECT 1 FROM subscription WHERE id = ?", id).Scan(&exists)
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
+			return 0, storage.ErrSubscribtionNotFound
+		}
+		if err != nil {
+			s.logger.Error(loggerMsg, "details", err)
+			return 0, fmt.Errorf("%s: check subscription: %w", op, err)
+		}
+
+		s.logger.Error(loggerMsg, "details", storage.ErrStatusChanged)
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrStatusChanged)
+	}
+
+	// 4.Store transition
+	res, err = tx.Exec(
+		`INSERT INTO subscription_status_transition (subscription_id,from_status,to_status,effective_from,changed_at)
+		values (?,?,?,?,?)`,
+		id,
+		string(transition.From),
+		string(transition.To),
+		transition.EffectiveFrom.ToStringISO(),
+		changedAt,
+	)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: insert transition: %w", op, err)
+	}
+
+	transitionId, err := res.LastInsertId()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to get last insert id: %w", op, err)
+	}
+
+	// 5.Regenerate charges and commit changes
+	if err := s.refreshCharges(tx, &loggerMsg, op, id); err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return transitionId, nil
+}
+
+// Mark active and paused subscriptions ended before specified month as expired (auto-renewing ones
+// are extended by renewer instead); returns number of expired
+func (s *SqliteStorage) ExpireSubscriptions(month model.Date) (int64, error) {
+	const op = "storage.sqlite.ExpireSubscriptions"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	const condition = "status IN ('active', 'paused') AND NOT auto_renew AND end_date <= ?"
+
+	// 1.Prepare transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	// 2.Store transitions (expiration applies since end date) and update statuses
+	changedAt := time.Now().UTC()
+
+	_, err = tx.Exec(
+		`INSERT INTO subscription_status_transition (subscription_id,from_status,to_status,effective_from,changed_at)
+		SELECT id, status, 'expired', end_date, ? FROM subscription WHERE `+condition,
+		changedAt,
+		month.ToStringISO(),
+	)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: insert transitions: %w", op, err)
+	}
+
+	res, err := tx.Exec("UPDATE subscription SET status = 'expired', updated_at = ? WHERE "+condition, changedAt, month.ToStringISO())
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: update statuses: %w", op, err)
+	}
+
+	expired, err := res.RowsAffected()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: get affected rows: %w", op, err)
+	}
+
+	// 3.Commit changes
+	err = tx.Commit()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return expired, nil
+}
+
+// Get status histories (ordered by effective date) for subscriptions with specified ids
+func (s *SqliteStorage) getStatusHistories(q querier, loggerMsg *string, op string, ids []int64) (map[int64][]model.StatusTransition, error) {
+	histories := make(map[int64][]model.StatusTransition)
+
+	query := `
+		SELECT id, subscription_id, from_status, to_status, effective_from, changed_at
+		FROM subscription_status_transition
+		WHERE subscription_id IN (%s)
+		ORDER BY effective_from, id
+	`
+
+	err := s.queryByIds(q, query, ids, func(rows *sql.Rows) error {
+		var transition model.StatusTransition
+		var subscriptionId int64
+		var effectiveFrom string
+
+		err := rows.Scan(&transition.ID, &subscriptionId, &transition.From, &transition.To, &effectiveFrom, &transition.ChangedAt)
+		if err != nil {
+			return fmt.Errorf("scan status transition row: %w", err)
+		}
+
+		transition.EffectiveFrom, err = model.DateFromStringISO(effectiveFrom)
+		if err != nil {
+			return fmt.Errorf("getting effective date: %w", err)
+		}
+
+		histories[subscriptionId] = append(histories[subscriptionId], transition)
+
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: get status histories: %w", op, err)
+	}
+
+	return histories, nil
+}
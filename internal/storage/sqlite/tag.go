@@ -0,0 +1,129 @@
+package sqlite
+
+import (
+	"database/sql"
+	"em_golang_rest_service_example/internal/model"
+	"em_golang_rest_service_example/internal/storage"
+	"fmt"
+	"time"
+)
+
+// Replace category and tags of subscription
+func (s *SqliteStorage) SetTags(id int64, category string, tags []string) error {
+	const op = "storage.sqlite.SetTags"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Prepare transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	// 2.Set category (subscription existence is checked too)
+	res, err := tx.Exec("UPDATE subscription SET category = ?, updated_at = ? WHERE id = ?", category, time.Now().UTC(), id)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: update category: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: get affected rows: %w", op, err)
+	}
+	if affected == 0 {
+		s.logger.Error(loggerMsg, "details", storage.ErrSubscribtionNotFound)
+		return storage.ErrSubscribtionNotFound
+	}
+
+	// 3.Replace tags
+	_, err = tx.Exec("DELETE FROM subscription_tag WHERE subscription_id = ?", id)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: delete tags: %w", op, err)
+	}
+
+	if err := insertTags(tx, id, tags); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// 4.Commit changes
+	err = tx.Commit()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+func insertTags(tx *sql.Tx, id int64, tags []string) error {
+	for _, tag := range tags {
+		_, err := tx.Exec("INSERT INTO subscription_tag (subscription_id,tag) values (?,?)", id, tag)
+		if err != nil {
+			return fmt.Errorf("insert tag: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Get tags (in order of addition) of subscriptions with specified ids
+func (s *SqliteStorage) getTags(q querier, loggerMsg *string, op string, ids []int64) (map[int64][]string, error) {
+	tags := make(map[int64][]string)
+
+	query := `
+		SELECT subscription_id, tag
+		FROM subscription_tag
+		WHERE subscription_id IN (%s)
+		ORDER BY id
+	`
+
+	err := s.queryByIds(q, query, ids, func(rows *sql.Rows) error {
+		var subscriptionId int64
+		var tag string
+
+		if err := rows.Scan(&subscriptionId, &tag); err != nil {
+			return fmt.Errorf("scan tag row: %w", err)
+		}
+
+		tags[subscriptionId] = append(tags[subscriptionId], tag)
+
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(*loggerMsg, "details", err)
+		return nil, fmt.Errorf("%s: get tags: %w", op, err)
+	}
+
+	return tags, nil
+}
+
+// Fill tags of subscriptions
+func (s *SqliteStorage) fillTags(loggerMsg *string, op string, subscriptions []model.Subscription) error {
+	ids := make([]int64, 0, len(subscriptions))
+	for i := 0; i < len(subscriptions); i++ {
+		ids = append(ids, subscriptions[i].ID)
+	}
+
+	tags, err := s.getTags(s.db, loggerMsg, op, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(subscriptions); i++ {
+		subscriptions[i].Tags = tags[subscriptions[i].ID]
+	}
+
+	return nil
+}
+
+// Column with JSON array of subscription tags (in order of addition)
+const tagsColumn = "(SELECT json_group_array(tag) FROM (SELECT tag FROM subscription_tag WHERE subscription_id = subscription.id ORDER BY id))"
+
+// Condition matching subscriptions having tag
+const tagCondition = "id IN (SELECT subscription_id FROM subscription_tag WHERE tag = ?)"
@@ -0,0 +1,128 @@
+package sqlite
+
+import (
+	"em_golang_rest_service_example/internal/model"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *SqliteStorage) GetUserSubscriptions(userId uuid.UUID, limit, offset *int) ([]model.Subscription, error) {
+	const op = "storage.sqlite.GetUserSubscriptions"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Validation
+	if limit != nil && offset == nil {
+		s.logger.Error(loggerMsg, "details", "no offset value while limit is set")
+		return []model.Subscription{}, errors.New("no offset value while limit is set")
+	} else if limit == nil && offset != nil {
+		s.logger.Error(loggerMsg, "details", "no limit value while offset is set")
+		return []model.Subscription{}, errors.New("no limit value while offset is set")
+	}
+
+	// 2.Prepare query (ordered to keep pages stable), shared subscriptions are included
+	query := "SELECT " + subscriptionColumns + " FROM subscription WHERE " + userCondition + " ORDER BY id"
+	args := []interface{}{userId.String(), userId.String()}
+
+	if limit != nil {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, *limit, *offset)
+	}
+
+	// 3.Run it
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return []model.Subscription{}, fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	// 4.Get data
+	subscriptions, err := s.getSubscriptionsFromSqliteRows(&loggerMsg, op, rows, nil)
+	if err != nil {
+		return []model.Subscription{}, err
+	}
+
+	// 5.Get subscriptions details (needed for cost calculation)
+	err = s.getSubscriptionsDetails(s.db, &loggerMsg, op, subscriptions, nil)
+	if err != nil {
+		return []model.Subscription{}, err
+	}
+
+	return subscriptions, nil
+}
+
+func (s *SqliteStorage) DeleteUserSubscriptions(userId uuid.UUID) (int64, error) {
+	const op = "storage.sqlite.DeleteUserSubscriptions"
+	var loggerMsg string = fmt.Sprintf("operation is %s", op)
+
+	// 1.Prepare transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: prepare transaction: %w", op, err)
+	}
+
+	defer tx.Rollback()
+
+	// 2.Delete own subscriptions (child tables are cleaned by cascade) and leave shared ones
+	res, err := tx.Exec("DELETE FROM subscription WHERE user_id = ?", userId.String())
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: get affected rows: %w", op, err)
+	}
+
+	// 3.Touch shared subscriptions left and remove user from their members
+	rows, err := tx.Query(
+		"UPDATE subscription SET updated_at = ? WHERE id IN (SELECT subscription_id FROM subscription_member WHERE user_id = ?) RETURNING id",
+		time.Now().UTC(), userId.String(),
+	)
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: update shared subscriptions: %w", op, err)
+	}
+
+	var shared []int64
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			s.logger.Error(loggerMsg, "details", err)
+			return 0, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		shared = append(shared, id)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+
+	_, err = tx.Exec("DELETE FROM subscription_member WHERE user_id = ?", userId.String())
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: delete memberships: %w", op, err)
+	}
+
+	// 4.Regenerate charges of shared subscriptions (their shares are split among members left) and commit changes
+	if err := s.refreshCharges(tx, &loggerMsg, op, shared...); err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		s.logger.Error(loggerMsg, "details", err)
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return deleted, nil
+}
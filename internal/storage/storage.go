@@ -5,6 +5,13 @@ import (
 )
 
 var (
-	ErrSubscribtionNotFound = errors.New("subscription not found")
-	ErrSubscriptionExists   = errors.New("subscription exists")
+	ErrSubscribtionNotFound   = errors.New("subscription not found")
+	ErrSubscriptionExists     = errors.New("subscription exists")
+	ErrPriceChangeExists      = errors.New("price change exists")
+	ErrStatusChanged          = errors.New("subscription status changed")
+	ErrServiceNotFound        = errors.New("service not found")
+	ErrServiceExists          = errors.New("service name or alias exists")
+	ErrServiceInUse           = errors.New("service is used by subscriptions")
+	ErrIdempotencyKeyExists   = errors.New("idempotency key exists")
+	ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
 )
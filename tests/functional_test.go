@@ -2,11 +2,16 @@ package tests
 
 import (
 	"em_golang_rest_service_example/internal/http-server/handlers"
+	"em_golang_rest_service_example/internal/model"
+	"encoding/csv"
+	"encoding/json"
 	"strconv"
+	"strings"
 
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/gavv/httpexpect/v2"
 	"github.com/google/uuid"
@@ -21,7 +26,17 @@ var (
 	u = url.URL{
 		Scheme: "http",
 		Host:   host,
+		Path:   "/api/v1",
 	}
+
+	// Unversioned paths are deprecated aliases of v1
+	legacyURL = url.URL{
+		Scheme: "http",
+		Host:   host,
+	}
+
+	// Errors are reported as problem details
+	problemJSON = httpexpect.ContentOpts{MediaType: handlers.ProblemContentType}
 )
 
 func TestCreate(t *testing.T) {
@@ -46,7 +61,9 @@ func TestCreate(t *testing.T) {
 		WithJSON(req).
 		Expect().
 		Status(http.StatusConflict).
-		JSON().Object().Value("error").IsEqual("subscription already exists")
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeSubscriptionExists).
+		HasValue("detail", "subscription already exists")
 }
 
 func TestRead(t *testing.T) {
@@ -69,41 +86,62 @@ func TestRead(t *testing.T) {
 
 	// 2.Try to get it
 	expectedResp := handlers.ReadResponse{
-		Id:          int64(id),
-		ServiceName: req.ServiceName,
-		Price:       req.Price,
-		UserID:      req.UserID,
-		StartDate:   req.StartDate,
-		EndDate:     req.EndDate,
-		Response:    handlers.RespOK(),
+		Id:                 int64(id),
+		ServiceName:        req.ServiceName,
+		Price:              req.Price,
+		UserID:             req.UserID,
+		StartDate:          req.StartDate,
+		EndDate:            req.EndDate,
+		SubscriptionStatus: string(model.StatusActive),
+		StatusHistory:      []handlers.StatusTransitionItem{},
+		PriceSchedule:      []handlers.PriceScheduleItem{},
+		Discounts:          []handlers.DiscountItem{},
+		Members:            []handlers.MemberItem{},
+		Tags:               []string{},
+		Metadata:           json.RawMessage("{}"),
+		BillingPeriod:      1,
+		Renewals:           []handlers.RenewalItem{},
+		Response:           handlers.RespOK(),
 	}
 
-	e.GET("/subscription/" + strconv.FormatInt(int64(id), 10)).
+	resp := e.GET("/subscription/" + strconv.FormatInt(int64(id), 10)).
 		Expect().
-		Status(http.StatusOK).
-		JSON().Object().IsEqual(expectedResp)
+		Status(http.StatusOK)
 
-	// 3.Try to get non-existen data
-	expectedResp = handlers.ReadResponse{
-		Response: handlers.RespError("subscription not found"),
-	}
+	// Response status is not hidden by subscription status
+	resp.Body().Contains(`"status":"OK"`)
 
+	obj := resp.JSON().Object()
+
+	expectedResp.ServiceID = int64(obj.Value("service_id").Number().Gt(0).Raw())
+	expectedResp.CreatedAt = obj.Value("created_at").String().NotEmpty().Raw()
+	expectedResp.UpdatedAt = obj.Value("updated_at").String().NotEmpty().Raw()
+	obj.IsEqual(expectedResp)
+
+	// 3.Try to get non-existen data
 	e.GET("/subscription/-532").
 		Expect().
 		Status(http.StatusNotFound).
-		JSON().Object().IsEqual(expectedResp)
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeSubscriptionNotFound).
+		HasValue("detail", "subscription not found")
 }
 
 func TestUpdate(t *testing.T) {
 	e := httpexpect.Default(t, u.String())
 
 	// 1.Send request on create
+	autoRenew := true
 	req := handlers.CreateRequest{
 		ServiceName: "Netflix",
 		Price:       900,
 		UserID:      uuid.NewString(),
 		StartDate:   "01-2026",
 		EndDate:     "02-2026",
+		TrialMonths: 1,
+		Category:    "video",
+		Tags:        []string{"family"},
+		AutoRenew:   &autoRenew,
 	}
 
 	id := e.POST("/subscription").
@@ -120,34 +158,88 @@ func TestUpdate(t *testing.T) {
 		EndDate:     "03-2026",
 	}
 
-	e.PATCH("/subscription/" + strconv.FormatInt(int64(id), 10)).
+	e.PUT("/subscription/" + strconv.FormatInt(int64(id), 10)).
 		WithJSON(updateReq).
 		Expect().
 		Status(http.StatusOK).
 		JSON().Object().IsEqual(handlers.RespOK())
 
-	// 3.Get it updated
+	// 3.Get it updated (trial, category, tags and renewal omitted in replacement are reset)
 	expectedResp := handlers.ReadResponse{
-		Id:          int64(id),
-		ServiceName: updateReq.ServiceName,
-		Price:       updateReq.Price,
-		UserID:      req.UserID,
-		StartDate:   updateReq.StartDate,
-		EndDate:     updateReq.EndDate,
-		Response:    handlers.RespOK(),
+		Id:                 int64(id),
+		ServiceName:        updateReq.ServiceName,
+		Price:              updateReq.Price,
+		UserID:             req.UserID,
+		StartDate:          updateReq.StartDate,
+		EndDate:            updateReq.EndDate,
+		SubscriptionStatus: string(model.StatusActive),
+		StatusHistory:      []handlers.StatusTransitionItem{},
+		PriceSchedule:      []handlers.PriceScheduleItem{},
+		Discounts:          []handlers.DiscountItem{},
+		Members:            []handlers.MemberItem{},
+		Tags:               []string{},
+		Metadata:           json.RawMessage("{}"),
+		BillingPeriod:      1,
+		Renewals:           []handlers.RenewalItem{},
+		Response:           handlers.RespOK(),
 	}
 
-	e.GET("/subscription/" + strconv.FormatInt(int64(id), 10)).
+	resp := e.GET("/subscription/" + strconv.FormatInt(int64(id), 10)).
+		Expect().
+		Status(http.StatusOK)
+
+	// Response status is not hidden by subscription status
+	resp.Body().Contains(`"status":"OK"`)
+
+	obj := resp.JSON().Object()
+
+	expectedResp.ServiceID = int64(obj.Value("service_id").Number().Gt(0).Raw())
+	expectedResp.CreatedAt = obj.Value("created_at").String().NotEmpty().Raw()
+	expectedResp.UpdatedAt = obj.Value("updated_at").String().NotEmpty().Raw()
+	obj.IsEqual(expectedResp)
+
+	// 4.Patch only price and end date
+	e.PATCH("/subscription/"+strconv.FormatInt(int64(id), 10)).
+		WithHeader("Content-Type", "application/merge-patch+json").
+		WithBytes([]byte(`{"price": "8.00", "end_date": "06-2026"}`)).
 		Expect().
 		Status(http.StatusOK).
-		JSON().Object().IsEqual(expectedResp)
+		JSON().Object().IsEqual(handlers.RespOK())
 
-	// 4.Try to update non-existen data
-	e.PATCH("/subscription/-532").
+	obj = e.GET("/subscription/" + strconv.FormatInt(int64(id), 10)).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+
+	obj.HasValue("service_name", updateReq.ServiceName)
+	obj.HasValue("price", "8.00")
+	obj.HasValue("start_date", updateReq.StartDate)
+	obj.HasValue("end_date", "06-2026")
+
+	// 5.Patched subscription is validated as a whole
+	e.PATCH("/subscription/"+strconv.FormatInt(int64(id), 10)).
+		WithJSON(map[string]interface{}{"start_date": "07-2026"}).
+		Expect().
+		Status(http.StatusBadRequest).
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeValidationFailed).
+		HasValue("field", "end_date")
+
+	// 6.Try to update non-existen data
+	e.PUT("/subscription/-532").
 		WithJSON(updateReq).
 		Expect().
 		Status(http.StatusNotFound).
-		JSON().Object().IsEqual(handlers.RespError("subscription not found"))
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeSubscriptionNotFound).
+		HasValue("detail", "subscription not found")
+
+	e.PATCH("/subscription/-532").
+		WithJSON(map[string]interface{}{"price": "8.00"}).
+		Expect().
+		Status(http.StatusNotFound).
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeSubscriptionNotFound)
 }
 
 func TestDelete(t *testing.T) {
@@ -177,7 +269,9 @@ func TestDelete(t *testing.T) {
 	e.DELETE("/subscription/-532").
 		Expect().
 		Status(http.StatusNotFound).
-		JSON().Object().IsEqual(handlers.RespError("subscription not found"))
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeSubscriptionNotFound).
+		HasValue("detail", "subscription not found")
 }
 
 func TestList(t *testing.T) {
@@ -185,7 +279,7 @@ func TestList(t *testing.T) {
 
 	// 1.Create some data
 	services := []string{"Yandex", "VKMusic", "Google", "Netflix", "Wink"}
-	prices := []int{400, 75, 800, 900, 200}
+	prices := []model.Money{400, 75, 800, 900, 200}
 
 	createdIDs := make([]int64, 0, len(prices))
 
@@ -224,7 +318,7 @@ func TestTotalCost(t *testing.T) {
 
 	// 1.Create some data for one user
 	services := []string{"Yandex", "VKMusic", "Google", "Netflix", "Wink"}
-	prices := []int{400, 75, 800, 900, 200}
+	prices := []model.Money{400, 75, 800, 900, 200}
 
 	createdIDs := make([]int64, 0, len(prices))
 
@@ -260,5 +354,1255 @@ func TestTotalCost(t *testing.T) {
 
 	// 3.Check it
 	assert.Equal(t, handlers.RespOK(), resp.Response)
-	assert.Equal(t, 2375, resp.TotalCost)
+	assert.Equal(t, model.Money(2375), resp.TotalCost)
+
+	// 4.Report stays the same as of moment before later edit
+	asOf := time.Now().UTC().Format(time.RFC3339Nano)
+	time.Sleep(10 * time.Millisecond)
+
+	e.PUT("/subscription/" + strconv.FormatInt(createdIDs[0], 10)).
+		WithJSON(handlers.UpdateRequest{ServiceName: services[0], Price: 500, StartDate: "05-2027", EndDate: "06-2027"}).
+		Expect().
+		Status(http.StatusOK)
+
+	getTotal := func(asOf string) model.Money {
+		req := e.GET("/subscriptions/total-cost").
+			WithQuery("start_date", "01-2025").
+			WithQuery("end_date", "01-2028").
+			WithQuery("user_id", userId)
+		if asOf != "" {
+			req = req.WithQuery("as_of", asOf)
+		}
+
+		var resp handlers.TotalCostResponse
+		req.Expect().Status(http.StatusOK).JSON().Decode(&resp)
+
+		return resp.TotalCost
+	}
+
+	assert.Equal(t, model.Money(2475), getTotal(""))
+	assert.Equal(t, model.Money(2375), getTotal(asOf))
+}
+
+func TestAddPrice(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	userId := uuid.NewString()
+
+	// 1.Create subscription for 4 months
+	req := handlers.CreateRequest{
+		ServiceName: "Kinopoisk",
+		Price:       30000,
+		UserID:      userId,
+		StartDate:   "01-2028",
+		EndDate:     "05-2028",
+	}
+
+	id := e.POST("/subscription").
+		WithJSON(req).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").Number().Raw()
+
+	idStr := strconv.FormatInt(int64(id), 10)
+
+	// 2.Raise price since third month
+	newPrice := model.Money(40000)
+	priceReq := handlers.AddPriceRequest{Price: &newPrice, EffectiveFrom: "03-2028"}
+
+	e.POST("/subscription/" + idStr + "/prices").
+		WithJSON(priceReq).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").IsNumber()
+
+	e.POST("/subscription/"+idStr+"/prices").
+		WithJSON(priceReq).
+		Expect().
+		Status(http.StatusConflict).
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodePriceChangeExists).
+		HasValue("detail", "price change already exists")
+
+	// 3.Check schedule is shown
+	e.GET("/subscription/" + idStr).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("price_schedule").Array().Length().IsEqual(1)
+
+	// 4.Check total cost uses effective prices
+	var resp handlers.TotalCostResponse
+
+	e.GET("/subscriptions/total-cost").
+		WithQuery("start_date", "12-2027").
+		WithQuery("end_date", "06-2028").
+		WithQuery("user_id", userId).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Decode(&resp)
+
+	assert.Equal(t, model.Money(30000*2+40000*2), resp.TotalCost)
+}
+
+func TestTrialAndDiscount(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	userId := uuid.NewString()
+
+	// 1.Create subscription for a year with 3 free months
+	req := handlers.CreateRequest{
+		ServiceName: "Okko",
+		Price:       40000,
+		UserID:      userId,
+		StartDate:   "01-2029",
+		EndDate:     "01-2030",
+		TrialMonths: 3,
+	}
+
+	id := e.POST("/subscription").
+		WithJSON(req).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").Number().Raw()
+
+	idStr := strconv.FormatInt(int64(id), 10)
+
+	// 2.Add 50% discount for the next 3 months
+	discountReq := handlers.AddDiscountRequest{Kind: "percent", Percent: 50, ValidFrom: "04-2029", ValidTo: "07-2029"}
+
+	e.POST("/subscription/" + idStr + "/discounts").
+		WithJSON(discountReq).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").IsNumber()
+
+	// 3.Check discount is shown
+	obj := e.GET("/subscription/" + idStr).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+
+	obj.Value("trial_months").IsEqual(3)
+	obj.Value("discounts").Array().Length().IsEqual(1)
+
+	// 4.Check total cost honours trial and discount
+	var resp handlers.TotalCostResponse
+
+	e.GET("/subscriptions/total-cost").
+		WithQuery("start_date", "12-2028").
+		WithQuery("end_date", "02-2030").
+		WithQuery("user_id", userId).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Decode(&resp)
+
+	assert.Equal(t, model.Money(3*20000+6*40000), resp.TotalCost)
+}
+
+func TestPauseAndCancel(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	userId := uuid.NewString()
+
+	// 1.Create subscription for a year
+	req := handlers.CreateRequest{
+		ServiceName: "Ivi",
+		Price:       50000,
+		UserID:      userId,
+		StartDate:   "01-2031",
+		EndDate:     "01-2032",
+	}
+
+	id := e.POST("/subscription").
+		WithJSON(req).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").Number().Raw()
+
+	idStr := strconv.FormatInt(int64(id), 10)
+
+	// 2.Pause for two months and cancel immediately since September
+	e.POST("/subscription/"+idStr+"/pause").
+		WithJSON(handlers.ChangeStatusRequest{EffectiveFrom: "03-2031"}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().
+		HasValue("status", handlers.StatusOK).
+		HasValue("subscription_status", "paused")
+
+	e.POST("/subscription/" + idStr + "/pause").
+		WithJSON(handlers.ChangeStatusRequest{EffectiveFrom: "04-2031"}).
+		Expect().
+		Status(http.StatusConflict)
+
+	e.GET("/subscriptions").
+		WithQuery("status", "paused").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("items").Array().Length().Gt(0)
+
+	e.POST("/subscription/"+idStr+"/resume").
+		WithJSON(handlers.ChangeStatusRequest{EffectiveFrom: "05-2031"}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().
+		HasValue("status", handlers.StatusOK).
+		HasValue("subscription_status", "active")
+
+	e.POST("/subscription/" + idStr + "/cancel").
+		WithJSON(handlers.CancelRequest{Mode: handlers.CancelImmediate, EffectiveFrom: "09-2031"}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("end_date").IsEqual("09-2031")
+
+	// 3.Check status history is stored
+	obj := e.GET("/subscription/" + idStr).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+
+	obj.Value("subscription_status").IsEqual("cancelled")
+	obj.Value("status_history").Array().Length().IsEqual(3)
+
+	// 4.Check paused months are not billed
+	var resp handlers.TotalCostResponse
+
+	e.GET("/subscriptions/total-cost").
+		WithQuery("start_date", "12-2030").
+		WithQuery("end_date", "02-2032").
+		WithQuery("user_id", userId).
+		WithQuery("status", "cancelled").
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Decode(&resp)
+
+	assert.Equal(t, model.Money(6*50000), resp.TotalCost)
+}
+
+func TestServiceCatalog(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	suffix := uuid.NewString()[:8]
+
+	// 1.Register service with alias
+	serviceReq := handlers.ServiceRequest{
+		Name:         "Kinopoisk " + suffix,
+		Aliases:      []string{"KP-" + suffix},
+		DefaultPrice: 29900,
+		Category:     "video",
+	}
+
+	serviceId := e.POST("/services").
+		WithJSON(serviceReq).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").Number().Raw()
+
+	serviceIdStr := strconv.FormatInt(int64(serviceId), 10)
+
+	e.POST("/services").
+		WithJSON(handlers.ServiceRequest{Name: "kinopoisk_" + suffix}).
+		Expect().
+		Status(http.StatusConflict)
+
+	// 2.Create subscription by alias without price
+	id := e.POST("/subscription").
+		WithJSON(handlers.CreateRequest{
+			ServiceName: "kp " + suffix,
+			UserID:      uuid.NewString(),
+			StartDate:   "01-2026",
+		}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").Number().Raw()
+
+	obj := e.GET("/subscription/" + strconv.FormatInt(int64(id), 10)).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+
+	obj.Value("service_name").IsEqual(serviceReq.Name)
+	obj.Value("service_id").IsEqual(serviceId)
+	obj.Value("price").IsEqual("299.00")
+
+	// 3.Create subscription by service id
+	e.POST("/subscription").
+		WithJSON(handlers.CreateRequest{
+			ServiceID: int64(serviceId),
+			Price:     10000,
+			UserID:    uuid.NewString(),
+			StartDate: "01-2026",
+		}).
+		Expect().
+		Status(http.StatusCreated)
+
+	// 4.Used service cannot be deleted
+	e.DELETE("/services/"+serviceIdStr).
+		Expect().
+		Status(http.StatusConflict).
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeServiceInUse).
+		HasValue("detail", "service is used by subscriptions")
+
+	e.GET("/services/" + serviceIdStr).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("aliases").Array().IsEqual(serviceReq.Aliases)
+
+	e.GET("/services/-532").
+		Expect().
+		Status(http.StatusNotFound).
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeServiceNotFound).
+		HasValue("detail", "service not found")
+}
+
+func TestUserEndpoints(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	userId := uuid.NewString()
+
+	// 1.Create user subscriptions
+	for _, name := range []string{"Amediateka", "Start"} {
+		e.POST("/subscription").
+			WithJSON(handlers.CreateRequest{
+				ServiceName: name,
+				Price:       50000,
+				UserID:      userId,
+				StartDate:   "01-2026",
+				EndDate:     "07-2026",
+			}).
+			Expect().
+			Status(http.StatusCreated)
+	}
+
+	// 2.Get them page by page
+	e.GET("/users/"+userId+"/subscriptions").
+		WithQuery("limit", 1).
+		WithQuery("offset", 1).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("items").Array().Length().IsEqual(1)
+
+	// 3.Get summary
+	var summary handlers.UserSummaryResponse
+
+	e.GET("/users/"+userId+"/summary").
+		WithQuery("month", "03-2026").
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Decode(&summary)
+
+	assert.Equal(t, int64(2), summary.ActiveCount)
+	assert.Equal(t, model.Money(2*50000), summary.MonthlyRunRate)
+	assert.Equal(t, model.Money(2*3*50000), summary.YearToDateSpend)
+	assert.Equal(t, "07-2026", summary.NextExpiring.EndDate)
+
+	// 4.Delete them all
+	e.DELETE("/users/" + userId + "/subscriptions").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("deleted").IsEqual(2)
+
+	e.GET("/users/" + userId + "/subscriptions").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("items").Array().IsEmpty()
+}
+
+func TestSharedSubscription(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	owner, member := uuid.NewString(), uuid.NewString()
+
+	// 1.Create family plan shared by two users
+	id := e.POST("/subscription").
+		WithJSON(handlers.CreateRequest{
+			ServiceName: "Apple One Family",
+			Price:       90000,
+			UserID:      owner,
+			StartDate:   "01-2026",
+			EndDate:     "03-2026",
+			Members: []handlers.MemberItem{
+				{UserID: owner, Weight: 2},
+				{UserID: member, Weight: 1},
+			},
+		}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").Number().Raw()
+
+	// 2.Every member pays own share
+	totalCost := func(userId string) model.Money {
+		var resp handlers.TotalCostResponse
+
+		e.GET("/subscriptions/total-cost").
+			WithQuery("start_date", "12-2025").
+			WithQuery("end_date", "04-2026").
+			WithQuery("user_id", userId).
+			Expect().
+			Status(http.StatusOK).
+			JSON().
+			Decode(&resp)
+
+		return resp.TotalCost
+	}
+
+	assert.Equal(t, model.Money(2*60000), totalCost(owner))
+	assert.Equal(t, model.Money(2*30000), totalCost(member))
+
+	// 3.Member pays alone after owner leaves
+	e.PUT("/subscription/" + strconv.FormatInt(int64(id), 10) + "/members").
+		WithJSON(handlers.MembersRequest{Members: []handlers.MemberItem{{UserID: member, Weight: 1}}}).
+		Expect().
+		Status(http.StatusOK)
+
+	assert.Equal(t, model.Money(0), totalCost(owner))
+	assert.Equal(t, model.Money(2*90000), totalCost(member))
+}
+
+func TestCategoryReport(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	userId := uuid.NewString()
+	tag := "project-" + uuid.NewString()[:8]
+
+	// 1.Create categorized subscriptions
+	create := func(serviceName string, price model.Money, category string, tags []string) int64 {
+		return int64(e.POST("/subscription").
+			WithJSON(handlers.CreateRequest{
+				ServiceName: serviceName,
+				Price:       price,
+				UserID:      userId,
+				StartDate:   "01-2026",
+				EndDate:     "02-2026",
+				Category:    category,
+				Tags:        tags,
+			}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().Object().Value("id").Number().Raw())
+	}
+
+	create("Netflix Report", 1000, "Streaming", []string{tag})
+	create("Okko Report", 500, "streaming", nil)
+	cloudId := create("AWS Report", 3000, "cloud", []string{tag, "work"})
+
+	e.GET("/subscription/"+strconv.FormatInt(cloudId, 10)).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().
+		HasValue("category", "cloud").
+		HasValue("tags", []string{tag, "work"})
+
+	// 2.Spend by category
+	var resp handlers.TotalCostResponse
+
+	e.GET("/subscriptions/total-cost").
+		WithQuery("start_date", "12-2025").
+		WithQuery("end_date", "03-2026").
+		WithQuery("user_id", userId).
+		WithQuery("group_by", "category").
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Decode(&resp)
+
+	assert.Equal(t, model.Money(4500), resp.TotalCost)
+	assert.Equal(t, []handlers.CostGroupItem{{Key: "cloud", Cost: 3000}, {Key: "streaming", Cost: 1500}}, resp.Groups)
+
+	// 3.Filters by tag and category
+	e.GET("/subscriptions").
+		WithQuery("tag", tag).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("items").Array().Length().IsEqual(2)
+
+	e.GET("/subscriptions").
+		WithQuery("tag", tag).
+		WithQuery("category", "Cloud").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("items").Array().Length().IsEqual(1)
+
+	// 4.Recategorize
+	e.PUT("/subscription/" + strconv.FormatInt(cloudId, 10) + "/tags").
+		WithJSON(handlers.TagsRequest{Category: "dev-tools", Tags: []string{}}).
+		Expect().
+		Status(http.StatusOK)
+
+	e.GET("/subscriptions").
+		WithQuery("tag", tag).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("items").Array().Length().IsEqual(1)
+}
+
+func TestMetadata(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	costCenter := "cc-" + uuid.NewString()[:8]
+
+	// 1.Create subscription with metadata
+	id := e.POST("/subscription").
+		WithJSON(map[string]interface{}{
+			"service_name": "Metadata Cloud",
+			"price":        "10.00",
+			"user_id":      uuid.NewString(),
+			"start_date":   "01-2026",
+			"metadata":     map[string]interface{}{"cost_center": costCenter, "seats": 3},
+		}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").Number().Raw()
+
+	path := "/subscription/" + strconv.FormatInt(int64(id), 10)
+
+	e.GET(path).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("metadata").Object().
+		HasValue("cost_center", costCenter).
+		HasValue("seats", 3)
+
+	// 2.Filter by metadata values
+	items := e.GET("/subscriptions").
+		WithQuery("metadata.cost_center", costCenter).
+		WithQuery("metadata.seats", "3").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("items").Array()
+
+	items.Length().IsEqual(1)
+	items.Value(0).Object().HasValue("id", id)
+
+	// 3.Limits are checked on update
+	e.PUT(path).
+		WithJSON(map[string]interface{}{
+			"service_name": "Metadata Cloud",
+			"price":        "10.00",
+			"start_date":   "01-2026",
+			"metadata":     map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"c": map[string]interface{}{}}}},
+		}).
+		Expect().
+		Status(http.StatusBadRequest)
+
+	e.PATCH(path).
+		WithJSON(map[string]interface{}{
+			"metadata": map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"c": map[string]interface{}{}}}},
+		}).
+		Expect().
+		Status(http.StatusBadRequest)
+
+	e.PUT(path).
+		WithJSON(map[string]interface{}{
+			"service_name": "Metadata Cloud",
+			"price":        "10.00",
+			"start_date":   "01-2026",
+			"metadata":     map[string]interface{}{},
+		}).
+		Expect().
+		Status(http.StatusOK)
+
+	e.GET("/subscriptions").
+		WithQuery("metadata.cost_center", costCenter).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("items").Array().Length().IsEqual(0)
+}
+
+func TestAutoRenewal(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	// 1.Subscription without end date is renewed by default
+	id := e.POST("/subscription").
+		WithJSON(map[string]interface{}{
+			"service_name":   "Renewal Music",
+			"price":          "5.00",
+			"user_id":        uuid.NewString(),
+			"start_date":     "01-2026",
+			"billing_period": 3,
+		}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").Number().Raw()
+
+	path := "/subscription/" + strconv.FormatInt(int64(id), 10)
+
+	obj := e.GET(path).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+
+	obj.HasValue("end_date", "04-2026")
+	obj.HasValue("auto_renew", true)
+	obj.HasValue("billing_period", 3)
+	obj.Value("renewals").Array().IsEmpty()
+
+	// 2.Turn renewal off
+	e.PUT(path + "/renewal").
+		WithJSON(handlers.RenewalRequest{AutoRenew: false, BillingPeriod: 12}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().IsEqual(handlers.RespOK())
+
+	obj = e.GET(path).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object()
+
+	obj.HasValue("auto_renew", false)
+	obj.HasValue("billing_period", 12)
+
+	// 3.Billing period is checked
+	e.PUT(path+"/renewal").
+		WithJSON(handlers.RenewalRequest{AutoRenew: true, BillingPeriod: 13}).
+		Expect().
+		Status(http.StatusBadRequest).
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeInvalidValue).
+		HasValue("detail", "request billing period is invalid")
+
+	e.PUT("/subscription/-532/renewal").
+		WithJSON(handlers.RenewalRequest{AutoRenew: true}).
+		Expect().
+		Status(http.StatusNotFound).
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeSubscriptionNotFound).
+		HasValue("detail", "subscription not found")
+}
+
+func TestReconciliation(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	userId := uuid.NewString()
+
+	// 1.Create subscriptions expected in statement
+	for _, req := range []handlers.CreateRequest{
+		{ServiceName: "Reconciled Video", Price: 49900, UserID: userId, StartDate: "01-2026", EndDate: "04-2026"},
+		{ServiceName: "Reconciled Cloud", Price: 9900, UserID: userId, StartDate: "02-2026", EndDate: "04-2026"},
+	} {
+		e.POST("/subscription").
+			WithJSON(req).
+			Expect().
+			Status(http.StatusCreated)
+	}
+
+	// 2.Upload statement: cloud storage is not charged in March, music service is not tracked
+	statement := "Дата;Описание;Сумма\n" +
+		"05.01.2026;RECONCILED VIDEO;-499,00\n" +
+		"05.02.2026;RECONCILED VIDEO;-499,00\n" +
+		"07.02.2026;RECONCILEDCLOUD.COM;-100,00\n" +
+		"05.03.2026;RECONCILED VIDEO;-499,00\n" +
+		"12.01.2026;UNTRACKED MUSIC;-169,00\n" +
+		"12.02.2026;UNTRACKED MUSIC;-169,00\n"
+
+	var resp handlers.ReconciliationResponse
+
+	e.POST("/reconciliation").
+		WithQuery("user_id", userId).
+		WithQuery("tolerance", "1.00").
+		WithHeader("Content-Type", "text/csv").
+		WithText(statement).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Decode(&resp)
+
+	assert.Equal(t, "01-2026", resp.From)
+	assert.Equal(t, "03-2026", resp.To)
+
+	assert.Len(t, resp.Matched, 4)
+	assert.Equal(t, "Reconciled Cloud", resp.Matched[2].ServiceName)
+	assert.Equal(t, model.Money(10000), resp.Matched[2].Payment.Amount)
+
+	assert.Len(t, resp.Missing, 1)
+	assert.Equal(t, "Reconciled Cloud", resp.Missing[0].ServiceName)
+	assert.Equal(t, "03-2026", resp.Missing[0].Month)
+
+	assert.Len(t, resp.Unknown, 1)
+	assert.Equal(t, "UNTRACKED MUSIC", resp.Unknown[0].Merchant)
+	assert.Len(t, resp.Unknown[0].Payments, 2)
+
+	// 3.Statement is checked
+	e.POST("/reconciliation").
+		WithText("date,merchant,amount\n").
+		Expect().
+		Status(http.StatusBadRequest).
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeEmptyBody).
+		HasValue("detail", "empty statement")
+}
+
+func TestExport(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	userId := uuid.NewString()
+	category := "export-" + uuid.NewString()[:8]
+
+	id := e.POST("/subscription").
+		WithJSON(handlers.CreateRequest{
+			ServiceName: "Export; Music",
+			Price:       19900,
+			UserID:      userId,
+			StartDate:   "01-2026",
+			EndDate:     "04-2026",
+			Category:    category,
+		}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").Number().Raw()
+
+	// 1.Exported with header row, delimiter inside value is quoted
+	resp := e.GET("/subscriptions/export.csv").
+		WithQuery("category", category).
+		WithQuery("date_format", "iso").
+		WithQuery("delimiter", "semicolon").
+		Expect().
+		Status(http.StatusOK)
+
+	resp.Header("Content-Type").IsEqual("text/csv; charset=utf-8")
+
+	reader := csv.NewReader(strings.NewReader(resp.Body().Raw()))
+	reader.Comma = ';'
+
+	records, err := reader.ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	assert.Equal(t, []string{"id", "service_name", "service_id", "user_id", "price"}, records[0][:5])
+	assert.Equal(t, strconv.FormatInt(int64(id), 10), records[1][0])
+	assert.Equal(t, "Export; Music", records[1][1])
+	assert.Equal(t, userId, records[1][3])
+	assert.Equal(t, "199.00", records[1][4])
+	assert.Equal(t, []string{"2026-01-01", "2026-04-01", "active", category}, records[1][7:11])
+
+	// 2.Format params are checked
+	e.GET("/subscriptions/export.csv").
+		WithQuery("delimiter", "pipe").
+		Expect().
+		Status(http.StatusBadRequest).
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeInvalidValue).
+		HasValue("detail", "delimiter is invalid")
+}
+
+func TestImport(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	userId := uuid.NewString()
+	category := "import-" + uuid.NewString()[:8]
+
+	csvData := "service_name,price,user_id,start_date,end_date,category,tags\n" +
+		"Import Video,9.99," + userId + ",01-2026,04-2026," + category + ",video|family\n" +
+		"Import Music,4.99," + userId + ",january,04-2026," + category + ",\n"
+
+	importCSV := func(query map[string]string) *httpexpect.Object {
+		req := e.POST("/subscriptions/import").
+			WithHeader("Content-Type", "text/csv").
+			WithText(csvData)
+		for key, value := range query {
+			req = req.WithQuery(key, value)
+		}
+
+		return req.Expect().Status(http.StatusOK).JSON().Object()
+	}
+
+	exported := func() int {
+		body := e.GET("/subscriptions/export.csv").
+			WithQuery("category", category).
+			Expect().
+			Status(http.StatusOK).
+			Body().Raw()
+
+		return strings.Count(body, "\n") - 1
+	}
+
+	// 1.Dry run reports errors and writes nothing
+	obj := importCSV(map[string]string{"dry_run": "true"})
+	obj.HasValue("dry_run", true)
+	obj.HasValue("created", 1)
+	obj.HasValue("failed", 1)
+	obj.Value("errors").Array().IsEqual([]handlers.ImportErrorItem{{Row: 3, Error: "request start date is invalid", Fields: []handlers.FieldErrorItem{
+		{Field: "start_date", Code: handlers.CodeInvalidValue, Message: "request start date is invalid"},
+	}}})
+
+	assert.Equal(t, 0, exported())
+
+	// 2.Import
+	obj = importCSV(nil)
+	obj.HasValue("created", 1)
+	obj.HasValue("failed", 1)
+
+	assert.Equal(t, 1, exported())
+
+	// 3.Conflict policies
+	obj = importCSV(nil)
+	obj.HasValue("created", 0)
+	obj.Value("errors").Array().Value(0).Object().HasValue("error", "subscription already exists")
+
+	obj = importCSV(map[string]string{"on_conflict": "skip"})
+	obj.HasValue("skipped", 1)
+
+	obj = importCSV(map[string]string{"on_conflict": "update"})
+	obj.HasValue("updated", 1)
+
+	assert.Equal(t, 1, exported())
+}
+
+func TestCalendar(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	userId := uuid.NewString()
+	now := time.Now().UTC()
+
+	e.POST("/subscription").
+		WithJSON(handlers.CreateRequest{
+			ServiceName: "Calendar, Music",
+			Price:       29900,
+			UserID:      userId,
+			StartDate:   now.Format("01-2006"),
+		}).
+		Expect().
+		Status(http.StatusCreated)
+
+	// 1.Monthly renewals within 12 months are listed
+	resp := e.GET("/users/" + userId + "/calendar.ics").
+		Expect().
+		Status(http.StatusOK)
+
+	resp.Header("Content-Type").IsEqual("text/calendar; charset=utf-8")
+
+	body := resp.Body().Raw()
+	assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\n"))
+	assert.Equal(t, 11, strings.Count(body, "BEGIN:VEVENT\r\n"))
+
+	next := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Contains(t, body, "DTSTART;VALUE=DATE:"+next.Format("20060102")+"\r\n")
+	assert.Contains(t, body, `SUMMARY:Calendar\, Music renewal`)
+	assert.Contains(t, body, "DESCRIPTION:Price: 299.00")
+
+	// 2.User id is checked
+	e.GET("/users/trash/calendar.ics").
+		Expect().
+		Status(http.StatusBadRequest).
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeInvalidValue).
+		HasValue("detail", "invalid user id format")
+}
+
+func TestListNDJSON(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	category := "ndjson-" + uuid.NewString()[:8]
+
+	var ids []int64
+	for _, name := range []string{"Stream One", "Stream Two"} {
+		id := e.POST("/subscription").
+			WithJSON(handlers.CreateRequest{
+				ServiceName: name,
+				Price:       9900,
+				UserID:      uuid.NewString(),
+				StartDate:   "01-2026",
+				Category:    category,
+				Tags:        []string{"stream"},
+			}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().Object().Value("id").Number().Raw()
+
+		ids = append(ids, int64(id))
+	}
+
+	// 1.One item per line in id order
+	resp := e.GET("/subscriptions").
+		WithHeader("Accept", "application/x-ndjson").
+		WithQuery("category", category).
+		Expect().
+		Status(http.StatusOK)
+
+	resp.Header("Content-Type").IsEqual("application/x-ndjson; charset=utf-8")
+
+	lines := strings.Split(strings.TrimSuffix(resp.Body().Raw(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+
+	for i, line := range lines {
+		var item handlers.ListItem
+		assert.NoError(t, json.Unmarshal([]byte(line), &item))
+		assert.Equal(t, ids[i], item.Id)
+		assert.Equal(t, []string{"stream"}, item.Tags)
+	}
+
+	// 2.Regular list is returned without header
+	e.GET("/subscriptions").
+		WithQuery("category", category).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("items").Array().Length().IsEqual(2)
+}
+
+func TestProblemDetails(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	// 1.Invalid field is named
+	e.POST("/subscription").
+		WithJSON(handlers.CreateRequest{ServiceName: "Problem Music", UserID: uuid.NewString(), StartDate: "13-2026x"}).
+		Expect().
+		Status(http.StatusBadRequest).
+		JSON(problemJSON).Object().
+		HasValue("title", "Bad Request").
+		HasValue("status", http.StatusBadRequest).
+		HasValue("code", handlers.CodeValidationFailed).
+		HasValue("field", "start_date").
+		ContainsKey("instance")
+
+	// 2.All invalid fields are listed
+	e.POST("/subscription").
+		WithJSON(handlers.CreateRequest{ServiceName: "Problem Music", Price: -100, UserID: "trash", StartDate: "07-2027", EndDate: "06-2027"}).
+		Expect().
+		Status(http.StatusBadRequest).
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeValidationFailed).
+		NotContainsKey("field").
+		Value("invalid_params").Array().IsEqual([]handlers.FieldErrorItem{
+		{Field: "price", Code: handlers.CodeInvalidValue, Message: "request price is invalid"},
+		{Field: "user_id", Code: handlers.CodeInvalidValue, Message: "request user id is invalid"},
+		{Field: "end_date", Code: handlers.CodeInvalidValue, Message: "request start date greater than end date"},
+	})
+
+	// 3.Unknown route and method
+	e.GET("/trash").
+		Expect().
+		Status(http.StatusNotFound).
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeRouteNotFound)
+
+	e.PUT("/subscriptions").
+		Expect().
+		Status(http.StatusMethodNotAllowed).
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeMethodNotAllowed)
+}
+
+func TestLegacyRoutes(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+	legacy := httpexpect.Default(t, legacyURL.String())
+
+	// 1.Versioned routes are not deprecated
+	resp := e.GET("/subscriptions").
+		WithQuery("limit", 1).
+		WithQuery("offset", 0).
+		Expect().
+		Status(http.StatusOK)
+
+	resp.Header("Deprecation").IsEmpty()
+	resp.Header("Sunset").IsEmpty()
+
+	// 2.Unversioned aliases work and point to v1
+	resp = legacy.GET("/subscriptions").
+		WithQuery("limit", 1).
+		WithQuery("offset", 0).
+		Expect().
+		Status(http.StatusOK)
+
+	resp.Header("Deprecation").HasPrefix("@")
+	resp.Header("Sunset").NotEmpty()
+	resp.Header("Link").IsEqual(`</api/v1/subscriptions?limit=1&offset=0>; rel="successor-version"`)
+
+	legacy.GET("/subscription/-532").
+		Expect().
+		Status(http.StatusNotFound).
+		Header("Deprecation").NotEmpty()
+}
+
+func TestIdempotentCreate(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	key := uuid.NewString()
+
+	req := handlers.CreateRequest{
+		ServiceName: "Idempotent Music",
+		Price:       250,
+		UserID:      uuid.NewString(),
+		StartDate:   "07-2025",
+	}
+
+	// 1.First request creates subscription
+	id := e.POST("/subscription").
+		WithHeader(handlers.IdempotencyKeyHeader, key).
+		WithJSON(req).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").Number().Raw()
+
+	// 2.Retry gets the same response instead of conflict
+	resp := e.POST("/subscription").
+		WithHeader(handlers.IdempotencyKeyHeader, key).
+		WithJSON(req).
+		Expect().
+		Status(http.StatusCreated)
+
+	resp.Header(handlers.IdempotentReplayedHeader).IsEqual("true")
+	resp.JSON().Object().HasValue("id", id)
+
+	// 3.Retry with another body is rejected
+	req.Price = 300
+
+	e.POST("/subscription").
+		WithHeader(handlers.IdempotencyKeyHeader, key).
+		WithJSON(req).
+		Expect().
+		Status(http.StatusUnprocessableEntity).
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeIdempotencyKeyReused)
+
+	// 4.Request without key is not replayed
+	req.Price = 250
+
+	e.POST("/subscription").
+		WithJSON(req).
+		Expect().
+		Status(http.StatusConflict)
+}
+
+func TestConditionalRead(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	userID := uuid.NewString()
+
+	// 1.Create subscription
+	id := e.POST("/subscription").
+		WithJSON(handlers.CreateRequest{
+			ServiceName: "Conditional Radio",
+			Price:       150,
+			UserID:      userID,
+			StartDate:   "07-2025",
+		}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").Number().Raw()
+
+	path := "/subscription/" + strconv.Itoa(int(id))
+
+	// 2.Read it and get validators
+	resp := e.GET(path).
+		Expect().
+		Status(http.StatusOK)
+
+	etag := resp.Header("ETag").NotEmpty().Raw()
+	lastModified := resp.Header("Last-Modified").NotEmpty().Raw()
+	updatedAt := resp.JSON().Object().Value("updated_at").String().NotEmpty().Raw()
+
+	// 3.Unchanged subscription is not sent again
+	e.GET(path).
+		WithHeader("If-None-Match", etag).
+		Expect().
+		Status(http.StatusNotModified).
+		Body().IsEmpty()
+
+	e.GET(path).
+		WithHeader("If-Modified-Since", lastModified).
+		Expect().
+		Status(http.StatusNotModified)
+
+	// 4.Changed subscription is sent with new validators
+	time.Sleep(time.Second)
+
+	e.PATCH(path).
+		WithHeader("Content-Type", "application/merge-patch+json").
+		WithBytes([]byte(`{"price": "2.00"}`)).
+		Expect().
+		Status(http.StatusOK)
+
+	resp = e.GET(path).
+		WithHeader("If-None-Match", etag).
+		Expect().
+		Status(http.StatusOK)
+
+	resp.Header("ETag").NotEqual(etag)
+	resp.JSON().Object().Value("updated_at").String().NotEqual(updatedAt)
+
+	// 5.List is requested conditionally too (by ETag only, so deletion is noticed)
+	filter := "user_id = " + userID
+
+	e.POST("/subscription").
+		WithJSON(handlers.CreateRequest{ServiceName: "Conditional Music", Price: 150, UserID: userID, StartDate: "07-2025"}).
+		Expect().
+		Status(http.StatusCreated)
+
+	resp = e.GET("/subscriptions").
+		WithQuery("filter", filter).
+		Expect().
+		Status(http.StatusOK)
+
+	resp.Header("Last-Modified").IsEmpty()
+	etag = resp.Header("ETag").NotEmpty().Raw()
+
+	e.GET("/subscriptions").
+		WithQuery("filter", filter).
+		WithHeader("If-None-Match", etag).
+		Expect().
+		Status(http.StatusNotModified)
+
+	e.DELETE(path).
+		Expect().
+		Status(http.StatusOK)
+
+	e.GET("/subscriptions").
+		WithQuery("filter", filter).
+		WithHeader("If-None-Match", etag).
+		WithHeader("If-Modified-Since", lastModified).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("items").Array().Length().IsEqual(1)
+}
+
+func TestSparseFields(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	// 1.Create subscription
+	id := e.POST("/subscription").
+		WithJSON(handlers.CreateRequest{
+			ServiceName: "Sparse Radio",
+			Price:       250,
+			UserID:      uuid.NewString(),
+			StartDate:   "07-2025",
+		}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().Value("id").Number().Raw()
+
+	// 2.Read requested fields only
+	e.GET("/subscription/"+strconv.Itoa(int(id))).
+		WithQuery("fields", "id,service_name,price").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().IsEqual(map[string]any{
+		"id":           id,
+		"service_name": "Sparse Radio",
+		"price":        "2.50",
+	})
+
+	// 3.List items have requested fields only
+	items := e.GET("/subscriptions").
+		WithQuery("fields", "id,price").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("items").Array()
+
+	items.NotEmpty()
+	items.Value(0).Object().Keys().ContainsOnly("id", "price")
+
+	// 4.Unknown field is rejected
+	e.GET("/subscriptions").
+		WithQuery("fields", "id,colour").
+		Expect().
+		Status(http.StatusBadRequest).
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeValidationFailed).
+		HasValue("field", "fields").
+		Value("detail").String().Contains("allowed fields: id, service_name, price")
+}
+
+func TestFilterExpression(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+	userID := uuid.NewString()
+
+	// 1.Create subscriptions of one user
+	for _, req := range []handlers.CreateRequest{
+		{ServiceName: "Filter Cinema", Price: 500, UserID: userID, StartDate: "01-2025", EndDate: "03-2025"},
+		{ServiceName: "Filter Books", Price: 200, UserID: userID, StartDate: "02-2025", EndDate: "12-2025"},
+		{ServiceName: "Other Games", Price: 900, UserID: userID, StartDate: "08-2025"},
+	} {
+		e.POST("/subscription").
+			WithJSON(req).
+			Expect().
+			Status(http.StatusCreated)
+	}
+
+	// 2.List is filtered by expression
+	items := e.GET("/subscriptions").
+		WithQuery("filter", `user_id = `+userID+` and service_name ~ "filter*" and (price >= 300 or active_in(2025-06, 2025-07))`).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("items").Array()
+
+	items.Length().IsEqual(2)
+	items.Value(0).Object().HasValue("service_name", "Filter Cinema")
+	items.Value(1).Object().HasValue("service_name", "Filter Books")
+
+	// 3.Total cost is filtered by expression
+	var resp handlers.TotalCostResponse
+
+	e.GET("/subscriptions/total-cost").
+		WithQuery("start_date", "12-2024").
+		WithQuery("end_date", "01-2026").
+		WithQuery("filter", `user_id = `+userID+` and service_name ~ "filter*" and not service_name = "Filter Books"`).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Decode(&resp)
+
+	assert.Equal(t, model.Money(1000), resp.TotalCost)
+
+	// 4.Invalid expression is rejected with position
+	e.GET("/subscriptions").
+		WithQuery("filter", "price >= 300 and colour = red").
+		Expect().
+		Status(http.StatusBadRequest).
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeInvalidValue).
+		HasValue("field", "filter").
+		Value("detail").String().Contains(`unknown field "colour"`).Contains("at position 18")
+}
+
+func TestSearch(t *testing.T) {
+	e := httpexpect.Default(t, u.String())
+
+	// 1.Create subscriptions with unusual names
+	ids := make([]float64, 0, 2)
+	for _, name := range []string{"Quokkaflix Premium", "Quokkaflix Kids"} {
+		id := e.POST("/subscription").
+			WithJSON(handlers.CreateRequest{
+				ServiceName: name,
+				Price:       300,
+				UserID:      uuid.NewString(),
+				StartDate:   "07-2025",
+			}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().Object().Value("id").Number().Raw()
+
+		ids = append(ids, id)
+	}
+
+	// 2.Misspelled name is found, the closest first
+	items := e.GET("/subscriptions/search").
+		WithQuery("q", "quokaflix kidz").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("items").Array()
+
+	items.Length().IsEqual(2)
+	items.Value(0).Object().HasValue("id", ids[1])
+	items.Value(1).Object().HasValue("id", ids[0])
+
+	e.GET("/subscriptions/search").
+		WithQuery("q", "QUOKAFLIX").
+		WithQuery("limit", 1).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().Value("items").Array().Length().IsEqual(1)
+
+	// 3.Query is required
+	e.GET("/subscriptions/search").
+		Expect().
+		Status(http.StatusBadRequest).
+		JSON(problemJSON).Object().
+		HasValue("code", handlers.CodeMissingValue).
+		HasValue("field", "q")
 }